@@ -15,6 +15,7 @@ import (
 	"github.com/dushixiang/pika/internal/migrate"
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/scheduler"
+	"github.com/dushixiang/pika/internal/service"
 	"github.com/dushixiang/pika/pkg/replace"
 	"github.com/dushixiang/pika/pkg/version"
 	"github.com/dushixiang/pika/web"
@@ -91,6 +92,11 @@ func setup(app *orz.App) error {
 	// 启动指标监控任务（用于告警检测）
 	go startMetricsMonitoring(ctx, components, app.Logger())
 
+	// 启动告警分组通知和摘要发送任务
+	go startAlertGroupFlush(ctx, components, app.Logger())
+	// 启动通知发送任务（发件箱）
+	go components.NotificationOutboxService.Run(ctx)
+
 	// 启动服务监控任务调度器
 	monitorScheduler := scheduler.NewMonitorScheduler(components.MonitorService, app.Logger())
 	// 将调度器注入到 MonitorService（避免循环依赖）
@@ -104,6 +110,8 @@ func setup(app *orz.App) error {
 	go components.DDNSService.Run(ctx)
 	// 启动公网 IP 采集定时任务
 	go components.PublicIPService.Run(ctx)
+	// 启动可用性报告定时任务
+	go components.SLAService.Run(ctx)
 
 	// 设置API
 	setupApi(app, components)
@@ -189,6 +197,29 @@ func setupApi(app *orz.App, components *AppComponents) {
 		publicApi.GET("/agent/version", components.AgentHandler.GetAgentVersion)
 		publicApi.GET("/agent/downloads/:filename", components.AgentHandler.DownloadAgent)
 		publicApi.GET("/agent/install.sh", components.AgentHandler.GetInstallScript)
+
+		// 推送监控心跳上报（通过令牌识别，无需认证）
+		publicApi.GET("/push/:token", components.MonitorHandler.Push)
+		publicApi.POST("/push/:token", components.MonitorHandler.Push)
+
+		// 状态页（公开访问）
+		publicApi.GET("/status-pages/:slug", components.StatusPageHandler.GetPublicStatus)
+		publicApi.GET("/status-pages/:slug/history", components.StatusPageHandler.GetPublicHistory)
+		publicApi.GET("/status-pages/:slug/feed.rss", components.StatusPageHandler.GetRSSFeed)
+		publicApi.GET("/status-pages/:slug/feed.atom", components.StatusPageHandler.GetAtomFeed)
+		// 状态页（通过自定义域名访问，按 Host 请求头匹配）
+		publicApi.GET("/status-page", components.StatusPageHandler.GetPublicStatus)
+		publicApi.GET("/status-page/history", components.StatusPageHandler.GetPublicHistory)
+		publicApi.GET("/status-page/feed.rss", components.StatusPageHandler.GetRSSFeed)
+		publicApi.GET("/status-page/feed.atom", components.StatusPageHandler.GetAtomFeed)
+
+		// 状态徽章（仅公开可见的监控项和探针）
+		publicApi.GET("/badges/monitors/:id/status", components.BadgeHandler.MonitorStatus)
+		publicApi.GET("/badges/monitors/:id/uptime", components.BadgeHandler.MonitorUptime)
+		publicApi.GET("/badges/monitors/:id/response-time", components.BadgeHandler.MonitorResponseTime)
+		publicApi.GET("/badges/monitors/:id/cert", components.BadgeHandler.MonitorCert)
+		publicApi.GET("/badges/agents/:id/status", components.BadgeHandler.AgentStatus)
+		publicApi.GET("/badges/agents/:id/uptime", components.BadgeHandler.AgentUptime)
 	}
 
 	// 公开接口（支持可选认证）- 已登录返回全部数据，未登录只返回公开数据
@@ -246,6 +277,7 @@ func setupApi(app *orz.App, components *AppComponents) {
 		adminApi.GET("/agents/tags", components.AgentHandler.GetTags)
 		adminApi.GET("/agents/:id", components.AgentHandler.GetForAdmin)
 		adminApi.GET("/agents/:id/metrics/latest", components.AgentHandler.GetAdminLatestMetrics)
+		adminApi.GET("/agents/:id/alert-policy", components.AlertPolicyHandler.GetAgentEffective)
 		adminApi.PUT("/agents/:id", components.AgentHandler.UpdateInfo)
 		adminApi.POST("/agents/batch/tags", components.AgentHandler.BatchUpdateTags)
 		adminApi.POST("/agents/batch/visibility", components.AgentHandler.BatchUpdateVisibility)
@@ -279,17 +311,91 @@ func setupApi(app *orz.App, components *AppComponents) {
 
 		// 通知渠道测试（从数据库读取配置测试）
 		adminApi.POST("/notification-channels/:type/test", components.PropertyHandler.TestNotificationChannel)
+		adminApi.POST("/notification-routing/dry-run", components.PropertyHandler.DryRunNotificationRouting)
+		adminApi.GET("/notification-templates/variables", components.PropertyHandler.GetMessageTemplateVariables)
+		adminApi.POST("/notification-templates/preview", components.PropertyHandler.PreviewMessageTemplate)
 
 		// 告警记录查询
 		adminApi.GET("/alert-records", components.AlertHandler.ListAlertRecords)
 		adminApi.DELETE("/alert-records", components.AlertHandler.ClearAlertRecords)
 
+		// 告警策略管理
+		adminApi.GET("/alert-policies", components.AlertPolicyHandler.Paging)
+		adminApi.POST("/alert-policies", components.AlertPolicyHandler.Create)
+		adminApi.GET("/alert-policies/:id", components.AlertPolicyHandler.Get)
+		adminApi.PUT("/alert-policies/:id", components.AlertPolicyHandler.Update)
+		adminApi.DELETE("/alert-policies/:id", components.AlertPolicyHandler.Delete)
+		adminApi.GET("/alert-policies/:id/effective", components.AlertPolicyHandler.GetEffective)
+
+		// 表达式告警规则管理
+		adminApi.GET("/alert-rules", components.AlertRuleHandler.Paging)
+		adminApi.POST("/alert-rules", components.AlertRuleHandler.Create)
+		adminApi.POST("/alert-rules/preview", components.AlertRuleHandler.Preview)
+		adminApi.GET("/alert-rules/:id", components.AlertRuleHandler.Get)
+		adminApi.PUT("/alert-rules/:id", components.AlertRuleHandler.Update)
+		adminApi.DELETE("/alert-rules/:id", components.AlertRuleHandler.Delete)
+
+		// 告警静默管理
+		adminApi.GET("/silences", components.SilenceHandler.List)
+		adminApi.POST("/silences", components.SilenceHandler.Create)
+		adminApi.GET("/silences/:id", components.SilenceHandler.Get)
+		adminApi.PUT("/silences/:id", components.SilenceHandler.Update)
+		adminApi.DELETE("/silences/:id", components.SilenceHandler.Delete)
+		adminApi.POST("/silences/:id/expire", components.SilenceHandler.Expire)
+		adminApi.GET("/silences/:id/alerts", components.SilenceHandler.ListSuppressedAlerts)
+
+		// 告警升级策略管理
+		adminApi.GET("/escalation-policies", components.EscalationHandler.Paging)
+		adminApi.POST("/escalation-policies", components.EscalationHandler.Create)
+		adminApi.GET("/escalation-policies/:id", components.EscalationHandler.Get)
+		adminApi.PUT("/escalation-policies/:id", components.EscalationHandler.Update)
+		adminApi.DELETE("/escalation-policies/:id", components.EscalationHandler.Delete)
+
+		// 通知发送记录
+		adminApi.GET("/notification-deliveries", components.NotificationDeliveryHandler.Paging)
+		adminApi.GET("/notification-deliveries/:id", components.NotificationDeliveryHandler.Get)
+		adminApi.POST("/notification-deliveries/:id/resend", components.NotificationDeliveryHandler.Resend)
+
+		// 告警事件管理
+		adminApi.GET("/incidents", components.IncidentHandler.Paging)
+		adminApi.GET("/incidents/:id", components.IncidentHandler.Get)
+		adminApi.POST("/incidents/:id/ack", components.IncidentHandler.Acknowledge)
+		adminApi.POST("/incidents/:id/resolve", components.IncidentHandler.Resolve)
+		adminApi.POST("/incidents/:id/comments", components.IncidentHandler.AddComment)
+
 		// 服务监控配置
 		adminApi.GET("/monitors", components.MonitorHandler.List)
 		adminApi.POST("/monitors", components.MonitorHandler.Create)
 		adminApi.GET("/monitors/:id", components.MonitorHandler.Get)
 		adminApi.PUT("/monitors/:id", components.MonitorHandler.Update)
 		adminApi.DELETE("/monitors/:id", components.MonitorHandler.Delete)
+		adminApi.POST("/monitors/:id/push-token/reset", components.MonitorHandler.ResetPushToken)
+
+		// 维护窗口管理
+		adminApi.GET("/maintenance-windows", components.MaintenanceHandler.Paging)
+		adminApi.GET("/maintenance-windows/active", components.MaintenanceHandler.ListActive)
+		adminApi.POST("/maintenance-windows", components.MaintenanceHandler.Create)
+		adminApi.GET("/maintenance-windows/:id", components.MaintenanceHandler.Get)
+		adminApi.PUT("/maintenance-windows/:id", components.MaintenanceHandler.Update)
+		adminApi.DELETE("/maintenance-windows/:id", components.MaintenanceHandler.Delete)
+
+		// 可用性报告
+		adminApi.GET("/reports/monitors", components.SLAHandler.MonitorReports)
+		adminApi.GET("/reports/monitors/:id", components.SLAHandler.MonitorReport)
+		adminApi.GET("/reports/agents", components.SLAHandler.AgentReports)
+		adminApi.GET("/reports/agents/:id", components.SLAHandler.AgentReport)
+
+		// 状态页管理
+		adminApi.GET("/status-pages", components.StatusPageHandler.Paging)
+		adminApi.POST("/status-pages", components.StatusPageHandler.Create)
+		adminApi.GET("/status-pages/:id", components.StatusPageHandler.Get)
+		adminApi.PUT("/status-pages/:id", components.StatusPageHandler.Update)
+		adminApi.DELETE("/status-pages/:id", components.StatusPageHandler.Delete)
+		adminApi.GET("/status-pages/:id/incidents", components.StatusPageHandler.ListIncidents)
+		adminApi.POST("/status-pages/:id/incidents", components.StatusPageHandler.CreateIncident)
+		adminApi.PUT("/status-page-incidents/:id", components.StatusPageHandler.UpdateIncident)
+		adminApi.DELETE("/status-page-incidents/:id", components.StatusPageHandler.DeleteIncident)
+		adminApi.POST("/status-page-incidents/:id/updates", components.StatusPageHandler.AddIncidentUpdate)
 
 		// DNS Provider 管理
 		adminApi.GET("/dns-providers", components.DNSProviderHandler.GetAll)
@@ -318,24 +424,43 @@ func setupApi(app *orz.App, components *AppComponents) {
 func autoMigrate(database *gorm.DB) error {
 	// 自动迁移数据库表
 	return database.AutoMigrate(
-		&models.Agent{},         // 探针
-		&models.ApiKey{},        // ApiKey
-		&models.AuditResult{},   // 审计历史
-		&models.Property{},      // 系统属性
-		&models.AlertRecord{},   // 告警记录
-		&models.AlertState{},    // 告警状态
-		&models.MonitorTask{},   // 服务监控
-		&models.TamperEvent{},   // 防篡改事件
-		&models.DDNSConfig{},    // DDNS 配置
-		&models.DDNSRecord{},    // DDNS 记录
-		&models.SSHLoginEvent{}, // SSH 登录事件
+		&models.Agent{},                    // 探针
+		&models.ApiKey{},                   // ApiKey
+		&models.AuditResult{},              // 审计历史
+		&models.Property{},                 // 系统属性
+		&models.AlertRecord{},              // 告警记录
+		&models.AlertState{},               // 告警状态
+		&models.MonitorTask{},              // 服务监控
+		&models.TamperEvent{},              // 防篡改事件
+		&models.DDNSConfig{},               // DDNS 配置
+		&models.DDNSRecord{},               // DDNS 记录
+		&models.SSHLoginEvent{},            // SSH 登录事件
+		&models.MaintenanceWindow{},        // 维护窗口
+		&models.StatusPage{},               // 状态页
+		&models.StatusPageIncident{},       // 状态页事件
+		&models.StatusPageIncidentUpdate{}, // 状态页事件进展
+		&models.AgentStatusEvent{},         // 探针上下线事件
+		&models.Incident{},                 // 告警事件
+		&models.IncidentEvent{},            // 告警事件时间线
+		&models.AlertPolicy{},              // 告警策略
+		&models.AlertRule{},                // 表达式告警规则
+		&models.Silence{},                  // 告警静默
+		&models.AlertRecordSilence{},       // 告警静默抑制历史
+		&models.EscalationPolicy{},         // 告警升级策略
+		&models.AlertEscalation{},          // 待执行的告警升级
+		&models.NotificationDelivery{},     // 通知发件箱
+		&models.NotificationAttempt{},      // 通知发送尝试记录
 	)
 }
 
 // initDefaultProperties 初始化默认属性配置
 func initDefaultProperties(ctx context.Context, components *AppComponents, logger *zap.Logger) error {
 	// 使用 PropertyService 的初始化方法
-	return components.PropertyService.InitializeDefaultConfigs(ctx)
+	if err := components.PropertyService.InitializeDefaultConfigs(ctx); err != nil {
+		return err
+	}
+	// 确保默认告警策略存在（升级时由 migrate 从旧的全局告警规则迁移）
+	return components.AlertPolicyService.EnsureDefaultPolicy(ctx)
 }
 
 func ErrorHandler(logger *zap.Logger) func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -403,36 +528,61 @@ func startMetricsMonitoring(ctx context.Context, components *AppComponents, logg
 					continue
 				}
 
-				// 提取 CPU、内存、磁盘使用率、网速
-				var cpuUsage, memoryUsage, diskUsage, networkSpeed float64
-
-				if latest.CPU != nil {
-					cpuUsage = latest.CPU.UsagePercent
-				}
-
-				if latest.Memory != nil {
-					memoryUsage = latest.Memory.UsagePercent
-				}
-
-				if latest.Disk != nil {
-					diskUsage = latest.Disk.UsagePercent
-				}
-
-				if latest.Network != nil {
-					// 网速 = (发送速率 + 接收速率) / 1024 / 1024 (转换为 MB/s)
-					networkSpeed = float64(latest.Network.TotalBytesSentRate+latest.Network.TotalBytesRecvRate) / 1024 / 1024
-				}
-
 				// 检查告警规则
-				if err := components.AlertService.CheckMetrics(ctx, agent.ID, cpuUsage, memoryUsage, diskUsage, networkSpeed); err != nil {
+				if err := components.AlertService.CheckMetrics(ctx, agent.ID, service.NewAlertMetricValues(latest)); err != nil {
 					logger.Error("检查告警规则失败", zap.String("agentId", agent.ID), zap.Error(err))
 				}
 			}
 
+			// 检查推送监控心跳是否超时
+			if err := components.MonitorService.CheckPushMonitors(ctx); err != nil {
+				logger.Error("检查推送监控失败", zap.Error(err))
+			}
+
 			// 检查监控相关告警（证书和服务下线）
 			if err := components.AlertService.CheckMonitorAlerts(ctx); err != nil {
 				logger.Error("检查监控告警失败", zap.Error(err))
 			}
+
+			// 评估表达式告警规则
+			if err := components.AlertService.CheckExpressionRules(ctx); err != nil {
+				logger.Error("评估表达式告警规则失败", zap.Error(err))
+			}
+
+			// 静默结束后仍在告警中的记录补发通知
+			if err := components.AlertService.CheckSilencedAlerts(ctx); err != nil {
+				logger.Error("检查静默告警失败", zap.Error(err))
+			}
+
+			// 执行到期的告警升级
+			if err := components.AlertService.CheckEscalations(ctx); err != nil {
+				logger.Error("检查告警升级失败", zap.Error(err))
+			}
+
+			// 对未确认的告警事件重复发送通知
+			if err := components.AlertService.CheckRepeatNotifications(ctx); err != nil {
+				logger.Error("检查重复通知失败", zap.Error(err))
+			}
+		}
+	}
+}
+
+// startAlertGroupFlush 启动告警分组通知和摘要发送任务
+func startAlertGroupFlush(ctx context.Context, components *AppComponents, logger *zap.Logger) {
+	logger.Info("启动告警分组通知任务")
+
+	ticker := time.NewTicker(5 * time.Second) // 每5秒检查一次，保证分组等待时间的精度
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("告警分组通知任务已停止")
+			return
+		case <-ticker.C:
+			if err := components.AlertService.FlushAlertGroups(ctx); err != nil {
+				logger.Error("发送告警分组通知失败", zap.Error(err))
+			}
 		}
 	}
 }
@@ -0,0 +1,108 @@
+package badge
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const (
+	StyleFlat       = "flat"
+	StyleFlatSquare = "flat-square"
+	StylePlastic    = "plastic"
+)
+
+// 常用颜色
+const (
+	ColorBrightGreen = "#4c1"
+	ColorGreen       = "#97ca00"
+	ColorYellow      = "#dfb317"
+	ColorOrange      = "#fe7d37"
+	ColorRed         = "#e05d44"
+	ColorBlue        = "#007ec6"
+	ColorGrey        = "#9f9f9f"
+)
+
+// Badge 徽章内容
+type Badge struct {
+	Label   string // 左侧文字
+	Message string // 右侧文字
+	Color   string // 右侧背景色
+}
+
+type renderData struct {
+	Label, Message, Color string
+	Width, LabelWidth     int
+	MessageWidth          int
+	LabelX, MessageX      int // 文字中心位置（放大 10 倍，配合 scale(.1) 使用以获得更精确的定位）
+	LabelLength           int
+	MessageLength         int
+}
+
+var templates = map[string]*template.Template{
+	StyleFlat:       template.Must(template.New(StyleFlat).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}"><title>{{.Label}}: {{.Message}}</title><linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath><g clip-path="url(#r)"><rect width="{{.LabelWidth}}" height="20" fill="#555"/><rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/><rect width="{{.Width}}" height="20" fill="url(#s)"/></g><g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="110"><text aria-hidden="true" x="{{.LabelX}}" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.LabelLength}}">{{.Label}}</text><text x="{{.LabelX}}" y="140" transform="scale(.1)" fill="#fff" textLength="{{.LabelLength}}">{{.Label}}</text><text aria-hidden="true" x="{{.MessageX}}" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.MessageLength}}">{{.Message}}</text><text x="{{.MessageX}}" y="140" transform="scale(.1)" fill="#fff" textLength="{{.MessageLength}}">{{.Message}}</text></g></svg>`)),
+	StyleFlatSquare: template.Must(template.New(StyleFlatSquare).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}"><title>{{.Label}}: {{.Message}}</title><g shape-rendering="crispEdges"><rect width="{{.LabelWidth}}" height="20" fill="#555"/><rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/></g><g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="110"><text x="{{.LabelX}}" y="140" transform="scale(.1)" fill="#fff" textLength="{{.LabelLength}}">{{.Label}}</text><text x="{{.MessageX}}" y="140" transform="scale(.1)" fill="#fff" textLength="{{.MessageLength}}">{{.Message}}</text></g></svg>`)),
+	StylePlastic:    template.Must(template.New(StylePlastic).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="18" role="img" aria-label="{{.Label}}: {{.Message}}"><title>{{.Label}}: {{.Message}}</title><linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/><stop offset=".9" stop-color="#000" stop-opacity=".3"/><stop offset="1" stop-color="#000" stop-opacity=".5"/></linearGradient><clipPath id="r"><rect width="{{.Width}}" height="18" rx="4" fill="#fff"/></clipPath><g clip-path="url(#r)"><rect width="{{.LabelWidth}}" height="18" fill="#555"/><rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="18" fill="{{.Color}}"/><rect width="{{.Width}}" height="18" fill="url(#s)"/></g><g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="110"><text aria-hidden="true" x="{{.LabelX}}" y="140" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.LabelLength}}">{{.Label}}</text><text x="{{.LabelX}}" y="130" transform="scale(.1)" fill="#fff" textLength="{{.LabelLength}}">{{.Label}}</text><text aria-hidden="true" x="{{.MessageX}}" y="140" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.MessageLength}}">{{.Message}}</text><text x="{{.MessageX}}" y="130" transform="scale(.1)" fill="#fff" textLength="{{.MessageLength}}">{{.Message}}</text></g></svg>`)),
+}
+
+// ValidStyle 是否为支持的样式
+func ValidStyle(style string) bool {
+	_, ok := templates[style]
+	return ok
+}
+
+// Render 渲染 SVG 徽章，不支持的样式按 flat 渲染
+func Render(b Badge, style string) []byte {
+	tmpl, ok := templates[style]
+	if !ok {
+		tmpl = templates[StyleFlat]
+	}
+
+	// 左右两侧各留 5px 内边距
+	labelTextWidth := textWidth(b.Label)
+	messageTextWidth := textWidth(b.Message)
+	labelWidth := labelTextWidth + 10
+	messageWidth := messageTextWidth + 10
+
+	data := renderData{
+		Label:         html.EscapeString(b.Label),
+		Message:       html.EscapeString(b.Message),
+		Color:         html.EscapeString(b.Color),
+		Width:         labelWidth + messageWidth,
+		LabelWidth:    labelWidth,
+		MessageWidth:  messageWidth,
+		LabelX:        labelWidth * 10 / 2,
+		MessageX:      (labelWidth*2 + messageWidth) * 10 / 2,
+		LabelLength:   labelTextWidth * 10,
+		MessageLength: messageTextWidth * 10,
+	}
+
+	var buf bytes.Buffer
+	// 模板与数据均为内部构造，不会执行失败
+	_ = tmpl.Execute(&buf, data)
+	return buf.Bytes()
+}
+
+// textWidth 估算 11px Verdana 字体下文字的宽度（像素）
+func textWidth(text string) int {
+	var width float64
+	for _, r := range text {
+		switch {
+		case r > unicode.MaxASCII:
+			width += 11
+		case strings.ContainsRune("iljI.,:;'|!()[] ", r):
+			width += 4
+		case strings.ContainsRune("mwMW@%", r):
+			width += 10
+		case unicode.IsUpper(r):
+			width += 7.5
+		case unicode.IsDigit(r):
+			width += 7
+		default:
+			width += 6.5
+		}
+	}
+	return int(width + 0.5)
+}
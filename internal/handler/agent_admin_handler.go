@@ -9,6 +9,7 @@ import (
 
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/internal/service"
 	"github.com/go-orz/orz"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
@@ -56,7 +57,19 @@ func (h *AgentHandler) GetForAdmin(c echo.Context) error {
 		return err
 	}
 
-	return orz.Ok(c, agent)
+	// 预测失败不影响探针详情
+	forecast, err := h.forecastService.GetAgentForecast(ctx, agent)
+	if err != nil {
+		h.logger.Warn("failed to forecast agent capacity", zap.String("agentId", id), zap.Error(err))
+	}
+
+	return orz.Ok(c, agentDetail{Agent: agent, Forecast: forecast})
+}
+
+// agentDetail 探针详情，附带磁盘写满和流量超额预测
+type agentDetail struct {
+	*models.Agent
+	Forecast *service.AgentForecast `json:"forecast,omitempty"`
 }
 
 // GetAdminLatestMetrics 获取探针最新指标（管理员接口，显示完整信息）
@@ -20,12 +20,16 @@ type AgentHandler struct {
 	propertyService *service.PropertyService
 	wsManager       *ws.Manager
 	upgrader        websocket.Upgrader
+
+	maintenanceService *service.MaintenanceService
+	forecastService    *service.ForecastService
 }
 
 func NewAgentHandler(logger *zap.Logger, agentService *service.AgentService, trafficService *service.TrafficService,
 	metricService *service.MetricService, monitorService *service.MonitorService, tamperService *service.TamperService,
 	ddnsService *service.DDNSService, sshLoginService *service.SSHLoginService, apiKeyService *service.ApiKeyService,
-	propertyService *service.PropertyService, wsManager *ws.Manager) *AgentHandler {
+	propertyService *service.PropertyService, wsManager *ws.Manager, maintenanceService *service.MaintenanceService,
+	forecastService *service.ForecastService) *AgentHandler {
 
 	h := &AgentHandler{
 		logger:          logger,
@@ -39,6 +43,9 @@ func NewAgentHandler(logger *zap.Logger, agentService *service.AgentService, tra
 		apiKeyService:   apiKeyService,
 		propertyService: propertyService,
 		wsManager:       wsManager,
+
+		maintenanceService: maintenanceService,
+		forecastService:    forecastService,
 	}
 
 	// 初始化upgrader，需要在创建handler之后因为需要引用h.checkOrigin
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/utils"
 	"github.com/go-orz/orz"
@@ -50,13 +52,13 @@ func (h *AgentHandler) GetAgents(c echo.Context) error {
 
 	result := make([]map[string]interface{}, 0, len(agents))
 	for _, agent := range agents {
-		result = append(result, h.buildAgentListItem(agent, isAuthenticated))
+		result = append(result, h.buildAgentListItem(ctx, agent, isAuthenticated))
 	}
 
 	return orz.Ok(c, result)
 }
 
-func (h *AgentHandler) buildAgentListItem(agent models.Agent, isAuthenticated bool) map[string]interface{} {
+func (h *AgentHandler) buildAgentListItem(ctx context.Context, agent models.Agent, isAuthenticated bool) map[string]interface{} {
 	item := map[string]any{
 		"id":         agent.ID,
 		"name":       agent.Name,
@@ -69,6 +71,8 @@ func (h *AgentHandler) buildAgentListItem(agent models.Agent, isAuthenticated bo
 		"lastSeenAt": agent.LastSeenAt,
 		"visibility": agent.Visibility,
 		"weight":     agent.Weight,
+		// 是否处于维护窗口内
+		"maintenance": h.maintenanceService.IsInMaintenance(ctx, &agent, ""),
 	}
 
 	trafficStats := agent.TrafficStats.Data()
@@ -63,6 +63,12 @@ func (h *AgentHandler) HandleWebSocket(c echo.Context) error {
 		// 配置下发失败不中断连接，只记录日志
 	}
 
+	// 下发完整监控配置，由探针自主调度检测
+	if err := h.sendMonitorConfig(conn, agent.ID); err != nil {
+		h.logger.Error("failed to send monitor config", zap.Error(err))
+		// 配置下发失败不中断连接，只记录日志
+	}
+
 	// 创建客户端并注册到管理器
 	client := h.newClient(agent.ID, conn)
 
@@ -299,6 +305,22 @@ func (h *AgentHandler) sendSSHLoginConfig(conn *websocket.Conn, agentID string)
 	return conn.WriteMessage(websocket.TextMessage, msgData)
 }
 
+// sendMonitorConfig 发送探针需要执行的完整监控配置
+func (h *AgentHandler) sendMonitorConfig(conn *websocket.Conn, agentID string) error {
+	payload, err := h.monitorSvc.BuildMonitorConfigForAgent(context.Background(), agentID)
+	if err != nil {
+		return err
+	}
+	msgData, err := json.Marshal(protocol.OutboundMessage{
+		Type: protocol.MessageTypeMonitorConfig,
+		Data: payload,
+	})
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, msgData)
+}
+
 func (h *AgentHandler) sendPublicIPConfig(conn *websocket.Conn, agentID string) error {
 	config, err := h.propertyService.GetPublicIPConfig(context.Background())
 	if err != nil {
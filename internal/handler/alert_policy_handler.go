@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type AlertPolicyHandler struct {
+	logger             *zap.Logger
+	alertPolicyService *service.AlertPolicyService
+	agentService       *service.AgentService
+}
+
+func NewAlertPolicyHandler(logger *zap.Logger, alertPolicyService *service.AlertPolicyService, agentService *service.AgentService) *AlertPolicyHandler {
+	return &AlertPolicyHandler{
+		logger:             logger,
+		alertPolicyService: alertPolicyService,
+		agentService:       agentService,
+	}
+}
+
+// Paging 告警策略分页查询
+func (h *AlertPolicyHandler) Paging(c echo.Context) error {
+	name := c.QueryParam("name")
+
+	pr := orz.GetPageRequest(c, "created_at", "name", "priority")
+
+	builder := orz.NewPageBuilder(h.alertPolicyService.AlertPolicyRepo).
+		PageRequest(pr).
+		Contains("name", name)
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Create 创建告警策略
+func (h *AlertPolicyHandler) Create(c echo.Context) error {
+	var req service.AlertPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateAlertPolicy(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	policy, err := h.alertPolicyService.CreatePolicy(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to create alert policy", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, policy)
+}
+
+// Get 获取告警策略详情
+func (h *AlertPolicyHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	policy, err := h.alertPolicyService.AlertPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, policy)
+}
+
+// Update 更新告警策略
+func (h *AlertPolicyHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.AlertPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateAlertPolicy(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	policy, err := h.alertPolicyService.UpdatePolicy(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update alert policy", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, policy)
+}
+
+// Delete 删除告警策略
+func (h *AlertPolicyHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.alertPolicyService.DeletePolicy(ctx, id); err != nil {
+		h.logger.Error("failed to delete alert policy", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// GetEffective 获取策略按继承关系合并后的规则
+func (h *AlertPolicyHandler) GetEffective(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	effective, err := h.alertPolicyService.ResolvePolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, effective)
+}
+
+// GetAgentEffective 获取探针最终生效的告警策略
+func (h *AlertPolicyHandler) GetAgentEffective(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	agent, err := h.agentService.GetAgent(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	effective, err := h.alertPolicyService.ResolveForAgent(ctx, agent)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, effective)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type AlertRuleHandler struct {
+	logger           *zap.Logger
+	alertRuleService *service.AlertRuleService
+}
+
+func NewAlertRuleHandler(logger *zap.Logger, alertRuleService *service.AlertRuleService) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		logger:           logger,
+		alertRuleService: alertRuleService,
+	}
+}
+
+// Paging 表达式规则分页查询
+func (h *AlertRuleHandler) Paging(c echo.Context) error {
+	name := c.QueryParam("name")
+	enabled := c.QueryParam("enabled")
+
+	pr := orz.GetPageRequest(c, "created_at", "name")
+
+	builder := orz.NewPageBuilder(h.alertRuleService.AlertRuleRepo).
+		PageRequest(pr).
+		Contains("name", name)
+
+	// 处理启用状态筛选
+	if enabled == "true" {
+		builder.Equal("enabled", "1")
+	} else if enabled == "false" {
+		builder.Equal("enabled", "0")
+	}
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Create 创建表达式规则
+func (h *AlertRuleHandler) Create(c echo.Context) error {
+	var req service.AlertRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateAlertRule(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	rule, err := h.alertRuleService.CreateRule(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to create alert rule", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, rule)
+}
+
+// Get 获取表达式规则详情
+func (h *AlertRuleHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	rule, err := h.alertRuleService.AlertRuleRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, rule)
+}
+
+// Update 更新表达式规则
+func (h *AlertRuleHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.AlertRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateAlertRule(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	rule, err := h.alertRuleService.UpdateRule(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update alert rule", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, rule)
+}
+
+// Delete 删除表达式规则
+func (h *AlertRuleHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.alertRuleService.DeleteRule(ctx, id); err != nil {
+		h.logger.Error("failed to delete alert rule", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// Preview 预览表达式当前返回的序列
+func (h *AlertRuleHandler) Preview(c echo.Context) error {
+	var req struct {
+		Expr string `json:"expr"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if strings.TrimSpace(req.Expr) == "" {
+		return orz.NewError(400, "表达式不能为空")
+	}
+
+	ctx := c.Request().Context()
+	samples, err := h.alertRuleService.Query(ctx, req.Expr)
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	return orz.Ok(c, samples)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dushixiang/pika/internal/badge"
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// badgeStatusMaxAge 实时状态类徽章的缓存时间（秒）
+	badgeStatusMaxAge = 60
+	// badgeUptimeMaxAge 可用率和证书徽章的缓存时间（秒）
+	badgeUptimeMaxAge = 300
+	// defaultBadgeUptimeDays 可用率徽章默认统计天数
+	defaultBadgeUptimeDays = 30
+)
+
+type BadgeHandler struct {
+	logger       *zap.Logger
+	badgeService *service.BadgeService
+}
+
+func NewBadgeHandler(logger *zap.Logger, badgeService *service.BadgeService) *BadgeHandler {
+	return &BadgeHandler{
+		logger:       logger,
+		badgeService: badgeService,
+	}
+}
+
+// writeBadge 渲染徽章并设置缓存头，查询失败时同样返回 SVG，便于在 README 中直接展示
+func (h *BadgeHandler) writeBadge(c echo.Context, maxAge int, b badge.Badge, err error) error {
+	style := c.QueryParam("style")
+	if style != "" && !badge.ValidStyle(style) {
+		return orz.NewError(400, "样式只能是 flat、flat-square 或 plastic")
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		var orzErr *orz.Error
+		if errors.As(err, &orzErr) && orzErr.Code == http.StatusNotFound {
+			status = http.StatusNotFound
+			b = badge.Badge{Label: "pika", Message: "not found", Color: badge.ColorGrey}
+		} else {
+			h.logger.Error("failed to render badge", zap.Error(err))
+			status = http.StatusInternalServerError
+			b = badge.Badge{Label: "pika", Message: "error", Color: badge.ColorGrey}
+		}
+		maxAge = 0
+	}
+
+	data := badge.Render(b, style)
+	sum := sha1.Sum(data)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	header := c.Response().Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, s-maxage=%d", maxAge, maxAge))
+	header.Set("ETag", etag)
+	if status == http.StatusOK && c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(status, "image/svg+xml; charset=utf-8", data)
+}
+
+// parseBadgeDays 解析可用率统计天数，默认 30 天
+func parseBadgeDays(c echo.Context) (int, error) {
+	daysParam := c.QueryParam("days")
+	if daysParam == "" {
+		return defaultBadgeUptimeDays, nil
+	}
+	days, err := strconv.Atoi(daysParam)
+	if err != nil || days < 1 || days > maxReportRangeDays {
+		return 0, orz.NewError(400, fmt.Sprintf("天数必须在 1 ~ %d 之间", maxReportRangeDays))
+	}
+	return days, nil
+}
+
+// MonitorStatus 监控项状态徽章
+func (h *BadgeHandler) MonitorStatus(c echo.Context) error {
+	b, err := h.badgeService.MonitorStatusBadge(c.Request().Context(), c.Param("id"), c.QueryParam("label"))
+	return h.writeBadge(c, badgeStatusMaxAge, b, err)
+}
+
+// MonitorUptime 监控项可用率徽章
+func (h *BadgeHandler) MonitorUptime(c echo.Context) error {
+	return h.uptime(c, h.badgeService.MonitorUptimeBadge)
+}
+
+// MonitorResponseTime 监控项响应时间徽章
+func (h *BadgeHandler) MonitorResponseTime(c echo.Context) error {
+	b, err := h.badgeService.MonitorResponseTimeBadge(c.Request().Context(), c.Param("id"), c.QueryParam("label"))
+	return h.writeBadge(c, badgeStatusMaxAge, b, err)
+}
+
+// MonitorCert 监控项证书剩余天数徽章
+func (h *BadgeHandler) MonitorCert(c echo.Context) error {
+	b, err := h.badgeService.MonitorCertBadge(c.Request().Context(), c.Param("id"), c.QueryParam("label"))
+	return h.writeBadge(c, badgeUptimeMaxAge, b, err)
+}
+
+// AgentStatus 探针状态徽章
+func (h *BadgeHandler) AgentStatus(c echo.Context) error {
+	b, err := h.badgeService.AgentStatusBadge(c.Request().Context(), c.Param("id"), c.QueryParam("label"))
+	return h.writeBadge(c, badgeStatusMaxAge, b, err)
+}
+
+// AgentUptime 探针在线率徽章
+func (h *BadgeHandler) AgentUptime(c echo.Context) error {
+	return h.uptime(c, h.badgeService.AgentUptimeBadge)
+}
+
+func (h *BadgeHandler) uptime(c echo.Context, build func(ctx context.Context, id, label string, days int) (badge.Badge, error)) error {
+	days, err := parseBadgeDays(c)
+	if err != nil {
+		return err
+	}
+	b, err := build(c.Request().Context(), c.Param("id"), c.QueryParam("label"), days)
+	return h.writeBadge(c, badgeUptimeMaxAge, b, err)
+}
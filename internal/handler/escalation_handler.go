@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type EscalationHandler struct {
+	logger            *zap.Logger
+	escalationService *service.EscalationService
+}
+
+func NewEscalationHandler(logger *zap.Logger, escalationService *service.EscalationService) *EscalationHandler {
+	return &EscalationHandler{
+		logger:            logger,
+		escalationService: escalationService,
+	}
+}
+
+// Paging 升级策略分页查询
+func (h *EscalationHandler) Paging(c echo.Context) error {
+	name := c.QueryParam("name")
+
+	pr := orz.GetPageRequest(c, "created_at", "name")
+
+	builder := orz.NewPageBuilder(h.escalationService.EscalationPolicyRepo).
+		PageRequest(pr).
+		Contains("name", name)
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Create 创建升级策略
+func (h *EscalationHandler) Create(c echo.Context) error {
+	var req service.EscalationPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateEscalationPolicy(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	policy, err := h.escalationService.CreatePolicy(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to create escalation policy", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, policy)
+}
+
+// Get 获取升级策略详情
+func (h *EscalationHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	policy, err := h.escalationService.EscalationPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, policy)
+}
+
+// Update 更新升级策略
+func (h *EscalationHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.EscalationPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateEscalationPolicy(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	policy, err := h.escalationService.UpdatePolicy(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update escalation policy", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, policy)
+}
+
+// Delete 删除升级策略
+func (h *EscalationHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.escalationService.DeletePolicy(ctx, id); err != nil {
+		h.logger.Error("failed to delete escalation policy", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type IncidentHandler struct {
+	logger          *zap.Logger
+	incidentService *service.IncidentService
+}
+
+func NewIncidentHandler(logger *zap.Logger, incidentService *service.IncidentService) *IncidentHandler {
+	return &IncidentHandler{
+		logger:          logger,
+		incidentService: incidentService,
+	}
+}
+
+// IncidentActionRequest 确认/解决事件请求
+type IncidentActionRequest struct {
+	Note string `json:"note"` // 备注
+}
+
+// IncidentCommentRequest 评论请求
+type IncidentCommentRequest struct {
+	Content string `json:"content"` // 评论内容
+}
+
+func parseIncidentID(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, orz.NewError(400, "事件ID格式错误")
+	}
+	return id, nil
+}
+
+// currentUsername 当前登录用户名（由 JWT 中间件设置）
+func currentUsername(c echo.Context) string {
+	username, _ := c.Get("username").(string)
+	return username
+}
+
+// Paging 告警事件分页查询
+func (h *IncidentHandler) Paging(c echo.Context) error {
+	filter := repo.IncidentFilter{
+		State:     c.QueryParam("state"),
+		AgentID:   c.QueryParam("agentId"),
+		AlertType: c.QueryParam("alertType"),
+		Level:     c.QueryParam("level"),
+		Keyword:   c.QueryParam("keyword"),
+	}
+	if start := c.QueryParam("start"); start != "" {
+		v, err := strconv.ParseInt(start, 10, 64)
+		if err != nil {
+			return orz.NewError(400, "开始时间格式错误")
+		}
+		filter.Start = v
+	}
+	if end := c.QueryParam("end"); end != "" {
+		v, err := strconv.ParseInt(end, 10, 64)
+		if err != nil {
+			return orz.NewError(400, "结束时间格式错误")
+		}
+		filter.End = v
+	}
+
+	pr := orz.GetPageRequest(c)
+
+	ctx := c.Request().Context()
+	items, total, err := h.incidentService.IncidentRepo.FindPage(ctx, filter, pr.PageIndex, pr.PageSize)
+	if err != nil {
+		h.logger.Error("failed to list incidents", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.NewPageResult(items, total))
+}
+
+// Get 获取告警事件详情及时间线
+func (h *IncidentHandler) Get(c echo.Context) error {
+	id, err := parseIncidentID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	detail, err := h.incidentService.GetDetail(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, detail)
+}
+
+// Acknowledge 确认告警事件，确认后停止重复通知
+func (h *IncidentHandler) Acknowledge(c echo.Context) error {
+	id, err := parseIncidentID(c)
+	if err != nil {
+		return err
+	}
+
+	var req IncidentActionRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	ctx := c.Request().Context()
+	incident, err := h.incidentService.Acknowledge(ctx, id, currentUsername(c), req.Note)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, incident)
+}
+
+// Resolve 手动解决告警事件
+func (h *IncidentHandler) Resolve(c echo.Context) error {
+	id, err := parseIncidentID(c)
+	if err != nil {
+		return err
+	}
+
+	var req IncidentActionRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	ctx := c.Request().Context()
+	incident, err := h.incidentService.Resolve(ctx, id, currentUsername(c), req.Note)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, incident)
+}
+
+// AddComment 添加评论
+func (h *IncidentHandler) AddComment(c echo.Context) error {
+	id, err := parseIncidentID(c)
+	if err != nil {
+		return err
+	}
+
+	var req IncidentCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.incidentService.AddComment(ctx, id, currentUsername(c), req.Content); err != nil {
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{
+		"message": "评论成功",
+	})
+}
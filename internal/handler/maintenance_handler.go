@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type MaintenanceHandler struct {
+	logger             *zap.Logger
+	maintenanceService *service.MaintenanceService
+}
+
+func NewMaintenanceHandler(logger *zap.Logger, maintenanceService *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		logger:             logger,
+		maintenanceService: maintenanceService,
+	}
+}
+
+// Paging 维护窗口分页查询
+func (h *MaintenanceHandler) Paging(c echo.Context) error {
+	name := c.QueryParam("name")
+	enabled := c.QueryParam("enabled")
+
+	pr := orz.GetPageRequest(c, "created_at", "name")
+
+	builder := orz.NewPageBuilder(h.maintenanceService.MaintenanceRepo).
+		PageRequest(pr).
+		Contains("name", name)
+
+	// 处理启用状态筛选
+	if enabled == "true" {
+		builder.Equal("enabled", "1")
+	} else if enabled == "false" {
+		builder.Equal("enabled", "0")
+	}
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Create 创建维护窗口
+func (h *MaintenanceHandler) Create(c echo.Context) error {
+	var req service.MaintenanceWindowRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateMaintenanceWindow(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	window, err := h.maintenanceService.CreateWindow(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to create maintenance window", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, window)
+}
+
+// Get 获取维护窗口详情
+func (h *MaintenanceHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	window, err := h.maintenanceService.MaintenanceRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, window)
+}
+
+// Update 更新维护窗口
+func (h *MaintenanceHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.MaintenanceWindowRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateMaintenanceWindow(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	window, err := h.maintenanceService.UpdateWindow(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update maintenance window", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, window)
+}
+
+// Delete 删除维护窗口
+func (h *MaintenanceHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.maintenanceService.DeleteWindow(ctx, id); err != nil {
+		h.logger.Error("failed to delete maintenance window", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// ListActive 获取当前生效的维护窗口
+func (h *MaintenanceHandler) ListActive(c echo.Context) error {
+	ctx := c.Request().Context()
+	return orz.Ok(c, h.maintenanceService.ListActive(ctx))
+}
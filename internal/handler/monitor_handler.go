@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/dushixiang/pika/internal/service"
 	"github.com/dushixiang/pika/internal/utils"
 	"github.com/go-orz/orz"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type MonitorHandler struct {
@@ -209,3 +213,47 @@ func (h *MonitorHandler) GetHistoryByID(c echo.Context) error {
 
 	return orz.Ok(c, history)
 }
+
+// Push 接收推送监控的心跳上报（公开接口，通过令牌识别监控任务）
+// 可选参数: status(up/down)、msg(附加信息)、ping(耗时，毫秒)
+func (h *MonitorHandler) Push(c echo.Context) error {
+	token := c.Param("token")
+	status := c.QueryParam("status")
+	message := c.QueryParam("msg")
+	if status != "" && status != "up" && status != "down" {
+		return orz.NewError(400, "status 参数只能是 up 或 down")
+	}
+
+	var responseTime int64
+	if ping := c.QueryParam("ping"); ping != "" {
+		value, err := strconv.ParseInt(ping, 10, 64)
+		if err != nil || value < 0 {
+			return orz.NewError(400, "ping 参数错误")
+		}
+		responseTime = value
+	}
+
+	ctx := c.Request().Context()
+	if err := h.monitorService.HandlePush(ctx, token, status, message, responseTime); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrMonitorDisabled) {
+			return orz.NewError(404, "监控任务不存在或已禁用")
+		}
+		h.logger.Error("处理推送监控心跳失败", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// ResetPushToken 重新生成推送监控令牌
+func (h *MonitorHandler) ResetPushToken(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	item, err := h.monitorService.ResetPushToken(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, item)
+}
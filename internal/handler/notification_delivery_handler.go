@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type NotificationDeliveryHandler struct {
+	logger        *zap.Logger
+	outboxService *service.NotificationOutboxService
+}
+
+func NewNotificationDeliveryHandler(logger *zap.Logger, outboxService *service.NotificationOutboxService) *NotificationDeliveryHandler {
+	return &NotificationDeliveryHandler{
+		logger:        logger,
+		outboxService: outboxService,
+	}
+}
+
+// Paging 通知发送记录分页查询，可按状态、渠道类型、告警记录和探针筛选
+func (h *NotificationDeliveryHandler) Paging(c echo.Context) error {
+	pr := orz.GetPageRequest(c, "createdAt", "nextAttemptAt")
+
+	builder := orz.NewPageBuilder(h.outboxService.DeliveryRepo.Repository).
+		PageRequest(pr).
+		Equal("status", c.QueryParam("status")).
+		Equal("channelType", c.QueryParam("channelType")).
+		Equal("recordId", c.QueryParam("recordId")).
+		Equal("agentId", c.QueryParam("agentId"))
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Get 获取通知发送详情和每次尝试的记录
+func (h *NotificationDeliveryHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return orz.NewError(400, "ID格式错误")
+	}
+
+	ctx := c.Request().Context()
+	detail, err := h.outboxService.GetDelivery(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, detail)
+}
+
+// Resend 重新发送失败的通知
+func (h *NotificationDeliveryHandler) Resend(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return orz.NewError(400, "ID格式错误")
+	}
+
+	ctx := c.Request().Context()
+	delivery, err := h.outboxService.Resend(ctx, id)
+	if err != nil {
+		h.logger.Error("failed to resend notification", zap.Int64("id", id), zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, delivery)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/service"
@@ -92,6 +93,48 @@ func (h *PropertyHandler) SetProperty(c echo.Context) error {
 		}
 	}
 
+	// 特殊校验：通知渠道的消息模板、发送时段和渠道配置
+	if id == service.PropertyIDNotificationChannels {
+		var channels []models.NotificationChannelConfig
+		data, _ := json.Marshal(req.Value)
+		if err := json.Unmarshal(data, &channels); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": "无效的通知渠道配置",
+			})
+		}
+		if err := service.ValidateChannelTemplates(channels); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+		if err := service.ValidateChannelSchedules(channels); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+		if err := service.ValidateChatChannelConfigs(channels); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+	}
+
+	// 特殊校验：通知路由配置
+	if id == service.PropertyIDNotificationRouting {
+		var routing models.NotificationRoutingConfig
+		data, _ := json.Marshal(req.Value)
+		if err := json.Unmarshal(data, &routing); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": "无效的通知路由配置",
+			})
+		}
+		if err := service.ValidateNotificationRouting(&routing); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": err.Error(),
+			})
+		}
+	}
+
 	if err := h.service.Set(c.Request().Context(), id, req.Name, req.Value); err != nil {
 		h.logger.Error("设置属性失败", zap.String("id", id), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -223,3 +266,128 @@ func (h *PropertyHandler) TestNotificationChannel(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, echo.Map{})
 }
+
+// DryRunNotificationRouting 模拟通知路由，返回示例告警会发送到的渠道
+// 未传入 config 时使用已保存的路由配置
+func (h *PropertyHandler) DryRunNotificationRouting(c echo.Context) error {
+	var req struct {
+		Config    *models.NotificationRoutingConfig `json:"config"`
+		AlertType string                            `json:"alertType"`
+		Level     string                            `json:"level"`
+		Tags      []string                          `json:"tags"`
+		Time      int64                             `json:"time"` // 模拟的告警时间（时间戳毫秒），为空时使用当前时间
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "无效的请求参数",
+		})
+	}
+
+	ctx := c.Request().Context()
+	routing := req.Config
+	if routing == nil {
+		saved, err := h.service.GetNotificationRoutingConfig(ctx)
+		if err != nil {
+			h.logger.Error("获取通知路由配置失败", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"message": "获取通知路由配置失败",
+			})
+		}
+		routing = saved
+	} else if err := service.ValidateNotificationRouting(routing); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	channels, err := h.service.GetNotificationChannelConfigs(ctx)
+	if err != nil {
+		h.logger.Error("获取通知渠道配置失败", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": "获取通知渠道配置失败",
+		})
+	}
+
+	now := time.Now()
+	if req.Time > 0 {
+		now = time.UnixMilli(req.Time)
+	}
+	record := &models.AlertRecord{
+		AlertType: req.AlertType,
+		Level:     req.Level,
+	}
+	result := service.RouteNotification(routing, record, req.Tags, now)
+
+	// 列出最终会发送的渠道，以及路由到但未配置或未启用的渠道
+	type channelView struct {
+		Type    string `json:"type"`
+		Enabled bool   `json:"enabled"`
+	}
+	var views []channelView
+	if len(result.Channels) == 0 {
+		for _, channel := range channels {
+			if channel.Enabled {
+				views = append(views, channelView{Type: channel.Type, Enabled: true})
+			}
+		}
+	} else {
+		for _, channelType := range result.Channels {
+			enabled := false
+			for _, channel := range channels {
+				if channel.Type == channelType && channel.Enabled {
+					enabled = true
+					break
+				}
+			}
+			views = append(views, channelView{Type: channelType, Enabled: enabled})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"matchedRules": result.MatchedRules,
+		"fallback":     result.Fallback,
+		"channels":     views,
+	})
+}
+
+// GetMessageTemplateVariables 获取消息模板支持的事件类型和变量说明
+func (h *PropertyHandler) GetMessageTemplateVariables(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"kinds":     service.MessageTemplateKinds,
+		"variables": service.MessageTemplateVariables,
+	})
+}
+
+// PreviewMessageTemplate 使用示例数据预览消息模板，模板为空时预览默认消息
+func (h *PropertyHandler) PreviewMessageTemplate(c echo.Context) error {
+	var req struct {
+		ChannelType string `json:"channelType"`
+		Kind        string `json:"kind"`
+		Template    string `json:"template"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "无效的请求参数",
+		})
+	}
+	if req.Kind == "" {
+		req.Kind = service.TemplateKindFiring
+	}
+
+	var serverURL string
+	if systemConfig, err := h.service.GetSystemConfig(c.Request().Context()); err == nil {
+		serverURL = systemConfig.ServerURL
+	}
+
+	message, err := h.notifier.PreviewMessageTemplate(req.ChannelType, req.Kind, req.Template, serverURL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "模板渲染失败: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":  message,
+		"markdown": service.SupportsMarkdown(req.ChannelType),
+	})
+}
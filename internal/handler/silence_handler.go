@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type SilenceHandler struct {
+	logger         *zap.Logger
+	silenceService *service.SilenceService
+	alertService   *service.AlertService
+}
+
+func NewSilenceHandler(logger *zap.Logger, silenceService *service.SilenceService, alertService *service.AlertService) *SilenceHandler {
+	return &SilenceHandler{
+		logger:         logger,
+		silenceService: silenceService,
+		alertService:   alertService,
+	}
+}
+
+// List 查询静默列表，state 可选 pending、active、expired
+func (h *SilenceHandler) List(c echo.Context) error {
+	state := c.QueryParam("state")
+
+	ctx := c.Request().Context()
+	silences, err := h.silenceService.ListSilences(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, silences)
+}
+
+// Create 创建静默
+func (h *SilenceHandler) Create(c echo.Context) error {
+	var req service.SilenceRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateSilence(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	silence, err := h.silenceService.CreateSilence(ctx, &req, currentUsername(c))
+	if err != nil {
+		h.logger.Error("failed to create silence", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, silence)
+}
+
+// Get 获取静默详情
+func (h *SilenceHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	silence, err := h.silenceService.SilenceRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, silence)
+}
+
+// Update 更新静默
+func (h *SilenceHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.SilenceRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateSilence(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	silence, err := h.silenceService.UpdateSilence(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update silence", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, silence)
+}
+
+// Expire 立即结束静默
+func (h *SilenceHandler) Expire(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	silence, err := h.silenceService.ExpireSilence(ctx, id)
+	if err != nil {
+		h.logger.Error("failed to expire silence", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, silence)
+}
+
+// Delete 删除静默
+func (h *SilenceHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.silenceService.DeleteSilence(ctx, id); err != nil {
+		h.logger.Error("failed to delete silence", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{})
+}
+
+// ListSuppressedAlerts 查询被静默抑制过的告警记录
+func (h *SilenceHandler) ListSuppressedAlerts(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	pr := orz.GetPageRequest(c, "createdAt", "firedAt")
+
+	builder := orz.NewPageBuilder(h.alertService.AlertRecordRepo.Repository).
+		PageRequest(pr).
+		In("id", h.alertService.AlertRecordRepo.SuppressedRecordIDs(ctx, id))
+
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// 报告统计范围上限（天）
+const maxReportRangeDays = 366
+
+type SLAHandler struct {
+	logger     *zap.Logger
+	slaService *service.SLAService
+}
+
+func NewSLAHandler(logger *zap.Logger, slaService *service.SLAService) *SLAHandler {
+	return &SLAHandler{
+		logger:     logger,
+		slaService: slaService,
+	}
+}
+
+// parseReportPeriod 解析报告统计范围
+// 支持 month=2006-01（自然月）、range=30d（最近 N 天）或 start/end（时间戳毫秒），默认最近 30 天
+func parseReportPeriod(c echo.Context) (start, end time.Time, err error) {
+	if month := c.QueryParam("month"); month != "" {
+		start, err = time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return start, end, fmt.Errorf("无效的月份，格式: 2006-01")
+		}
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	startParam, endParam := c.QueryParam("start"), c.QueryParam("end")
+	if startParam != "" || endParam != "" {
+		startMs, endMs, err := parseTimeRangeOrStartEnd("", startParam, endParam)
+		if err != nil {
+			return start, end, err
+		}
+		start, end = time.UnixMilli(startMs), time.UnixMilli(endMs)
+		if end.Sub(start) > maxReportRangeDays*24*time.Hour {
+			return start, end, fmt.Errorf("统计范围不能超过 %d 天", maxReportRangeDays)
+		}
+		return start, end, nil
+	}
+
+	days := 30
+	if rangeParam := c.QueryParam("range"); rangeParam != "" {
+		days, err = strconv.Atoi(strings.TrimSuffix(rangeParam, "d"))
+		if err != nil || days < 1 || days > maxReportRangeDays {
+			return start, end, fmt.Errorf("无效的时间范围，支持 1d ~ %dd", maxReportRangeDays)
+		}
+	}
+	end = time.Now()
+	return end.AddDate(0, 0, -days), end, nil
+}
+
+// writeReports 按 format 参数输出 JSON 或 CSV
+func (h *SLAHandler) writeReports(c echo.Context, filename string, reports []metric.SLAReport) error {
+	if c.QueryParam("format") != "csv" {
+		return orz.Ok(c, reports)
+	}
+
+	var buf bytes.Buffer
+	if err := service.WriteReportsCSV(&buf, reports); err != nil {
+		return err
+	}
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// MonitorReports 所有监控项的可用性报告
+func (h *SLAHandler) MonitorReports(c echo.Context) error {
+	start, end, err := parseReportPeriod(c)
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	reports, err := h.slaService.MonitorReports(ctx, start, end)
+	if err != nil {
+		h.logger.Error("failed to build monitor sla reports", zap.Error(err))
+		return err
+	}
+	return h.writeReports(c, "monitor-sla-"+start.Format("20060102"), reports)
+}
+
+// MonitorReport 单个监控项的可用性报告
+func (h *SLAHandler) MonitorReport(c echo.Context) error {
+	id := c.Param("id")
+	start, end, err := parseReportPeriod(c)
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.slaService.MonitorReport(ctx, id, start, end)
+	if err != nil {
+		h.logger.Error("failed to build monitor sla report", zap.String("monitorId", id), zap.Error(err))
+		return err
+	}
+	if c.QueryParam("format") == "csv" {
+		return h.writeReports(c, "monitor-sla-"+id, []metric.SLAReport{*report})
+	}
+	return orz.Ok(c, report)
+}
+
+// AgentReports 所有探针的可用性报告
+func (h *SLAHandler) AgentReports(c echo.Context) error {
+	start, end, err := parseReportPeriod(c)
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	reports, err := h.slaService.AgentReports(ctx, start, end)
+	if err != nil {
+		h.logger.Error("failed to build agent sla reports", zap.Error(err))
+		return err
+	}
+	return h.writeReports(c, "agent-sla-"+start.Format("20060102"), reports)
+}
+
+// AgentReport 单个探针的可用性报告
+func (h *SLAHandler) AgentReport(c echo.Context) error {
+	id := c.Param("id")
+	start, end, err := parseReportPeriod(c)
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.slaService.AgentReport(ctx, id, start, end)
+	if err != nil {
+		h.logger.Error("failed to build agent sla report", zap.String("agentId", id), zap.Error(err))
+		return err
+	}
+	if c.QueryParam("format") == "csv" {
+		return h.writeReports(c, "agent-sla-"+id, []metric.SLAReport{*report})
+	}
+	return orz.Ok(c, report)
+}
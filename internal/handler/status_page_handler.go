@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type StatusPageHandler struct {
+	logger            *zap.Logger
+	statusPageService *service.StatusPageService
+}
+
+func NewStatusPageHandler(logger *zap.Logger, statusPageService *service.StatusPageService) *StatusPageHandler {
+	return &StatusPageHandler{
+		logger:            logger,
+		statusPageService: statusPageService,
+	}
+}
+
+// Paging 状态页分页查询
+func (h *StatusPageHandler) Paging(c echo.Context) error {
+	title := c.QueryParam("title")
+
+	pr := orz.GetPageRequest(c, "created_at", "title", "slug")
+
+	builder := orz.NewPageBuilder(h.statusPageService.StatusPageRepo).
+		PageRequest(pr).
+		Contains("title", title)
+
+	ctx := c.Request().Context()
+	page, err := builder.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Create 创建状态页
+func (h *StatusPageHandler) Create(c echo.Context) error {
+	var req service.StatusPageRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateStatusPage(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	page, err := h.statusPageService.CreatePage(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to create status page", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Get 获取状态页详情
+func (h *StatusPageHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	page, err := h.statusPageService.StatusPageRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Update 更新状态页
+func (h *StatusPageHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.StatusPageRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateStatusPage(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	page, err := h.statusPageService.UpdatePage(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to update status page", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, page)
+}
+
+// Delete 删除状态页
+func (h *StatusPageHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.statusPageService.DeletePage(ctx, id); err != nil {
+		h.logger.Error("failed to delete status page", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{
+		"message": "状态页删除成功",
+	})
+}
+
+// ListIncidents 获取状态页的事件列表
+func (h *StatusPageHandler) ListIncidents(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	incidents, err := h.statusPageService.ListIncidents(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, incidents)
+}
+
+// CreateIncident 发布事件
+func (h *StatusPageHandler) CreateIncident(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.IncidentRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateIncident(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	incident, err := h.statusPageService.CreateIncident(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to create incident", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, incident)
+}
+
+// UpdateIncident 修改事件标题
+func (h *StatusPageHandler) UpdateIncident(c echo.Context) error {
+	id := c.Param("id")
+
+	var req struct {
+		Title string `json:"title"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	ctx := c.Request().Context()
+	incident, err := h.statusPageService.UpdateIncidentTitle(ctx, id, req.Title)
+	if err != nil {
+		return err
+	}
+
+	return orz.Ok(c, incident)
+}
+
+// DeleteIncident 删除事件
+func (h *StatusPageHandler) DeleteIncident(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if err := h.statusPageService.DeleteIncident(ctx, id); err != nil {
+		h.logger.Error("failed to delete incident", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, orz.Map{
+		"message": "事件删除成功",
+	})
+}
+
+// AddIncidentUpdate 发布事件进展
+func (h *StatusPageHandler) AddIncidentUpdate(c echo.Context) error {
+	id := c.Param("id")
+
+	var req service.IncidentUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if err := service.ValidateIncidentUpdate(&req); err != nil {
+		return orz.NewError(400, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	update, err := h.statusPageService.AddIncidentUpdate(ctx, id, &req)
+	if err != nil {
+		h.logger.Error("failed to add incident update", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, update)
+}
+
+// resolvePublicPage 根据 slug 参数查找状态页，未提供 slug 时按请求的 Host 匹配自定义域名
+func (h *StatusPageHandler) resolvePublicPage(c echo.Context) (*models.StatusPage, error) {
+	return h.statusPageService.FindPublicPage(c.Request().Context(), c.Param("slug"), c.Request().Host)
+}
+
+// pageLink 状态页访问地址，与前端路由 /status/:slug 对应，自定义域名访问时为 /status
+func pageLink(c echo.Context, page *models.StatusPage) string {
+	base := c.Scheme() + "://" + c.Request().Host
+	if c.Param("slug") == "" {
+		return base + "/status"
+	}
+	return base + "/status/" + page.Slug
+}
+
+// GetPublicStatus 获取状态页当前状态（公开）
+func (h *StatusPageHandler) GetPublicStatus(c echo.Context) error {
+	page, err := h.resolvePublicPage(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	view, err := h.statusPageService.GetPageStatus(ctx, page)
+	if err != nil {
+		h.logger.Error("failed to get status page", zap.String("slug", page.Slug), zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, view)
+}
+
+// GetPublicHistory 获取状态页 90 天每日可用性（公开）
+func (h *StatusPageHandler) GetPublicHistory(c echo.Context) error {
+	page, err := h.resolvePublicPage(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.statusPageService.GetPageHistory(ctx, page)
+	if err != nil {
+		h.logger.Error("failed to get status page history", zap.String("slug", page.Slug), zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, history)
+}
+
+// GetRSSFeed 事件 RSS 订阅源（公开）
+func (h *StatusPageHandler) GetRSSFeed(c echo.Context) error {
+	return h.writeFeed(c, "application/rss+xml; charset=utf-8", service.BuildRSSFeed)
+}
+
+// GetAtomFeed 事件 Atom 订阅源（公开）
+func (h *StatusPageHandler) GetAtomFeed(c echo.Context) error {
+	return h.writeFeed(c, "application/atom+xml; charset=utf-8", service.BuildAtomFeed)
+}
+
+func (h *StatusPageHandler) writeFeed(c echo.Context, contentType string,
+	build func(page *models.StatusPage, link string, incidents []models.StatusPageIncident) ([]byte, error)) error {
+	page, err := h.resolvePublicPage(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	incidents, err := h.statusPageService.ListFeedIncidents(ctx, page.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := build(page, pageLink(c, page), incidents)
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, contentType, data)
+}
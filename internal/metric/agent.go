@@ -20,11 +20,18 @@ type NetworkSummary struct {
 	TotalInterfaces     int    `json:"totalInterfaces"`     // 网卡数量
 }
 
+// DiskIOSummary 磁盘 IO 汇总数据
+type DiskIOSummary struct {
+	TotalReadBytesRate  uint64 `json:"totalReadBytesRate"`  // 总读取速率(字节/秒)
+	TotalWriteBytesRate uint64 `json:"totalWriteBytesRate"` // 总写入速率(字节/秒)
+}
+
 // LatestMetrics 最新指标数据（用于API响应）
 type LatestMetrics struct {
 	CPU               *protocol.CPUData               `json:"cpu,omitempty"`
 	Memory            *protocol.MemoryData            `json:"memory,omitempty"`
 	Disk              *DiskSummary                    `json:"disk,omitempty"`
+	DiskIO            *DiskIOSummary                  `json:"diskIO,omitempty"`
 	Network           *NetworkSummary                 `json:"network,omitempty"`
 	NetworkInterfaces []protocol.NetworkData          `json:"networkInterfaces,omitempty"`
 	NetworkConnection *protocol.NetworkConnectionData `json:"networkConnection,omitempty"`
@@ -14,7 +14,7 @@ type LatestMonitorMetrics struct {
 
 // MonitorStatsResult 监控统计结果（所有探针的聚合数据）
 type MonitorStatsResult struct {
-	Status          string `json:"status"`                   // 聚合状态（up/down/unknown）
+	Status          string `json:"status"`                   // 聚合状态（up/down/unknown/maintenance）
 	ResponseTime    int64  `json:"responseTime"`             // 当前平均响应时间(ms)
 	ResponseTimeMin int64  `json:"responseTimeMin"`          // 最快响应时间(ms)
 	ResponseTimeMax int64  `json:"responseTimeMax"`          // 最慢响应时间(ms)
@@ -25,6 +25,7 @@ type MonitorStatsResult struct {
 		Up      int `json:"up"`      // 正常探针数量
 		Down    int `json:"down"`    // 异常探针数量
 		Unknown int `json:"unknown"` // 未知状态探针数量
+		Failed  int `json:"failed"`  // 达到连续失败次数的探针数量（多点确认）
 	} `json:"agentStats"` // 探针状态分布
 	LastCheckTime int64 `json:"lastCheckTime"` // 最后检测时间(毫秒时间戳)
 }
@@ -40,7 +41,7 @@ type PublicMonitorOverview struct {
 	Enabled          bool   `json:"enabled"`
 	Interval         int    `json:"interval"`
 	AgentCount       int    `json:"agentCount"`
-	Status           string `json:"status"`                   // up/down/unknown
+	Status           string `json:"status"`                   // up/down/unknown/maintenance
 	ResponseTime     int64  `json:"responseTime"`             // 当前平均响应时间(ms)
 	ResponseTimeMin  int64  `json:"responseTimeMin"`          // 最快响应时间(ms)
 	ResponseTimeMax  int64  `json:"responseTimeMax"`          // 最慢响应时间(ms)
@@ -50,6 +51,7 @@ type PublicMonitorOverview struct {
 		Up      int `json:"up"`      // 正常探针数量
 		Down    int `json:"down"`    // 异常探针数量
 		Unknown int `json:"unknown"` // 未知状态探针数量
+		Failed  int `json:"failed"`  // 达到连续失败次数的探针数量（多点确认）
 	} `json:"agentStats"` // 探针状态分布
 	LastCheckTime int64 `json:"lastCheckTime"` // 最后检测时间
 }
@@ -0,0 +1,35 @@
+package metric
+
+// TimeRange 时间段（时间戳毫秒，左闭右开）
+type TimeRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// SLAReport 可用性报告
+type SLAReport struct {
+	TargetType         string      `json:"targetType"`                // 统计对象类型: monitor, agent
+	TargetID           string      `json:"targetId"`                  // 统计对象ID
+	TargetName         string      `json:"targetName"`                // 统计对象名称
+	Start              int64       `json:"start"`                     // 统计开始时间(毫秒时间戳)
+	End                int64       `json:"end"`                       // 统计结束时间(毫秒时间戳)
+	UptimePercent      float64     `json:"uptimePercent"`             // 可用率(%)，不含维护和无数据时段，无有效数据时为 0
+	UpSeconds          int64       `json:"upSeconds"`                 // 正常时长(秒)
+	DownSeconds        int64       `json:"downSeconds"`               // 故障总时长(秒)
+	MaintenanceSeconds int64       `json:"maintenanceSeconds"`        // 维护时长(秒)
+	UnknownSeconds     int64       `json:"unknownSeconds"`            // 无数据时长(秒)
+	OutageCount        int         `json:"outageCount"`               // 故障次数
+	MTTR               int64       `json:"mttr"`                      // 平均恢复时间(秒)
+	AvgResponseTime    float64     `json:"avgResponseTime,omitempty"` // 平均响应时间(ms)，仅服务监控
+	Outages            []TimeRange `json:"outages"`                   // 故障时间段
+}
+
+// DailyUptime 单日可用性
+type DailyUptime struct {
+	Date               string  `json:"date"`               // 日期 2006-01-02
+	UptimePercent      float64 `json:"uptimePercent"`      // 可用率(%)
+	DownSeconds        int64   `json:"downSeconds"`        // 故障时长(秒)
+	MaintenanceSeconds int64   `json:"maintenanceSeconds"` // 维护时长(秒)
+	OutageCount        int     `json:"outageCount"`        // 故障次数
+	HasData            bool    `json:"hasData"`            // 当天是否有有效数据
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/dushixiang/pika/internal/migrate/v0_1_1"
 	"github.com/dushixiang/pika/internal/migrate/v0_1_2"
 	"github.com/dushixiang/pika/internal/migrate/v0_1_3"
+	"github.com/dushixiang/pika/internal/migrate/v0_1_4"
 	"github.com/dushixiang/pika/internal/service"
 	"github.com/dushixiang/pika/pkg/version"
 	"go.uber.org/zap"
@@ -37,6 +38,12 @@ func AutoMigrate(logger *zap.Logger, db *gorm.DB, propertyService *service.Prope
 			return err
 		}
 	}
+	// 升级到 v0.1.4 版本：全局告警规则迁移为默认告警策略
+	if strings.Compare(localVersion, "v0.1.4") < 0 {
+		if err := v0_1_4.Migrate(logger, db); err != nil {
+			return err
+		}
+	}
 
 	return propertyService.SetSystemVersion(ctx, version.Version)
 }
@@ -0,0 +1,87 @@
+package v0_1_4
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// legacyAlertConfig 旧版本的全局告警配置，规则直接保存在 alert_config 属性中
+// 规则保留原始 JSON，旧版本没有的字段使用默认规则中的值
+type legacyAlertConfig struct {
+	Rules json.RawMessage `json:"rules"`
+}
+
+// Migrate 将旧版本的全局告警规则迁移为默认告警策略
+func Migrate(logger *zap.Logger, db *gorm.DB) error {
+	logger.Info("开始执行 v0.1.4 版本数据迁移")
+
+	migrator := db.Migrator()
+	if migrator == nil {
+		logger.Warn("无法获取数据库 migrator，跳过迁移")
+		return nil
+	}
+
+	if !migrator.HasTable("properties") || !migrator.HasTable("alert_policies") {
+		logger.Info("未检测到 properties 或 alert_policies 表，跳过迁移")
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.AlertPolicy{}).Where("id = ?", models.DefaultAlertPolicyID).Count(&count).Error; err != nil {
+		logger.Error("查询默认告警策略失败", zap.Error(err))
+		return err
+	}
+	if count > 0 {
+		logger.Info("默认告警策略已存在，跳过迁移")
+		return nil
+	}
+
+	var property models.Property
+	err := db.Where("id = ?", "alert_config").First(&property).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Info("未检测到旧的告警配置，跳过迁移")
+		return nil
+	}
+	if err != nil {
+		logger.Error("查询告警配置失败", zap.Error(err))
+		return err
+	}
+
+	var legacy legacyAlertConfig
+	if err := json.Unmarshal([]byte(property.Value), &legacy); err != nil {
+		logger.Error("解析旧的告警配置失败", zap.Error(err))
+		return err
+	}
+	if len(legacy.Rules) == 0 || string(legacy.Rules) == "null" {
+		logger.Info("旧的告警配置中没有告警规则，跳过迁移")
+		return nil
+	}
+	rules := models.DefaultAlertRules()
+	if err := json.Unmarshal(legacy.Rules, &rules); err != nil {
+		logger.Error("解析旧的告警规则失败", zap.Error(err))
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	policy := models.AlertPolicy{
+		ID:          models.DefaultAlertPolicyID,
+		Name:        "默认策略",
+		Description: "由原全局告警规则迁移，未匹配到其他策略的探针使用此策略",
+		Rules:       datatypes.NewJSONType(models.FullAlertRuleOverrides(rules)),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := db.Create(&policy).Error; err != nil {
+		logger.Error("创建默认告警策略失败", zap.Error(err))
+		return err
+	}
+
+	logger.Info("v0.1.4 版本数据迁移完成，全局告警规则已迁移为默认告警策略")
+	return nil
+}
@@ -0,0 +1,96 @@
+package v0_1_4
+
+import (
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMigrateLegacyAlertConfig(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Property{}, &models.AlertPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// v0.1.4 之前的全局告警配置，只有 CPU、内存、磁盘、网络、证书、服务和离线规则
+	legacy := `{"rules":{
+		"cpuEnabled":true,"cpuThreshold":90,"cpuDuration":60,
+		"memoryEnabled":false,"memoryThreshold":80,"memoryDuration":300,
+		"diskEnabled":true,"diskThreshold":95,"diskDuration":300,
+		"networkEnabled":false,"networkThreshold":100,"networkDuration":300,
+		"certEnabled":true,"certThreshold":15,
+		"serviceEnabled":true,"serviceDuration":120,
+		"agentOfflineEnabled":true,"agentOfflineDuration":600
+	}}`
+	if err := db.Create(&models.Property{ID: "alert_config", Name: "告警配置", Value: legacy}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(zap.NewNop(), db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	var policy models.AlertPolicy
+	if err := db.First(&policy, "id = ?", models.DefaultAlertPolicyID).Error; err != nil {
+		t.Fatal(err)
+	}
+	rules := models.DefaultAlertRules()
+	policy.Rules.Data().ApplyTo(&rules)
+
+	// 旧配置中的值
+	if rules.CPUThreshold != 90 || rules.CPUDuration != 60 || rules.MemoryEnabled || rules.DiskThreshold != 95 ||
+		rules.CertThreshold != 15 || rules.ServiceDuration != 120 || rules.AgentOfflineDuration != 600 {
+		t.Errorf("旧配置的规则未迁移: %+v", rules)
+	}
+
+	// 旧版本没有的字段保持默认值
+	defaults := models.DefaultAlertRules()
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"CPUMode", rules.CPUMode, defaults.CPUMode},
+		{"DiskMode", rules.DiskMode, defaults.DiskMode},
+		{"AnomalySensitivity", rules.AnomalySensitivity, defaults.AnomalySensitivity},
+		{"AnomalyMinHistory", rules.AnomalyMinHistory, defaults.AnomalyMinHistory},
+		{"DiskForecastWindow", rules.DiskForecastWindow, defaults.DiskForecastWindow},
+		{"DiskForecastThreshold", rules.DiskForecastThreshold, defaults.DiskForecastThreshold},
+		{"LoadThreshold", rules.LoadThreshold, defaults.LoadThreshold},
+		{"SwapThreshold", rules.SwapThreshold, defaults.SwapThreshold},
+		{"ConnEstablishedThreshold", rules.ConnEstablishedThreshold, defaults.ConnEstablishedThreshold},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestMigrateSkipsWithoutRules(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Property{}, &models.AlertPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Property{ID: "alert_config", Value: `{"rules":null}`}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(zap.NewNop(), db); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Model(&models.AlertPolicy{}).Count(&count)
+	if count != 0 {
+		t.Errorf("没有旧规则时不应创建默认策略，count = %d", count)
+	}
+}
@@ -96,3 +96,20 @@ func (r SSHLoginConfigData) IsIPWhitelisted(ip string) bool {
 func (Agent) TableName() string {
 	return "agents"
 }
+
+const (
+	AgentStatusOnline  = "online"
+	AgentStatusOffline = "offline"
+)
+
+// AgentStatusEvent 探针上下线事件，用于可用性统计
+type AgentStatusEvent struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"` // 事件ID
+	AgentID   string `gorm:"index" json:"agentId"`               // 探针ID
+	Status    string `json:"status"`                             // 状态: online, offline
+	CreatedAt int64  `gorm:"index" json:"createdAt"`             // 发生时间（时间戳毫秒）
+}
+
+func (AgentStatusEvent) TableName() string {
+	return "agent_status_events"
+}
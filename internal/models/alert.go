@@ -1,20 +1,35 @@
 package models
 
+import "gorm.io/datatypes"
+
 // AlertRecord 告警记录
 type AlertRecord struct {
-	ID          int64   `gorm:"primaryKey;autoIncrement" json:"id"`    // 记录ID
-	AgentID     string  `gorm:"index" json:"agentId"`                  // 探针ID
-	AgentName   string  `json:"agentName"`                             // 探针名称
-	AlertType   string  `json:"alertType"`                             // 告警类型: cpu, memory, disk, network
-	Message     string  `json:"message"`                               // 告警消息
-	Threshold   float64 `json:"threshold"`                             // 告警阈值
-	ActualValue float64 `json:"actualValue"`                           // 实际值
-	Level       string  `json:"level"`                                 // 告警级别: info, warning, critical
-	Status      string  `json:"status"`                                // 状态: firing（告警中）, resolved（已恢复）
-	FiredAt     int64   `gorm:"index" json:"firedAt"`                  // 触发时间（时间戳毫秒）
-	ResolvedAt  int64   `json:"resolvedAt,omitempty"`                  // 恢复时间（时间戳毫秒）
-	CreatedAt   int64   `json:"createdAt"`                             // 创建时间（时间戳毫秒）
-	UpdatedAt   int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+	ID          int64   `gorm:"primaryKey;autoIncrement" json:"id"` // 记录ID
+	AgentID     string  `gorm:"index" json:"agentId"`               // 探针ID
+	AgentName   string  `json:"agentName"`                          // 探针名称
+	AlertType   string  `json:"alertType"`                          // 告警类型: cpu, memory, disk, network
+	Message     string  `json:"message"`                            // 告警消息
+	Threshold   float64 `json:"threshold"`                          // 告警阈值
+	ActualValue float64 `json:"actualValue"`                        // 实际值
+	Level       string  `json:"level"`                              // 告警级别: info, warning, critical
+	Status      string  `json:"status"`                             // 状态: firing（告警中）, resolved（已恢复）
+	IncidentID  int64   `gorm:"index" json:"incidentId"`            // 所属告警事件ID
+	StateID     string  `gorm:"index" json:"stateId"`               // 产生告警的状态ID（格式：agentId:configId:alertType），用于外部系统去重
+	PolicyID    string  `gorm:"index" json:"policyId"`              // 产生告警的策略ID
+	PolicyName  string  `json:"policyName"`                         // 产生告警的策略名称
+	MonitorID   string  `gorm:"index" json:"monitorId"`             // 关联的监控项ID（证书和服务告警）
+	SilenceID   string  `gorm:"index" json:"silenceId"`             // 首次静默该告警的静默ID，静默结束后保留
+	// 当前静默该告警的静默ID，非空时不发送通知，静默结束后清空并补发通知
+	SuppressedBy string `gorm:"index" json:"suppressedBy"`
+	RenotifiedAt int64  `json:"renotifiedAt,omitempty"`                // 静默结束后补发通知的时间（时间戳毫秒）
+	FiredAt      int64  `gorm:"index" json:"firedAt"`                  // 触发时间（时间戳毫秒）
+	ResolvedAt   int64  `json:"resolvedAt,omitempty"`                  // 恢复时间（时间戳毫秒）
+	CreatedAt    int64  `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt    int64  `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+
+	// 表达式规则告警
+	RuleID string                                `gorm:"index" json:"ruleId"` // 产生告警的表达式规则ID
+	Labels datatypes.JSONType[map[string]string] `json:"labels"`              // 告警标签
 }
 
 func (AlertRecord) TableName() string {
@@ -33,6 +48,11 @@ type AlertState struct {
 	LastCheckTime int64   `json:"lastCheckTime"`                         // 上次检查时间
 	IsFiring      bool    `json:"isFiring"`                              // 是否正在告警
 	LastRecordID  int64   `json:"lastRecordId"`                          // 最后一条告警记录ID
+	RuleID        string  `gorm:"index" json:"ruleId"`                   // 表达式规则ID（仅表达式告警）
+	Mode          string  `json:"mode"`                                  // 告警模式: threshold, anomaly（仅指标告警）
+	ExpectedMin   float64 `json:"expectedMin"`                           // 预期范围下限（仅异常检测）
+	ExpectedMax   float64 `json:"expectedMax"`                           // 预期范围上限（仅异常检测）
+	Target        string  `json:"target"`                                // 告警对象（如磁盘挂载点）
 	CreatedAt     int64   `json:"createdAt"`                             // 创建时间（时间戳毫秒）
 	UpdatedAt     int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
 }
@@ -0,0 +1,322 @@
+package models
+
+import "gorm.io/datatypes"
+
+// DefaultAlertPolicyID 默认告警策略的固定 ID
+const DefaultAlertPolicyID = "default"
+
+// AlertPolicy 告警策略，可分配给探针或标签
+// 除默认策略外，策略只记录需要覆盖的规则项，未覆盖的项继承父策略（未指定父策略时继承默认策略）
+type AlertPolicy struct {
+	ID          string `gorm:"primaryKey" json:"id"` // 策略ID (UUID)，默认策略固定为 default
+	Name        string `json:"name"`                 // 名称
+	Description string `json:"description"`          // 描述
+	ParentID    string `json:"parentId"`             // 父策略ID，为空时继承默认策略
+	Priority    int    `json:"priority"`             // 优先级，同一探针匹配多个策略时取值大的策略
+
+	// 作用范围，直接指定的探针优先于标签匹配
+	AgentIds datatypes.JSONSlice[string] `json:"agentIds"` // 探针ID列表
+	Tags     datatypes.JSONSlice[string] `json:"tags"`     // 探针标签列表
+
+	Rules datatypes.JSONType[AlertRuleOverrides] `json:"rules"` // 覆盖的告警规则
+
+	CreatedAt int64 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt int64 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AlertPolicy) TableName() string {
+	return "alert_policies"
+}
+
+// IsDefault 是否为默认策略
+func (p *AlertPolicy) IsDefault() bool {
+	return p.ID == DefaultAlertPolicyID
+}
+
+// 指标告警模式
+const (
+	AlertModeThreshold = "threshold" // 超过静态阈值时告警
+	AlertModeAnomaly   = "anomaly"   // 偏离历史基线时告警
+)
+
+// 异常检测的基线算法
+const (
+	AnomalyMethodStdDev = "stddev" // 均值 ± k 倍标准差
+	AnomalyMethodMAD    = "mad"    // 中位数 ± k 倍 MAD（中位数绝对偏差），对偶发尖峰不敏感
+)
+
+// AlertRuleOverrides 告警规则覆盖项，nil 表示继承
+type AlertRuleOverrides struct {
+	CPUEnabled   *bool    `json:"cpuEnabled,omitempty"`
+	CPUThreshold *float64 `json:"cpuThreshold,omitempty"`
+	CPUDuration  *int     `json:"cpuDuration,omitempty"`
+	CPUMode      *string  `json:"cpuMode,omitempty"`
+
+	MemoryEnabled   *bool    `json:"memoryEnabled,omitempty"`
+	MemoryThreshold *float64 `json:"memoryThreshold,omitempty"`
+	MemoryDuration  *int     `json:"memoryDuration,omitempty"`
+	MemoryMode      *string  `json:"memoryMode,omitempty"`
+
+	DiskEnabled   *bool    `json:"diskEnabled,omitempty"`
+	DiskThreshold *float64 `json:"diskThreshold,omitempty"`
+	DiskDuration  *int     `json:"diskDuration,omitempty"`
+	DiskMode      *string  `json:"diskMode,omitempty"`
+
+	NetworkEnabled   *bool    `json:"networkEnabled,omitempty"`
+	NetworkThreshold *float64 `json:"networkThreshold,omitempty"`
+	NetworkDuration  *int     `json:"networkDuration,omitempty"`
+	NetworkMode      *string  `json:"networkMode,omitempty"`
+
+	LoadEnabled   *bool    `json:"loadEnabled,omitempty"`
+	LoadThreshold *float64 `json:"loadThreshold,omitempty"`
+	LoadDuration  *int     `json:"loadDuration,omitempty"`
+
+	SwapEnabled   *bool    `json:"swapEnabled,omitempty"`
+	SwapThreshold *float64 `json:"swapThreshold,omitempty"`
+	SwapDuration  *int     `json:"swapDuration,omitempty"`
+
+	DiskIOEnabled   *bool    `json:"diskIOEnabled,omitempty"`
+	DiskIOThreshold *float64 `json:"diskIOThreshold,omitempty"`
+	DiskIODuration  *int     `json:"diskIODuration,omitempty"`
+
+	TemperatureEnabled   *bool    `json:"temperatureEnabled,omitempty"`
+	TemperatureThreshold *float64 `json:"temperatureThreshold,omitempty"`
+	TemperatureDuration  *int     `json:"temperatureDuration,omitempty"`
+
+	GPUEnabled   *bool    `json:"gpuEnabled,omitempty"`
+	GPUThreshold *float64 `json:"gpuThreshold,omitempty"`
+	GPUDuration  *int     `json:"gpuDuration,omitempty"`
+
+	GPUMemoryEnabled   *bool    `json:"gpuMemoryEnabled,omitempty"`
+	GPUMemoryThreshold *float64 `json:"gpuMemoryThreshold,omitempty"`
+	GPUMemoryDuration  *int     `json:"gpuMemoryDuration,omitempty"`
+
+	GPUTemperatureEnabled   *bool    `json:"gpuTemperatureEnabled,omitempty"`
+	GPUTemperatureThreshold *float64 `json:"gpuTemperatureThreshold,omitempty"`
+	GPUTemperatureDuration  *int     `json:"gpuTemperatureDuration,omitempty"`
+
+	ConnEstablishedEnabled   *bool    `json:"connEstablishedEnabled,omitempty"`
+	ConnEstablishedThreshold *float64 `json:"connEstablishedThreshold,omitempty"`
+	ConnEstablishedDuration  *int     `json:"connEstablishedDuration,omitempty"`
+
+	ConnTimeWaitEnabled   *bool    `json:"connTimeWaitEnabled,omitempty"`
+	ConnTimeWaitThreshold *float64 `json:"connTimeWaitThreshold,omitempty"`
+	ConnTimeWaitDuration  *int     `json:"connTimeWaitDuration,omitempty"`
+
+	AnomalyMethod      *string  `json:"anomalyMethod,omitempty"`
+	AnomalySensitivity *float64 `json:"anomalySensitivity,omitempty"`
+	AnomalyMinHistory  *int     `json:"anomalyMinHistory,omitempty"`
+
+	DiskForecastEnabled   *bool `json:"diskForecastEnabled,omitempty"`
+	DiskForecastWindow    *int  `json:"diskForecastWindow,omitempty"`
+	DiskForecastThreshold *int  `json:"diskForecastThreshold,omitempty"`
+
+	TrafficForecastEnabled *bool `json:"trafficForecastEnabled,omitempty"`
+
+	CertEnabled   *bool    `json:"certEnabled,omitempty"`
+	CertThreshold *float64 `json:"certThreshold,omitempty"`
+
+	ServiceEnabled  *bool `json:"serviceEnabled,omitempty"`
+	ServiceDuration *int  `json:"serviceDuration,omitempty"`
+
+	AgentOfflineEnabled  *bool `json:"agentOfflineEnabled,omitempty"`
+	AgentOfflineDuration *int  `json:"agentOfflineDuration,omitempty"`
+}
+
+// ApplyTo 将覆盖项应用到告警规则上
+func (o AlertRuleOverrides) ApplyTo(rules *AlertRules) {
+	override(&rules.CPUEnabled, o.CPUEnabled)
+	override(&rules.CPUThreshold, o.CPUThreshold)
+	override(&rules.CPUDuration, o.CPUDuration)
+	override(&rules.CPUMode, o.CPUMode)
+	override(&rules.MemoryEnabled, o.MemoryEnabled)
+	override(&rules.MemoryThreshold, o.MemoryThreshold)
+	override(&rules.MemoryDuration, o.MemoryDuration)
+	override(&rules.MemoryMode, o.MemoryMode)
+	override(&rules.DiskEnabled, o.DiskEnabled)
+	override(&rules.DiskThreshold, o.DiskThreshold)
+	override(&rules.DiskDuration, o.DiskDuration)
+	override(&rules.DiskMode, o.DiskMode)
+	override(&rules.NetworkEnabled, o.NetworkEnabled)
+	override(&rules.NetworkThreshold, o.NetworkThreshold)
+	override(&rules.NetworkDuration, o.NetworkDuration)
+	override(&rules.NetworkMode, o.NetworkMode)
+	override(&rules.LoadEnabled, o.LoadEnabled)
+	override(&rules.LoadThreshold, o.LoadThreshold)
+	override(&rules.LoadDuration, o.LoadDuration)
+	override(&rules.SwapEnabled, o.SwapEnabled)
+	override(&rules.SwapThreshold, o.SwapThreshold)
+	override(&rules.SwapDuration, o.SwapDuration)
+	override(&rules.DiskIOEnabled, o.DiskIOEnabled)
+	override(&rules.DiskIOThreshold, o.DiskIOThreshold)
+	override(&rules.DiskIODuration, o.DiskIODuration)
+	override(&rules.TemperatureEnabled, o.TemperatureEnabled)
+	override(&rules.TemperatureThreshold, o.TemperatureThreshold)
+	override(&rules.TemperatureDuration, o.TemperatureDuration)
+	override(&rules.GPUEnabled, o.GPUEnabled)
+	override(&rules.GPUThreshold, o.GPUThreshold)
+	override(&rules.GPUDuration, o.GPUDuration)
+	override(&rules.GPUMemoryEnabled, o.GPUMemoryEnabled)
+	override(&rules.GPUMemoryThreshold, o.GPUMemoryThreshold)
+	override(&rules.GPUMemoryDuration, o.GPUMemoryDuration)
+	override(&rules.GPUTemperatureEnabled, o.GPUTemperatureEnabled)
+	override(&rules.GPUTemperatureThreshold, o.GPUTemperatureThreshold)
+	override(&rules.GPUTemperatureDuration, o.GPUTemperatureDuration)
+	override(&rules.ConnEstablishedEnabled, o.ConnEstablishedEnabled)
+	override(&rules.ConnEstablishedThreshold, o.ConnEstablishedThreshold)
+	override(&rules.ConnEstablishedDuration, o.ConnEstablishedDuration)
+	override(&rules.ConnTimeWaitEnabled, o.ConnTimeWaitEnabled)
+	override(&rules.ConnTimeWaitThreshold, o.ConnTimeWaitThreshold)
+	override(&rules.ConnTimeWaitDuration, o.ConnTimeWaitDuration)
+	override(&rules.AnomalyMethod, o.AnomalyMethod)
+	override(&rules.AnomalySensitivity, o.AnomalySensitivity)
+	override(&rules.AnomalyMinHistory, o.AnomalyMinHistory)
+	override(&rules.DiskForecastEnabled, o.DiskForecastEnabled)
+	override(&rules.DiskForecastWindow, o.DiskForecastWindow)
+	override(&rules.DiskForecastThreshold, o.DiskForecastThreshold)
+	override(&rules.TrafficForecastEnabled, o.TrafficForecastEnabled)
+	override(&rules.CertEnabled, o.CertEnabled)
+	override(&rules.CertThreshold, o.CertThreshold)
+	override(&rules.ServiceEnabled, o.ServiceEnabled)
+	override(&rules.ServiceDuration, o.ServiceDuration)
+	override(&rules.AgentOfflineEnabled, o.AgentOfflineEnabled)
+	override(&rules.AgentOfflineDuration, o.AgentOfflineDuration)
+}
+
+func override[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// FullAlertRuleOverrides 将完整规则转换为覆盖项（用于默认策略）
+func FullAlertRuleOverrides(rules AlertRules) AlertRuleOverrides {
+	return AlertRuleOverrides{
+		CPUEnabled:               &rules.CPUEnabled,
+		CPUThreshold:             &rules.CPUThreshold,
+		CPUDuration:              &rules.CPUDuration,
+		CPUMode:                  &rules.CPUMode,
+		MemoryEnabled:            &rules.MemoryEnabled,
+		MemoryThreshold:          &rules.MemoryThreshold,
+		MemoryDuration:           &rules.MemoryDuration,
+		MemoryMode:               &rules.MemoryMode,
+		DiskEnabled:              &rules.DiskEnabled,
+		DiskThreshold:            &rules.DiskThreshold,
+		DiskDuration:             &rules.DiskDuration,
+		DiskMode:                 &rules.DiskMode,
+		NetworkEnabled:           &rules.NetworkEnabled,
+		NetworkThreshold:         &rules.NetworkThreshold,
+		NetworkDuration:          &rules.NetworkDuration,
+		NetworkMode:              &rules.NetworkMode,
+		LoadEnabled:              &rules.LoadEnabled,
+		LoadThreshold:            &rules.LoadThreshold,
+		LoadDuration:             &rules.LoadDuration,
+		SwapEnabled:              &rules.SwapEnabled,
+		SwapThreshold:            &rules.SwapThreshold,
+		SwapDuration:             &rules.SwapDuration,
+		DiskIOEnabled:            &rules.DiskIOEnabled,
+		DiskIOThreshold:          &rules.DiskIOThreshold,
+		DiskIODuration:           &rules.DiskIODuration,
+		TemperatureEnabled:       &rules.TemperatureEnabled,
+		TemperatureThreshold:     &rules.TemperatureThreshold,
+		TemperatureDuration:      &rules.TemperatureDuration,
+		GPUEnabled:               &rules.GPUEnabled,
+		GPUThreshold:             &rules.GPUThreshold,
+		GPUDuration:              &rules.GPUDuration,
+		GPUMemoryEnabled:         &rules.GPUMemoryEnabled,
+		GPUMemoryThreshold:       &rules.GPUMemoryThreshold,
+		GPUMemoryDuration:        &rules.GPUMemoryDuration,
+		GPUTemperatureEnabled:    &rules.GPUTemperatureEnabled,
+		GPUTemperatureThreshold:  &rules.GPUTemperatureThreshold,
+		GPUTemperatureDuration:   &rules.GPUTemperatureDuration,
+		ConnEstablishedEnabled:   &rules.ConnEstablishedEnabled,
+		ConnEstablishedThreshold: &rules.ConnEstablishedThreshold,
+		ConnEstablishedDuration:  &rules.ConnEstablishedDuration,
+		ConnTimeWaitEnabled:      &rules.ConnTimeWaitEnabled,
+		ConnTimeWaitThreshold:    &rules.ConnTimeWaitThreshold,
+		ConnTimeWaitDuration:     &rules.ConnTimeWaitDuration,
+		AnomalyMethod:            &rules.AnomalyMethod,
+		AnomalySensitivity:       &rules.AnomalySensitivity,
+		AnomalyMinHistory:        &rules.AnomalyMinHistory,
+		DiskForecastEnabled:      &rules.DiskForecastEnabled,
+		DiskForecastWindow:       &rules.DiskForecastWindow,
+		DiskForecastThreshold:    &rules.DiskForecastThreshold,
+		TrafficForecastEnabled:   &rules.TrafficForecastEnabled,
+		CertEnabled:              &rules.CertEnabled,
+		CertThreshold:            &rules.CertThreshold,
+		ServiceEnabled:           &rules.ServiceEnabled,
+		ServiceDuration:          &rules.ServiceDuration,
+		AgentOfflineEnabled:      &rules.AgentOfflineEnabled,
+		AgentOfflineDuration:     &rules.AgentOfflineDuration,
+	}
+}
+
+// DefaultAlertRules 默认告警规则
+func DefaultAlertRules() AlertRules {
+	return AlertRules{
+		CPUEnabled:               true,
+		CPUThreshold:             80,
+		CPUDuration:              300, // 5分钟
+		CPUMode:                  AlertModeThreshold,
+		MemoryEnabled:            true,
+		MemoryThreshold:          80,
+		MemoryDuration:           300, // 5分钟
+		MemoryMode:               AlertModeThreshold,
+		DiskEnabled:              true,
+		DiskThreshold:            85,
+		DiskDuration:             300, // 5分钟
+		DiskMode:                 AlertModeThreshold,
+		NetworkEnabled:           false,
+		NetworkThreshold:         100,
+		NetworkDuration:          300, // 5分钟
+		NetworkMode:              AlertModeThreshold,
+		LoadEnabled:              false,
+		LoadThreshold:            1.5,
+		LoadDuration:             300, // 5分钟
+		SwapEnabled:              false,
+		SwapThreshold:            50,
+		SwapDuration:             300, // 5分钟
+		DiskIOEnabled:            false,
+		DiskIOThreshold:          200,
+		DiskIODuration:           300, // 5分钟
+		TemperatureEnabled:       false,
+		TemperatureThreshold:     85,
+		TemperatureDuration:      300, // 5分钟
+		GPUEnabled:               false,
+		GPUThreshold:             90,
+		GPUDuration:              300, // 5分钟
+		GPUMemoryEnabled:         false,
+		GPUMemoryThreshold:       90,
+		GPUMemoryDuration:        300, // 5分钟
+		GPUTemperatureEnabled:    false,
+		GPUTemperatureThreshold:  85,
+		GPUTemperatureDuration:   300, // 5分钟
+		ConnEstablishedEnabled:   false,
+		ConnEstablishedThreshold: 10000,
+		ConnEstablishedDuration:  300, // 5分钟
+		ConnTimeWaitEnabled:      false,
+		ConnTimeWaitThreshold:    20000,
+		ConnTimeWaitDuration:     300, // 5分钟
+		AnomalyMethod:            AnomalyMethodStdDev,
+		AnomalySensitivity:       3,
+		AnomalyMinHistory:        7, // 7天
+		DiskForecastEnabled:      false,
+		DiskForecastWindow:       24, // 24小时
+		DiskForecastThreshold:    72, // 3天
+		TrafficForecastEnabled:   false,
+		CertEnabled:              true,
+		CertThreshold:            30, // 30天
+		ServiceEnabled:           true,
+		ServiceDuration:          300, // 5分钟
+		AgentOfflineEnabled:      true,
+		AgentOfflineDuration:     300, // 5分钟
+	}
+}
+
+// EffectiveAlertPolicy 探针最终生效的告警策略
+type EffectiveAlertPolicy struct {
+	PolicyID   string     `json:"policyId"`   // 生效的策略ID
+	PolicyName string     `json:"policyName"` // 生效的策略名称
+	Chain      []string   `json:"chain"`      // 继承链（从默认策略到生效策略的ID）
+	Rules      AlertRules `json:"rules"`      // 合并后的告警规则
+}
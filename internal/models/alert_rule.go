@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/datatypes"
+
+// AlertTypeExpression 表达式规则告警类型
+const AlertTypeExpression = "expression"
+
+// AlertRule 表达式告警规则，使用 MetricsQL/PromQL 表达式查询指标存储
+// 表达式返回的每条序列视为一个告警实例，通过 agent_id 标签关联到探针
+type AlertRule struct {
+	ID          string                                `gorm:"primaryKey" json:"id"`     // 规则ID (UUID)
+	Name        string                                `json:"name"`                     // 名称
+	Description string                                `json:"description"`              // 描述
+	Enabled     bool                                  `json:"enabled"`                  // 是否启用
+	Expr        string                                `gorm:"type:text" json:"expr"`    // 查询表达式，如 pika_cpu_usage_percent > 90
+	For         int                                   `json:"for"`                      // 持续时间（秒），序列持续返回超过该时间后触发
+	Severity    string                                `json:"severity"`                 // 告警级别: info, warning, critical
+	Labels      datatypes.JSONType[map[string]string] `json:"labels"`                   // 附加标签，覆盖序列中的同名标签
+	Message     string                                `gorm:"type:text" json:"message"` // 消息模板（text/template），为空时使用默认消息
+
+	CreatedAt int64 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt int64 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
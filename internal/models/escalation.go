@@ -0,0 +1,46 @@
+package models
+
+import "gorm.io/datatypes"
+
+// EscalationPolicy 告警升级策略
+// 告警触发后按步骤依次通知不同的渠道组，未确认时按间隔重复通知当前步骤的渠道组
+type EscalationPolicy struct {
+	ID             string                              `gorm:"primaryKey" json:"id"` // 策略ID (UUID)
+	Name           string                              `json:"name"`                 // 名称
+	Description    string                              `json:"description"`          // 描述
+	Enabled        bool                                `json:"enabled"`              // 是否启用
+	Levels         datatypes.JSONSlice[string]         `json:"levels"`               // 适用的告警级别
+	AlertPolicyIds datatypes.JSONSlice[string]         `json:"alertPolicyIds"`       // 适用的告警策略ID，优先于告警级别匹配
+	Steps          datatypes.JSONSlice[EscalationStep] `json:"steps"`                // 升级步骤
+	RepeatInterval int                                 `json:"repeatInterval"`       // 未确认时重复通知的间隔（分钟），0表示不重复
+
+	CreatedAt int64 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt int64 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (EscalationPolicy) TableName() string {
+	return "escalation_policies"
+}
+
+// EscalationStep 升级步骤
+type EscalationStep struct {
+	Delay    int      `json:"delay"`    // 告警触发后多少分钟执行该步骤
+	Channels []string `json:"channels"` // 通知渠道类型列表
+}
+
+// AlertEscalation 告警的待执行升级，持久化以保证服务重启后继续执行
+type AlertEscalation struct {
+	ID             int64                       `gorm:"primaryKey;autoIncrement" json:"id"`    // ID
+	RecordID       int64                       `gorm:"uniqueIndex" json:"recordId"`           // 告警记录ID
+	PolicyID       string                      `gorm:"index" json:"policyId"`                 // 升级策略ID
+	StepIndex      int                         `json:"stepIndex"`                             // 已执行的步骤序号，-1 表示尚未执行
+	Channels       datatypes.JSONSlice[string] `json:"channels"`                              // 已通知过的渠道类型（恢复时通知这些渠道）
+	NextEscalateAt int64                       `gorm:"index" json:"nextEscalateAt"`           // 下一步骤的执行时间（时间戳毫秒），0表示没有下一步
+	NextRepeatAt   int64                       `gorm:"index" json:"nextRepeatAt"`             // 下一次重复通知时间（时间戳毫秒），0表示不重复
+	CreatedAt      int64                       `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt      int64                       `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (AlertEscalation) TableName() string {
+	return "alert_escalations"
+}
@@ -0,0 +1,61 @@
+package models
+
+const (
+	IncidentStateOpen         = "open"         // 未处理
+	IncidentStateAcknowledged = "acknowledged" // 已确认
+	IncidentStateResolved     = "resolved"     // 已解决
+)
+
+// Incident 告警事件，同一告警目标（探针+告警类型+监控项）连续产生的告警归并为一个事件
+type Incident struct {
+	ID             int64  `gorm:"primaryKey;autoIncrement" json:"id"`    // 事件ID
+	GroupKey       string `gorm:"index" json:"groupKey"`                 // 归并键，与告警状态ID一致
+	AgentID        string `gorm:"index" json:"agentId"`                  // 探针ID
+	AgentName      string `json:"agentName"`                             // 探针名称
+	AlertType      string `gorm:"index" json:"alertType"`                // 告警类型
+	Title          string `json:"title"`                                 // 标题（最近一次告警消息）
+	Level          string `gorm:"index" json:"level"`                    // 告警级别（归并告警中的最高级别）
+	State          string `gorm:"index" json:"state"`                    // 状态: open, acknowledged, resolved
+	AlertCount     int    `json:"alertCount"`                            // 归并的告警次数
+	LastRecordID   int64  `json:"lastRecordId"`                          // 最后一条告警记录ID
+	AcknowledgedBy string `json:"acknowledgedBy,omitempty"`              // 确认人
+	AcknowledgedAt int64  `json:"acknowledgedAt,omitempty"`              // 确认时间（时间戳毫秒）
+	ResolvedBy     string `json:"resolvedBy,omitempty"`                  // 手动解决人，告警自动恢复时为空
+	ResolvedAt     int64  `json:"resolvedAt,omitempty"`                  // 解决时间（时间戳毫秒）
+	NotifyCount    int    `json:"notifyCount"`                           // 已发送通知次数
+	LastNotifiedAt int64  `json:"lastNotifiedAt,omitempty"`              // 最后通知时间（时间戳毫秒）
+	StartedAt      int64  `gorm:"index" json:"startedAt"`                // 开始时间（时间戳毫秒）
+	CreatedAt      int64  `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt      int64  `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (Incident) TableName() string {
+	return "incidents"
+}
+
+const (
+	IncidentEventAlertFiring   = "alert_firing"   // 告警触发
+	IncidentEventAlertResolved = "alert_resolved" // 告警恢复
+	IncidentEventNotification  = "notification"   // 发送通知
+	IncidentEventAcknowledged  = "acknowledged"   // 确认
+	IncidentEventResolved      = "resolved"       // 手动解决
+	IncidentEventReopened      = "reopened"       // 重新打开
+	IncidentEventComment       = "comment"        // 评论
+	IncidentEventSSHLogin      = "ssh_login"      // SSH 登录（查询时由登录事件生成，不落库）
+	IncidentEventTamper        = "tamper"         // 防篡改事件（查询时由防篡改事件生成，不落库）
+)
+
+// IncidentEvent 告警事件时间线
+type IncidentEvent struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement" json:"id"` // 记录ID
+	IncidentID int64  `gorm:"index" json:"incidentId"`            // 事件ID
+	Type       string `json:"type"`                               // 类型
+	Message    string `json:"message"`                            // 内容
+	Operator   string `json:"operator,omitempty"`                 // 操作人
+	RefID      string `json:"refId,omitempty"`                    // 关联记录ID（告警记录、SSH登录事件、防篡改事件）
+	CreatedAt  int64  `gorm:"index" json:"createdAt"`             // 创建时间（时间戳毫秒）
+}
+
+func (IncidentEvent) TableName() string {
+	return "incident_events"
+}
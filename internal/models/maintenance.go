@@ -0,0 +1,43 @@
+package models
+
+import "gorm.io/datatypes"
+
+const (
+	MaintenanceTypeOnce      = "once"      // 一次性维护
+	MaintenanceTypeRecurring = "recurring" // 周期性维护（cron 表达式）
+)
+
+// MaintenanceWindow 维护窗口，窗口生效期间不触发告警，且不计入可用性统计
+type MaintenanceWindow struct {
+	ID          string `gorm:"primaryKey" json:"id"` // 维护窗口ID (UUID)
+	Name        string `json:"name"`                 // 名称
+	Description string `json:"description"`          // 描述
+	Enabled     bool   `json:"enabled"`              // 是否启用
+	Type        string `json:"type"`                 // 类型: once, recurring
+
+	// 一次性维护的起止时间；周期性维护时表示规则的有效期，0 表示不限制
+	StartTime int64 `json:"startTime"` // 开始时间（时间戳毫秒）
+	EndTime   int64 `json:"endTime"`   // 结束时间（时间戳毫秒）
+
+	// 周期性维护配置
+	Cron     string `json:"cron"`     // cron 表达式（分 时 日 月 周），表示每次维护的开始时间
+	Duration int    `json:"duration"` // 每次维护持续时间（分钟）
+	Timezone string `json:"timezone"` // 时区，如 Asia/Shanghai，默认使用服务器时区
+
+	// 作用范围，均为空时对所有探针和监控项生效
+	AgentIds   datatypes.JSONSlice[string] `json:"agentIds"`   // 探针ID列表
+	Tags       datatypes.JSONSlice[string] `json:"tags"`       // 探针标签列表
+	MonitorIds datatypes.JSONSlice[string] `json:"monitorIds"` // 监控项ID列表
+
+	CreatedAt int64 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt int64 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
+
+// IsGlobal 是否对所有探针和监控项生效
+func (w *MaintenanceWindow) IsGlobal() bool {
+	return len(w.AgentIds) == 0 && len(w.Tags) == 0 && len(w.MonitorIds) == 0
+}
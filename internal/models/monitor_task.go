@@ -9,7 +9,7 @@ import (
 type MonitorTask struct {
 	ID               string                                         `gorm:"primaryKey" json:"id"`                  // 任务 ID
 	Name             string                                         `gorm:"uniqueIndex" json:"name"`               // 任务名称
-	Type             string                                         `gorm:"index" json:"type"`                     // 监控类型 http/tcp
+	Type             string                                         `gorm:"index" json:"type"`                     // 监控类型 http/tcp/icmp/push
 	Target           string                                         `json:"target"`                                // 目标地址
 	Description      string                                         `json:"description"`                           // 描述信息
 	Enabled          bool                                           `json:"enabled"`                               // 是否启用
@@ -21,6 +21,10 @@ type MonitorTask struct {
 	HTTPConfig       datatypes.JSONType[protocol.HTTPMonitorConfig] `json:"httpConfig"`                            // HTTP 监控配置
 	TCPConfig        datatypes.JSONType[protocol.TCPMonitorConfig]  `json:"tcpConfig"`                             // TCP 监控配置
 	ICMPConfig       datatypes.JSONType[protocol.ICMPMonitorConfig] `json:"icmpConfig"`                            // ICMP 监控配置
+	PushConfig       datatypes.JSONType[protocol.PushMonitorConfig] `json:"pushConfig"`                            // 推送监控配置
+	PushToken        string                                         `gorm:"index" json:"pushToken"`                // 推送监控令牌（仅 push 类型）
+	LastPushAt       int64                                          `json:"lastPushAt"`                            // 最后一次收到心跳的时间
+	QuorumConfig     datatypes.JSONType[MonitorQuorumConfig]        `json:"quorumConfig"`                          // 多点确认配置
 	CreatedAt        int64                                          `gorm:"autoCreateTime:milli" json:"createdAt"` // 创建时间
 	UpdatedAt        int64                                          `gorm:"autoUpdateTime:milli" json:"updatedAt"` // 更新时间
 }
@@ -28,3 +32,38 @@ type MonitorTask struct {
 func (MonitorTask) TableName() string {
 	return "monitor_tasks"
 }
+
+// MonitorQuorumConfig 多点确认配置，多个探针共同判定监控项是否离线
+type MonitorQuorumConfig struct {
+	Enabled             bool    `json:"enabled"`             // 是否启用
+	MinFailedAgents     int     `json:"minFailedAgents"`     // 判定离线所需的最少失败探针数量，0表示不限制
+	MinFailedPercent    float64 `json:"minFailedPercent"`    // 判定离线所需的失败探针百分比，0表示不限制
+	ConsecutiveFailures int     `json:"consecutiveFailures"` // 单个探针连续失败多少次后才计为失败，默认1
+	RetryOnFailure      bool    `json:"retryOnFailure"`      // 探针检测失败时立即请求其他探针复检
+}
+
+// RequiredConsecutiveFailures 单个探针计为失败所需的连续失败次数
+func (c MonitorQuorumConfig) RequiredConsecutiveFailures() int {
+	if c.ConsecutiveFailures <= 0 {
+		return 1
+	}
+	return c.ConsecutiveFailures
+}
+
+// IsQuorumMet 判断失败探针数量是否满足离线判定条件
+// 未设置数量和百分比时，要求所有探针都失败
+func (c MonitorQuorumConfig) IsQuorumMet(failed, total int) bool {
+	if total == 0 || failed == 0 {
+		return false
+	}
+	if c.MinFailedAgents <= 0 && c.MinFailedPercent <= 0 {
+		return failed >= total
+	}
+	if c.MinFailedAgents > 0 && failed < c.MinFailedAgents {
+		return false
+	}
+	if c.MinFailedPercent > 0 && float64(failed)/float64(total)*100 < c.MinFailedPercent {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestIsQuorumMet(t *testing.T) {
+	tests := []struct {
+		name   string
+		config MonitorQuorumConfig
+		failed int
+		total  int
+		want   bool
+	}{
+		{"无探针", MonitorQuorumConfig{}, 0, 0, false},
+		{"无失败", MonitorQuorumConfig{MinFailedAgents: 1}, 0, 3, false},
+		{"默认要求全部失败", MonitorQuorumConfig{}, 2, 3, false},
+		{"默认全部失败", MonitorQuorumConfig{}, 3, 3, true},
+		{"数量不足", MonitorQuorumConfig{MinFailedAgents: 2}, 1, 3, false},
+		{"数量满足", MonitorQuorumConfig{MinFailedAgents: 2}, 2, 3, true},
+		{"百分比不足", MonitorQuorumConfig{MinFailedPercent: 50}, 1, 3, false},
+		{"百分比恰好满足", MonitorQuorumConfig{MinFailedPercent: 50}, 2, 4, true},
+		{"数量满足百分比不足", MonitorQuorumConfig{MinFailedAgents: 2, MinFailedPercent: 60}, 2, 4, false},
+		{"数量和百分比都满足", MonitorQuorumConfig{MinFailedAgents: 2, MinFailedPercent: 60}, 3, 4, true},
+		{"数量超过探针总数", MonitorQuorumConfig{MinFailedAgents: 5}, 3, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.IsQuorumMet(tt.failed, tt.total); got != tt.want {
+				t.Errorf("IsQuorumMet(%d, %d) = %v, want %v", tt.failed, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredConsecutiveFailures(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{0, 1},
+		{-1, 1},
+		{1, 1},
+		{3, 3},
+	}
+	for _, tt := range tests {
+		config := MonitorQuorumConfig{ConsecutiveFailures: tt.configured}
+		if got := config.RequiredConsecutiveFailures(); got != tt.want {
+			t.Errorf("RequiredConsecutiveFailures(%d) = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
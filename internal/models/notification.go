@@ -0,0 +1,64 @@
+package models
+
+import "gorm.io/datatypes"
+
+// 通知投递状态
+const (
+	DeliveryStatusPending = "pending" // 等待发送（含等待重试）
+	DeliveryStatusSending = "sending" // 发送中
+	DeliveryStatusSuccess = "success" // 发送成功
+	DeliveryStatusFailed  = "failed"  // 重试次数用尽，发送失败
+
+	DeliveryStatusSuperseded = "superseded" // 发送前告警已恢复，被恢复通知取代，不再发送
+)
+
+// 通知内容类型
+const (
+	DeliveryKindAlert   = "alert"   // 告警通知，发送时按渠道模板渲染消息
+	DeliveryKindMessage = "message" // 已构建好的消息（分组通知和摘要）
+)
+
+// NotificationDelivery 通知发件箱，每个渠道一条，由后台任务发送并按指数退避重试
+type NotificationDelivery struct {
+	ID            int64                           `gorm:"primaryKey;autoIncrement" json:"id"`    // 投递ID
+	ChannelType   string                          `gorm:"index" json:"channelType"`              // 通知渠道类型
+	Kind          string                          `json:"kind"`                                  // 内容类型: alert, message
+	RecordID      int64                           `gorm:"index" json:"recordId"`                 // 告警记录ID（通知类告警可能为0）
+	AgentID       string                          `gorm:"index" json:"agentId"`                  // 探针ID
+	AlertType     string                          `json:"alertType"`                             // 告警类型
+	Level         string                          `json:"level"`                                 // 告警级别
+	Summary       string                          `json:"summary"`                               // 摘要，用于列表展示
+	Message       string                          `json:"message,omitempty"`                     // 已构建好的消息（kind 为 message 时）
+	MaskIP        bool                            `json:"maskIP"`                                // 是否打码 IP
+	Record        datatypes.JSONType[AlertRecord] `json:"record"`                                // 告警记录快照
+	Agent         datatypes.JSONType[Agent]       `json:"agent"`                                 // 探针快照
+	Status        string                          `gorm:"index" json:"status"`                   // 投递状态: pending, sending, success, failed, superseded
+	Attempts      int                             `json:"attempts"`                              // 已尝试次数
+	MaxAttempts   int                             `json:"maxAttempts"`                           // 最大尝试次数
+	NextAttemptAt int64                           `gorm:"index" json:"nextAttemptAt"`            // 下次尝试时间（时间戳毫秒）
+	LastError     string                          `json:"lastError"`                             // 最近一次失败原因
+	DeliveredAt   int64                           `json:"deliveredAt,omitempty"`                 // 发送成功时间（时间戳毫秒）
+	CreatedAt     int64                           `gorm:"index" json:"createdAt"`                // 创建时间（时间戳毫秒）
+	UpdatedAt     int64                           `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// NotificationAttempt 通知投递的每次尝试记录
+type NotificationAttempt struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement" json:"id"` // 记录ID
+	DeliveryID int64  `gorm:"index" json:"deliveryId"`            // 投递ID
+	Attempt    int    `json:"attempt"`                            // 第几次尝试
+	Status     string `json:"status"`                             // 结果: success, failed
+	StatusCode int    `json:"statusCode,omitempty"`               // HTTP 状态码（HTTP 类渠道）
+	Response   string `json:"response"`                           // 响应内容
+	Error      string `json:"error"`                              // 失败原因
+	Duration   int64  `json:"duration"`                           // 耗时（毫秒）
+	CreatedAt  int64  `json:"createdAt"`                          // 尝试时间（时间戳毫秒）
+}
+
+func (NotificationAttempt) TableName() string {
+	return "notification_attempts"
+}
@@ -15,21 +15,70 @@ func (Property) TableName() string {
 
 // NotificationChannelConfig 通知渠道配置（存储在 Property 中）
 type NotificationChannelConfig struct {
-	Type    string                 `json:"type"`    // 类型: dingtalk, wecom, feishu, webhook
-	Enabled bool                   `json:"enabled"` // 是否启用
-	Config  map[string]interface{} `json:"config"`  // 配置对象
+	Type      string                 `json:"type"`                // 类型: dingtalk, wecom, feishu, slack, discord, matrix, ntfy, gotify, bark, serverchan, pushplus, pagerduty, opsgenie, webhook
+	Enabled   bool                   `json:"enabled"`             // 是否启用
+	Config    map[string]interface{} `json:"config"`              // 配置对象
+	Templates map[string]string      `json:"templates,omitempty"` // 消息模板（Go text/template），键为事件类型: firing, resolved, ssh_login, tamper, traffic, cert
+	Schedule  *ChannelSchedule       `json:"schedule,omitempty"`  // 发送时段（可选），为空时全天发送
+}
+
+// 非发送时段的处理方式
+const (
+	ScheduleOutsideDrop         = "drop"          // 丢弃
+	ScheduleOutsideDelay        = "delay"         // 延迟到下一个发送时段开始时发送
+	ScheduleOutsideCriticalOnly = "critical_only" // 仅发送严重级别的告警，其他丢弃
+)
+
+// ChannelSchedule 通知渠道的发送时段（免打扰）
+type ChannelSchedule struct {
+	Enabled  bool             `json:"enabled"`  // 是否启用
+	Timezone string           `json:"timezone"` // 时区，如 Asia/Shanghai，为空时使用服务器时区
+	Windows  []ScheduleWindow `json:"windows"`  // 发送时段，命中任一即可发送
+	Outside  string           `json:"outside"`  // 非发送时段的处理方式: drop, delay, critical_only，默认 critical_only
+}
+
+// ScheduleWindow 发送时段
+type ScheduleWindow struct {
+	Weekdays  []int  `json:"weekdays"`  // 生效的星期（0 表示周日），为空表示每天
+	StartTime string `json:"startTime"` // 开始时间（HH:MM），跨零点时从当天开始计算
+	EndTime   string `json:"endTime"`   // 结束时间（HH:MM），与开始时间相同表示全天
 }
 
 // 配置格式说明：
 // dingtalk: { "secretKey": "xxx", "signSecret": "xxx" }
 // wecom:    { "secretKey": "xxx" }
 // feishu:   { "secretKey": "xxx", "signSecret": "xxx" }
+// email:    {
+//   "smtpHost": "smtp.example.com", "smtpPort": 465,
+//   "fromEmail": "alert@example.com", "fromName": "Pika",  // fromName 可选：发件人显示名称
+//   "username": "xxx", "password": "xxx",  // username 默认与 fromEmail 相同；password 为空时不认证（本机或内网 MTA）
+//   "toEmail": ["a@example.com"], "cc": [], "bcc": [],  // 支持数组或逗号分隔，支持 "名称 <地址>" 格式
+//   "tlsMode": "",  // 可选：ssl（隐式 TLS）、starttls（强制 STARTTLS）、none（不加密），默认 465 端口使用 ssl，其他端口自动 STARTTLS
+//   "skipVerify": false,  // 可选：跳过证书校验（自签名证书的内网中继）
+//   "subject": "[{{.Level}}] {{.Agent.Name}} {{.AlertTypeName}}",  // 可选：主题模板，变量与消息模板相同
+//   "htmlTemplate": ""  // 可选：HTML 正文模板（html/template），{{.Text}} 为纯文本消息，为空时由纯文本消息生成
+// }
+// slack:    { "webhookUrl": "https://hooks.slack.com/services/..." }
+// discord:  { "webhookUrl": "https://discord.com/api/webhooks/..." }
+// matrix:   { "homeserver": "https://matrix.org", "accessToken": "xxx", "roomId": "!xxx:matrix.org" }
+// ntfy:       { "server": "https://ntfy.sh", "topic": "xxx", "token": "xxx", "tags": ["pika"] }  // server、token、tags 可选
+// gotify:     { "server": "https://gotify.example.com", "appToken": "xxx" }
+// bark:       { "server": "https://api.day.app", "deviceKey": "xxx", "sound": "alarm", "group": "Pika" }  // server、sound、group 可选
+// serverchan: { "sendKey": "xxx" }
+// pushplus:   { "token": "xxx", "topic": "xxx" }  // topic 可选，一对多推送的群组编码
+// pagerduty:  { "routingKey": "xxx", "server": "https://events.pagerduty.com" }  // server 可选
+// opsgenie:   { "apiKey": "xxx", "server": "https://api.opsgenie.com" }  // server 可选，欧洲区使用 https://api.eu.opsgenie.com
 // webhook:  {
 //   "url": "https://...",
 //   "method": "POST",  // 可选：GET, POST, PUT, PATCH, DELETE，默认 POST
 //   "headers": {"key": "value"},  // 可选：自定义请求头
-//   "customBody": ""  // 自定义请求体模板，支持变量替换
+//   "customBody": "",  // 自定义请求体模板，支持变量替换
+//   "secret": ""  // 可选：签名密钥，请求头携带 X-Pika-Timestamp、X-Pika-Signature、X-Pika-Delivery
 // }
+// 渠道配置的 "schedule" 字段为发送时段，例如工作日 09:00 ~ 22:00 之外仅发送严重告警：
+//   { "enabled": true, "timezone": "Asia/Shanghai", "outside": "critical_only",
+//     "windows": [{ "weekdays": [1, 2, 3, 4, 5], "startTime": "09:00", "endTime": "22:00" }] }
+// 所有渠道均可配置 "rateLimit"：每分钟最多发送的消息数，默认钉钉、企业微信、Telegram 20，飞书 100，Discord 30，其他 60
 
 // DNSProviderConfig DNS 服务商配置（存储在 Property 中）
 type DNSProviderConfig struct {
@@ -50,6 +99,7 @@ type WebhookConfig struct {
 	Method     string            `json:"method,omitempty"`     // 请求方法，默认 POST
 	Headers    map[string]string `json:"headers,omitempty"`    // 自定义请求头
 	CustomBody string            `json:"customBody,omitempty"` // 自定义请求体模板（支持变量）
+	Secret     string            `json:"secret,omitempty"`     // 签名密钥（可选），配置后请求携带 HMAC-SHA256 签名，接收方可使用 pkg/webhook 校验
 }
 
 type SystemConfig struct {
@@ -60,6 +110,7 @@ type SystemConfig struct {
 	DefaultView  string `json:"defaultView"`  // 默认视图 grid | list
 	CustomCSS    string `json:"customCSS"`    // 自定义 CSS
 	CustomJS     string `json:"customJS"`     // 自定义 JS
+	ServerURL    string `json:"serverUrl"`    // 站点访问地址，用于通知消息中的链接
 	Version      string `json:"-"`            // 系统版本
 }
 
@@ -107,35 +158,152 @@ func (c *PublicIPConfig) IsIPv6Target(agentID string) bool {
 	return false
 }
 
+const (
+	SLAReportScheduleMonthly = "monthly" // 每月1日发送上个自然月的报告
+	SLAReportScheduleWeekly  = "weekly"  // 每周一发送上周的报告
+)
+
+// SLAReportConfig 可用性报告定时发送配置
+type SLAReportConfig struct {
+	Enabled        bool     `json:"enabled"`        // 是否启用定时发送
+	Schedule       string   `json:"schedule"`       // 发送周期: monthly, weekly
+	Hour           int      `json:"hour"`           // 发送时间（小时，0-23，服务器时区）
+	ToEmails       []string `json:"toEmails"`       // 收件人列表，为空时使用邮件通知渠道的收件人
+	LastSentPeriod string   `json:"lastSentPeriod"` // 最近一次已发送的报告周期
+}
+
+// NotificationRoutingConfig 通知路由配置
+// 启用后按顺序匹配路由规则，告警发送到所有匹配规则的渠道；没有规则匹配时发送到默认渠道
+type NotificationRoutingConfig struct {
+	Enabled         bool                `json:"enabled"`         // 是否启用通知路由，未启用时发送到所有启用的渠道
+	Rules           []NotificationRoute `json:"rules"`           // 路由规则
+	DefaultChannels []string            `json:"defaultChannels"` // 没有规则匹配时的渠道类型，为空时发送到所有启用的渠道
+}
+
+// NotificationRoute 通知路由规则，各条件为空时不限制，多个条件需同时满足
+type NotificationRoute struct {
+	Name       string   `json:"name"`       // 规则名称
+	Levels     []string `json:"levels"`     // 告警级别: info, warning, critical
+	AlertTypes []string `json:"alertTypes"` // 告警类型: cpu, cert, service, traffic, ssh_login, tamper 等
+	Tags       []string `json:"tags"`       // 探针标签，命中任一即可
+	StartTime  string   `json:"startTime"`  // 生效开始时间（HH:MM，服务器时区），可跨零点
+	EndTime    string   `json:"endTime"`    // 生效结束时间（HH:MM，服务器时区）
+	Weekdays   []int    `json:"weekdays"`   // 生效的星期（0 表示周日）
+	Channels   []string `json:"channels"`   // 通知渠道类型
+	Stop       bool     `json:"stop"`       // 匹配后不再继续匹配后续规则
+}
+
 // AlertConfig 全局告警配置
 type AlertConfig struct {
-	Enabled       bool               `json:"enabled"`       // 是否启用全局告警
-	MaskIP        bool               `json:"maskIP"`        // 是否在通知中打码 IP 地址
-	Rules         AlertRules         `json:"rules"`         // 告警规则
-	Notifications AlertNotifications `json:"notifications"` // 通知开关
+	Enabled        bool               `json:"enabled"`        // 是否启用全局告警
+	MaskIP         bool               `json:"maskIP"`         // 是否在通知中打码 IP 地址
+	Notifications  AlertNotifications `json:"notifications"`  // 通知开关
+	RepeatInterval int                `json:"repeatInterval"` // 事件未确认且未恢复时重复通知的间隔（分钟），0表示不重复
+	Grouping       AlertGrouping      `json:"grouping"`       // 告警分组
+	Digest         AlertDigest        `json:"digest"`         // 告警摘要
+}
+
+// AlertGrouping 告警分组配置，分组键相同的告警在等待时间内合并为一条通知
+// 合并消息发送到分组内每条告警按通知路由匹配到的渠道的并集
+// 等待发送的分组只保存在内存中，服务重启后尚未发送的分组通知会丢失
+type AlertGrouping struct {
+	Enabled       bool     `json:"enabled"`       // 是否启用分组
+	By            []string `json:"by"`            // 分组字段: alertType, level, tag, agentId，为空时按 alertType 分组
+	GroupWait     int      `json:"groupWait"`     // 分组首次通知前的等待时间（秒），默认30
+	GroupInterval int      `json:"groupInterval"` // 分组内后续变化的通知间隔（秒），默认300
 }
 
-// AlertRules 告警规则
+// AlertDigest 告警摘要配置，指定级别的告警不单独通知，按周期汇总发送
+// 待汇总的告警只保存在内存中，服务重启后当前周期尚未发送的摘要会丢失
+type AlertDigest struct {
+	Enabled  bool     `json:"enabled"`  // 是否启用摘要
+	Levels   []string `json:"levels"`   // 汇总的告警级别，为空时汇总 info 级别
+	Interval int      `json:"interval"` // 摘要发送周期（分钟），默认60
+}
+
+// AlertRules 告警规则（由告警策略按继承关系合并得到）
 type AlertRules struct {
 	// CPU 告警配置
 	CPUEnabled   bool    `json:"cpuEnabled"`   // 是否启用CPU告警
 	CPUThreshold float64 `json:"cpuThreshold"` // CPU使用率阈值(0-100)
 	CPUDuration  int     `json:"cpuDuration"`  // 持续时间（秒）
+	CPUMode      string  `json:"cpuMode"`      // 告警模式: threshold（静态阈值）, anomaly（偏离历史基线）
 
 	// 内存告警配置
 	MemoryEnabled   bool    `json:"memoryEnabled"`   // 是否启用内存告警
 	MemoryThreshold float64 `json:"memoryThreshold"` // 内存使用率阈值(0-100)
 	MemoryDuration  int     `json:"memoryDuration"`  // 持续时间（秒）
+	MemoryMode      string  `json:"memoryMode"`      // 告警模式: threshold（静态阈值）, anomaly（偏离历史基线）
 
 	// 磁盘告警配置
 	DiskEnabled   bool    `json:"diskEnabled"`   // 是否启用磁盘告警
 	DiskThreshold float64 `json:"diskThreshold"` // 磁盘使用率阈值(0-100)
 	DiskDuration  int     `json:"diskDuration"`  // 持续时间（秒）
+	DiskMode      string  `json:"diskMode"`      // 告警模式: threshold（静态阈值）, anomaly（偏离历史基线）
 
 	// 网络告警配置
 	NetworkEnabled   bool    `json:"networkEnabled"`   // 是否启用网络告警
 	NetworkThreshold float64 `json:"networkThreshold"` // 网速阈值(MB/s)
 	NetworkDuration  int     `json:"networkDuration"`  // 持续时间（秒）
+	NetworkMode      string  `json:"networkMode"`      // 告警模式: threshold（静态阈值）, anomaly（偏离历史基线）
+
+	// 负载告警配置
+	LoadEnabled   bool    `json:"loadEnabled"`   // 是否启用负载告警
+	LoadThreshold float64 `json:"loadThreshold"` // 每核负载阈值（1分钟负载 / 逻辑核心数）
+	LoadDuration  int     `json:"loadDuration"`  // 持续时间（秒）
+
+	// Swap告警配置
+	SwapEnabled   bool    `json:"swapEnabled"`   // 是否启用Swap告警
+	SwapThreshold float64 `json:"swapThreshold"` // Swap使用率阈值(0-100)
+	SwapDuration  int     `json:"swapDuration"`  // 持续时间（秒）
+
+	// 磁盘IO告警配置
+	DiskIOEnabled   bool    `json:"diskIOEnabled"`   // 是否启用磁盘IO告警
+	DiskIOThreshold float64 `json:"diskIOThreshold"` // 磁盘读写吞吐阈值(MB/s)
+	DiskIODuration  int     `json:"diskIODuration"`  // 持续时间（秒）
+
+	// 温度告警配置
+	TemperatureEnabled   bool    `json:"temperatureEnabled"`   // 是否启用温度告警
+	TemperatureThreshold float64 `json:"temperatureThreshold"` // 最高传感器温度阈值(°C)
+	TemperatureDuration  int     `json:"temperatureDuration"`  // 持续时间（秒）
+
+	// GPU使用率告警配置
+	GPUEnabled   bool    `json:"gpuEnabled"`   // 是否启用GPU使用率告警
+	GPUThreshold float64 `json:"gpuThreshold"` // GPU使用率阈值(0-100)
+	GPUDuration  int     `json:"gpuDuration"`  // 持续时间（秒）
+
+	// GPU显存告警配置
+	GPUMemoryEnabled   bool    `json:"gpuMemoryEnabled"`   // 是否启用GPU显存告警
+	GPUMemoryThreshold float64 `json:"gpuMemoryThreshold"` // GPU显存使用率阈值(0-100)
+	GPUMemoryDuration  int     `json:"gpuMemoryDuration"`  // 持续时间（秒）
+
+	// GPU温度告警配置
+	GPUTemperatureEnabled   bool    `json:"gpuTemperatureEnabled"`   // 是否启用GPU温度告警
+	GPUTemperatureThreshold float64 `json:"gpuTemperatureThreshold"` // GPU温度阈值(°C)
+	GPUTemperatureDuration  int     `json:"gpuTemperatureDuration"`  // 持续时间（秒）
+
+	// ESTABLISHED连接数告警配置
+	ConnEstablishedEnabled   bool    `json:"connEstablishedEnabled"`   // 是否启用ESTABLISHED连接数告警
+	ConnEstablishedThreshold float64 `json:"connEstablishedThreshold"` // ESTABLISHED 连接数阈值
+	ConnEstablishedDuration  int     `json:"connEstablishedDuration"`  // 持续时间（秒）
+
+	// TIME_WAIT连接数告警配置
+	ConnTimeWaitEnabled   bool    `json:"connTimeWaitEnabled"`   // 是否启用TIME_WAIT连接数告警
+	ConnTimeWaitThreshold float64 `json:"connTimeWaitThreshold"` // TIME_WAIT 连接数阈值
+	ConnTimeWaitDuration  int     `json:"connTimeWaitDuration"`  // 持续时间（秒）
+
+	// 异常检测配置（告警模式为 anomaly 的指标使用）
+	AnomalyMethod      string  `json:"anomalyMethod"`      // 基线算法: stddev（均值 ± k 倍标准差）, mad（中位数 ± k 倍 MAD）
+	AnomalySensitivity float64 `json:"anomalySensitivity"` // 正常范围的倍数 k，越大越不敏感
+	AnomalyMinHistory  int     `json:"anomalyMinHistory"`  // 生效前至少需要的历史数据（天）
+
+	// 磁盘写满预测告警配置
+	DiskForecastEnabled   bool `json:"diskForecastEnabled"`   // 是否启用磁盘写满预测告警
+	DiskForecastWindow    int  `json:"diskForecastWindow"`    // 线性拟合使用的历史数据（小时）
+	DiskForecastThreshold int  `json:"diskForecastThreshold"` // 预计写满的剩余时间阈值（小时）
+
+	// 流量超额预测告警配置
+	TrafficForecastEnabled bool `json:"trafficForecastEnabled"` // 是否启用流量超额预测告警（需配置流量限额和重置日期）
 
 	// HTTPS 证书告警配置
 	CertEnabled   bool    `json:"certEnabled"`   // 是否启用证书告警
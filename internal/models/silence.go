@@ -0,0 +1,72 @@
+package models
+
+import "gorm.io/datatypes"
+
+const (
+	SilenceStatePending = "pending" // 未开始
+	SilenceStateActive  = "active"  // 生效中
+	SilenceStateExpired = "expired" // 已过期
+)
+
+// 静默匹配字段
+const (
+	SilenceMatcherAlertType = "alertType" // 告警类型
+	SilenceMatcherAgentID   = "agentId"   // 探针ID
+	SilenceMatcherTag       = "tag"       // 探针标签
+	SilenceMatcherMonitorID = "monitorId" // 监控项ID
+	SilenceMatcherLevel     = "level"     // 告警级别
+)
+
+// 静默匹配运算符
+const (
+	SilenceOperatorEqual    = "="  // 等于
+	SilenceOperatorNotEqual = "!=" // 不等于
+	SilenceOperatorRegex    = "=~" // 正则匹配
+)
+
+// Silence 告警静默，匹配的告警仍会记录状态和告警记录，但不发送通知
+type Silence struct {
+	ID        string                              `gorm:"primaryKey" json:"id"`  // 静默ID (UUID)
+	Matchers  datatypes.JSONSlice[SilenceMatcher] `json:"matchers"`              // 匹配条件，全部满足时生效
+	StartsAt  int64                               `gorm:"index" json:"startsAt"` // 开始时间（时间戳毫秒）
+	EndsAt    int64                               `gorm:"index" json:"endsAt"`   // 结束时间（时间戳毫秒）
+	CreatedBy string                              `json:"createdBy"`             // 创建人
+	Comment   string                              `json:"comment"`               // 备注
+
+	CreatedAt int64 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt int64 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (Silence) TableName() string {
+	return "silences"
+}
+
+// AlertRecordSilence 告警记录被静默抑制的历史，一条告警可能先后被多个静默抑制
+type AlertRecordSilence struct {
+	RecordID     int64  `gorm:"primaryKey;autoIncrement:false" json:"recordId"` // 告警记录ID
+	SilenceID    string `gorm:"primaryKey;index" json:"silenceId"`              // 静默ID
+	SuppressedAt int64  `json:"suppressedAt"`                                   // 开始抑制时间（时间戳毫秒）
+}
+
+func (AlertRecordSilence) TableName() string {
+	return "alert_record_silences"
+}
+
+// SilenceMatcher 静默匹配条件
+type SilenceMatcher struct {
+	Name     string `json:"name"`     // 匹配字段: alertType, agentId, tag, monitorId, level
+	Operator string `json:"operator"` // 运算符: =, !=, =~，默认为 =
+	Value    string `json:"value"`    // 匹配值
+}
+
+// State 静默在指定时间的状态
+func (s *Silence) State(now int64) string {
+	switch {
+	case now < s.StartsAt:
+		return SilenceStatePending
+	case now >= s.EndsAt:
+		return SilenceStateExpired
+	default:
+		return SilenceStateActive
+	}
+}
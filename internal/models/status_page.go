@@ -0,0 +1,64 @@
+package models
+
+import "gorm.io/datatypes"
+
+// StatusPage 公开状态页
+type StatusPage struct {
+	ID          string                                 `gorm:"primaryKey" json:"id"`                  // 状态页ID (UUID)
+	Slug        string                                 `gorm:"uniqueIndex" json:"slug"`               // 访问路径标识
+	Title       string                                 `json:"title"`                                 // 标题
+	Description string                                 `json:"description"`                           // 描述
+	Domain      string                                 `gorm:"index" json:"domain"`                   // 自定义域名，通过 Host 请求头匹配
+	Enabled     bool                                   `json:"enabled"`                               // 是否启用
+	Sections    datatypes.JSONSlice[StatusPageSection] `json:"sections"`                              // 分组
+	CreatedAt   int64                                  `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt   int64                                  `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (StatusPage) TableName() string {
+	return "status_pages"
+}
+
+// StatusPageSection 状态页分组
+type StatusPageSection struct {
+	Name       string   `json:"name"`       // 分组名称
+	MonitorIds []string `json:"monitorIds"` // 监控项ID列表
+	AgentIds   []string `json:"agentIds"`   // 探针ID列表
+}
+
+const (
+	IncidentStatusInvestigating = "investigating" // 调查中
+	IncidentStatusIdentified    = "identified"    // 已定位
+	IncidentStatusMonitoring    = "monitoring"    // 观察中
+	IncidentStatusResolved      = "resolved"      // 已解决
+)
+
+// StatusPageIncident 状态页事件公告
+type StatusPageIncident struct {
+	ID           string `gorm:"primaryKey" json:"id"`                  // 事件ID (UUID)
+	StatusPageID string `gorm:"index" json:"statusPageId"`             // 状态页ID
+	Title        string `json:"title"`                                 // 标题
+	Status       string `json:"status"`                                // 当前状态: investigating, identified, monitoring, resolved
+	ResolvedAt   int64  `json:"resolvedAt"`                            // 解决时间（时间戳毫秒）
+	CreatedAt    int64  `gorm:"index" json:"createdAt"`                // 创建时间（时间戳毫秒）
+	UpdatedAt    int64  `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+
+	Updates []StatusPageIncidentUpdate `gorm:"-" json:"updates"` // 进展更新（按时间倒序）
+}
+
+func (StatusPageIncident) TableName() string {
+	return "status_page_incidents"
+}
+
+// StatusPageIncidentUpdate 事件进展更新
+type StatusPageIncidentUpdate struct {
+	ID         string `gorm:"primaryKey" json:"id"`    // 更新ID (UUID)
+	IncidentID string `gorm:"index" json:"incidentId"` // 事件ID
+	Status     string `json:"status"`                  // 状态
+	Message    string `json:"message"`                 // 内容
+	CreatedAt  int64  `json:"createdAt"`               // 创建时间（时间戳毫秒）
+}
+
+func (StatusPageIncidentUpdate) TableName() string {
+	return "status_page_incident_updates"
+}
@@ -315,6 +315,8 @@ type MonitorData struct {
 	// TLS 证书信息（仅用于 HTTPS）
 	CertExpiryTime int64 `json:"certExpiryTime,omitempty"` // 证书过期时间(毫秒时间戳)
 	CertDaysLeft   int   `json:"certDaysLeft,omitempty"`   // 证书剩余天数
+	// 服务端统计信息
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"` // 连续失败次数
 }
 
 // TamperProtectConfig 防篡改保护配置（增量更新）
@@ -1,8 +1,11 @@
 package protocol
 
 // MonitorConfigPayload 监控配置 payload
+// Full 为 true 时表示探针需要执行的完整监控项集合，探针按各监控项的 Interval 自主调度检测；
+// 否则表示立即执行一次检测
 type MonitorConfigPayload struct {
-	Interval int           `json:"interval"`
+	Interval int           `json:"interval"` // 默认检测频率（秒），监控项未设置时使用
+	Full     bool          `json:"full,omitempty"`
 	Items    []MonitorItem `json:"items"`
 }
 
@@ -11,6 +14,7 @@ type MonitorItem struct {
 	ID         string             `json:"id"`
 	Type       string             `json:"type"`
 	Target     string             `json:"target"`
+	Interval   int                `json:"interval,omitempty"` // 检测频率（秒）
 	HTTPConfig *HTTPMonitorConfig `json:"httpConfig,omitempty"`
 	TCPConfig  *TCPMonitorConfig  `json:"tcpConfig,omitempty"`
 	ICMPConfig *ICMPMonitorConfig `json:"icmpConfig,omitempty"`
@@ -36,3 +40,8 @@ type ICMPMonitorConfig struct {
 	Timeout int `json:"timeout"` // 超时时间（秒）
 	Count   int `json:"count"`   // Ping 次数
 }
+
+// PushMonitorConfig 推送（心跳）监控配置
+type PushMonitorConfig struct {
+	Grace int `json:"grace"` // 宽限时间（秒），超过 检测频率+宽限时间 未收到心跳则判定为离线
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+// AgentStatusEventRepo 探针上下线事件数据访问层
+type AgentStatusEventRepo struct {
+	orz.Repository[models.AgentStatusEvent, int64]
+}
+
+func NewAgentStatusEventRepo(db *gorm.DB) *AgentStatusEventRepo {
+	return &AgentStatusEventRepo{
+		Repository: orz.NewRepository[models.AgentStatusEvent, int64](db),
+	}
+}
+
+// FindByAgentBetween 查询探针在 [start, end) 时间范围内的上下线事件（按时间升序）
+func (r *AgentStatusEventRepo) FindByAgentBetween(ctx context.Context, agentID string, start, end int64) ([]models.AgentStatusEvent, error) {
+	var events []models.AgentStatusEvent
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND created_at >= ? AND created_at < ?", agentID, start, end).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// FindLastBefore 查询探针在指定时间之前的最后一个上下线事件，不存在时返回 nil
+func (r *AgentStatusEventRepo) FindLastBefore(ctx context.Context, agentID string, ts int64) (*models.AgentStatusEvent, error) {
+	var event models.AgentStatusEvent
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND created_at < ?", agentID, ts).
+		Order("created_at DESC, id DESC").
+		First(&event).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// DeleteByAgentID 删除探针的所有上下线事件
+func (r *AgentStatusEventRepo) DeleteByAgentID(ctx context.Context, agentID string) error {
+	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.AgentStatusEvent{}).Error
+}
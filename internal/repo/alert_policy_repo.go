@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AlertPolicyRepo struct {
+	orz.Repository[models.AlertPolicy, string]
+	db *gorm.DB
+}
+
+func NewAlertPolicyRepo(db *gorm.DB) *AlertPolicyRepo {
+	return &AlertPolicyRepo{
+		Repository: orz.NewRepository[models.AlertPolicy, string](db),
+		db:         db,
+	}
+}
+
+// FindAllPolicies 查找所有告警策略（按创建时间升序）
+func (r *AlertPolicyRepo) FindAllPolicies(ctx context.Context) ([]models.AlertPolicy, error) {
+	var policies []models.AlertPolicy
+	err := r.db.WithContext(ctx).
+		Order("created_at ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// CountChildren 统计以指定策略为父策略的策略数量
+func (r *AlertPolicyRepo) CountChildren(ctx context.Context, parentID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.AlertPolicy{}).
+		Where("parent_id = ?", parentID).
+		Count(&count).Error
+	return count, err
+}
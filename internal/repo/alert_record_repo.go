@@ -2,10 +2,12 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/go-orz/orz"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AlertRecordRepo struct {
@@ -22,12 +24,39 @@ func NewAlertRecordRepo(db *gorm.DB) *AlertRecordRepo {
 
 // CreateAlertRecord 创建告警记录
 func (r *AlertRecordRepo) CreateAlertRecord(ctx context.Context, record *models.AlertRecord) error {
-	return r.db.WithContext(ctx).Create(record).Error
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		return err
+	}
+	return r.saveSuppression(ctx, record)
 }
 
 // UpdateAlertRecord 更新告警记录
 func (r *AlertRecordRepo) UpdateAlertRecord(ctx context.Context, record *models.AlertRecord) error {
-	return r.db.WithContext(ctx).Save(record).Error
+	if err := r.db.WithContext(ctx).Save(record).Error; err != nil {
+		return err
+	}
+	return r.saveSuppression(ctx, record)
+}
+
+// saveSuppression 记录告警被当前静默抑制的历史，已记录时忽略
+func (r *AlertRecordRepo) saveSuppression(ctx context.Context, record *models.AlertRecord) error {
+	if record.SuppressedBy == "" {
+		return nil
+	}
+	suppression := &models.AlertRecordSilence{
+		RecordID:     record.ID,
+		SilenceID:    record.SuppressedBy,
+		SuppressedAt: time.Now().UnixMilli(),
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(suppression).Error
+}
+
+// SuppressedRecordIDs 被指定静默抑制过的告警记录ID子查询
+func (r *AlertRecordRepo) SuppressedRecordIDs(ctx context.Context, silenceID string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&models.AlertRecordSilence{}).
+		Select("record_id").
+		Where("silence_id = ?", silenceID)
 }
 
 // GetAlertRecordByID 根据记录ID获取告警记录
@@ -55,6 +84,41 @@ func (r *AlertRecordRepo) GetLatestAlertRecord(ctx context.Context, configID str
 	return &record, nil
 }
 
+// CountBySilenceIDs 统计各静默抑制过的告警数量，静默结束后仍计入
+func (r *AlertRecordRepo) CountBySilenceIDs(ctx context.Context, silenceIDs []string) (map[string]int64, error) {
+	var rows []struct {
+		SilenceID string
+		Count     int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.AlertRecordSilence{}).
+		Select("silence_id, COUNT(*) AS count").
+		Where("silence_id IN ?", silenceIDs).
+		Group("silence_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.SilenceID] = row.Count
+	}
+	return counts, nil
+}
+
+// FindFiringSilenced 查找仍在告警中且正被静默的记录
+func (r *AlertRecordRepo) FindFiringSilenced(ctx context.Context) ([]models.AlertRecord, error) {
+	var records []models.AlertRecord
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND suppressed_by <> ?", "firing", "").
+		Find(&records).Error
+	return records, err
+}
+
 func (r *AlertRecordRepo) Clear(ctx context.Context) error {
+	if err := r.db.WithContext(ctx).Where("1=1").Delete(&models.AlertRecordSilence{}).Error; err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Where("1=1").Delete(&models.AlertRecord{}).Error
 }
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type AlertRuleRepo struct {
+	orz.Repository[models.AlertRule, string]
+	db *gorm.DB
+}
+
+func NewAlertRuleRepo(db *gorm.DB) *AlertRuleRepo {
+	return &AlertRuleRepo{
+		Repository: orz.NewRepository[models.AlertRule, string](db),
+		db:         db,
+	}
+}
+
+// FindAllEnabled 查找所有已启用的表达式规则
+func (r *AlertRuleRepo) FindAllEnabled(ctx context.Context) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
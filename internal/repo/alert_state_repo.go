@@ -46,6 +46,27 @@ func (r *AlertStateRepo) DeleteAlertStatesByConfigID(ctx context.Context, config
 	return r.db.WithContext(ctx).Where("config_id = ?", configID).Delete(&models.AlertState{}).Error
 }
 
+// FindByAlertType 查找指定告警类型的所有状态
+func (r *AlertStateRepo) FindByAlertType(ctx context.Context, alertType string) ([]models.AlertState, error) {
+	var states []models.AlertState
+	err := r.db.WithContext(ctx).Where("alert_type = ?", alertType).Find(&states).Error
+	return states, err
+}
+
+// FindFiringByAlertType 查找指定告警类型中正在告警的状态
+func (r *AlertStateRepo) FindFiringByAlertType(ctx context.Context, alertType string) ([]models.AlertState, error) {
+	var states []models.AlertState
+	err := r.db.WithContext(ctx).Where("alert_type = ? AND is_firing = ?", alertType, true).Find(&states).Error
+	return states, err
+}
+
+// FindByAgentAndAlertType 查找探针指定告警类型的所有状态
+func (r *AlertStateRepo) FindByAgentAndAlertType(ctx context.Context, agentID, alertType string) ([]models.AlertState, error) {
+	var states []models.AlertState
+	err := r.db.WithContext(ctx).Where("agent_id = ? AND alert_type = ?", agentID, alertType).Find(&states).Error
+	return states, err
+}
+
 // LoadAllStates 加载所有告警状态
 func (r *AlertStateRepo) LoadAllStates(ctx context.Context) ([]models.AlertState, error) {
 	var states []models.AlertState
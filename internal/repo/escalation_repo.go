@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type EscalationPolicyRepo struct {
+	orz.Repository[models.EscalationPolicy, string]
+	db *gorm.DB
+}
+
+func NewEscalationPolicyRepo(db *gorm.DB) *EscalationPolicyRepo {
+	return &EscalationPolicyRepo{
+		Repository: orz.NewRepository[models.EscalationPolicy, string](db),
+		db:         db,
+	}
+}
+
+// FindAllEnabled 查找所有已启用的升级策略（按创建时间升序）
+func (r *EscalationPolicyRepo) FindAllEnabled(ctx context.Context) ([]models.EscalationPolicy, error) {
+	var policies []models.EscalationPolicy
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("created_at ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+type AlertEscalationRepo struct {
+	orz.Repository[models.AlertEscalation, int64]
+	db *gorm.DB
+}
+
+func NewAlertEscalationRepo(db *gorm.DB) *AlertEscalationRepo {
+	return &AlertEscalationRepo{
+		Repository: orz.NewRepository[models.AlertEscalation, int64](db),
+		db:         db,
+	}
+}
+
+// FindDue 查找到期需要执行的升级或重复通知
+func (r *AlertEscalationRepo) FindDue(ctx context.Context, now int64) ([]models.AlertEscalation, error) {
+	var escalations []models.AlertEscalation
+	err := r.db.WithContext(ctx).
+		Where("(next_escalate_at > 0 AND next_escalate_at <= ?) OR (next_repeat_at > 0 AND next_repeat_at <= ?)", now, now).
+		Find(&escalations).Error
+	return escalations, err
+}
+
+// FindByRecordID 查找告警记录的升级，不存在时返回 nil
+func (r *AlertEscalationRepo) FindByRecordID(ctx context.Context, recordID int64) (*models.AlertEscalation, error) {
+	var escalation models.AlertEscalation
+	err := r.db.WithContext(ctx).Where("record_id = ?", recordID).First(&escalation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &escalation, nil
+}
+
+// DeleteByRecordID 删除告警记录的升级
+func (r *AlertEscalationRepo) DeleteByRecordID(ctx context.Context, recordID int64) error {
+	return r.db.WithContext(ctx).Where("record_id = ?", recordID).Delete(&models.AlertEscalation{}).Error
+}
+
+// Clear 清空所有升级
+func (r *AlertEscalationRepo) Clear(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("1=1").Delete(&models.AlertEscalation{}).Error
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+// IncidentRepo 告警事件数据访问层
+type IncidentRepo struct {
+	orz.Repository[models.Incident, int64]
+}
+
+func NewIncidentRepo(db *gorm.DB) *IncidentRepo {
+	return &IncidentRepo{
+		Repository: orz.NewRepository[models.Incident, int64](db),
+	}
+}
+
+// IncidentFilter 告警事件查询条件
+type IncidentFilter struct {
+	State     string // 状态
+	AgentID   string // 探针ID
+	AlertType string // 告警类型
+	Level     string // 告警级别
+	Keyword   string // 标题关键字
+	Start     int64  // 开始时间下限（时间戳毫秒）
+	End       int64  // 开始时间上限（时间戳毫秒）
+}
+
+// FindPage 按条件分页查询告警事件（按开始时间倒序）
+func (r *IncidentRepo) FindPage(ctx context.Context, filter IncidentFilter, pageIndex, pageSize int) ([]models.Incident, int64, error) {
+	db := r.GetDB(ctx).Model(&models.Incident{})
+	if filter.State != "" {
+		db = db.Where("state = ?", filter.State)
+	}
+	if filter.AgentID != "" {
+		db = db.Where("agent_id = ?", filter.AgentID)
+	}
+	if filter.AlertType != "" {
+		db = db.Where("alert_type = ?", filter.AlertType)
+	}
+	if filter.Level != "" {
+		db = db.Where("level = ?", filter.Level)
+	}
+	if filter.Keyword != "" {
+		db = db.Where("title LIKE ? OR agent_name LIKE ?", "%"+filter.Keyword+"%", "%"+filter.Keyword+"%")
+	}
+	if filter.Start > 0 {
+		db = db.Where("started_at >= ?", filter.Start)
+	}
+	if filter.End > 0 {
+		db = db.Where("started_at < ?", filter.End)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	incidents := make([]models.Incident, 0)
+	err := db.Order("started_at DESC, id DESC").
+		Offset((pageIndex - 1) * pageSize).
+		Limit(pageSize).
+		Find(&incidents).Error
+	return incidents, total, err
+}
+
+// FindActiveByGroupKey 查询归并键对应的未解决事件，不存在时返回 nil
+func (r *IncidentRepo) FindActiveByGroupKey(ctx context.Context, groupKey string) (*models.Incident, error) {
+	return r.findOne(r.GetDB(ctx).
+		Where("group_key = ? AND state <> ?", groupKey, models.IncidentStateResolved).
+		Order("id DESC"))
+}
+
+// FindLatestResolvedByGroupKey 查询归并键对应的最近一个在指定时间之后自动解决的事件，不存在时返回 nil
+func (r *IncidentRepo) FindLatestResolvedByGroupKey(ctx context.Context, groupKey string, since int64) (*models.Incident, error) {
+	return r.findOne(r.GetDB(ctx).
+		Where("group_key = ? AND state = ? AND resolved_by = ? AND resolved_at >= ?", groupKey, models.IncidentStateResolved, "", since).
+		Order("resolved_at DESC"))
+}
+
+// FindOpenNotifiedBefore 查询未确认且最后通知时间早于指定时间的事件
+func (r *IncidentRepo) FindOpenNotifiedBefore(ctx context.Context, before int64) ([]models.Incident, error) {
+	var incidents []models.Incident
+	err := r.GetDB(ctx).
+		Where("state = ? AND last_notified_at > 0 AND last_notified_at <= ?", models.IncidentStateOpen, before).
+		Find(&incidents).Error
+	return incidents, err
+}
+
+func (r *IncidentRepo) findOne(db *gorm.DB) (*models.Incident, error) {
+	var incident models.Incident
+	if err := db.First(&incident).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &incident, nil
+}
+
+func (r *IncidentRepo) Clear(ctx context.Context) error {
+	return r.GetDB(ctx).Where("1=1").Delete(&models.Incident{}).Error
+}
+
+// IncidentEventRepo 告警事件时间线数据访问层
+type IncidentEventRepo struct {
+	orz.Repository[models.IncidentEvent, int64]
+}
+
+func NewIncidentEventRepo(db *gorm.DB) *IncidentEventRepo {
+	return &IncidentEventRepo{
+		Repository: orz.NewRepository[models.IncidentEvent, int64](db),
+	}
+}
+
+// FindByIncidentID 查询事件的时间线记录（按时间升序）
+func (r *IncidentEventRepo) FindByIncidentID(ctx context.Context, incidentID int64) ([]models.IncidentEvent, error) {
+	var events []models.IncidentEvent
+	err := r.GetDB(ctx).
+		Where("incident_id = ?", incidentID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	return events, err
+}
+
+func (r *IncidentEventRepo) Clear(ctx context.Context) error {
+	return r.GetDB(ctx).Where("1=1").Delete(&models.IncidentEvent{}).Error
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type MaintenanceRepo struct {
+	orz.Repository[models.MaintenanceWindow, string]
+	db *gorm.DB
+}
+
+func NewMaintenanceRepo(db *gorm.DB) *MaintenanceRepo {
+	return &MaintenanceRepo{
+		Repository: orz.NewRepository[models.MaintenanceWindow, string](db),
+		db:         db,
+	}
+}
+
+// FindAllEnabled 查找所有已启用的维护窗口
+func (r *MaintenanceRepo) FindAllEnabled(ctx context.Context) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("created_at DESC").
+		Find(&windows).Error
+	return windows, err
+}
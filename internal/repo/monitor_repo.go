@@ -79,3 +79,28 @@ func (r *MonitorRepo) FindByEnabledAndType(ctx context.Context, enabled bool, ty
 	}
 	return monitors, nil
 }
+
+// FindByPushToken 根据推送令牌查找监控任务
+func (r *MonitorRepo) FindByPushToken(ctx context.Context, token string) (*models.MonitorTask, error) {
+	var task models.MonitorTask
+	err := r.GetDB(ctx).
+		Where("push_token = ? AND type = ?", token, "push").
+		First(&task).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// SaveConfig 保存监控任务配置，不覆盖编辑期间由心跳更新的最后上报时间
+func (r *MonitorRepo) SaveConfig(ctx context.Context, task *models.MonitorTask) error {
+	return r.GetDB(ctx).Omit("last_push_at").Save(task).Error
+}
+
+// UpdateLastPushAt 更新最后一次收到心跳的时间
+func (r *MonitorRepo) UpdateLastPushAt(ctx context.Context, id string, lastPushAt int64) error {
+	return r.GetDB(ctx).
+		Model(&models.MonitorTask{}).
+		Where("id = ?", id).
+		UpdateColumn("last_push_at", lastPushAt).Error
+}
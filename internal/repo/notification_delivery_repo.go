@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type NotificationDeliveryRepo struct {
+	orz.Repository[models.NotificationDelivery, int64]
+	db *gorm.DB
+}
+
+func NewNotificationDeliveryRepo(db *gorm.DB) *NotificationDeliveryRepo {
+	return &NotificationDeliveryRepo{
+		Repository: orz.NewRepository[models.NotificationDelivery, int64](db),
+		db:         db,
+	}
+}
+
+// FindDue 查找到达发送时间的待发送通知，按创建顺序返回
+func (r *NotificationDeliveryRepo) FindDue(ctx context.Context, now int64, limit int) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Claim 将待发送的通知标记为发送中，通知已被其他任务领取或已取代时返回 false
+func (r *NotificationDeliveryRepo) Claim(ctx context.Context, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.NotificationDelivery{}).
+		Where("id = ? AND status = ?", id, models.DeliveryStatusPending).
+		Update("status", models.DeliveryStatusSending)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Finish 写入发送结果，只更新仍处于发送中的通知，返回 false 表示状态已被其他任务修改
+func (r *NotificationDeliveryRepo) Finish(ctx context.Context, delivery *models.NotificationDelivery) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.NotificationDelivery{}).
+		Where("id = ? AND status = ?", delivery.ID, models.DeliveryStatusSending).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SupersedePending 将同一告警记录在指定渠道上等待发送的告警通知标记为已取代
+func (r *NotificationDeliveryRepo) SupersedePending(ctx context.Context, recordID int64, channelType string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.NotificationDelivery{}).
+		Where("record_id = ? AND channel_type = ? AND kind = ? AND status = ?",
+			recordID, channelType, models.DeliveryKindAlert, models.DeliveryStatusPending).
+		Update("status", models.DeliveryStatusSuperseded)
+	return result.RowsAffected, result.Error
+}
+
+// ResetSending 将发送中的通知重置为待发送（服务重启时发送中断的通知）
+func (r *NotificationDeliveryRepo) ResetSending(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.NotificationDelivery{}).
+		Where("status = ?", models.DeliveryStatusSending).
+		Update("status", models.DeliveryStatusPending)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteFinishedBefore 删除指定时间之前已结束（成功、失败或已取代）的通知及其尝试记录
+func (r *NotificationDeliveryRepo) DeleteFinishedBefore(ctx context.Context, before int64) error {
+	statuses := []string{models.DeliveryStatusSuccess, models.DeliveryStatusFailed, models.DeliveryStatusSuperseded}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		finished := tx.Model(&models.NotificationDelivery{}).
+			Select("id").
+			Where("status IN ? AND created_at < ?", statuses, before)
+		if err := tx.Where("delivery_id IN (?)", finished).Delete(&models.NotificationAttempt{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("status IN ? AND created_at < ?", statuses, before).
+			Delete(&models.NotificationDelivery{}).Error
+	})
+}
+
+type NotificationAttemptRepo struct {
+	orz.Repository[models.NotificationAttempt, int64]
+	db *gorm.DB
+}
+
+func NewNotificationAttemptRepo(db *gorm.DB) *NotificationAttemptRepo {
+	return &NotificationAttemptRepo{
+		Repository: orz.NewRepository[models.NotificationAttempt, int64](db),
+		db:         db,
+	}
+}
+
+// FindByDeliveryID 查询投递的全部尝试记录
+func (r *NotificationAttemptRepo) FindByDeliveryID(ctx context.Context, deliveryID int64) ([]models.NotificationAttempt, error) {
+	var attempts []models.NotificationAttempt
+	err := r.db.WithContext(ctx).
+		Where("delivery_id = ?", deliveryID).
+		Order("attempt ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
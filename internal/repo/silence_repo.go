@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type SilenceRepo struct {
+	orz.Repository[models.Silence, string]
+	db *gorm.DB
+}
+
+func NewSilenceRepo(db *gorm.DB) *SilenceRepo {
+	return &SilenceRepo{
+		Repository: orz.NewRepository[models.Silence, string](db),
+		db:         db,
+	}
+}
+
+// FindActive 查找指定时间生效的静默
+func (r *SilenceRepo) FindActive(ctx context.Context, now int64) ([]models.Silence, error) {
+	var silences []models.Silence
+	err := r.db.WithContext(ctx).
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Order("created_at ASC").
+		Find(&silences).Error
+	return silences, err
+}
+
+// FindByState 按状态查找静默，state 为空时返回全部
+func (r *SilenceRepo) FindByState(ctx context.Context, state string, now int64) ([]models.Silence, error) {
+	db := r.db.WithContext(ctx)
+	switch state {
+	case models.SilenceStatePending:
+		db = db.Where("starts_at > ?", now)
+	case models.SilenceStateActive:
+		db = db.Where("starts_at <= ? AND ends_at > ?", now, now)
+	case models.SilenceStateExpired:
+		db = db.Where("ends_at <= ?", now)
+	}
+
+	var silences []models.Silence
+	err := db.Order("created_at DESC").Find(&silences).Error
+	return silences, err
+}
@@ -24,3 +24,13 @@ func NewSSHLoginEventRepo(db *gorm.DB) *SSHLoginEventRepo {
 func (r *SSHLoginEventRepo) DeleteEventsByAgentID(ctx context.Context, agentID string) error {
 	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.SSHLoginEvent{}).Error
 }
+
+// FindByAgentBetween 查询探针在 [start, end) 时间范围内的登录事件（按时间升序）
+func (r *SSHLoginEventRepo) FindByAgentBetween(ctx context.Context, agentID string, start, end int64) ([]models.SSHLoginEvent, error) {
+	var events []models.SSHLoginEvent
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND timestamp >= ? AND timestamp < ?", agentID, start, end).
+		Order("timestamp ASC").
+		Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+// StatusPageRepo 状态页数据访问层
+type StatusPageRepo struct {
+	orz.Repository[models.StatusPage, string]
+}
+
+func NewStatusPageRepo(db *gorm.DB) *StatusPageRepo {
+	return &StatusPageRepo{
+		Repository: orz.NewRepository[models.StatusPage, string](db),
+	}
+}
+
+// FindBySlug 根据访问路径标识查询状态页，不存在时返回 nil
+func (r *StatusPageRepo) FindBySlug(ctx context.Context, slug string) (*models.StatusPage, error) {
+	return r.findOne(ctx, "slug = ?", slug)
+}
+
+// FindByDomain 根据自定义域名查询状态页，不存在时返回 nil
+func (r *StatusPageRepo) FindByDomain(ctx context.Context, domain string) (*models.StatusPage, error) {
+	return r.findOne(ctx, "domain = ?", domain)
+}
+
+func (r *StatusPageRepo) findOne(ctx context.Context, query string, args ...any) (*models.StatusPage, error) {
+	var page models.StatusPage
+	err := r.GetDB(ctx).Where(query, args...).First(&page).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &page, nil
+}
+
+// StatusPageIncidentRepo 状态页事件数据访问层
+type StatusPageIncidentRepo struct {
+	orz.Repository[models.StatusPageIncident, string]
+}
+
+func NewStatusPageIncidentRepo(db *gorm.DB) *StatusPageIncidentRepo {
+	return &StatusPageIncidentRepo{
+		Repository: orz.NewRepository[models.StatusPageIncident, string](db),
+	}
+}
+
+// FindByPageID 查询状态页的事件（按创建时间倒序），limit 为 0 时不限制数量
+func (r *StatusPageIncidentRepo) FindByPageID(ctx context.Context, pageID string, limit int) ([]models.StatusPageIncident, error) {
+	var incidents []models.StatusPageIncident
+	db := r.GetDB(ctx).
+		Where("status_page_id = ?", pageID).
+		Order("created_at DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	err := db.Find(&incidents).Error
+	return incidents, err
+}
+
+// FindVisibleByPageID 查询状态页中未解决或在指定时间之后创建的事件（按创建时间倒序）
+func (r *StatusPageIncidentRepo) FindVisibleByPageID(ctx context.Context, pageID string, since int64) ([]models.StatusPageIncident, error) {
+	var incidents []models.StatusPageIncident
+	err := r.GetDB(ctx).
+		Where("status_page_id = ? AND (status <> ? OR created_at >= ?)", pageID, models.IncidentStatusResolved, since).
+		Order("created_at DESC").
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// DeleteByPageID 删除状态页的所有事件
+func (r *StatusPageIncidentRepo) DeleteByPageID(ctx context.Context, pageID string) error {
+	return r.GetDB(ctx).Where("status_page_id = ?", pageID).Delete(&models.StatusPageIncident{}).Error
+}
+
+// StatusPageIncidentUpdateRepo 事件进展更新数据访问层
+type StatusPageIncidentUpdateRepo struct {
+	orz.Repository[models.StatusPageIncidentUpdate, string]
+}
+
+func NewStatusPageIncidentUpdateRepo(db *gorm.DB) *StatusPageIncidentUpdateRepo {
+	return &StatusPageIncidentUpdateRepo{
+		Repository: orz.NewRepository[models.StatusPageIncidentUpdate, string](db),
+	}
+}
+
+// FindByIncidentIDs 查询多个事件的进展更新（按创建时间倒序）
+func (r *StatusPageIncidentUpdateRepo) FindByIncidentIDs(ctx context.Context, incidentIDs []string) ([]models.StatusPageIncidentUpdate, error) {
+	var updates []models.StatusPageIncidentUpdate
+	if len(incidentIDs) == 0 {
+		return updates, nil
+	}
+	err := r.GetDB(ctx).
+		Where("incident_id IN ?", incidentIDs).
+		Order("created_at DESC").
+		Find(&updates).Error
+	return updates, err
+}
+
+// DeleteByIncidentIDs 删除多个事件的进展更新
+func (r *StatusPageIncidentUpdateRepo) DeleteByIncidentIDs(ctx context.Context, incidentIDs []string) error {
+	if len(incidentIDs) == 0 {
+		return nil
+	}
+	return r.GetDB(ctx).Where("incident_id IN ?", incidentIDs).Delete(&models.StatusPageIncidentUpdate{}).Error
+}
@@ -22,3 +22,13 @@ func NewTamperEventRepo(db *gorm.DB) *TamperEventRepo {
 func (r *TamperEventRepo) DeleteEventsByAgentID(ctx context.Context, agentID string) error {
 	return r.GetDB(ctx).Where("agent_id = ?", agentID).Delete(&models.TamperEvent{}).Error
 }
+
+// FindByAgentBetween 查询探针在 [start, end) 时间范围内的防篡改事件（按时间升序）
+func (r *TamperEventRepo) FindByAgentBetween(ctx context.Context, agentID string, start, end int64) ([]models.TamperEvent, error) {
+	var events []models.TamperEvent
+	err := r.GetDB(ctx).
+		Where("agent_id = ? AND timestamp >= ? AND timestamp < ?", agentID, start, end).
+		Order("timestamp ASC").
+		Find(&events).Error
+	return events, err
+}
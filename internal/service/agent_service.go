@@ -22,6 +22,7 @@ type AgentService struct {
 	AgentRepo         *repo.AgentRepo
 	TamperEventRepo   *repo.TamperEventRepo
 	SSHLoginEventRepo *repo.SSHLoginEventRepo
+	StatusEventRepo   *repo.AgentStatusEventRepo
 	apiKeyService     *ApiKeyService
 	metricService     *MetricService
 	geoipService      *GeoIPService
@@ -34,6 +35,7 @@ func NewAgentService(logger *zap.Logger, db *gorm.DB, apiKeyService *ApiKeyServi
 		AgentRepo:         repo.NewAgentRepo(db),
 		TamperEventRepo:   repo.NewTamperEventRepo(db),
 		SSHLoginEventRepo: repo.NewSSHLoginEventRepo(db),
+		StatusEventRepo:   repo.NewAgentStatusEventRepo(db),
 		apiKeyService:     apiKeyService,
 		metricService:     metricService,
 		geoipService:      geoipService,
@@ -74,6 +76,7 @@ func (s *AgentService) RegisterAgent(ctx context.Context, ip string, info *proto
 		if err := s.AgentRepo.UpdateById(ctx, &existingAgent); err != nil {
 			return nil, err
 		}
+		s.recordStatusEvent(ctx, existingAgent.ID, models.AgentStatusOnline, now)
 		s.logger.Info("agent re-registered",
 			zap.String("agentID", existingAgent.ID),
 			zap.String("name", info.Name),
@@ -102,6 +105,7 @@ func (s *AgentService) RegisterAgent(ctx context.Context, ip string, info *proto
 	if err := s.AgentRepo.Create(ctx, agent); err != nil {
 		return nil, err
 	}
+	s.recordStatusEvent(ctx, agent.ID, models.AgentStatusOnline, now)
 
 	s.logger.Info("agent registered successfully",
 		zap.String("agentID", agent.ID),
@@ -114,7 +118,27 @@ func (s *AgentService) RegisterAgent(ctx context.Context, ip string, info *proto
 
 // UpdateAgentStatus 更新探针状态
 func (s *AgentService) UpdateAgentStatus(ctx context.Context, agentID string, status int) error {
-	return s.AgentRepo.UpdateStatus(ctx, agentID, status, time.Now().UnixMilli())
+	now := time.Now().UnixMilli()
+	if err := s.AgentRepo.UpdateStatus(ctx, agentID, status, now); err != nil {
+		return err
+	}
+	// 上线事件在注册时记录，心跳只会更新为在线状态
+	if status == 0 {
+		s.recordStatusEvent(ctx, agentID, models.AgentStatusOffline, now)
+	}
+	return nil
+}
+
+// recordStatusEvent 记录探针上下线事件
+func (s *AgentService) recordStatusEvent(ctx context.Context, agentID, status string, ts int64) {
+	event := &models.AgentStatusEvent{
+		AgentID:   agentID,
+		Status:    status,
+		CreatedAt: ts,
+	}
+	if err := s.StatusEventRepo.Create(ctx, event); err != nil {
+		s.logger.Error("记录探针上下线事件失败", zap.String("agentId", agentID), zap.Error(err))
+	}
 }
 
 // UpdatePublicIP 更新探针的公网 IP 信息
@@ -383,7 +407,13 @@ func (s *AgentService) DeleteAgent(ctx context.Context, agentID string) error {
 			return err
 		}
 
-		// 4. 最后删除探针本身
+		// 4. 删除探针的上下线事件
+		if err := s.StatusEventRepo.DeleteByAgentID(ctx, agentID); err != nil {
+			s.logger.Error("删除探针上下线事件失败", zap.String("agentId", agentID), zap.Error(err))
+			return err
+		}
+
+		// 5. 最后删除探针本身
 		if err := s.AgentRepo.DeleteById(ctx, agentID); err != nil {
 			s.logger.Error("删除探针失败", zap.String("agentId", agentID), zap.Error(err))
 			return err
@@ -538,6 +568,10 @@ func (s *AgentService) InitStatus(ctx context.Context) error {
 		if err := s.AgentRepo.UpdateStatus(ctx, agent.ID, 0, 0); err != nil {
 			return err
 		}
+		// 服务端重启前在线的探针，以最后上线时间作为离线时间
+		if agent.Status == 1 && agent.LastSeenAt > 0 {
+			s.recordStatusEvent(ctx, agent.ID, models.AgentStatusOffline, agent.LastSeenAt)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// 预测告警类型
+const (
+	AlertTypeDiskForecast    = "disk_forecast"
+	AlertTypeTrafficForecast = "traffic_forecast"
+)
+
+// diskForecastResolveMargin 磁盘写满预测告警的恢复余量，已触发的告警在预计剩余时间超过阈值的 1.2 倍后才恢复，
+// 避免剩余时间在阈值附近波动时反复触发和恢复
+const diskForecastResolveMargin = 1.2
+
+// diskForecastBreached 判断磁盘写满预测是否低于阈值，已触发时按恢复余量判断
+func diskForecastBreached(forecast *DiskForecast, threshold float64, firing bool) bool {
+	if forecast.FullAt <= 0 {
+		return false
+	}
+	if firing {
+		return forecast.HoursToFull < threshold*diskForecastResolveMargin
+	}
+	return forecast.HoursToFull < threshold
+}
+
+// checkDiskForecast 检查磁盘写满预测告警，每个挂载点单独触发和恢复
+func (s *AlertService) checkDiskForecast(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, now int64) {
+	rules := &policy.Rules
+	forecasts, err := s.forecastService.ForecastDisks(ctx, agent.ID, time.Duration(rules.DiskForecastWindow)*time.Hour)
+	if err != nil {
+		// 查询失败时保持当前状态，避免误恢复
+		s.logger.Warn("预测磁盘写满时间失败", zap.String("agentId", agent.ID), zap.Error(err))
+		return
+	}
+
+	threshold := float64(rules.DiskForecastThreshold)
+	checked := make(map[string]bool, len(forecasts))
+	for _, forecast := range forecasts {
+		state := s.loadForecastState(ctx, agent, AlertTypeDiskForecast, forecast.MountPoint)
+		checked[state.ID] = true
+		state.Threshold = threshold
+		breached := diskForecastBreached(&forecast, threshold, state.IsFiring)
+		s.updateMetricState(ctx, policy, agent, state, forecast.HoursToFull, breached, 0, now)
+	}
+
+	// 挂载点已不存在或数据不足时恢复告警
+	states, err := s.AlertStateRepo.FindByAgentAndAlertType(ctx, agent.ID, AlertTypeDiskForecast)
+	if err != nil {
+		s.logger.Error("查询告警状态失败", zap.Error(err))
+		return
+	}
+	for i := range states {
+		state := &states[i]
+		if checked[state.ID] || !state.IsFiring {
+			continue
+		}
+		s.updateMetricState(ctx, policy, agent, state, state.Value, false, 0, now)
+	}
+}
+
+// checkTrafficForecast 检查流量超额预测告警，已超出限额时由流量告警通知，预测告警恢复
+func (s *AlertService) checkTrafficForecast(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, now int64) {
+	stats := agent.TrafficStats.Data()
+	forecast := ForecastTraffic(&stats, time.UnixMilli(now))
+
+	state := s.loadForecastState(ctx, agent, AlertTypeTrafficForecast, "")
+	state.Threshold = 100
+	if forecast == nil {
+		s.updateMetricState(ctx, policy, agent, state, 0, false, 0, now)
+		return
+	}
+	breached := forecast.ExceedAt > 0
+	s.updateMetricState(ctx, policy, agent, state, forecast.ProjectedPercent, breached, 0, now)
+}
+
+// loadForecastState 加载预测告警状态，target 为告警对象（如磁盘挂载点）
+func (s *AlertService) loadForecastState(ctx context.Context, agent *models.Agent, alertType, target string) *models.AlertState {
+	stateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
+	if target != "" {
+		stateKey += ":" + target
+	}
+
+	state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
+	if err != nil {
+		state = &models.AlertState{
+			ID:        stateKey,
+			AgentID:   agent.ID,
+			AlertType: alertType,
+		}
+	}
+	state.Target = target
+	return state
+}
+
+// buildForecastMessage 构建预测告警消息
+func buildForecastMessage(state *models.AlertState) string {
+	switch state.AlertType {
+	case AlertTypeDiskForecast:
+		return fmt.Sprintf("磁盘%s按近期增长趋势预计%.1f小时后写满，低于阈值%.0f小时", state.Target, state.Value, state.Threshold)
+	default:
+		return fmt.Sprintf("按本周期的平均使用速度，预计周期结束时流量使用达到限额的%.2f%%", state.Value)
+	}
+}
+
+// forecastLevel 计算预测告警级别，磁盘预计在阈值的三分之一时间内写满时为严重
+func forecastLevel(state *models.AlertState) string {
+	if state.AlertType == AlertTypeDiskForecast && state.Value < state.Threshold/3 {
+		return "critical"
+	}
+	return "warning"
+}
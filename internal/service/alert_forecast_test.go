@@ -0,0 +1,31 @@
+package service
+
+import "testing"
+
+func TestDiskForecastBreached(t *testing.T) {
+	const threshold = 72
+
+	tests := []struct {
+		name        string
+		hoursToFull float64
+		fullAt      int64
+		firing      bool
+		want        bool
+	}{
+		{"低于阈值触发", 71, 1, false, true},
+		{"高于阈值不触发", 80, 1, false, false},
+		{"已触发时在恢复余量内保持", 80, 1, true, true},
+		{"已触发时超过恢复余量恢复", 86.5, 1, true, false},
+		{"不会写满时不触发", 0, 0, false, false},
+		{"不会写满时恢复", 0, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := &DiskForecast{HoursToFull: tt.hoursToFull, FullAt: tt.fullAt}
+			if got := diskForecastBreached(forecast, threshold, tt.firing); got != tt.want {
+				t.Fatalf("diskForecastBreached() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
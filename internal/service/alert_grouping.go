@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultGroupWait      = 30 * time.Second
+	defaultGroupInterval  = 5 * time.Minute
+	defaultDigestInterval = 60 * time.Minute
+
+	// groupMessageMaxItems 分组和摘要消息中最多列出的告警数量
+	groupMessageMaxItems = 30
+)
+
+// groupedAlert 等待合并通知的告警
+type groupedAlert struct {
+	record models.AlertRecord
+	agent  models.Agent
+}
+
+// alertGroup 告警分组
+type alertGroup struct {
+	key          string
+	pending      []groupedAlert         // 尚未通知的变化
+	pendingSince time.Time              // 最早一条未通知变化的时间
+	lastSentAt   time.Time              // 上次发送分组通知的时间
+	firing       map[int64]groupedAlert // 已通知且仍在告警中的记录
+}
+
+// alertGrouper 告警分组和摘要的内存缓冲，服务重启后未发送的分组会丢失
+type alertGrouper struct {
+	mu     sync.Mutex
+	groups map[string]*alertGroup
+
+	digest       []groupedAlert
+	digestSentAt time.Time
+}
+
+func newAlertGrouper() *alertGrouper {
+	return &alertGrouper{
+		groups:       make(map[string]*alertGroup),
+		digestSentAt: time.Now(),
+	}
+}
+
+// groupKey 按配置的分组字段计算分组键
+func groupKey(by []string, record *models.AlertRecord, agent *models.Agent) string {
+	if len(by) == 0 {
+		by = []string{models.SilenceMatcherAlertType}
+	}
+	parts := make([]string, 0, len(by))
+	for _, field := range by {
+		var value string
+		switch field {
+		case models.SilenceMatcherAlertType:
+			value = record.AlertType
+		case models.SilenceMatcherLevel:
+			value = record.Level
+		case models.SilenceMatcherAgentID:
+			value = record.AgentID
+		case models.SilenceMatcherTag:
+			if agent != nil {
+				tags := slices.Clone(agent.Tags)
+				slices.Sort(tags)
+				value = strings.Join(tags, ",")
+			}
+		default:
+			continue
+		}
+		parts = append(parts, field+"="+value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// add 将告警变化加入分组，已触发但尚未通知的告警恢复时两者都不再通知
+// 恢复的告警不属于该分组时（例如启用分组前触发的告警）返回 false，由调用方单独通知
+func (g *alertGrouper) add(key string, record *models.AlertRecord, agent *models.Agent, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[key]
+	if !ok {
+		group = &alertGroup{key: key, firing: make(map[int64]groupedAlert)}
+		g.groups[key] = group
+	}
+
+	if record.Status == "resolved" {
+		index := slices.IndexFunc(group.pending, func(item groupedAlert) bool {
+			return item.record.ID == record.ID && item.record.Status == "firing"
+		})
+		if index >= 0 {
+			group.pending = slices.Delete(group.pending, index, index+1)
+			return true
+		}
+		if _, ok := group.firing[record.ID]; !ok {
+			if len(group.pending) == 0 && len(group.firing) == 0 {
+				delete(g.groups, key)
+			}
+			return false
+		}
+	}
+
+	if len(group.pending) == 0 {
+		group.pendingSince = now
+	}
+	group.pending = append(group.pending, groupedAlert{record: *record, agent: *agent})
+	return true
+}
+
+// due 取出到期需要发送的分组，并清理已空闲的分组
+func (g *alertGrouper) due(wait, interval time.Duration, now time.Time) []*alertGroup {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var groups []*alertGroup
+	for key, group := range g.groups {
+		if len(group.pending) == 0 {
+			if len(group.firing) == 0 && now.Sub(group.lastSentAt) >= interval {
+				delete(g.groups, key)
+			}
+			continue
+		}
+
+		if group.lastSentAt.IsZero() {
+			if now.Sub(group.pendingSince) < wait {
+				continue
+			}
+		} else if now.Sub(group.lastSentAt) < interval {
+			continue
+		}
+
+		// 复制一份交给调用方发送，避免持锁发送通知
+		sent := &alertGroup{
+			key:     group.key,
+			pending: group.pending,
+			firing:  make(map[int64]groupedAlert),
+		}
+		for _, item := range group.pending {
+			if item.record.Status == "firing" {
+				group.firing[item.record.ID] = item
+			} else {
+				delete(group.firing, item.record.ID)
+			}
+		}
+		for id, item := range group.firing {
+			sent.firing[id] = item
+		}
+		group.pending = nil
+		group.lastSentAt = now
+		groups = append(groups, sent)
+	}
+	return groups
+}
+
+// addDigest 将告警变化加入摘要
+func (g *alertGrouper) addDigest(record *models.AlertRecord, agent *models.Agent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.digest = append(g.digest, groupedAlert{record: *record, agent: *agent})
+}
+
+// dueDigest 周期到达时取出摘要内容
+func (g *alertGrouper) dueDigest(interval time.Duration, now time.Time) ([]groupedAlert, time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now.Sub(g.digestSentAt) < interval {
+		return nil, time.Time{}, false
+	}
+	items, since := g.digest, g.digestSentAt
+	g.digest = nil
+	g.digestSentAt = now
+	return items, since, len(items) > 0
+}
+
+// clear 清空缓冲中的分组和摘要
+func (g *alertGrouper) clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.groups = make(map[string]*alertGroup)
+	g.digest = nil
+}
+
+// queueGroupedNotification 按分组或摘要配置缓冲告警通知，返回 false 表示需要立即单独通知
+func (s *AlertService) queueGroupedNotification(ctx context.Context, record *models.AlertRecord, agent *models.Agent) bool {
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return false
+	}
+
+	if digest := alertConfig.Digest; digest.Enabled {
+		levels := digest.Levels
+		if len(levels) == 0 {
+			levels = []string{"info"}
+		}
+		if slices.Contains(levels, record.Level) {
+			s.grouper.addDigest(record, agent)
+			return true
+		}
+	}
+
+	if grouping := alertConfig.Grouping; grouping.Enabled {
+		return s.grouper.add(groupKey(grouping.By, record, agent), record, agent, time.Now())
+	}
+	return false
+}
+
+// FlushAlertGroups 发送到期的分组通知和告警摘要
+func (s *AlertService) FlushAlertGroups(ctx context.Context) error {
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	wait := durationOrDefault(alertConfig.Grouping.GroupWait, time.Second, defaultGroupWait)
+	interval := durationOrDefault(alertConfig.Grouping.GroupInterval, time.Second, defaultGroupInterval)
+	for _, group := range s.grouper.due(wait, interval, now) {
+		s.sendGroupNotification(ctx, group, alertConfig.MaskIP)
+	}
+
+	digestInterval := durationOrDefault(alertConfig.Digest.Interval, time.Minute, defaultDigestInterval)
+	if items, since, ok := s.grouper.dueDigest(digestInterval, now); ok {
+		s.sendDigestNotification(ctx, items, since, now, alertConfig.MaskIP)
+	}
+	return nil
+}
+
+func durationOrDefault(value int, unit, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+	return time.Duration(value) * unit
+}
+
+// sendGroupNotification 发送分组通知
+func (s *AlertService) sendGroupNotification(ctx context.Context, group *alertGroup, maskIP bool) {
+	var firing, resolved []groupedAlert
+	for _, item := range group.pending {
+		if item.record.Status == "firing" {
+			firing = append(firing, item)
+		} else {
+			resolved = append(resolved, item)
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("🔔 告警分组通知（新触发 %d 条，已恢复 %d 条）", len(firing), len(resolved)),
+		fmt.Sprintf("分组: %s", group.key),
+	}
+	if len(firing) > 0 {
+		lines = append(lines, "", "触发:")
+		lines = append(lines, groupedAlertLines(firing, maskIP)...)
+	}
+	if len(resolved) > 0 {
+		lines = append(lines, "", "恢复:")
+		lines = append(lines, groupedAlertLines(resolved, maskIP)...)
+	}
+	lines = append(lines, "", fmt.Sprintf("当前仍在告警: %d 条", len(group.firing)))
+
+	s.sendBufferedMessage(ctx, strings.Join(lines, "\n"), group.pending, "已发送分组告警通知")
+}
+
+// sendDigestNotification 发送告警摘要
+func (s *AlertService) sendDigestNotification(ctx context.Context, items []groupedAlert, since, now time.Time, maskIP bool) {
+	counts := map[string]int{}
+	var types []string
+	for _, item := range items {
+		if counts[item.record.AlertType] == 0 {
+			types = append(types, item.record.AlertType)
+		}
+		counts[item.record.AlertType]++
+	}
+
+	lines := []string{
+		fmt.Sprintf("📋 告警摘要（%s ~ %s）", utils.FormatTimestamp(since.UnixMilli()), utils.FormatTimestamp(now.UnixMilli())),
+		fmt.Sprintf("共 %d 条告警变化", len(items)),
+		"",
+	}
+	for _, alertType := range types {
+		lines = append(lines, fmt.Sprintf("%s: %d 条", getAlertTypeMetadata(alertType).Name, counts[alertType]))
+	}
+	lines = append(lines, "", "明细:")
+	lines = append(lines, groupedAlertLines(items, maskIP)...)
+
+	s.sendBufferedMessage(ctx, strings.Join(lines, "\n"), items, "已发送告警摘要")
+}
+
+// leadsGroup 判断 a 是否比 b 更适合代表合并消息的级别，触发优先于恢复，同状态时级别高者优先
+func leadsGroup(a, b models.AlertRecord) bool {
+	if (a.Status == "firing") != (b.Status == "firing") {
+		return a.Status == "firing"
+	}
+	return alertLevelOrder[a.Level] > alertLevelOrder[b.Level]
+}
+
+// groupedAlertLines 生成告警列表，超过上限时省略
+func groupedAlertLines(items []groupedAlert, maskIP bool) []string {
+	lines := make([]string, 0, min(len(items), groupMessageMaxItems)+1)
+	for i, item := range items {
+		if i == groupMessageMaxItems {
+			lines = append(lines, fmt.Sprintf("... 等共 %d 条", len(items)))
+			break
+		}
+		status := getLevelIcon(item.record.Level)
+		if item.record.Status == "resolved" {
+			status = "✅"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (%s) %s: %s",
+			status, item.agent.Name, formatAgentIP(&item.agent, maskIP),
+			getAlertTypeMetadata(item.record.AlertType).Name, item.record.Message))
+	}
+	return lines
+}
+
+// sendBufferedMessage 发送合并后的消息，并记录到相关告警事件的时间线
+// 每条告警分别按通知路由选择渠道，合并消息发送到所有告警路由结果的并集，值班系统渠道只接收路由到该渠道的告警
+func (s *AlertService) sendBufferedMessage(ctx context.Context, message string, items []groupedAlert, action string) {
+	if len(items) == 0 {
+		return
+	}
+
+	var errs []error
+	var messageChannels []models.NotificationChannelConfig
+	channelTypes := map[string]bool{}
+	for _, item := range items {
+		channels, err := s.propertyService.GetRoutedChannelConfigs(ctx, &item.record, &item.agent)
+		if err != nil {
+			// 单条告警路由失败不影响其他告警已解析的渠道
+			errs = append(errs, fmt.Errorf("获取告警 %d 的通知渠道失败: %w", item.record.ID, err))
+			continue
+		}
+
+		// 值班系统渠道需要按告警逐条发送，才能用去重键关联触发和恢复
+		var oncallChannels []models.NotificationChannelConfig
+		for _, channel := range channels {
+			if IsOncallChannel(channel.Type) {
+				oncallChannels = append(oncallChannels, channel)
+			} else if !channelTypes[channel.Type] {
+				messageChannels = append(messageChannels, channel)
+			}
+			channelTypes[channel.Type] = true
+		}
+		if len(oncallChannels) > 0 {
+			if err := s.outbox.Enqueue(ctx, oncallChannels, &item.record, &item.agent, false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(messageChannels) > 0 {
+		// 按最高级别的告警判断发送时段和渠道的颜色、优先级，避免合并消息中的严重告警被免打扰丢弃或静默推送
+		// 有新触发的告警时优先以触发的告警为准，全部为恢复时按恢复通知发送
+		lead := items[0]
+		for _, item := range items {
+			if leadsGroup(item.record, lead.record) {
+				lead = item
+			}
+		}
+		if err := s.outbox.EnqueueMessage(ctx, messageChannels, message, &lead.record, &lead.agent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		s.logger.Error("发送合并通知失败", zap.Error(err))
+	}
+	if len(channelTypes) == 0 {
+		return
+	}
+
+	timeline := fmt.Sprintf("%s（%d 个渠道）", action, len(channelTypes))
+	if err != nil {
+		timeline = fmt.Sprintf("%s，部分发送失败: %v", timeline, err)
+	}
+	notified := map[int64]bool{}
+	for _, item := range items {
+		incidentID := item.record.IncidentID
+		if incidentID == 0 || notified[incidentID] {
+			continue
+		}
+		notified[incidentID] = true
+		if err := s.incidentService.OnNotificationSent(ctx, incidentID, timeline); err != nil {
+			s.logger.Error("记录告警事件通知失败", zap.Int64("incidentId", incidentID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func groupedRecord(id int64, status string) *models.AlertRecord {
+	return &models.AlertRecord{ID: id, AgentID: "a1", AlertType: "cpu", Level: "warning", Status: status}
+}
+
+func TestAlertGrouperAdd(t *testing.T) {
+	agent := &models.Agent{ID: "a1"}
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+
+	t.Run("未通知的触发和恢复相互抵消", func(t *testing.T) {
+		g := newAlertGrouper()
+		if !g.add("k", groupedRecord(1, "firing"), agent, now) {
+			t.Fatal("firing add() = false")
+		}
+		if !g.add("k", groupedRecord(1, "resolved"), agent, now) {
+			t.Fatal("resolved add() = false")
+		}
+		if n := len(g.groups["k"].pending); n != 0 {
+			t.Fatalf("pending = %d, want 0", n)
+		}
+	})
+
+	t.Run("不属于分组的恢复单独通知", func(t *testing.T) {
+		g := newAlertGrouper()
+		if g.add("k", groupedRecord(1, "resolved"), agent, now) {
+			t.Fatal("add() = true, want false")
+		}
+		if _, ok := g.groups["k"]; ok {
+			t.Fatal("空分组未清理")
+		}
+	})
+
+	t.Run("已通知的告警恢复加入分组", func(t *testing.T) {
+		g := newAlertGrouper()
+		g.add("k", groupedRecord(1, "firing"), agent, now)
+		g.due(0, time.Minute, now)
+		if !g.add("k", groupedRecord(1, "resolved"), agent, now.Add(time.Second)) {
+			t.Fatal("add() = false, want true")
+		}
+		group := g.groups["k"]
+		if len(group.pending) != 1 || !group.pendingSince.Equal(now.Add(time.Second)) {
+			t.Fatalf("pending = %d since %v", len(group.pending), group.pendingSince)
+		}
+	})
+}
+
+func TestAlertGrouperDue(t *testing.T) {
+	agent := &models.Agent{ID: "a1"}
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+	wait, interval := 30*time.Second, 5*time.Minute
+
+	g := newAlertGrouper()
+	g.add("k", groupedRecord(1, "firing"), agent, start)
+	g.add("k", groupedRecord(2, "firing"), agent, start.Add(10*time.Second))
+
+	steps := []struct {
+		name        string
+		add         *models.AlertRecord
+		at          time.Duration
+		wantPending int
+		wantFiring  int
+		wantGroups  int
+	}{
+		{"等待时间内不发送", nil, 29 * time.Second, 0, 0, 0},
+		{"首次等待到期发送", nil, 30 * time.Second, 2, 2, 1},
+		{"间隔内不发送", groupedRecord(1, "resolved"), 1 * time.Minute, 0, 0, 0},
+		{"间隔到期发送变化", nil, 30*time.Second + interval, 1, 1, 1},
+		{"没有变化不发送", nil, 30*time.Second + 2*interval, 0, 0, 0},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			now := start.Add(step.at)
+			if step.add != nil {
+				g.add("k", step.add, agent, now)
+			}
+			groups := g.due(wait, interval, now)
+			if len(groups) != step.wantGroups {
+				t.Fatalf("due() = %d groups, want %d", len(groups), step.wantGroups)
+			}
+			if len(groups) == 0 {
+				return
+			}
+			if got := len(groups[0].pending); got != step.wantPending {
+				t.Fatalf("pending = %d, want %d", got, step.wantPending)
+			}
+			if got := len(groups[0].firing); got != step.wantFiring {
+				t.Fatalf("firing = %d, want %d", got, step.wantFiring)
+			}
+		})
+	}
+
+	// 全部恢复并通知后，空闲超过间隔的分组被清理
+	end := start.Add(30*time.Second + 3*interval)
+	g.add("k", groupedRecord(2, "resolved"), agent, end)
+	if groups := g.due(wait, interval, end); len(groups) != 1 || len(groups[0].firing) != 0 {
+		t.Fatalf("due() = %+v, want one group without firing", groups)
+	}
+	g.due(wait, interval, end.Add(interval))
+	if _, ok := g.groups["k"]; ok {
+		t.Fatal("空闲分组未清理")
+	}
+}
+
+func TestAlertGrouperDueDigest(t *testing.T) {
+	agent := &models.Agent{ID: "a1"}
+	g := newAlertGrouper()
+	start := g.digestSentAt
+	interval := time.Hour
+
+	if _, _, ok := g.dueDigest(interval, start.Add(interval)); ok {
+		t.Fatal("没有告警时不应发送摘要")
+	}
+	if !g.digestSentAt.Equal(start.Add(interval)) {
+		t.Fatal("周期到达后应重置摘要开始时间")
+	}
+
+	g.addDigest(groupedRecord(1, "firing"), agent)
+	g.addDigest(groupedRecord(1, "resolved"), agent)
+	if _, _, ok := g.dueDigest(interval, start.Add(interval+time.Minute)); ok {
+		t.Fatal("周期未到不应发送摘要")
+	}
+
+	items, since, ok := g.dueDigest(interval, start.Add(2*interval))
+	if !ok || len(items) != 2 {
+		t.Fatalf("dueDigest() = %d items, %v, want 2 items", len(items), ok)
+	}
+	if !since.Equal(start.Add(interval)) {
+		t.Fatalf("since = %v, want %v", since, start.Add(interval))
+	}
+	if len(g.digest) != 0 {
+		t.Fatal("发送后应清空摘要")
+	}
+}
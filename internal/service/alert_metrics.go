@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// AlertMetricValues 指标告警使用的探针最新指标，未上报的指标为 0
+type AlertMetricValues struct {
+	CPU             float64 // CPU使用率(%)
+	Memory          float64 // 内存使用率(%)
+	Disk            float64 // 磁盘使用率(%)
+	NetworkSpeed    float64 // 网速(MB/s)
+	LoadPerCore     float64 // 每核负载（1分钟负载 / 逻辑核心数）
+	Swap            float64 // Swap使用率(%)
+	DiskIO          float64 // 磁盘读写吞吐(MB/s)
+	Temperature     float64 // 最高传感器温度(°C)
+	GPU             float64 // 最高GPU使用率(%)
+	GPUMemory       float64 // 最高GPU显存使用率(%)
+	GPUTemperature  float64 // 最高GPU温度(°C)
+	ConnEstablished float64 // ESTABLISHED 连接数
+	ConnTimeWait    float64 // TIME_WAIT 连接数
+}
+
+// NewAlertMetricValues 从探针最新指标中提取告警使用的值
+func NewAlertMetricValues(latest *metric.LatestMetrics) AlertMetricValues {
+	var values AlertMetricValues
+
+	if latest.CPU != nil {
+		values.CPU = latest.CPU.UsagePercent
+	}
+
+	if latest.Memory != nil {
+		values.Memory = latest.Memory.UsagePercent
+		if latest.Memory.SwapTotal > 0 {
+			values.Swap = float64(latest.Memory.SwapUsed) / float64(latest.Memory.SwapTotal) * 100
+		}
+	}
+
+	if latest.Disk != nil {
+		values.Disk = latest.Disk.UsagePercent
+	}
+
+	if latest.Network != nil {
+		// 网速 = (发送速率 + 接收速率) / 1024 / 1024 (转换为 MB/s)
+		values.NetworkSpeed = float64(latest.Network.TotalBytesSentRate+latest.Network.TotalBytesRecvRate) / 1024 / 1024
+	}
+
+	// 负载随主机信息上报，按逻辑核心数折算
+	if latest.Host != nil && latest.CPU != nil && latest.CPU.LogicalCores > 0 {
+		values.LoadPerCore = latest.Host.Load1 / float64(latest.CPU.LogicalCores)
+	}
+
+	if latest.DiskIO != nil {
+		values.DiskIO = float64(latest.DiskIO.TotalReadBytesRate+latest.DiskIO.TotalWriteBytesRate) / 1024 / 1024
+	}
+
+	for _, temp := range latest.Temp {
+		values.Temperature = max(values.Temperature, temp.Temperature)
+	}
+
+	// 多块 GPU 取最大值
+	for _, gpu := range latest.GPU {
+		values.GPU = max(values.GPU, gpu.Utilization)
+		if gpu.MemoryTotal > 0 {
+			values.GPUMemory = max(values.GPUMemory, float64(gpu.MemoryUsed)/float64(gpu.MemoryTotal)*100)
+		}
+		values.GPUTemperature = max(values.GPUTemperature, gpu.Temperature)
+	}
+
+	if latest.NetworkConnection != nil {
+		values.ConnEstablished = float64(latest.NetworkConnection.Established)
+		values.ConnTimeWait = float64(latest.NetworkConnection.TimeWait)
+	}
+
+	return values
+}
+
+// extendedMetricNames 扩展指标告警在消息中的名称
+var extendedMetricNames = map[string]string{
+	"load":             "每核负载",
+	"swap":             "Swap使用率",
+	"disk_io":          "磁盘IO吞吐",
+	"temperature":      "最高温度",
+	"gpu":              "GPU使用率",
+	"gpu_memory":       "GPU显存使用率",
+	"gpu_temperature":  "GPU温度",
+	"conn_established": "ESTABLISHED连接数",
+	"conn_time_wait":   "TIME_WAIT连接数",
+}
+
+// checkExtendedMetricAlerts 检查负载、Swap、磁盘IO、温度、GPU 和连接数告警
+func (s *AlertService) checkExtendedMetricAlerts(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, values AlertMetricValues, now int64) {
+	rules := &policy.Rules
+	checks := []struct {
+		alertType string
+		enabled   bool
+		value     float64
+		threshold float64
+		duration  int
+	}{
+		{"load", rules.LoadEnabled, values.LoadPerCore, rules.LoadThreshold, rules.LoadDuration},
+		{"swap", rules.SwapEnabled, values.Swap, rules.SwapThreshold, rules.SwapDuration},
+		{"disk_io", rules.DiskIOEnabled, values.DiskIO, rules.DiskIOThreshold, rules.DiskIODuration},
+		{"temperature", rules.TemperatureEnabled, values.Temperature, rules.TemperatureThreshold, rules.TemperatureDuration},
+		{"gpu", rules.GPUEnabled, values.GPU, rules.GPUThreshold, rules.GPUDuration},
+		{"gpu_memory", rules.GPUMemoryEnabled, values.GPUMemory, rules.GPUMemoryThreshold, rules.GPUMemoryDuration},
+		{"gpu_temperature", rules.GPUTemperatureEnabled, values.GPUTemperature, rules.GPUTemperatureThreshold, rules.GPUTemperatureDuration},
+		{"conn_established", rules.ConnEstablishedEnabled, values.ConnEstablished, rules.ConnEstablishedThreshold, rules.ConnEstablishedDuration},
+		{"conn_time_wait", rules.ConnTimeWaitEnabled, values.ConnTimeWait, rules.ConnTimeWaitThreshold, rules.ConnTimeWaitDuration},
+	}
+	for _, check := range checks {
+		if check.enabled {
+			s.checkAlert(ctx, policy, agent, check.alertType, check.value, check.threshold, check.duration, now)
+		}
+	}
+}
+
+// isExtendedMetric 判断是否为扩展指标告警
+func isExtendedMetric(alertType string) bool {
+	_, ok := extendedMetricNames[alertType]
+	return ok
+}
+
+// buildExtendedMetricMessage 构建扩展指标告警消息，数值单位与通知元数据一致
+func buildExtendedMetricMessage(state *models.AlertState) string {
+	unit := getAlertTypeMetadata(state.AlertType).ValueUnit
+	format := "%.2f"
+	if state.AlertType == "conn_established" || state.AlertType == "conn_time_wait" {
+		format = "%.0f"
+	}
+	return fmt.Sprintf("%s持续%d秒超过%s%s，当前值%s%s",
+		extendedMetricNames[state.AlertType],
+		state.Duration,
+		fmt.Sprintf(format, state.Threshold), unit,
+		fmt.Sprintf(format, state.Value), unit,
+	)
+}
+
+// extendedMetricLevel 计算扩展指标的告警级别
+// 百分比指标与 CPU、内存一致按超出的百分点计算，其他指标按超出阈值的比例计算
+func (s *AlertService) extendedMetricLevel(state *models.AlertState) string {
+	if getAlertTypeMetadata(state.AlertType).ValueUnit == "%" {
+		return s.calculateLevel(state.Value, state.Threshold)
+	}
+	if state.Threshold <= 0 {
+		return "warning"
+	}
+	ratio := state.Value / state.Threshold
+	if ratio < 1.2 {
+		return "info"
+	} else if ratio < 1.5 {
+		return "warning"
+	}
+	return "critical"
+}
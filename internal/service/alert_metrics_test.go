@@ -0,0 +1,59 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+func TestNewAlertMetricValues(t *testing.T) {
+	latest := &metric.LatestMetrics{
+		CPU:    &protocol.CPUData{LogicalCores: 4, UsagePercent: 30},
+		Memory: &protocol.MemoryData{UsagePercent: 60, SwapTotal: 4096, SwapUsed: 1024},
+		DiskIO: &metric.DiskIOSummary{TotalReadBytesRate: 3 * 1024 * 1024, TotalWriteBytesRate: 1024 * 1024},
+		Host:   &protocol.HostInfoData{Load1: 6},
+		GPU: []protocol.GPUData{
+			{Utilization: 40, MemoryTotal: 1000, MemoryUsed: 900, Temperature: 70},
+			{Utilization: 95, MemoryTotal: 1000, MemoryUsed: 100, Temperature: 65},
+		},
+		Temp:              []protocol.TemperatureData{{Temperature: 55}, {Temperature: 88}},
+		NetworkConnection: &protocol.NetworkConnectionData{Established: 1200, TimeWait: 300},
+	}
+
+	values := NewAlertMetricValues(latest)
+	checks := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"CPU", values.CPU, 30},
+		{"Memory", values.Memory, 60},
+		{"LoadPerCore", values.LoadPerCore, 1.5},
+		{"Swap", values.Swap, 25},
+		{"DiskIO", values.DiskIO, 4},
+		{"Temperature", values.Temperature, 88},
+		{"GPU", values.GPU, 95},
+		{"GPUMemory", values.GPUMemory, 90},
+		{"GPUTemperature", values.GPUTemperature, 70},
+		{"ConnEstablished", values.ConnEstablished, 1200},
+		{"ConnTimeWait", values.ConnTimeWait, 300},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestNewAlertMetricValuesMissing(t *testing.T) {
+	// 未上报主机信息或无 Swap 时对应值为 0
+	values := NewAlertMetricValues(&metric.LatestMetrics{
+		CPU:    &protocol.CPUData{LogicalCores: 4},
+		Memory: &protocol.MemoryData{},
+	})
+	if values != (AlertMetricValues{}) {
+		t.Errorf("NewAlertMetricValues() = %+v, want zero values", values)
+	}
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/cache"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// alertPolicyCacheKey 告警策略的缓存键
+const alertPolicyCacheKey = "all"
+
+// AlertPolicyService 告警策略服务
+type AlertPolicyService struct {
+	logger          *zap.Logger
+	AlertPolicyRepo *repo.AlertPolicyRepo // 导出用于 handler 的 PageBuilder
+
+	policyCache cache.Cache[string, []models.AlertPolicy]
+}
+
+func NewAlertPolicyService(logger *zap.Logger, db *gorm.DB) *AlertPolicyService {
+	return &AlertPolicyService{
+		logger:          logger,
+		AlertPolicyRepo: repo.NewAlertPolicyRepo(db),
+		policyCache:     cache.New[string, []models.AlertPolicy](time.Minute),
+	}
+}
+
+// AlertPolicyRequest 创建/更新告警策略请求
+type AlertPolicyRequest struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	ParentID    string                    `json:"parentId"`
+	Priority    int                       `json:"priority"`
+	AgentIds    []string                  `json:"agentIds"`
+	Tags        []string                  `json:"tags"`
+	Rules       models.AlertRuleOverrides `json:"rules"`
+}
+
+// ValidateAlertPolicy 校验告警策略配置
+func ValidateAlertPolicy(req *AlertPolicyRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("名称不能为空")
+	}
+
+	rules := req.Rules
+	percents := []struct {
+		name      string
+		threshold *float64
+	}{
+		{"CPU", rules.CPUThreshold},
+		{"内存", rules.MemoryThreshold},
+		{"磁盘", rules.DiskThreshold},
+		{"Swap", rules.SwapThreshold},
+		{"GPU", rules.GPUThreshold},
+		{"GPU显存", rules.GPUMemoryThreshold},
+	}
+	for _, item := range percents {
+		if item.threshold != nil && (*item.threshold < 0 || *item.threshold > 100) {
+			return fmt.Errorf("%s阈值必须在 0 ~ 100 之间", item.name)
+		}
+	}
+	if rules.NetworkThreshold != nil && *rules.NetworkThreshold < 0 {
+		return errors.New("网速阈值不能小于0")
+	}
+	if rules.CertThreshold != nil && *rules.CertThreshold < 0 {
+		return errors.New("证书剩余天数阈值不能小于0")
+	}
+	nonNegatives := []struct {
+		name      string
+		threshold *float64
+	}{
+		{"负载", rules.LoadThreshold},
+		{"磁盘IO", rules.DiskIOThreshold},
+		{"温度", rules.TemperatureThreshold},
+		{"GPU温度", rules.GPUTemperatureThreshold},
+		{"ESTABLISHED连接数", rules.ConnEstablishedThreshold},
+		{"TIME_WAIT连接数", rules.ConnTimeWaitThreshold},
+	}
+	for _, item := range nonNegatives {
+		if item.threshold != nil && *item.threshold < 0 {
+			return fmt.Errorf("%s阈值不能小于0", item.name)
+		}
+	}
+	for _, mode := range []*string{rules.CPUMode, rules.MemoryMode, rules.DiskMode, rules.NetworkMode} {
+		if mode != nil && *mode != models.AlertModeThreshold && *mode != models.AlertModeAnomaly {
+			return fmt.Errorf("告警模式不支持: %s", *mode)
+		}
+	}
+	if rules.AnomalyMethod != nil && *rules.AnomalyMethod != models.AnomalyMethodStdDev && *rules.AnomalyMethod != models.AnomalyMethodMAD {
+		return fmt.Errorf("基线算法不支持: %s", *rules.AnomalyMethod)
+	}
+	if rules.AnomalySensitivity != nil && *rules.AnomalySensitivity <= 0 {
+		return errors.New("异常检测倍数必须大于0")
+	}
+	maxHistory := int(baselineLookback / (24 * time.Hour))
+	if rules.AnomalyMinHistory != nil && (*rules.AnomalyMinHistory < 1 || *rules.AnomalyMinHistory > maxHistory) {
+		return fmt.Errorf("异常检测所需历史数据必须在 1 ~ %d 天之间", maxHistory)
+	}
+	if rules.DiskForecastWindow != nil && (*rules.DiskForecastWindow < 1 || *rules.DiskForecastWindow > 24*30) {
+		return errors.New("磁盘预测的拟合时长必须在 1 ~ 720 小时之间")
+	}
+	if rules.DiskForecastThreshold != nil && *rules.DiskForecastThreshold < 1 {
+		return errors.New("磁盘预测的剩余时间阈值不能小于1小时")
+	}
+	for _, duration := range []*int{rules.CPUDuration, rules.MemoryDuration, rules.DiskDuration,
+		rules.NetworkDuration, rules.ServiceDuration, rules.AgentOfflineDuration, rules.LoadDuration,
+		rules.SwapDuration, rules.DiskIODuration, rules.TemperatureDuration, rules.GPUDuration,
+		rules.GPUMemoryDuration, rules.GPUTemperatureDuration, rules.ConnEstablishedDuration,
+		rules.ConnTimeWaitDuration} {
+		if duration != nil && *duration < 0 {
+			return errors.New("持续时间不能小于0")
+		}
+	}
+	return nil
+}
+
+// EnsureDefaultPolicy 确保默认策略存在
+func (s *AlertPolicyService) EnsureDefaultPolicy(ctx context.Context) error {
+	_, exists, err := s.AlertPolicyRepo.FindByIdExists(ctx, models.DefaultAlertPolicyID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	policy := &models.AlertPolicy{
+		ID:          models.DefaultAlertPolicyID,
+		Name:        "默认策略",
+		Description: "未匹配到其他策略的探针使用此策略",
+		Rules:       datatypes.NewJSONType(models.FullAlertRuleOverrides(models.DefaultAlertRules())),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.AlertPolicyRepo.Create(ctx, policy); err != nil {
+		return err
+	}
+	s.policyCache.Delete(alertPolicyCacheKey)
+	s.logger.Info("已创建默认告警策略")
+	return nil
+}
+
+// CreatePolicy 创建告警策略
+func (s *AlertPolicyService) CreatePolicy(ctx context.Context, req *AlertPolicyRequest) (*models.AlertPolicy, error) {
+	now := time.Now().UnixMilli()
+	policy := &models.AlertPolicy{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.checkParent(ctx, policy.ID, req.ParentID); err != nil {
+		return nil, err
+	}
+	s.applyRequest(policy, req)
+
+	if err := s.AlertPolicyRepo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.policyCache.Delete(alertPolicyCacheKey)
+	return policy, nil
+}
+
+// UpdatePolicy 更新告警策略
+// 默认策略对所有探针生效且保存完整规则，更新时忽略作用范围和父策略，未填写的规则项保持原值
+func (s *AlertPolicyService) UpdatePolicy(ctx context.Context, id string, req *AlertPolicyRequest) (*models.AlertPolicy, error) {
+	policy, err := s.AlertPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.IsDefault() {
+		rules := models.DefaultAlertRules()
+		policy.Rules.Data().ApplyTo(&rules)
+		req.Rules.ApplyTo(&rules)
+
+		policy.Name = req.Name
+		policy.Description = req.Description
+		policy.Rules = datatypes.NewJSONType(models.FullAlertRuleOverrides(rules))
+	} else {
+		if err := s.checkParent(ctx, policy.ID, req.ParentID); err != nil {
+			return nil, err
+		}
+		s.applyRequest(&policy, req)
+	}
+
+	if err := s.AlertPolicyRepo.Save(ctx, &policy); err != nil {
+		return nil, err
+	}
+	s.policyCache.Delete(alertPolicyCacheKey)
+	return &policy, nil
+}
+
+// DeletePolicy 删除告警策略
+func (s *AlertPolicyService) DeletePolicy(ctx context.Context, id string) error {
+	if id == models.DefaultAlertPolicyID {
+		return orz.NewError(400, "默认策略不能删除")
+	}
+	count, err := s.AlertPolicyRepo.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return orz.NewError(400, "存在继承该策略的子策略，请先修改子策略")
+	}
+
+	if err := s.AlertPolicyRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	s.policyCache.Delete(alertPolicyCacheKey)
+	return nil
+}
+
+// checkParent 校验父策略存在且不会形成循环继承
+func (s *AlertPolicyService) checkParent(ctx context.Context, id, parentID string) error {
+	if parentID == "" || parentID == models.DefaultAlertPolicyID {
+		return nil
+	}
+	if parentID == id {
+		return orz.NewError(400, "策略不能继承自身")
+	}
+
+	policies, err := s.AlertPolicyRepo.FindAllPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]models.AlertPolicy, len(policies))
+	for _, policy := range policies {
+		byID[policy.ID] = policy
+	}
+
+	// 记录已访问的策略，数据库中已存在的循环继承也能终止
+	visited := make(map[string]bool)
+	current := parentID
+	for current != "" && current != models.DefaultAlertPolicyID {
+		if visited[current] {
+			return orz.NewError(400, "父策略存在循环继承")
+		}
+		visited[current] = true
+		parent, ok := byID[current]
+		if !ok {
+			return orz.NewError(400, "父策略不存在")
+		}
+		if parent.ParentID == id {
+			return orz.NewError(400, "不能形成循环继承")
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+func (s *AlertPolicyService) applyRequest(policy *models.AlertPolicy, req *AlertPolicyRequest) {
+	policy.Name = req.Name
+	policy.Description = req.Description
+	policy.ParentID = req.ParentID
+	if policy.ParentID == models.DefaultAlertPolicyID {
+		policy.ParentID = ""
+	}
+	policy.Priority = req.Priority
+	policy.AgentIds = req.AgentIds
+	policy.Tags = req.Tags
+	policy.Rules = datatypes.NewJSONType(req.Rules)
+}
+
+// listPolicies 获取所有告警策略（带缓存）
+func (s *AlertPolicyService) listPolicies(ctx context.Context) ([]models.AlertPolicy, error) {
+	if policies, ok := s.policyCache.Get(alertPolicyCacheKey); ok {
+		return policies, nil
+	}
+	policies, err := s.AlertPolicyRepo.FindAllPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.policyCache.Set(alertPolicyCacheKey, policies, time.Minute)
+	return policies, nil
+}
+
+// NewResolver 加载当前所有策略，用于批量解析探针的生效策略
+func (s *AlertPolicyService) NewResolver(ctx context.Context) (*AlertPolicyResolver, error) {
+	policies, err := s.listPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newAlertPolicyResolver(policies), nil
+}
+
+// ResolveForAgent 获取探针生效的告警策略
+func (s *AlertPolicyService) ResolveForAgent(ctx context.Context, agent *models.Agent) (*models.EffectiveAlertPolicy, error) {
+	resolver, err := s.NewResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.Resolve(agent), nil
+}
+
+// ResolvePolicy 获取指定策略按继承关系合并后的规则
+func (s *AlertPolicyService) ResolvePolicy(ctx context.Context, id string) (*models.EffectiveAlertPolicy, error) {
+	resolver, err := s.NewResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, ok := resolver.policies[id]
+	if !ok {
+		return nil, orz.NewError(404, "告警策略不存在")
+	}
+	return resolver.effective(policy), nil
+}
+
+// AlertPolicyResolver 告警策略解析器
+type AlertPolicyResolver struct {
+	policies map[string]*models.AlertPolicy
+	ordered  []*models.AlertPolicy // 按优先级降序、创建时间升序
+}
+
+func newAlertPolicyResolver(policies []models.AlertPolicy) *AlertPolicyResolver {
+	r := &AlertPolicyResolver{
+		policies: make(map[string]*models.AlertPolicy, len(policies)),
+	}
+	for i := range policies {
+		policy := &policies[i]
+		r.policies[policy.ID] = policy
+		if !policy.IsDefault() {
+			r.ordered = append(r.ordered, policy)
+		}
+	}
+	slices.SortStableFunc(r.ordered, func(a, b *models.AlertPolicy) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.CreatedAt, b.CreatedAt)
+	})
+	return r
+}
+
+// Match 查找探针匹配的策略：直接指定探针的策略优先于标签匹配，同类匹配中取优先级最高的，均未匹配时使用默认策略
+func (r *AlertPolicyResolver) Match(agent *models.Agent) *models.AlertPolicy {
+	for _, policy := range r.ordered {
+		if slices.Contains(policy.AgentIds, agent.ID) {
+			return policy
+		}
+	}
+	for _, policy := range r.ordered {
+		for _, tag := range policy.Tags {
+			if slices.Contains(agent.Tags, tag) {
+				return policy
+			}
+		}
+	}
+	return r.policies[models.DefaultAlertPolicyID]
+}
+
+// Resolve 解析探针最终生效的告警策略
+func (r *AlertPolicyResolver) Resolve(agent *models.Agent) *models.EffectiveAlertPolicy {
+	return r.effective(r.Match(agent))
+}
+
+// effective 从默认策略开始沿继承链依次应用覆盖项
+func (r *AlertPolicyResolver) effective(policy *models.AlertPolicy) *models.EffectiveAlertPolicy {
+	result := &models.EffectiveAlertPolicy{
+		PolicyID:   models.DefaultAlertPolicyID,
+		PolicyName: "默认策略",
+		Rules:      models.DefaultAlertRules(),
+	}
+	if policy == nil {
+		result.Chain = []string{models.DefaultAlertPolicyID}
+		return result
+	}
+	result.PolicyID = policy.ID
+	result.PolicyName = policy.Name
+
+	// 收集继承链（子 -> 父），防止异常数据导致的循环
+	var chain []*models.AlertPolicy
+	visited := make(map[string]bool)
+	for current := policy; current != nil && !visited[current.ID]; {
+		visited[current.ID] = true
+		chain = append(chain, current)
+		if current.IsDefault() {
+			break
+		}
+		parentID := current.ParentID
+		if parentID == "" {
+			parentID = models.DefaultAlertPolicyID
+		}
+		current = r.policies[parentID]
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		chain[i].Rules.Data().ApplyTo(&result.Rules)
+		result.Chain = append(result.Chain, chain[i].ID)
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+func testPolicy(id, parentID string, priority int, overrides models.AlertRuleOverrides) models.AlertPolicy {
+	return models.AlertPolicy{ID: id, Name: id, ParentID: parentID, Priority: priority, Rules: datatypes.NewJSONType(overrides)}
+}
+
+func TestAlertPolicyResolverMatch(t *testing.T) {
+	byAgent := testPolicy("by-agent", "", 0, models.AlertRuleOverrides{})
+	byAgent.AgentIds = []string{"agent-1"}
+	lowTag := testPolicy("low-tag", "", 1, models.AlertRuleOverrides{})
+	lowTag.Tags = []string{"db"}
+	highTag := testPolicy("high-tag", "", 10, models.AlertRuleOverrides{})
+	highTag.Tags = []string{"db", "cache"}
+	resolver := newAlertPolicyResolver([]models.AlertPolicy{
+		testPolicy(models.DefaultAlertPolicyID, "", 0, models.AlertRuleOverrides{}),
+		byAgent, lowTag, highTag,
+	})
+
+	tests := []struct {
+		name  string
+		agent models.Agent
+		want  string
+	}{
+		{"直接指定的探针优先于标签", models.Agent{ID: "agent-1", Tags: []string{"db"}}, "by-agent"},
+		{"标签匹配取优先级高的", models.Agent{ID: "agent-2", Tags: []string{"db"}}, "high-tag"},
+		{"未匹配使用默认策略", models.Agent{ID: "agent-3", Tags: []string{"web"}}, models.DefaultAlertPolicyID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolver.Match(&tt.agent); got.ID != tt.want {
+				t.Errorf("Match() = %s, want %s", got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertPolicyResolverInheritance(t *testing.T) {
+	defaultCPU, defaultDuration := 70.0, 120
+	parentCPU, parentMemory := 85.0, 90.0
+	childCPU := 95.0
+	childDisk := false
+
+	resolver := newAlertPolicyResolver([]models.AlertPolicy{
+		testPolicy(models.DefaultAlertPolicyID, "", 0, models.AlertRuleOverrides{CPUThreshold: &defaultCPU, CPUDuration: &defaultDuration}),
+		testPolicy("parent", "", 0, models.AlertRuleOverrides{CPUThreshold: &parentCPU, MemoryThreshold: &parentMemory}),
+		testPolicy("child", "parent", 0, models.AlertRuleOverrides{CPUThreshold: &childCPU, DiskEnabled: &childDisk}),
+		// 异常数据中的循环继承
+		testPolicy("loop-a", "loop-b", 0, models.AlertRuleOverrides{}),
+		testPolicy("loop-b", "loop-a", 0, models.AlertRuleOverrides{}),
+	})
+
+	// 子策略覆盖父策略，父策略覆盖默认策略，未覆盖的字段使用内置默认值
+	effective := resolver.effective(resolver.policies["child"])
+	if !slices.Equal(effective.Chain, []string{models.DefaultAlertPolicyID, "parent", "child"}) {
+		t.Errorf("Chain = %v", effective.Chain)
+	}
+	rules := effective.Rules
+	if rules.CPUThreshold != childCPU || rules.MemoryThreshold != parentMemory || rules.CPUDuration != defaultDuration || rules.DiskEnabled {
+		t.Errorf("合并后的规则错误: %+v", rules)
+	}
+	if rules.DiskThreshold != models.DefaultAlertRules().DiskThreshold {
+		t.Errorf("DiskThreshold = %v，未覆盖的字段应使用内置默认值", rules.DiskThreshold)
+	}
+
+	effective = resolver.effective(resolver.policies["parent"])
+	if effective.Rules.CPUThreshold != parentCPU || !slices.Equal(effective.Chain, []string{models.DefaultAlertPolicyID, "parent"}) {
+		t.Errorf("parent = %v %v", effective.Rules.CPUThreshold, effective.Chain)
+	}
+
+	// 循环继承不会死循环
+	effective = resolver.effective(resolver.policies["loop-a"])
+	if !slices.Equal(effective.Chain, []string{"loop-b", "loop-a"}) {
+		t.Errorf("loop Chain = %v", effective.Chain)
+	}
+}
+
+func TestCheckParent(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &models.AlertPolicy{})
+	s := NewAlertPolicyService(zap.NewNop(), db)
+
+	for _, policy := range []models.AlertPolicy{
+		testPolicy("a", "", 0, models.AlertRuleOverrides{}),
+		testPolicy("b", "a", 0, models.AlertRuleOverrides{}),
+		// 数据库中已存在的循环继承
+		testPolicy("loop-a", "loop-b", 0, models.AlertRuleOverrides{}),
+		testPolicy("loop-b", "loop-a", 0, models.AlertRuleOverrides{}),
+	} {
+		if err := db.Create(&policy).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		parentID string
+		wantErr  bool
+	}{
+		{"继承默认策略", "b", models.DefaultAlertPolicyID, false},
+		{"继承其他策略", "c", "b", false},
+		{"继承自身", "a", "a", true},
+		{"形成循环", "a", "b", true},
+		{"父策略不存在", "c", "missing", true},
+		{"父策略已存在循环", "c", "loop-a", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkParent(ctx, tt.id, tt.parentID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkParent(%s, %s) = %v, wantErr %v", tt.id, tt.parentID, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AlertRuleService 表达式告警规则服务
+type AlertRuleService struct {
+	logger        *zap.Logger
+	AlertRuleRepo *repo.AlertRuleRepo // 导出用于 handler 的 PageBuilder
+	vmClient      *vmclient.VMClient
+}
+
+func NewAlertRuleService(logger *zap.Logger, db *gorm.DB, vmClient *vmclient.VMClient) *AlertRuleService {
+	return &AlertRuleService{
+		logger:        logger,
+		AlertRuleRepo: repo.NewAlertRuleRepo(db),
+		vmClient:      vmClient,
+	}
+}
+
+// AlertRuleRequest 创建/更新表达式规则请求
+type AlertRuleRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Enabled     bool              `json:"enabled"`
+	Expr        string            `json:"expr"`
+	For         int               `json:"for"`
+	Severity    string            `json:"severity"`
+	Labels      map[string]string `json:"labels"`
+	Message     string            `json:"message"`
+}
+
+// AlertRuleSample 表达式查询返回的单条序列
+type AlertRuleSample struct {
+	AgentID string            `json:"agentId"` // 探针ID（来自 agent_id 标签）
+	Labels  map[string]string `json:"labels"`  // 序列标签
+	Value   float64           `json:"value"`   // 当前值
+}
+
+// AlertRuleMessageData 消息模板可用的变量
+type AlertRuleMessageData struct {
+	RuleName  string            // 规则名称
+	AgentID   string            // 探针ID
+	AgentName string            // 探针名称
+	Severity  string            // 告警级别
+	Value     float64           // 当前值
+	Labels    map[string]string // 合并后的标签
+}
+
+// ValidateAlertRule 校验表达式规则配置
+func ValidateAlertRule(req *AlertRuleRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("名称不能为空")
+	}
+	if strings.TrimSpace(req.Expr) == "" {
+		return errors.New("表达式不能为空")
+	}
+	if req.For < 0 {
+		return errors.New("持续时间不能小于0")
+	}
+	if _, ok := alertLevelOrder[req.Severity]; !ok {
+		return errors.New("告警级别只能是 info、warning 或 critical")
+	}
+	if req.Message != "" {
+		if _, err := template.New("message").Parse(req.Message); err != nil {
+			return fmt.Errorf("消息模板错误: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateRule 创建表达式规则
+func (s *AlertRuleService) CreateRule(ctx context.Context, req *AlertRuleRequest) (*models.AlertRule, error) {
+	now := time.Now().UnixMilli()
+	rule := &models.AlertRule{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.applyRequest(rule, req)
+
+	if err := s.AlertRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule 更新表达式规则
+func (s *AlertRuleService) UpdateRule(ctx context.Context, id string, req *AlertRuleRequest) (*models.AlertRule, error) {
+	rule, err := s.AlertRuleRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.applyRequest(&rule, req)
+
+	if err := s.AlertRuleRepo.Save(ctx, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeleteRule 删除表达式规则，已触发的告警在下一次评估时自动恢复
+func (s *AlertRuleService) DeleteRule(ctx context.Context, id string) error {
+	return s.AlertRuleRepo.DeleteById(ctx, id)
+}
+
+func (s *AlertRuleService) applyRequest(rule *models.AlertRule, req *AlertRuleRequest) {
+	rule.Name = req.Name
+	rule.Description = req.Description
+	rule.Enabled = req.Enabled
+	rule.Expr = strings.TrimSpace(req.Expr)
+	rule.For = req.For
+	rule.Severity = req.Severity
+	if req.Labels == nil {
+		req.Labels = map[string]string{}
+	}
+	rule.Labels = datatypes.NewJSONType(req.Labels)
+	rule.Message = req.Message
+}
+
+// Query 执行表达式即时查询，返回带有 agent_id 标签的序列，其他序列无法关联到探针将被忽略
+func (s *AlertRuleService) Query(ctx context.Context, expr string) ([]AlertRuleSample, error) {
+	result, err := s.vmClient.Query(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]AlertRuleSample, 0, len(result.Data.Result))
+	for _, series := range result.Data.Result {
+		agentID := series.Metric["agent_id"]
+		if agentID == "" {
+			continue
+		}
+		value, ok := series.InstantValue()
+		if !ok {
+			continue
+		}
+		samples = append(samples, AlertRuleSample{
+			AgentID: agentID,
+			Labels:  series.Metric,
+			Value:   value,
+		})
+	}
+	return samples, nil
+}
+
+// RenderMessage 渲染告警消息，模板为空或渲染失败时使用默认消息
+func (s *AlertRuleService) RenderMessage(rule *models.AlertRule, data AlertRuleMessageData) string {
+	defaultMessage := fmt.Sprintf("规则 %s 触发，当前值%.2f", rule.Name, data.Value)
+	if rule.Message == "" {
+		return defaultMessage
+	}
+
+	tmpl, err := template.New("message").Option("missingkey=zero").Parse(rule.Message)
+	if err != nil {
+		s.logger.Warn("解析规则消息模板失败", zap.String("ruleId", rule.ID), zap.Error(err))
+		return defaultMessage
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		s.logger.Warn("渲染规则消息模板失败", zap.String("ruleId", rule.ID), zap.Error(err))
+		return defaultMessage
+	}
+	return buf.String()
+}
+
+// mergeRuleLabels 合并序列标签和规则附加标签，规则标签优先
+func mergeRuleLabels(seriesLabels, ruleLabels map[string]string) map[string]string {
+	labels := make(map[string]string, len(seriesLabels)+len(ruleLabels))
+	for k, v := range seriesLabels {
+		if k == "__name__" {
+			continue
+		}
+		labels[k] = v
+	}
+	for k, v := range ruleLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// seriesFingerprint 计算序列标签的指纹，用于区分同一探针的多条序列
+func seriesFingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != "__name__" {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	h := fnv.New64a()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(labels[k]))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestMergeRuleLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		series map[string]string
+		rule   map[string]string
+		want   map[string]string
+	}{
+		{"去掉指标名", map[string]string{"__name__": "cpu_usage", "agent_id": "a1"}, nil, map[string]string{"agent_id": "a1"}},
+		{"规则标签优先", map[string]string{"agent_id": "a1", "team": "ops"}, map[string]string{"team": "dba"}, map[string]string{"agent_id": "a1", "team": "dba"}},
+		{"追加规则标签", map[string]string{"agent_id": "a1"}, map[string]string{"env": "prod"}, map[string]string{"agent_id": "a1", "env": "prod"}},
+		{"均为空", nil, nil, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRuleLabels(tt.series, tt.rule); !maps.Equal(got, tt.want) {
+				t.Fatalf("mergeRuleLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeriesFingerprint(t *testing.T) {
+	base := map[string]string{"agent_id": "a1", "mountpoint": "/"}
+
+	tests := []struct {
+		name  string
+		other map[string]string
+		same  bool
+	}{
+		{"相同标签", map[string]string{"agent_id": "a1", "mountpoint": "/"}, true},
+		{"忽略指标名", map[string]string{"__name__": "disk_usage", "agent_id": "a1", "mountpoint": "/"}, true},
+		{"标签值不同", map[string]string{"agent_id": "a1", "mountpoint": "/data"}, false},
+		{"多出标签", map[string]string{"agent_id": "a1", "mountpoint": "/", "device": "sda"}, false},
+		{"键值拼接不混淆", map[string]string{"agent_id": "a1", "mountpoint": "", "/": ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seriesFingerprint(tt.other) == seriesFingerprint(base); got != tt.same {
+				t.Fatalf("fingerprint equal = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	s := &AlertRuleService{logger: zap.NewNop()}
+	data := AlertRuleMessageData{
+		RuleName:  "磁盘使用率",
+		AgentName: "web-1",
+		Severity:  "warning",
+		Value:     91.256,
+		Labels:    map[string]string{"mountpoint": "/data"},
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"空模板使用默认消息", "", "规则 磁盘使用率 触发，当前值91.26"},
+		{"渲染变量", `{{.AgentName}} {{.Labels.mountpoint}} {{printf "%.1f" .Value}}`, "web-1 /data 91.3"},
+		{"缺失标签为空", `[{{.Labels.device}}]`, "[]"},
+		{"解析失败使用默认消息", `{{.AgentName`, "规则 磁盘使用率 触发，当前值91.26"},
+		{"执行失败使用默认消息", `{{.Missing}}`, "规则 磁盘使用率 触发，当前值91.26"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &models.AlertRule{ID: "r1", Name: "磁盘使用率", Message: tt.message}
+			if got := s.RenderMessage(rule, data); got != tt.want {
+				t.Fatalf("RenderMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
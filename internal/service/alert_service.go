@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dushixiang/pika/internal/models"
@@ -10,6 +11,7 @@ import (
 	"github.com/dushixiang/pika/internal/repo"
 	"github.com/go-orz/orz"
 	"go.uber.org/zap"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -21,11 +23,22 @@ type AlertService struct {
 	agentRepo       *repo.AgentRepo
 	monitorService  *MonitorService
 	propertyService *PropertyService
-	notifier        *Notifier
+	outbox          *NotificationOutboxService
 	logger          *zap.Logger
+
+	maintenanceService *MaintenanceService
+	incidentService    *IncidentService
+	policyService      *AlertPolicyService
+	ruleService        *AlertRuleService
+	silenceService     *SilenceService
+	escalationService  *EscalationService
+	baselineService    *BaselineService
+	forecastService    *ForecastService
+
+	grouper *alertGrouper
 }
 
-func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, monitorService *MonitorService, notifier *Notifier) *AlertService {
+func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, monitorService *MonitorService, outbox *NotificationOutboxService, maintenanceService *MaintenanceService, incidentService *IncidentService, policyService *AlertPolicyService, ruleService *AlertRuleService, silenceService *SilenceService, escalationService *EscalationService, baselineService *BaselineService, forecastService *ForecastService) *AlertService {
 	return &AlertService{
 		Service:         orz.NewService(db),
 		AlertRecordRepo: repo.NewAlertRecordRepo(db),
@@ -33,8 +46,19 @@ func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyS
 		agentRepo:       repo.NewAgentRepo(db),
 		monitorService:  monitorService,
 		propertyService: propertyService,
-		notifier:        notifier,
+		outbox:          outbox,
 		logger:          logger,
+
+		maintenanceService: maintenanceService,
+		incidentService:    incidentService,
+		policyService:      policyService,
+		ruleService:        ruleService,
+		silenceService:     silenceService,
+		escalationService:  escalationService,
+		baselineService:    baselineService,
+		forecastService:    forecastService,
+
+		grouper: newAlertGrouper(),
 	}
 }
 
@@ -53,12 +77,27 @@ func (s *AlertService) Clear(ctx context.Context) error {
 			return err
 		}
 
+		// 清空告警事件
+		if err := s.incidentService.Clear(ctx); err != nil {
+			s.logger.Error("清空告警事件失败", zap.Error(err))
+			return err
+		}
+
+		// 清空告警升级
+		if err := s.escalationService.AlertEscalationRepo.Clear(ctx); err != nil {
+			s.logger.Error("清空告警升级失败", zap.Error(err))
+			return err
+		}
+
+		// 清空等待合并通知的告警
+		s.grouper.clear()
+
 		return nil
 	})
 }
 
 // CheckMetrics 检查指标并触发告警
-func (s *AlertService) CheckMetrics(ctx context.Context, agentID string, cpu, memory, disk, networkSpeed float64) error {
+func (s *AlertService) CheckMetrics(ctx context.Context, agentID string, values AlertMetricValues) error {
 	// 获取全局告警配置
 	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
 	if err != nil {
@@ -78,36 +117,106 @@ func (s *AlertService) CheckMetrics(ctx context.Context, agentID string, cpu, me
 		return err
 	}
 
+	// 解析探针生效的告警策略
+	policy, err := s.policyService.ResolveForAgent(ctx, &agent)
+	if err != nil {
+		s.logger.Error("解析告警策略失败", zap.String("agentId", agentID), zap.Error(err))
+		return err
+	}
+	rules := policy.Rules
+
 	now := time.Now().UnixMilli()
 
 	// 检查 CPU 告警
-	if alertConfig.Rules.CPUEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "cpu", cpu, alertConfig.Rules.CPUThreshold, alertConfig.Rules.CPUDuration, now)
+	if rules.CPUEnabled {
+		s.checkMetricAlert(ctx, policy, &agent, "cpu", values.CPU, rules.CPUMode, rules.CPUThreshold, rules.CPUDuration, now)
 	}
 
 	// 检查内存告警
-	if alertConfig.Rules.MemoryEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "memory", memory, alertConfig.Rules.MemoryThreshold, alertConfig.Rules.MemoryDuration, now)
+	if rules.MemoryEnabled {
+		s.checkMetricAlert(ctx, policy, &agent, "memory", values.Memory, rules.MemoryMode, rules.MemoryThreshold, rules.MemoryDuration, now)
 	}
 
 	// 检查磁盘告警
-	if alertConfig.Rules.DiskEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "disk", disk, alertConfig.Rules.DiskThreshold, alertConfig.Rules.DiskDuration, now)
+	if rules.DiskEnabled {
+		s.checkMetricAlert(ctx, policy, &agent, "disk", values.Disk, rules.DiskMode, rules.DiskThreshold, rules.DiskDuration, now)
 	}
 
 	// 检查网速告警
-	if alertConfig.Rules.NetworkEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "network", networkSpeed, alertConfig.Rules.NetworkThreshold, alertConfig.Rules.NetworkDuration, now)
+	if rules.NetworkEnabled {
+		s.checkMetricAlert(ctx, policy, &agent, "network", values.NetworkSpeed, rules.NetworkMode, rules.NetworkThreshold, rules.NetworkDuration, now)
+	}
+
+	// 检查负载、Swap、磁盘IO、温度、GPU 和连接数告警
+	s.checkExtendedMetricAlerts(ctx, policy, &agent, values, now)
+
+	// 检查磁盘写满预测告警
+	if rules.DiskForecastEnabled {
+		s.checkDiskForecast(ctx, policy, &agent, now)
+	}
+
+	// 检查流量超额预测告警
+	if rules.TrafficForecastEnabled {
+		s.checkTrafficForecast(ctx, policy, &agent, now)
 	}
 
 	return nil
 }
 
+// checkMetricAlert 按告警模式检查指标告警
+func (s *AlertService) checkMetricAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, alertType string, currentValue float64, mode string, threshold float64, duration int, now int64) {
+	if mode == models.AlertModeAnomaly {
+		s.checkAnomalyAlert(ctx, policy, agent, alertType, currentValue, duration, now)
+		return
+	}
+	s.checkAlert(ctx, policy, agent, alertType, currentValue, threshold, duration, now)
+}
+
 // checkAlert 检查单个告警规则
-func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, alertType string, currentValue, threshold float64, duration int, now int64) {
-	stateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
+func (s *AlertService) checkAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, alertType string, currentValue, threshold float64, duration int, now int64) {
+	state := s.loadMetricState(ctx, agent, alertType)
+	state.Mode = models.AlertModeThreshold
+	state.Threshold = threshold
+	state.ExpectedMin = 0
+	state.ExpectedMax = 0
+	s.updateMetricState(ctx, policy, agent, state, currentValue, currentValue >= threshold, duration, now)
+}
 
-	var shouldFire, shouldResolve bool
+// checkAnomalyAlert 检查异常检测告警，当前值持续超出历史基线的正常范围时触发
+// 历史数据不足时不触发告警，已触发的告警会恢复
+func (s *AlertService) checkAnomalyAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, alertType string, currentValue float64, duration int, now int64) {
+	rules := &policy.Rules
+	baseline, err := s.baselineService.GetBaseline(ctx, agent.ID, alertType, rules.AnomalyMethod, rules.AnomalyMinHistory, time.UnixMilli(now))
+	if err != nil {
+		// 查询失败时保持当前状态，避免误恢复
+		s.logger.Warn("获取指标基线失败", zap.String("agentId", agent.ID), zap.String("alertType", alertType), zap.Error(err))
+		return
+	}
+
+	state := s.loadMetricState(ctx, agent, alertType)
+	state.Mode = models.AlertModeAnomaly
+	if baseline == nil {
+		state.Threshold = 0
+		state.ExpectedMin = 0
+		state.ExpectedMax = 0
+		s.updateMetricState(ctx, policy, agent, state, currentValue, false, duration, now)
+		return
+	}
+
+	lower, upper := baseline.Range(alertType, rules.AnomalySensitivity)
+	state.ExpectedMin = lower
+	state.ExpectedMax = upper
+	// 阈值记录为被突破的边界
+	state.Threshold = upper
+	if currentValue < lower {
+		state.Threshold = lower
+	}
+	s.updateMetricState(ctx, policy, agent, state, currentValue, currentValue < lower || currentValue > upper, duration, now)
+}
+
+// loadMetricState 加载指标告警状态，不存在时创建新状态
+func (s *AlertService) loadMetricState(ctx context.Context, agent *models.Agent, alertType string) *models.AlertState {
+	stateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
 
 	// 从数据库加载状态
 	state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
@@ -119,22 +228,26 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 			AlertType: alertType,
 		}
 	}
+	return state
+}
+
+// updateMetricState 更新指标告警状态，breached 持续超过 duration 后触发告警，恢复正常后恢复告警
+func (s *AlertService) updateMetricState(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, state *models.AlertState, currentValue float64, breached bool, duration int, now int64) {
+	var shouldFire, shouldResolve bool
 
 	// 按探针维度更新最新阈值/持续时间，支持配置变更
 	state.AgentID = agent.ID
-	state.AlertType = alertType
-	state.Threshold = threshold
 	state.Duration = duration
 	state.Value = currentValue
 	state.LastCheckTime = now
 
-	if currentValue >= threshold {
+	if breached {
 		if state.StartTime == 0 {
 			state.StartTime = now
 		}
 
 		elapsedSeconds := (now - state.StartTime) / 1000
-		if elapsedSeconds >= int64(duration) && !state.IsFiring {
+		if elapsedSeconds >= int64(duration) && !state.IsFiring && !s.inMaintenance(ctx, agent, "") {
 			shouldFire = true
 			state.IsFiring = true
 		}
@@ -151,16 +264,16 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 	}
 
 	if shouldFire {
-		s.fireAlert(ctx, config, agent, state)
+		s.fireAlert(ctx, policy, agent, state)
 	}
 
 	if shouldResolve {
-		s.resolveAlert(ctx, config, agent, state)
+		s.resolveAlert(ctx, policy, agent, state)
 	}
 }
 
 // fireAlert 触发告警
-func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) {
+func (s *AlertService) fireAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, state *models.AlertState) {
 	s.logger.Info("触发告警",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -175,16 +288,21 @@ func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig
 	record := &models.AlertRecord{
 		AgentID:     agent.ID,
 		AgentName:   agent.Name,
+		StateID:     state.ID,
+		PolicyID:    policy.PolicyID,
+		PolicyName:  policy.PolicyName,
 		AlertType:   state.AlertType,
 		Message:     s.buildAlertMessage(state),
 		Threshold:   state.Threshold,
 		ActualValue: state.Value,
-		Level:       s.calculateLevel(state.Value, state.Threshold),
+		Level:       s.calculateStateLevel(state),
 		Status:      "firing",
 		FiredAt:     now,
 		CreatedAt:   now,
 	}
 
+	s.applySilence(ctx, record, agent)
+
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建告警记录失败", zap.Error(err))
@@ -199,12 +317,14 @@ func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 
+	s.openIncident(ctx, state, record)
+
 	// 发送通知 - 使用新的 context 避免父 context 取消影响通知发送
 	go s.sendAlertNotification(record, agent)
 }
 
 // resolveAlert 恢复告警
-func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) {
+func (s *AlertService) resolveAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, state *models.AlertState) {
 	s.logger.Info("告警恢复",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -233,6 +353,7 @@ func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertCon
 				if err != nil {
 					s.logger.Error("更新告警记录失败", zap.Error(err))
 				} else {
+					s.closeIncident(ctx, existingRecord)
 					// 发送恢复通知
 					go s.sendAlertNotification(existingRecord, agent)
 				}
@@ -250,6 +371,16 @@ func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertCon
 
 // buildAlertMessage 构建告警消息
 func (s *AlertService) buildAlertMessage(state *models.AlertState) string {
+	if state.Mode == models.AlertModeAnomaly {
+		return s.buildAnomalyMessage(state)
+	}
+	if state.AlertType == AlertTypeDiskForecast || state.AlertType == AlertTypeTrafficForecast {
+		return buildForecastMessage(state)
+	}
+	if isExtendedMetric(state.AlertType) {
+		return buildExtendedMetricMessage(state)
+	}
+
 	var alertTypeName string
 	switch state.AlertType {
 	case "cpu":
@@ -280,6 +411,53 @@ func (s *AlertService) buildAlertMessage(state *models.AlertState) string {
 	)
 }
 
+// buildAnomalyMessage 构建异常检测告警消息，包含历史基线的预期范围
+func (s *AlertService) buildAnomalyMessage(state *models.AlertState) string {
+	names := map[string]string{
+		"cpu":     "CPU使用率",
+		"memory":  "内存使用率",
+		"disk":    "磁盘使用率",
+		"network": "网速",
+	}
+	name, ok := names[state.AlertType]
+	if !ok {
+		name = state.AlertType
+	}
+	unit := getAlertTypeMetadata(state.AlertType).ValueUnit
+	direction := "高于"
+	if state.Value < state.ExpectedMin {
+		direction = "低于"
+	}
+	return fmt.Sprintf("%s持续%d秒%s历史同期水平，当前值%.2f%s，预期范围%.2f%s ~ %.2f%s",
+		name,
+		state.Duration,
+		direction,
+		state.Value, unit,
+		state.ExpectedMin, unit,
+		state.ExpectedMax, unit,
+	)
+}
+
+// calculateStateLevel 根据告警状态计算告警级别
+// 预测告警按剩余时间计算，异常检测模式下偏离预期范围超过一倍范围半宽时为严重，否则为警告
+func (s *AlertService) calculateStateLevel(state *models.AlertState) string {
+	if state.AlertType == AlertTypeDiskForecast || state.AlertType == AlertTypeTrafficForecast {
+		return forecastLevel(state)
+	}
+	if isExtendedMetric(state.AlertType) {
+		return s.extendedMetricLevel(state)
+	}
+	if state.Mode != models.AlertModeAnomaly {
+		return s.calculateLevel(state.Value, state.Threshold)
+	}
+	half := (state.ExpectedMax - state.ExpectedMin) / 2
+	deviation := max(state.Value-state.ExpectedMax, state.ExpectedMin-state.Value)
+	if half > 0 && deviation > half {
+		return "critical"
+	}
+	return "warning"
+}
+
 // calculateLevel 计算告警级别
 func (s *AlertService) calculateLevel(value, threshold float64) string {
 	diff := value - threshold
@@ -294,6 +472,7 @@ func (s *AlertService) calculateLevel(value, threshold float64) string {
 }
 
 // sendAlertNotification 发送告警通知(带panic恢复)
+// 匹配升级策略的告警按升级步骤通知，恢复时只通知已通知过的渠道；其他告警按分组和摘要配置合并通知或立即通知
 func (s *AlertService) sendAlertNotification(record *models.AlertRecord, agent *models.Agent) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -304,26 +483,100 @@ func (s *AlertService) sendAlertNotification(record *models.AlertRecord, agent *
 		}
 	}()
 
+	// 被静默的告警不发送通知也不开始升级，静默结束后重新调用
+	if record.SuppressedBy != "" {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// 获取告警配置（包含 MaskIP 设置）
-	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if record.Status == "resolved" {
+		escalation, err := s.escalationService.AlertEscalationRepo.FindByRecordID(ctx, record.ID)
+		if err != nil {
+			s.logger.Error("查询告警升级失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		}
+		if escalation == nil {
+			if !s.queueGroupedNotification(ctx, record, agent) {
+				s.notifyAlert(record, agent, nil, "")
+			}
+			return
+		}
+		if err := s.escalationService.AlertEscalationRepo.DeleteByRecordID(ctx, record.ID); err != nil {
+			s.logger.Error("删除告警升级失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		}
+		// 升级第一步尚未执行时没有通知过任何渠道，无需发送恢复通知
+		if len(escalation.Channels) > 0 {
+			s.notifyAlert(record, agent, escalation.Channels, "")
+		}
+		return
+	}
+
+	policy := s.escalationService.MatchPolicy(ctx, record)
+	if policy == nil {
+		if !s.queueGroupedNotification(ctx, record, agent) {
+			s.notifyAlert(record, agent, nil, "已发送告警通知")
+		}
+		return
+	}
+
+	_, channels, err := s.escalationService.Start(ctx, policy, record)
 	if err != nil {
-		s.logger.Error("获取告警配置失败", zap.Error(err))
+		// 升级创建失败时退化为通知所有渠道，避免漏发
+		s.logger.Error("创建告警升级失败", zap.Int64("recordId", record.ID), zap.Error(err))
+		s.notifyAlert(record, agent, nil, "已发送告警通知")
 		return
 	}
+	if len(channels) > 0 {
+		s.notifyAlert(record, agent, channels, fmt.Sprintf("已按升级策略 %s 发送告警通知", policy.Name))
+	}
+}
 
-	channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+// notifyAlert 发送告警通知并记录到告警事件时间线
+// channelTypes 为空时按通知路由选择渠道，action 为时间线中的通知描述（恢复通知使用固定描述）
+func (s *AlertService) notifyAlert(record *models.AlertRecord, agent *models.Agent, channelTypes []string, action string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("发送告警通知时发生panic",
+				zap.Any("panic", r),
+				zap.Int64("recordId", record.ID),
+			)
+		}
+	}()
+
+	// 被静默的告警不发送触发和恢复通知
+	if record.SuppressedBy != "" {
+		s.logger.Debug("告警已被静默，跳过通知",
+			zap.Int64("recordId", record.ID),
+			zap.String("silenceId", record.SuppressedBy),
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// 获取告警配置（包含 MaskIP 设置）
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
 	if err != nil {
-		s.logger.Error("获取通知渠道配置失败", zap.Error(err))
+		s.logger.Error("获取告警配置失败", zap.Error(err))
 		return
 	}
 
+	// 升级策略指定了渠道时直接使用，否则按通知路由选择渠道
 	var enabledChannels []models.NotificationChannelConfig
-	for _, channel := range channelConfigs {
-		if channel.Enabled {
-			enabledChannels = append(enabledChannels, channel)
+	if len(channelTypes) > 0 {
+		channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+		if err != nil {
+			s.logger.Error("获取通知渠道配置失败", zap.Error(err))
+			return
+		}
+		enabledChannels = filterChannels(channelConfigs, channelTypes)
+	} else {
+		enabledChannels, err = s.propertyService.GetRoutedChannelConfigs(ctx, record, agent)
+		if err != nil {
+			s.logger.Error("获取通知渠道配置失败", zap.Error(err))
+			return
 		}
 	}
 
@@ -331,9 +584,203 @@ func (s *AlertService) sendAlertNotification(record *models.AlertRecord, agent *
 		return
 	}
 
-	if err := s.notifier.SendNotificationByConfigs(ctx, enabledChannels, record, agent, alertConfig.MaskIP); err != nil {
+	err = s.outbox.Enqueue(ctx, enabledChannels, record, agent, alertConfig.MaskIP)
+	if err != nil {
 		s.logger.Error("发送告警通知失败", zap.Error(err))
 	}
+
+	// 记录到告警事件时间线
+	if record.Status == "resolved" {
+		action = "已发送恢复通知"
+	}
+	message := fmt.Sprintf("%s（%d 个渠道）", action, len(enabledChannels))
+	if err != nil {
+		message = fmt.Sprintf("%s，部分发送失败: %v", message, err)
+	}
+	if err := s.incidentService.OnNotificationSent(ctx, record.IncidentID, message); err != nil {
+		s.logger.Error("记录告警事件通知失败", zap.Int64("incidentId", record.IncidentID), zap.Error(err))
+	}
+}
+
+// applySilence 匹配生效中的静默，匹配时记录静默ID，告警状态和记录照常保存但不发送通知
+func (s *AlertService) applySilence(ctx context.Context, record *models.AlertRecord, agent *models.Agent) {
+	if !s.silenceService.Apply(ctx, record, agent) {
+		return
+	}
+	s.logger.Info("告警匹配静默，不发送通知",
+		zap.String("agentId", record.AgentID),
+		zap.String("alertType", record.AlertType),
+		zap.String("silenceId", record.SuppressedBy),
+	)
+}
+
+// CheckSilencedAlerts 静默结束后仍在告警中的记录补发通知
+func (s *AlertService) CheckSilencedAlerts(ctx context.Context) error {
+	records, err := s.AlertRecordRepo.FindFiringSilenced(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range records {
+		record := &records[i]
+		if s.silenceService.IsActive(ctx, record.SuppressedBy) {
+			continue
+		}
+
+		agent := models.Agent{ID: record.AgentID, Name: record.AgentName}
+		if found, err := s.agentRepo.FindById(ctx, record.AgentID); err == nil {
+			agent = found
+		}
+
+		// 可能被其他生效中的静默继续抑制，首次抑制的静默ID保留在记录中
+		now := time.Now().UnixMilli()
+		record.SuppressedBy = ""
+		s.applySilence(ctx, record, &agent)
+		if record.SuppressedBy == "" {
+			record.RenotifiedAt = now
+		}
+		record.UpdatedAt = now
+		if err := s.AlertRecordRepo.UpdateAlertRecord(ctx, record); err != nil {
+			s.logger.Error("更新告警记录失败", zap.Error(err))
+			continue
+		}
+
+		if record.SuppressedBy == "" {
+			go s.sendAlertNotification(record, &agent)
+		}
+	}
+	return nil
+}
+
+// openIncident 将触发的告警归并到告警事件
+func (s *AlertService) openIncident(ctx context.Context, state *models.AlertState, record *models.AlertRecord) {
+	if err := s.incidentService.OnAlertFired(ctx, state.ID, record); err != nil {
+		s.logger.Error("归并告警事件失败", zap.Int64("recordId", record.ID), zap.Error(err))
+	}
+}
+
+// closeIncident 告警恢复时更新告警事件
+func (s *AlertService) closeIncident(ctx context.Context, record *models.AlertRecord) {
+	if err := s.incidentService.OnAlertResolved(ctx, record); err != nil {
+		s.logger.Error("更新告警事件失败", zap.Int64("incidentId", record.IncidentID), zap.Error(err))
+	}
+}
+
+// CheckRepeatNotifications 对未确认且未恢复的告警事件按间隔重复发送通知，确认后停止
+func (s *AlertService) CheckRepeatNotifications(ctx context.Context) error {
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !alertConfig.Enabled || alertConfig.RepeatInterval <= 0 {
+		return nil
+	}
+
+	incidents, err := s.incidentService.ListDueForRepeat(ctx, time.Duration(alertConfig.RepeatInterval)*time.Minute)
+	if err != nil {
+		return err
+	}
+
+	for _, incident := range incidents {
+		record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, incident.LastRecordID)
+		if err != nil || record.Status != "firing" {
+			continue
+		}
+
+		// 按升级策略通知的告警由 CheckEscalations 负责重复通知
+		escalation, err := s.escalationService.AlertEscalationRepo.FindByRecordID(ctx, record.ID)
+		if err != nil || escalation != nil {
+			continue
+		}
+
+		// 推送监控和多点确认的告警没有真实探针
+		agent := models.Agent{ID: record.AgentID, Name: record.AgentName}
+		if found, err := s.agentRepo.FindById(ctx, record.AgentID); err == nil {
+			agent = found
+		}
+		s.notifyAlert(record, &agent, nil, "已重复发送告警通知")
+	}
+	return nil
+}
+
+// CheckEscalations 执行到期的告警升级和重复通知，告警恢复、事件被确认或升级策略被删除后停止
+func (s *AlertService) CheckEscalations(ctx context.Context) error {
+	now := time.Now().UnixMilli()
+	escalations, err := s.escalationService.AlertEscalationRepo.FindDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for i := range escalations {
+		escalation := &escalations[i]
+
+		record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, escalation.RecordID)
+		if err != nil || record.Status != "firing" {
+			if err := s.escalationService.AlertEscalationRepo.DeleteByRecordID(ctx, escalation.RecordID); err != nil {
+				s.logger.Error("删除告警升级失败", zap.Int64("recordId", escalation.RecordID), zap.Error(err))
+			}
+			continue
+		}
+		if record.SuppressedBy != "" {
+			continue
+		}
+
+		// 策略被删除或禁用、事件已确认时停止升级，保留已通知的渠道用于恢复通知
+		policy := s.escalationService.FindPolicy(ctx, escalation.PolicyID)
+		if policy == nil || !s.isIncidentOpen(ctx, record.IncidentID) {
+			escalation.NextEscalateAt = 0
+			escalation.NextRepeatAt = 0
+			if err := s.escalationService.AlertEscalationRepo.Save(ctx, escalation); err != nil {
+				s.logger.Error("停止告警升级失败", zap.Int64("recordId", record.ID), zap.Error(err))
+			}
+			continue
+		}
+
+		var channels []string
+		var action string
+		if escalation.NextEscalateAt > 0 && escalation.NextEscalateAt <= now && escalation.StepIndex+1 < len(policy.Steps) {
+			s.escalationService.Advance(escalation, policy, now)
+			channels = policy.Steps[escalation.StepIndex].Channels
+			action = fmt.Sprintf("已按升级策略 %s 升级至第 %d 步发送告警通知", policy.Name, escalation.StepIndex+1)
+		} else if escalation.NextRepeatAt > 0 && escalation.NextRepeatAt <= now && escalation.StepIndex >= 0 {
+			s.escalationService.ScheduleRepeat(escalation, policy, now)
+			channels = escalation.Channels
+			action = "已重复发送告警通知"
+		} else {
+			// 策略步骤被修改后没有可执行的下一步
+			escalation.NextEscalateAt = 0
+			if escalation.StepIndex < 0 {
+				escalation.NextRepeatAt = 0
+			}
+		}
+
+		if err := s.escalationService.AlertEscalationRepo.Save(ctx, escalation); err != nil {
+			s.logger.Error("更新告警升级失败", zap.Int64("recordId", record.ID), zap.Error(err))
+			continue
+		}
+		if len(channels) == 0 {
+			continue
+		}
+
+		agent := models.Agent{ID: record.AgentID, Name: record.AgentName}
+		if found, err := s.agentRepo.FindById(ctx, record.AgentID); err == nil {
+			agent = found
+		}
+		s.notifyAlert(record, &agent, channels, action)
+	}
+	return nil
+}
+
+// isIncidentOpen 判断告警事件是否仍未处理，没有关联事件时视为未处理
+func (s *AlertService) isIncidentOpen(ctx context.Context, incidentID int64) bool {
+	if incidentID == 0 {
+		return true
+	}
+	incident, err := s.incidentService.IncidentRepo.FindById(ctx, incidentID)
+	if err != nil {
+		return true
+	}
+	return incident.State == models.IncidentStateOpen
 }
 
 // CheckMonitorAlerts 检查监控相关告警（证书和服务下线）
@@ -350,34 +797,35 @@ func (s *AlertService) CheckMonitorAlerts(ctx context.Context) error {
 		return nil
 	}
 
+	// 加载告警策略，按探针解析各自生效的规则
+	resolver, err := s.policyService.NewResolver(ctx)
+	if err != nil {
+		s.logger.Error("加载告警策略失败", zap.Error(err))
+		return err
+	}
+
 	now := time.Now().UnixMilli()
 
 	// 检查证书告警
-	if alertConfig.Rules.CertEnabled {
-		if err := s.checkCertificateAlerts(ctx, alertConfig, now); err != nil {
-			s.logger.Error("检查证书告警失败", zap.Error(err))
-		}
+	if err := s.checkCertificateAlerts(ctx, resolver, now); err != nil {
+		s.logger.Error("检查证书告警失败", zap.Error(err))
 	}
 
 	// 检查服务下线告警
-	if alertConfig.Rules.ServiceEnabled {
-		if err := s.checkServiceDownAlerts(ctx, alertConfig, now); err != nil {
-			s.logger.Error("检查服务下线告警失败", zap.Error(err))
-		}
+	if err := s.checkServiceDownAlerts(ctx, resolver, now); err != nil {
+		s.logger.Error("检查服务下线告警失败", zap.Error(err))
 	}
 
 	// 检查探针离线告警
-	if alertConfig.Rules.AgentOfflineEnabled {
-		if err := s.checkAgentOfflineAlerts(ctx, alertConfig, now); err != nil {
-			s.logger.Error("检查探针离线告警失败", zap.Error(err))
-		}
+	if err := s.checkAgentOfflineAlerts(ctx, resolver, now); err != nil {
+		s.logger.Error("检查探针离线告警失败", zap.Error(err))
 	}
 
 	return nil
 }
 
 // checkCertificateAlerts 检查证书告警
-func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+func (s *AlertService) checkCertificateAlerts(ctx context.Context, resolver *AlertPolicyResolver, now int64) error {
 	// 获取所有最新的监控指标（仅HTTPS类型）
 	// 这里需要查询最新的 monitor_metrics 记录，获取证书剩余天数
 	monitors, err := s.monitorService.GetLatestMonitorMetricsByType(ctx, "http")
@@ -400,13 +848,18 @@ func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *model
 			continue
 		}
 
+		policy := resolver.Resolve(&agent)
+		if !policy.Rules.CertEnabled {
+			continue
+		}
+
 		// 检查证书剩余天数是否低于阈值
-		if certDaysLeft <= config.Rules.CertThreshold && certDaysLeft >= 0 {
+		if certDaysLeft <= policy.Rules.CertThreshold && certDaysLeft >= 0 {
 			// 触发告警（证书告警不需要持续时间，直接触发）
-			s.checkCertAlert(ctx, config, &agent, &monitor, certDaysLeft, now)
+			s.checkCertAlert(ctx, policy, &agent, &monitor, certDaysLeft, now)
 		} else {
 			// 恢复告警（如果之前触发过）
-			s.resolveCertAlert(ctx, config, &agent, &monitor, certDaysLeft)
+			s.resolveCertAlert(ctx, policy, &agent, &monitor, certDaysLeft)
 		}
 	}
 
@@ -414,7 +867,7 @@ func (s *AlertService) checkCertificateAlerts(ctx context.Context, config *model
 }
 
 // checkCertAlert 检查并触发证书告警
-func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, monitor *protocol.MonitorData, certDaysLeft float64, now int64) {
+func (s *AlertService) checkCertAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, monitor *protocol.MonitorData, certDaysLeft float64, now int64) {
 	stateKey := fmt.Sprintf("%s:global:cert:%s", agent.ID, monitor.MonitorId)
 
 	// 从数据库加载状态
@@ -429,12 +882,12 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 	}
 	state.AgentID = agent.ID
 	state.AlertType = "cert"
-	state.Threshold = config.Rules.CertThreshold
+	state.Threshold = policy.Rules.CertThreshold
 	state.Duration = 0
 	state.Value = certDaysLeft
 	state.LastCheckTime = now
 
-	shouldFire := certDaysLeft <= config.Rules.CertThreshold && !state.IsFiring
+	shouldFire := certDaysLeft <= policy.Rules.CertThreshold && !state.IsFiring && !s.inMaintenance(ctx, agent, monitor.MonitorId)
 
 	if shouldFire {
 		state.IsFiring = true
@@ -455,23 +908,27 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		zap.String("monitorName", monitor.MonitorName),
 		zap.String("target", monitor.Target),
 		zap.Float64("certDaysLeft", certDaysLeft),
-		zap.Float64("threshold", config.Rules.CertThreshold),
+		zap.Float64("threshold", policy.Rules.CertThreshold),
 	)
 
 	// 构建告警消息，优先使用监控任务名称
 	var message string
 	if monitor.MonitorName != "" {
-		message = fmt.Sprintf("监控项 %s (%s) 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.MonitorName, monitor.Target, certDaysLeft, config.Rules.CertThreshold)
+		message = fmt.Sprintf("监控项 %s (%s) 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.MonitorName, monitor.Target, certDaysLeft, policy.Rules.CertThreshold)
 	} else {
-		message = fmt.Sprintf("监控项 %s 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.Target, certDaysLeft, config.Rules.CertThreshold)
+		message = fmt.Sprintf("监控项 %s 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.Target, certDaysLeft, policy.Rules.CertThreshold)
 	}
 
 	record := &models.AlertRecord{
 		AgentID:     agent.ID,
 		AgentName:   agent.Name,
+		StateID:     state.ID,
+		PolicyID:    policy.PolicyID,
+		PolicyName:  policy.PolicyName,
 		AlertType:   "cert",
+		MonitorID:   monitor.MonitorId,
 		Message:     message,
-		Threshold:   config.Rules.CertThreshold,
+		Threshold:   policy.Rules.CertThreshold,
 		ActualValue: certDaysLeft,
 		Level:       s.calculateCertLevel(certDaysLeft),
 		Status:      "firing",
@@ -479,6 +936,8 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		CreatedAt:   now,
 	}
 
+	s.applySilence(ctx, record, agent)
+
 	err = s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建证书告警记录失败", zap.Error(err))
@@ -490,12 +949,14 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 
+	s.openIncident(ctx, state, record)
+
 	// 发送通知
 	go s.sendAlertNotification(record, agent)
 }
 
 // resolveCertAlert 恢复证书告警
-func (s *AlertService) resolveCertAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, monitor *protocol.MonitorData, certDaysLeft float64) {
+func (s *AlertService) resolveCertAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, monitor *protocol.MonitorData, certDaysLeft float64) {
 	stateKey := fmt.Sprintf("%s:global:cert:%s", agent.ID, monitor.MonitorId)
 
 	state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
@@ -525,6 +986,7 @@ func (s *AlertService) resolveCertAlert(ctx context.Context, config *models.Aler
 			if err != nil {
 				s.logger.Error("更新证书告警记录失败", zap.Error(err))
 			} else {
+				s.closeIncident(ctx, existingRecord)
 				// 发送恢复通知
 				go s.sendAlertNotification(existingRecord, agent)
 			}
@@ -550,21 +1012,27 @@ func (s *AlertService) calculateCertLevel(daysLeft float64) string {
 }
 
 // checkServiceDownAlerts 检查服务下线告警
-func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+func (s *AlertService) checkServiceDownAlerts(ctx context.Context, resolver *AlertPolicyResolver, now int64) error {
 	// 获取所有最新的监控指标
-	monitors, err := s.monitorService.GetAllLatestMonitorMetrics(ctx)
+	monitors, err := s.monitorService.GetServiceStatusMetrics(ctx)
 	if err != nil {
 		return err
 	}
+	s.resolveSwitchedServiceStates(ctx, resolver, monitors)
 
 	for _, monitor := range monitors {
 		// 获取探针信息
-		agent, err := s.agentRepo.FindById(ctx, monitor.AgentId)
+		agent, err := s.findMonitorAgent(ctx, &monitor)
 		if err != nil {
 			s.logger.Error("获取探针信息失败", zap.String("agentId", monitor.AgentId), zap.Error(err))
 			continue
 		}
 
+		policy := resolver.Resolve(&agent)
+		if !policy.Rules.ServiceEnabled {
+			continue
+		}
+
 		stateKey := fmt.Sprintf("%s:global:service:%s", agent.ID, monitor.MonitorId)
 
 		var shouldFire, shouldResolve bool
@@ -581,7 +1049,7 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 		}
 		state.AgentID = agent.ID
 		state.AlertType = "service"
-		state.Duration = config.Rules.ServiceDuration
+		state.Duration = policy.Rules.ServiceDuration
 		state.LastCheckTime = now
 
 		if monitor.Status == "down" {
@@ -590,7 +1058,7 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 			}
 
 			elapsedSeconds := (now - state.StartTime) / 1000
-			if elapsedSeconds >= int64(config.Rules.ServiceDuration) && !state.IsFiring {
+			if elapsedSeconds >= int64(policy.Rules.ServiceDuration) && !state.IsFiring && !s.inMaintenance(ctx, &agent, monitor.MonitorId) {
 				shouldFire = true
 				state.IsFiring = true
 			}
@@ -607,19 +1075,82 @@ func (s *AlertService) checkServiceDownAlerts(ctx context.Context, config *model
 		}
 
 		if shouldFire {
-			s.fireServiceDownAlert(ctx, config, &agent, &monitor, state, now)
+			s.fireServiceDownAlert(ctx, policy, &agent, &monitor, state, now)
 		}
 
 		if shouldResolve {
-			s.resolveServiceDownAlert(ctx, config, &agent, &monitor, state)
+			s.resolveServiceDownAlert(ctx, policy, &agent, &monitor, state)
 		}
 	}
 
 	return nil
 }
 
+// resolveSwitchedServiceStates 监控项切换多点确认模式后，恢复切换前模式下仍在告警的状态
+// 启用多点确认后按探针的告警不再更新，关闭多点确认后聚合告警不再更新，不处理会一直处于告警中
+func (s *AlertService) resolveSwitchedServiceStates(ctx context.Context, resolver *AlertPolicyResolver, monitors []protocol.MonitorData) {
+	quorumMonitors := make(map[string]protocol.MonitorData)
+	agentMonitors := make(map[string]protocol.MonitorData)
+	for _, monitor := range monitors {
+		if monitor.AgentId == QuorumMonitorAgentID {
+			quorumMonitors[monitor.MonitorId] = monitor
+		} else {
+			agentMonitors[monitor.MonitorId] = monitor
+		}
+	}
+
+	states, err := s.AlertStateRepo.FindFiringByAlertType(ctx, "service")
+	if err != nil {
+		s.logger.Error("获取服务下线告警状态失败", zap.Error(err))
+		return
+	}
+	for i := range states {
+		state := &states[i]
+		_, monitorID, ok := strings.Cut(state.ID, ":global:service:")
+		if !ok {
+			continue
+		}
+
+		var monitor protocol.MonitorData
+		var agent models.Agent
+		if state.AgentID == QuorumMonitorAgentID {
+			if monitor, ok = agentMonitors[monitorID]; !ok {
+				continue
+			}
+			agent = models.Agent{ID: QuorumMonitorAgentID, Name: monitor.MonitorName}
+		} else {
+			if monitor, ok = quorumMonitors[monitorID]; !ok {
+				continue
+			}
+			if agent, err = s.agentRepo.FindById(ctx, state.AgentID); err != nil {
+				agent = models.Agent{ID: state.AgentID, Name: monitor.MonitorName}
+			}
+		}
+		s.resolveServiceDownAlert(ctx, resolver.Resolve(&agent), &agent, &monitor, state)
+	}
+}
+
+// inMaintenance 判断告警目标是否处于维护窗口内，维护期间不触发新告警，维护结束后若仍满足条件会立即触发
+func (s *AlertService) inMaintenance(ctx context.Context, agent *models.Agent, monitorID string) bool {
+	if s.maintenanceService == nil {
+		return false
+	}
+	return s.maintenanceService.IsInMaintenance(ctx, agent, monitorID)
+}
+
+// findMonitorAgent 获取监控数据对应的探针，推送监控和多点确认的聚合结果没有真实探针，使用监控任务构造虚拟探针
+func (s *AlertService) findMonitorAgent(ctx context.Context, monitor *protocol.MonitorData) (models.Agent, error) {
+	if monitor.AgentId == PushMonitorAgentID || monitor.AgentId == QuorumMonitorAgentID {
+		return models.Agent{
+			ID:   monitor.AgentId,
+			Name: monitor.MonitorName,
+		}, nil
+	}
+	return s.agentRepo.FindById(ctx, monitor.AgentId)
+}
+
 // fireServiceDownAlert 触发服务下线告警
-func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, monitor *protocol.MonitorData, state *models.AlertState, now int64) {
+func (s *AlertService) fireServiceDownAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, monitor *protocol.MonitorData, state *models.AlertState, now int64) {
 	s.logger.Info("触发服务下线告警",
 		zap.String("agentId", agent.ID),
 		zap.String("monitorId", monitor.MonitorId),
@@ -635,12 +1166,19 @@ func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.
 	} else {
 		message = fmt.Sprintf("监控项 %s 持续离线%d秒", monitor.Target, state.Duration)
 	}
+	if monitor.AgentId == QuorumMonitorAgentID && monitor.Message != "" {
+		message = fmt.Sprintf("%s（%s）", message, monitor.Message)
+	}
 
 	// 创建告警记录
 	record := &models.AlertRecord{
 		AgentID:     agent.ID,
 		AgentName:   agent.Name,
+		StateID:     state.ID,
+		PolicyID:    policy.PolicyID,
+		PolicyName:  policy.PolicyName,
 		AlertType:   "service",
+		MonitorID:   monitor.MonitorId,
 		Message:     message,
 		Threshold:   0,
 		ActualValue: float64(state.Duration),
@@ -650,6 +1188,8 @@ func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.
 		CreatedAt:   now,
 	}
 
+	s.applySilence(ctx, record, agent)
+
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建服务下线告警记录失败", zap.Error(err))
@@ -661,12 +1201,14 @@ func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 
+	s.openIncident(ctx, state, record)
+
 	// 发送通知
 	go s.sendAlertNotification(record, agent)
 }
 
 // resolveServiceDownAlert 恢复服务下线告警
-func (s *AlertService) resolveServiceDownAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, monitor *protocol.MonitorData, state *models.AlertState) {
+func (s *AlertService) resolveServiceDownAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, monitor *protocol.MonitorData, state *models.AlertState) {
 	s.logger.Info("服务下线告警恢复",
 		zap.String("agentId", agent.ID),
 		zap.String("monitorId", monitor.MonitorId),
@@ -687,6 +1229,7 @@ func (s *AlertService) resolveServiceDownAlert(ctx context.Context, config *mode
 			if err != nil {
 				s.logger.Error("更新服务下线告警记录失败", zap.Error(err))
 			} else {
+				s.closeIncident(ctx, existingRecord)
 				// 发送恢复通知
 				go s.sendAlertNotification(existingRecord, agent)
 			}
@@ -701,7 +1244,7 @@ func (s *AlertService) resolveServiceDownAlert(ctx context.Context, config *mode
 }
 
 // checkAgentOfflineAlerts 检查探针离线告警
-func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, resolver *AlertPolicyResolver, now int64) error {
 	// 获取所有探针
 	agents, err := s.agentRepo.FindAll(ctx)
 	if err != nil {
@@ -709,6 +1252,16 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 	}
 
 	for _, agent := range agents {
+		policy := resolver.Resolve(&agent)
+		if !policy.Rules.AgentOfflineEnabled {
+			continue
+		}
+
+		// 维护中的探针不检查离线告警
+		if s.inMaintenance(ctx, &agent, "") {
+			continue
+		}
+
 		stateKey := fmt.Sprintf("%s:global:agent_offline:%s", agent.ID, agent.ID)
 
 		// 防止时钟回拨导致负数
@@ -730,14 +1283,14 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 
 		state.AgentID = agent.ID
 		state.AlertType = "agent_offline"
-		state.Duration = config.Rules.AgentOfflineDuration
-		state.Threshold = float64(config.Rules.AgentOfflineDuration)
+		state.Duration = policy.Rules.AgentOfflineDuration
+		state.Threshold = float64(policy.Rules.AgentOfflineDuration)
 		state.Value = float64(offlineSeconds)
 		state.LastCheckTime = now
 
 		var shouldFire, shouldResolve bool
 
-		if offlineSeconds >= int64(config.Rules.AgentOfflineDuration) {
+		if offlineSeconds >= int64(policy.Rules.AgentOfflineDuration) {
 			if !state.IsFiring {
 				shouldFire = true
 				state.IsFiring = true
@@ -754,11 +1307,11 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 		}
 
 		if shouldFire {
-			s.fireAgentOfflineAlert(ctx, config, &agent, state, offlineSeconds, now)
+			s.fireAgentOfflineAlert(ctx, policy, &agent, state, offlineSeconds, now)
 		}
 
 		if shouldResolve {
-			s.resolveAgentOfflineAlert(ctx, config, &agent, state)
+			s.resolveAgentOfflineAlert(ctx, policy, &agent, state)
 		}
 	}
 
@@ -766,7 +1319,7 @@ func (s *AlertService) checkAgentOfflineAlerts(ctx context.Context, config *mode
 }
 
 // fireAgentOfflineAlert 触发探针离线告警
-func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState, offlineSeconds int64, now int64) {
+func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, state *models.AlertState, offlineSeconds int64, now int64) {
 	s.logger.Info("触发探针离线告警",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -778,6 +1331,9 @@ func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models
 	record := &models.AlertRecord{
 		AgentID:     agent.ID,
 		AgentName:   agent.Name,
+		StateID:     state.ID,
+		PolicyID:    policy.PolicyID,
+		PolicyName:  policy.PolicyName,
 		AlertType:   "agent_offline",
 		Message:     fmt.Sprintf("探针 %s 已离线%d秒，超过阈值%d秒", agent.Name, offlineSeconds, state.Duration),
 		Threshold:   float64(state.Duration),
@@ -788,6 +1344,8 @@ func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models
 		CreatedAt:   now,
 	}
 
+	s.applySilence(ctx, record, agent)
+
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建探针离线告警记录失败", zap.Error(err))
@@ -799,12 +1357,14 @@ func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 
+	s.openIncident(ctx, state, record)
+
 	// 发送通知
 	go s.sendAlertNotification(record, agent)
 }
 
 // resolveAgentOfflineAlert 恢复探针离线告警
-func (s *AlertService) resolveAgentOfflineAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) {
+func (s *AlertService) resolveAgentOfflineAlert(ctx context.Context, policy *models.EffectiveAlertPolicy, agent *models.Agent, state *models.AlertState) {
 	s.logger.Info("探针离线告警恢复",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -825,6 +1385,7 @@ func (s *AlertService) resolveAgentOfflineAlert(ctx context.Context, config *mod
 			if err != nil {
 				s.logger.Error("更新探针离线告警记录失败", zap.Error(err))
 			} else {
+				s.closeIncident(ctx, existingRecord)
 				// 发送恢复通知
 				go s.sendAlertNotification(existingRecord, agent)
 			}
@@ -837,3 +1398,189 @@ func (s *AlertService) resolveAgentOfflineAlert(ctx context.Context, config *mod
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 }
+
+// CheckExpressionRules 评估所有已启用的表达式告警规则
+func (s *AlertService) CheckExpressionRules(ctx context.Context) error {
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		s.logger.Error("获取全局告警配置失败", zap.Error(err))
+		return err
+	}
+	if !alertConfig.Enabled {
+		return nil
+	}
+
+	rules, err := s.ruleService.AlertRuleRepo.FindAllEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	states, err := s.AlertStateRepo.FindByAlertType(ctx, models.AlertTypeExpression)
+	if err != nil {
+		return err
+	}
+
+	// 按规则分组已有状态
+	statesByRule := make(map[string]map[string]*models.AlertState)
+	for i := range states {
+		state := &states[i]
+		if statesByRule[state.RuleID] == nil {
+			statesByRule[state.RuleID] = make(map[string]*models.AlertState)
+		}
+		statesByRule[state.RuleID][state.ID] = state
+	}
+
+	agents := make(map[string]*models.Agent)
+	now := time.Now().UnixMilli()
+	for i := range rules {
+		rule := &rules[i]
+		ruleStates := statesByRule[rule.ID]
+		delete(statesByRule, rule.ID)
+		s.evaluateRule(ctx, rule, ruleStates, agents, now)
+	}
+
+	// 规则已删除或禁用：恢复已触发的告警并清理状态
+	for _, ruleStates := range statesByRule {
+		for _, state := range ruleStates {
+			s.clearRuleState(ctx, state, agents)
+		}
+	}
+	return nil
+}
+
+// evaluateRule 评估单条表达式规则，表达式返回的序列持续超过 for 时间后触发，序列消失后恢复
+func (s *AlertService) evaluateRule(ctx context.Context, rule *models.AlertRule, ruleStates map[string]*models.AlertState, agents map[string]*models.Agent, now int64) {
+	samples, err := s.ruleService.Query(ctx, rule.Expr)
+	if err != nil {
+		// 查询失败时保持当前状态，避免误恢复
+		s.logger.Warn("评估表达式规则失败", zap.String("ruleId", rule.ID), zap.String("expr", rule.Expr), zap.Error(err))
+		return
+	}
+
+	active := make(map[string]bool, len(samples))
+	for _, sample := range samples {
+		agent := s.lookupAgent(ctx, agents, sample.AgentID)
+		if agent == nil {
+			continue
+		}
+
+		stateKey := fmt.Sprintf("%s:rule:%s:%s", agent.ID, rule.ID, seriesFingerprint(sample.Labels))
+		active[stateKey] = true
+
+		state, ok := ruleStates[stateKey]
+		if !ok {
+			state = &models.AlertState{
+				ID:        stateKey,
+				AgentID:   agent.ID,
+				AlertType: models.AlertTypeExpression,
+				RuleID:    rule.ID,
+			}
+		}
+		state.Duration = rule.For
+		state.Value = sample.Value
+		state.LastCheckTime = now
+		if state.StartTime == 0 {
+			state.StartTime = now
+		}
+
+		shouldFire := false
+		elapsedSeconds := (now - state.StartTime) / 1000
+		if elapsedSeconds >= int64(rule.For) && !state.IsFiring && !s.inMaintenance(ctx, agent, "") {
+			shouldFire = true
+			state.IsFiring = true
+		}
+
+		if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+			s.logger.Error("保存告警状态失败", zap.Error(err))
+		}
+
+		if shouldFire {
+			s.fireRuleAlert(ctx, rule, agent, state, sample)
+		}
+	}
+
+	for key, state := range ruleStates {
+		if !active[key] {
+			s.clearRuleState(ctx, state, agents)
+		}
+	}
+}
+
+// lookupAgent 获取探针信息，同一轮评估内缓存查询结果
+func (s *AlertService) lookupAgent(ctx context.Context, agents map[string]*models.Agent, agentID string) *models.Agent {
+	if agent, ok := agents[agentID]; ok {
+		return agent
+	}
+	var result *models.Agent
+	agent, err := s.agentRepo.FindById(ctx, agentID)
+	if err == nil {
+		result = &agent
+	}
+	agents[agentID] = result
+	return result
+}
+
+// clearRuleState 序列不再满足条件时恢复已触发的告警，并删除状态
+func (s *AlertService) clearRuleState(ctx context.Context, state *models.AlertState, agents map[string]*models.Agent) {
+	if state.IsFiring {
+		agent := s.lookupAgent(ctx, agents, state.AgentID)
+		if agent == nil {
+			agent = &models.Agent{ID: state.AgentID}
+		}
+		s.resolveAlert(ctx, nil, agent, state)
+	}
+	if err := s.AlertStateRepo.DeleteAlertState(ctx, state.ID); err != nil {
+		s.logger.Error("删除告警状态失败", zap.Error(err))
+	}
+}
+
+// fireRuleAlert 触发表达式规则告警
+func (s *AlertService) fireRuleAlert(ctx context.Context, rule *models.AlertRule, agent *models.Agent, state *models.AlertState, sample AlertRuleSample) {
+	labels := mergeRuleLabels(sample.Labels, rule.Labels.Data())
+
+	s.logger.Info("触发表达式规则告警",
+		zap.String("ruleId", rule.ID),
+		zap.String("ruleName", rule.Name),
+		zap.String("agentId", agent.ID),
+		zap.Float64("value", sample.Value),
+	)
+
+	now := time.Now().UnixMilli()
+	record := &models.AlertRecord{
+		AgentID:   agent.ID,
+		AgentName: agent.Name,
+		StateID:   state.ID,
+		AlertType: models.AlertTypeExpression,
+		Message: s.ruleService.RenderMessage(rule, AlertRuleMessageData{
+			RuleName:  rule.Name,
+			AgentID:   agent.ID,
+			AgentName: agent.Name,
+			Severity:  rule.Severity,
+			Value:     sample.Value,
+			Labels:    labels,
+		}),
+		ActualValue: sample.Value,
+		Level:       rule.Severity,
+		Status:      "firing",
+		FiredAt:     now,
+		CreatedAt:   now,
+		RuleID:      rule.ID,
+		Labels:      datatypes.NewJSONType(labels),
+	}
+
+	s.applySilence(ctx, record, agent)
+
+	if err := s.AlertRecordRepo.CreateAlertRecord(ctx, record); err != nil {
+		s.logger.Error("创建表达式规则告警记录失败", zap.Error(err))
+		return
+	}
+
+	state.LastRecordID = record.ID
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+	}
+
+	s.openIncident(ctx, state, record)
+
+	// 发送通知
+	go s.sendAlertNotification(record, agent)
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dushixiang/pika/internal/badge"
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/cache"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// badgeUptimeTTL 可用率徽章的缓存时间
+	badgeUptimeTTL = 5 * time.Minute
+	// BadgeLabelTarget 使用监控目标地址作为徽章标签（仅监控项允许公开目标地址时生效）
+	BadgeLabelTarget = "target"
+)
+
+// BadgeService 状态徽章服务，仅对公开可见的监控项和探针生效
+type BadgeService struct {
+	logger             *zap.Logger
+	monitorRepo        *repo.MonitorRepo
+	agentRepo          *repo.AgentRepo
+	monitorService     *MonitorService
+	maintenanceService *MaintenanceService
+	slaService         *SLAService
+
+	uptimeCache cache.Cache[string, *metric.SLAReport] // key: 类型:ID:天数
+}
+
+func NewBadgeService(logger *zap.Logger, db *gorm.DB, monitorService *MonitorService,
+	maintenanceService *MaintenanceService, slaService *SLAService) *BadgeService {
+	return &BadgeService{
+		logger:             logger,
+		monitorRepo:        repo.NewMonitorRepo(db),
+		agentRepo:          repo.NewAgentRepo(db),
+		monitorService:     monitorService,
+		maintenanceService: maintenanceService,
+		slaService:         slaService,
+		uptimeCache:        cache.New[string, *metric.SLAReport](time.Minute),
+	}
+}
+
+func (s *BadgeService) findPublicMonitor(ctx context.Context, id string) (*models.MonitorTask, error) {
+	monitor, err := s.monitorRepo.FindPublicMonitorByID(ctx, id)
+	if err != nil || !monitor.Enabled {
+		return nil, orz.NewError(404, "监控项不存在")
+	}
+	return monitor, nil
+}
+
+func (s *BadgeService) findPublicAgent(ctx context.Context, id string) (*models.Agent, error) {
+	agent, err := s.agentRepo.FindPublicAgentByID(ctx, id)
+	if err != nil {
+		return nil, orz.NewError(404, "探针不存在")
+	}
+	return agent, nil
+}
+
+// monitorLabel 监控项徽章标签，未指定时使用默认值
+func monitorLabel(monitor *models.MonitorTask, label, defaultLabel string) string {
+	switch label {
+	case "":
+		return defaultLabel
+	case BadgeLabelTarget:
+		if monitor.ShowTargetPublic {
+			return monitor.Target
+		}
+		return monitor.Name
+	default:
+		return label
+	}
+}
+
+func statusBadge(label, status string) badge.Badge {
+	switch status {
+	case "up":
+		return badge.Badge{Label: label, Message: "up", Color: badge.ColorBrightGreen}
+	case "down":
+		return badge.Badge{Label: label, Message: "down", Color: badge.ColorRed}
+	case "maintenance":
+		return badge.Badge{Label: label, Message: "maintenance", Color: badge.ColorBlue}
+	default:
+		return badge.Badge{Label: label, Message: "unknown", Color: badge.ColorGrey}
+	}
+}
+
+func uptimeBadge(label string, report *metric.SLAReport) badge.Badge {
+	if report.UpSeconds+report.DownSeconds == 0 {
+		return badge.Badge{Label: label, Message: "no data", Color: badge.ColorGrey}
+	}
+
+	uptime := report.UptimePercent
+	color := badge.ColorRed
+	switch {
+	case uptime >= 99.9:
+		color = badge.ColorBrightGreen
+	case uptime >= 99:
+		color = badge.ColorGreen
+	case uptime >= 95:
+		color = badge.ColorYellow
+	case uptime >= 90:
+		color = badge.ColorOrange
+	}
+
+	message := fmt.Sprintf("%.2f%%", uptime)
+	if uptime == 100 {
+		message = "100%"
+	}
+	return badge.Badge{Label: label, Message: message, Color: color}
+}
+
+// MonitorStatusBadge 监控项当前状态徽章
+func (s *BadgeService) MonitorStatusBadge(ctx context.Context, id, label string) (badge.Badge, error) {
+	monitor, err := s.findPublicMonitor(ctx, id)
+	if err != nil {
+		return badge.Badge{}, err
+	}
+	stats := s.monitorService.getMonitorStats(ctx, monitor.ID)
+	return statusBadge(monitorLabel(monitor, label, monitor.Name), stats.Status), nil
+}
+
+// MonitorUptimeBadge 监控项最近 days 天可用率徽章
+func (s *BadgeService) MonitorUptimeBadge(ctx context.Context, id, label string, days int) (badge.Badge, error) {
+	monitor, err := s.findPublicMonitor(ctx, id)
+	if err != nil {
+		return badge.Badge{}, err
+	}
+
+	key := fmt.Sprintf("monitor:%s:%d", monitor.ID, days)
+	report, ok := s.uptimeCache.Get(key)
+	if !ok {
+		end := time.Now()
+		report, err = s.slaService.buildMonitorReport(ctx, monitor, end.AddDate(0, 0, -days), end)
+		if err != nil {
+			return badge.Badge{}, err
+		}
+		s.uptimeCache.Set(key, report, badgeUptimeTTL)
+	}
+	return uptimeBadge(monitorLabel(monitor, label, fmt.Sprintf("uptime %dd", days)), report), nil
+}
+
+// MonitorResponseTimeBadge 监控项当前平均响应时间徽章
+func (s *BadgeService) MonitorResponseTimeBadge(ctx context.Context, id, label string) (badge.Badge, error) {
+	monitor, err := s.findPublicMonitor(ctx, id)
+	if err != nil {
+		return badge.Badge{}, err
+	}
+	label = monitorLabel(monitor, label, "response time")
+
+	stats := s.monitorService.getMonitorStats(ctx, monitor.ID)
+	if stats.LastCheckTime == 0 {
+		return badge.Badge{Label: label, Message: "no data", Color: badge.ColorGrey}, nil
+	}
+
+	color := badge.ColorRed
+	switch {
+	case stats.ResponseTime < 300:
+		color = badge.ColorBrightGreen
+	case stats.ResponseTime < 800:
+		color = badge.ColorYellow
+	case stats.ResponseTime < 2000:
+		color = badge.ColorOrange
+	}
+	return badge.Badge{Label: label, Message: fmt.Sprintf("%dms", stats.ResponseTime), Color: color}, nil
+}
+
+// MonitorCertBadge 监控项证书剩余天数徽章
+func (s *BadgeService) MonitorCertBadge(ctx context.Context, id, label string) (badge.Badge, error) {
+	monitor, err := s.findPublicMonitor(ctx, id)
+	if err != nil {
+		return badge.Badge{}, err
+	}
+	label = monitorLabel(monitor, label, "certificate")
+
+	stats := s.monitorService.getMonitorStats(ctx, monitor.ID)
+	if stats.CertExpiryTime == 0 {
+		return badge.Badge{Label: label, Message: "no data", Color: badge.ColorGrey}, nil
+	}
+
+	daysLeft := stats.CertDaysLeft
+	color := badge.ColorRed
+	message := fmt.Sprintf("%d days", daysLeft)
+	switch {
+	case daysLeft < 0:
+		message = "expired"
+	case daysLeft > 30:
+		color = badge.ColorBrightGreen
+	case daysLeft > 14:
+		color = badge.ColorYellow
+	case daysLeft > 7:
+		color = badge.ColorOrange
+	}
+	return badge.Badge{Label: label, Message: message, Color: color}, nil
+}
+
+// AgentStatusBadge 探针当前状态徽章
+func (s *BadgeService) AgentStatusBadge(ctx context.Context, id, label string) (badge.Badge, error) {
+	agent, err := s.findPublicAgent(ctx, id)
+	if err != nil {
+		return badge.Badge{}, err
+	}
+	if label == "" {
+		label = agent.Name
+	}
+
+	switch {
+	case s.maintenanceService.IsInMaintenance(ctx, agent, ""):
+		return statusBadge(label, "maintenance"), nil
+	case agent.Status == 1:
+		return badge.Badge{Label: label, Message: "online", Color: badge.ColorBrightGreen}, nil
+	default:
+		return badge.Badge{Label: label, Message: "offline", Color: badge.ColorRed}, nil
+	}
+}
+
+// AgentUptimeBadge 探针最近 days 天在线率徽章
+func (s *BadgeService) AgentUptimeBadge(ctx context.Context, id, label string, days int) (badge.Badge, error) {
+	agent, err := s.findPublicAgent(ctx, id)
+	if err != nil {
+		return badge.Badge{}, err
+	}
+	if label == "" {
+		label = fmt.Sprintf("uptime %dd", days)
+	}
+
+	key := fmt.Sprintf("agent:%s:%d", agent.ID, days)
+	report, ok := s.uptimeCache.Get(key)
+	if !ok {
+		end := time.Now()
+		report, err = s.slaService.buildAgentReport(ctx, agent, end.AddDate(0, 0, -days), end)
+		if err != nil {
+			return badge.Badge{}, err
+		}
+		s.uptimeCache.Set(key, report, badgeUptimeTTL)
+	}
+	return uptimeBadge(label, report), nil
+}
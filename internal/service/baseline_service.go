@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"github.com/go-orz/cache"
+	"go.uber.org/zap"
+)
+
+const (
+	baselineLookback = 28 * 24 * time.Hour // 计算基线使用的历史数据范围
+	baselineStep     = 5 * time.Minute     // 历史数据采样间隔
+	// 同一每周小时至少需要的样本数，避免样本过少时范围失真
+	baselineMinSamples = 6
+	// madScale 将 MAD 换算为与标准差可比的尺度（正态分布下 σ ≈ 1.4826 × MAD）
+	madScale = 1.4826
+	// baselineQueryTimeout 单次历史数据查询的超时时间，避免拖慢整轮告警检查
+	baselineQueryTimeout = 10 * time.Second
+	// baselineFailureTTL 查询失败后的冷却时间，期间直接返回上次的错误，不再重复查询
+	baselineFailureTTL = 5 * time.Minute
+)
+
+// baselineQueries 支持异常检测的指标查询，值的单位与静态阈值一致
+var baselineQueries = map[string]string{
+	"cpu":     `pika_cpu_usage_percent{agent_id="%s"}`,
+	"memory":  `pika_memory_usage_percent{agent_id="%s"}`,
+	"disk":    `sum(pika_disk_used_bytes{agent_id="%[1]s"}) / sum(pika_disk_total_bytes{agent_id="%[1]s"}) * 100`,
+	"network": `(sum(pika_network_sent_bytes_rate{agent_id="%[1]s"}) + sum(pika_network_recv_bytes_rate{agent_id="%[1]s"})) / 1024 / 1024`,
+}
+
+// baselineMinBand 正常范围的最小半宽，避免历史数据几乎不变时轻微波动也触发告警
+var baselineMinBand = map[string]float64{
+	"cpu":     5,
+	"memory":  5,
+	"disk":    2,
+	"network": 1,
+}
+
+// BaselineService 异常检测基线服务，按探针和每周小时（星期 + 小时）从历史指标计算基线
+type BaselineService struct {
+	logger       *zap.Logger
+	vmClient     *vmclient.VMClient
+	queryTimeout time.Duration
+
+	samplesCache cache.Cache[string, *baselineSamples]
+	failureCache cache.Cache[string, error]
+}
+
+// baselineSamples 某个每周小时的历史样本
+type baselineSamples struct {
+	values      []float64
+	historyDays float64 // 可用历史数据的天数
+}
+
+// Baseline 指标基线
+type Baseline struct {
+	Method      string  `json:"method"`      // 基线算法: stddev, mad
+	Center      float64 `json:"center"`      // 均值或中位数
+	Spread      float64 `json:"spread"`      // 标准差或换算后的 MAD
+	Samples     int     `json:"samples"`     // 样本数
+	HistoryDays float64 `json:"historyDays"` // 可用历史数据的天数
+}
+
+func NewBaselineService(logger *zap.Logger, vmClient *vmclient.VMClient) *BaselineService {
+	return &BaselineService{
+		logger:       logger,
+		vmClient:     vmClient,
+		queryTimeout: baselineQueryTimeout,
+		samplesCache: cache.New[string, *baselineSamples](time.Minute),
+		failureCache: cache.New[string, error](time.Minute),
+	}
+}
+
+// GetBaseline 获取指标在当前每周小时的基线，历史数据不足时返回 nil
+func (s *BaselineService) GetBaseline(ctx context.Context, agentID, alertType, method string, minHistoryDays int, now time.Time) (*Baseline, error) {
+	samples, err := s.getSamples(ctx, agentID, alertType, now)
+	if err != nil {
+		return nil, err
+	}
+	if samples.historyDays < float64(minHistoryDays) || len(samples.values) < baselineMinSamples {
+		return nil, nil
+	}
+
+	baseline := &Baseline{
+		Method:      method,
+		Samples:     len(samples.values),
+		HistoryDays: samples.historyDays,
+	}
+	if method == models.AnomalyMethodMAD {
+		baseline.Center, baseline.Spread = medianMAD(samples.values)
+	} else {
+		baseline.Method = models.AnomalyMethodStdDev
+		baseline.Center, baseline.Spread = meanStdDev(samples.values)
+	}
+	return baseline, nil
+}
+
+// Range 计算正常范围，百分比指标限制在 0 ~ 100 之间
+func (b *Baseline) Range(alertType string, sensitivity float64) (lower, upper float64) {
+	half := max(sensitivity*b.Spread, baselineMinBand[alertType])
+	lower = max(b.Center-half, 0)
+	upper = b.Center + half
+	if alertType != "network" {
+		upper = min(upper, 100)
+	}
+	return lower, upper
+}
+
+// getSamples 查询历史数据中与当前同一每周小时的样本，每小时重新计算一次
+// 当前小时的数据不计入基线，避免异常数据拉偏基线；查询失败时在冷却时间内不再重复查询
+func (s *BaselineService) getSamples(ctx context.Context, agentID, alertType string, now time.Time) (*baselineSamples, error) {
+	hour := now.Truncate(time.Hour)
+	cacheKey := fmt.Sprintf("%s:%s:%d", agentID, alertType, hour.Unix())
+	if samples, ok := s.samplesCache.Get(cacheKey); ok {
+		return samples, nil
+	}
+	if err, ok := s.failureCache.Get(cacheKey); ok {
+		return nil, err
+	}
+
+	queryTemplate, ok := baselineQueries[alertType]
+	if !ok {
+		return nil, fmt.Errorf("指标 %s 不支持异常检测", alertType)
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, s.queryTimeout)
+	defer cancel()
+	result, err := s.vmClient.QueryRange(queryCtx, fmt.Sprintf(queryTemplate, agentID), hour.Add(-baselineLookback), hour, baselineStep)
+	if err != nil {
+		// 服务停止时的取消不计入失败冷却
+		if ctx.Err() == nil {
+			err = fmt.Errorf("查询基线历史数据失败: %w", err)
+			s.failureCache.Set(cacheKey, err, baselineFailureTTL)
+		}
+		return nil, err
+	}
+
+	samples := &baselineSamples{}
+	weekHour := hourOfWeek(now)
+	earliest := hour.UnixMilli()
+	for _, point := range vmclient.ConvertToDataPoints(result) {
+		if math.IsNaN(point.Value) {
+			continue
+		}
+		earliest = min(earliest, point.Timestamp)
+		if hourOfWeek(time.UnixMilli(point.Timestamp)) == weekHour {
+			samples.values = append(samples.values, point.Value)
+		}
+	}
+	samples.historyDays = hour.Sub(time.UnixMilli(earliest)).Hours() / 24
+
+	s.samplesCache.Set(cacheKey, samples, time.Hour)
+	return samples, nil
+}
+
+// hourOfWeek 计算每周小时（服务器时区），周日 0 点为 0
+func hourOfWeek(t time.Time) int {
+	t = t.In(time.Local)
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// meanStdDev 计算均值和总体标准差
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// medianMAD 计算中位数和换算为标准差尺度的 MAD
+func medianMAD(values []float64) (float64, float64) {
+	m := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+	}
+	return m, median(deviations) * madScale
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
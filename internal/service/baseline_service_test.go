@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"go.uber.org/zap"
+)
+
+func TestBaselineStatistics(t *testing.T) {
+	values := []float64{4, 5, 5, 6, 5, 4, 6, 95}
+
+	mean, stddev := meanStdDev(values)
+	if math.Abs(mean-16.25) > 1e-9 {
+		t.Errorf("mean = %v", mean)
+	}
+	if stddev < 29 || stddev > 30 {
+		t.Errorf("stddev = %v", stddev)
+	}
+
+	// 单个尖峰不影响中位数和 MAD
+	center, spread := medianMAD(values)
+	if center != 5 {
+		t.Errorf("median = %v", center)
+	}
+	if math.Abs(spread-madScale) > 1e-9 {
+		t.Errorf("mad = %v", spread)
+	}
+}
+
+func TestBaselineRange(t *testing.T) {
+	baseline := &Baseline{Method: models.AnomalyMethodMAD, Center: 5, Spread: 0.5}
+
+	// 范围半宽不小于最小值
+	lower, upper := baseline.Range("cpu", 3)
+	if lower != 0 || upper != 10 {
+		t.Errorf("Range() = %v ~ %v, want 0 ~ 10", lower, upper)
+	}
+
+	baseline = &Baseline{Center: 90, Spread: 5}
+	lower, upper = baseline.Range("memory", 3)
+	if lower != 75 || upper != 100 {
+		t.Errorf("Range() = %v ~ %v, want 75 ~ 100", lower, upper)
+	}
+
+	// 网速不限制上限
+	baseline = &Baseline{Center: 90, Spread: 5}
+	if _, upper = baseline.Range("network", 3); upper != 105 {
+		t.Errorf("Range() upper = %v, want 105", upper)
+	}
+}
+
+func TestBaselineSamplesCache(t *testing.T) {
+	now := time.Date(2026, 10, 16, 10, 30, 0, 0, time.Local)
+	ctx := context.Background()
+
+	t.Run("查询成功时缓存到下一小时", func(t *testing.T) {
+		server := newCaptureServer(t, http.StatusOK, `{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+		s := NewBaselineService(zap.NewNop(), vmclient.NewVMClient(server.URL, 0, 0))
+		for i := 0; i < 2; i++ {
+			if baseline, err := s.GetBaseline(ctx, "a1", "cpu", models.AnomalyMethodMAD, 7, now); err != nil || baseline != nil {
+				t.Fatalf("GetBaseline() = %v, %v, want nil, nil", baseline, err)
+			}
+		}
+		if n := len(server.Requests()); n != 1 {
+			t.Fatalf("请求数 = %d, want 1", n)
+		}
+		if _, err := s.GetBaseline(ctx, "a1", "cpu", models.AnomalyMethodMAD, 7, now.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(server.Requests()); n != 2 {
+			t.Fatalf("请求数 = %d, want 2", n)
+		}
+	})
+
+	t.Run("查询失败后冷却期内不再查询", func(t *testing.T) {
+		server := newCaptureServer(t, http.StatusServiceUnavailable, `{"status":"error"}`)
+		s := NewBaselineService(zap.NewNop(), vmclient.NewVMClient(server.URL, 0, 0))
+		for i := 0; i < 3; i++ {
+			if _, err := s.GetBaseline(ctx, "a1", "cpu", models.AnomalyMethodMAD, 7, now); err == nil {
+				t.Fatal("查询失败时应返回错误")
+			}
+		}
+		if n := len(server.Requests()); n != 1 {
+			t.Fatalf("请求数 = %d, want 1", n)
+		}
+		// 其他指标不受影响
+		if _, err := s.GetBaseline(ctx, "a1", "memory", models.AnomalyMethodMAD, 7, now); err == nil {
+			t.Fatal("查询失败时应返回错误")
+		}
+		if n := len(server.Requests()); n != 2 {
+			t.Fatalf("请求数 = %d, want 2", n)
+		}
+	})
+
+	t.Run("查询超时", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		t.Cleanup(server.Close)
+		s := NewBaselineService(zap.NewNop(), vmclient.NewVMClient(server.URL, 0, 0))
+		s.queryTimeout = 50 * time.Millisecond
+
+		start := time.Now()
+		if _, err := s.GetBaseline(ctx, "a1", "cpu", models.AnomalyMethodMAD, 7, now); err == nil {
+			t.Fatal("查询超时时应返回错误")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("查询耗时 %v，未按超时时间返回", elapsed)
+		}
+	})
+}
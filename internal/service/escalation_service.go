@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/cache"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// escalationPolicyCacheKey 升级策略的缓存键
+const escalationPolicyCacheKey = "enabled"
+
+// EscalationService 告警升级服务
+type EscalationService struct {
+	logger               *zap.Logger
+	EscalationPolicyRepo *repo.EscalationPolicyRepo // 导出用于 handler 的 PageBuilder
+	AlertEscalationRepo  *repo.AlertEscalationRepo
+
+	policyCache cache.Cache[string, []models.EscalationPolicy]
+}
+
+func NewEscalationService(logger *zap.Logger, db *gorm.DB) *EscalationService {
+	return &EscalationService{
+		logger:               logger,
+		EscalationPolicyRepo: repo.NewEscalationPolicyRepo(db),
+		AlertEscalationRepo:  repo.NewAlertEscalationRepo(db),
+		policyCache:          cache.New[string, []models.EscalationPolicy](time.Minute),
+	}
+}
+
+// EscalationPolicyRequest 创建/更新升级策略请求
+type EscalationPolicyRequest struct {
+	Name           string                  `json:"name"`
+	Description    string                  `json:"description"`
+	Enabled        bool                    `json:"enabled"`
+	Levels         []string                `json:"levels"`
+	AlertPolicyIds []string                `json:"alertPolicyIds"`
+	Steps          []models.EscalationStep `json:"steps"`
+	RepeatInterval int                     `json:"repeatInterval"`
+}
+
+// ValidateEscalationPolicy 校验升级策略配置
+func ValidateEscalationPolicy(req *EscalationPolicyRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("名称不能为空")
+	}
+	if len(req.Levels) == 0 && len(req.AlertPolicyIds) == 0 {
+		return errors.New("至少需要关联一个告警级别或告警策略")
+	}
+	for _, level := range req.Levels {
+		if _, ok := alertLevelOrder[level]; !ok {
+			return fmt.Errorf("不支持的告警级别: %s", level)
+		}
+	}
+	if len(req.Steps) == 0 {
+		return errors.New("至少需要一个升级步骤")
+	}
+	for i, step := range req.Steps {
+		if step.Delay < 0 {
+			return fmt.Errorf("第 %d 步的延迟不能小于0", i+1)
+		}
+		if i > 0 && step.Delay <= req.Steps[i-1].Delay {
+			return fmt.Errorf("第 %d 步的延迟必须大于上一步", i+1)
+		}
+		if len(step.Channels) == 0 {
+			return fmt.Errorf("第 %d 步至少需要一个通知渠道", i+1)
+		}
+	}
+	if req.RepeatInterval < 0 {
+		return errors.New("重复通知间隔不能小于0")
+	}
+	return nil
+}
+
+// CreatePolicy 创建升级策略
+func (s *EscalationService) CreatePolicy(ctx context.Context, req *EscalationPolicyRequest) (*models.EscalationPolicy, error) {
+	now := time.Now().UnixMilli()
+	policy := &models.EscalationPolicy{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyEscalationRequest(policy, req)
+
+	if err := s.EscalationPolicyRepo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.policyCache.Delete(escalationPolicyCacheKey)
+	return policy, nil
+}
+
+// UpdatePolicy 更新升级策略，已开始的升级在下一步执行时使用新的配置
+func (s *EscalationService) UpdatePolicy(ctx context.Context, id string, req *EscalationPolicyRequest) (*models.EscalationPolicy, error) {
+	policy, err := s.EscalationPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	applyEscalationRequest(&policy, req)
+
+	if err := s.EscalationPolicyRepo.Save(ctx, &policy); err != nil {
+		return nil, err
+	}
+	s.policyCache.Delete(escalationPolicyCacheKey)
+	return &policy, nil
+}
+
+// DeletePolicy 删除升级策略，已开始的升级在下一次检查时停止
+func (s *EscalationService) DeletePolicy(ctx context.Context, id string) error {
+	if err := s.EscalationPolicyRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	s.policyCache.Delete(escalationPolicyCacheKey)
+	return nil
+}
+
+func applyEscalationRequest(policy *models.EscalationPolicy, req *EscalationPolicyRequest) {
+	policy.Name = req.Name
+	policy.Description = req.Description
+	policy.Enabled = req.Enabled
+	policy.Levels = req.Levels
+	policy.AlertPolicyIds = req.AlertPolicyIds
+	policy.Steps = req.Steps
+	policy.RepeatInterval = req.RepeatInterval
+}
+
+// listEnabled 获取已启用的升级策略（带缓存）
+func (s *EscalationService) listEnabled(ctx context.Context) []models.EscalationPolicy {
+	if policies, ok := s.policyCache.Get(escalationPolicyCacheKey); ok {
+		return policies
+	}
+	policies, err := s.EscalationPolicyRepo.FindAllEnabled(ctx)
+	if err != nil {
+		s.logger.Error("查询升级策略失败", zap.Error(err))
+		return nil
+	}
+	s.policyCache.Set(escalationPolicyCacheKey, policies, time.Minute)
+	return policies
+}
+
+// MatchPolicy 查找适用于告警记录的升级策略，关联告警策略的优先于关联告警级别的，未匹配时返回 nil
+func (s *EscalationService) MatchPolicy(ctx context.Context, record *models.AlertRecord) *models.EscalationPolicy {
+	policies := s.listEnabled(ctx)
+	if record.PolicyID != "" {
+		for i := range policies {
+			if slices.Contains(policies[i].AlertPolicyIds, record.PolicyID) {
+				return &policies[i]
+			}
+		}
+	}
+	for i := range policies {
+		if slices.Contains(policies[i].Levels, record.Level) {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// FindPolicy 获取已启用的升级策略，不存在或已禁用时返回 nil
+func (s *EscalationService) FindPolicy(ctx context.Context, id string) *models.EscalationPolicy {
+	policies := s.listEnabled(ctx)
+	for i := range policies {
+		if policies[i].ID == id {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// Start 为触发的告警创建升级，返回需要立即通知的渠道（第一步无延迟时）
+func (s *EscalationService) Start(ctx context.Context, policy *models.EscalationPolicy, record *models.AlertRecord) (*models.AlertEscalation, []string, error) {
+	now := time.Now().UnixMilli()
+	escalation := &models.AlertEscalation{
+		RecordID:  record.ID,
+		PolicyID:  policy.ID,
+		StepIndex: -1,
+		Channels:  []string{},
+		CreatedAt: record.FiredAt,
+		UpdatedAt: now,
+	}
+	if escalation.CreatedAt == 0 {
+		escalation.CreatedAt = now
+	}
+
+	var channels []string
+	if policy.Steps[0].Delay == 0 {
+		channels = policy.Steps[0].Channels
+		s.Advance(escalation, policy, now)
+	} else {
+		escalation.NextEscalateAt = escalation.CreatedAt + int64(policy.Steps[0].Delay)*time.Minute.Milliseconds()
+	}
+
+	if err := s.AlertEscalationRepo.Create(ctx, escalation); err != nil {
+		return nil, nil, err
+	}
+	return escalation, channels, nil
+}
+
+// Advance 将升级推进到下一步，并计算下一步和下一次重复通知的时间
+func (s *EscalationService) Advance(escalation *models.AlertEscalation, policy *models.EscalationPolicy, now int64) {
+	escalation.StepIndex++
+	step := policy.Steps[escalation.StepIndex]
+	for _, channel := range step.Channels {
+		if !slices.Contains(escalation.Channels, channel) {
+			escalation.Channels = append(escalation.Channels, channel)
+		}
+	}
+
+	escalation.NextEscalateAt = 0
+	if next := escalation.StepIndex + 1; next < len(policy.Steps) {
+		escalation.NextEscalateAt = escalation.CreatedAt + int64(policy.Steps[next].Delay)*time.Minute.Milliseconds()
+	}
+	s.ScheduleRepeat(escalation, policy, now)
+}
+
+// ScheduleRepeat 计算下一次重复通知的时间
+func (s *EscalationService) ScheduleRepeat(escalation *models.AlertEscalation, policy *models.EscalationPolicy, now int64) {
+	escalation.NextRepeatAt = 0
+	if policy.RepeatInterval > 0 {
+		escalation.NextRepeatAt = now + int64(policy.RepeatInterval)*time.Minute.Milliseconds()
+	}
+}
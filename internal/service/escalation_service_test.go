@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func testEscalationPolicy() *models.EscalationPolicy {
+	return &models.EscalationPolicy{
+		ID:   "e1",
+		Name: "值班升级",
+		Steps: []models.EscalationStep{
+			{Delay: 0, Channels: []string{"email"}},
+			{Delay: 10, Channels: []string{"dingtalk", "email"}},
+			{Delay: 30, Channels: []string{"pagerduty"}},
+		},
+		RepeatInterval: 15,
+	}
+}
+
+func TestEscalationAdvance(t *testing.T) {
+	const createdAt = int64(1_000_000)
+	minute := time.Minute.Milliseconds()
+	now := createdAt + 12*minute
+
+	tests := []struct {
+		name         string
+		stepIndex    int
+		channels     []string
+		repeat       int
+		wantStep     int
+		wantChannels []string
+		wantEscalate int64
+		wantRepeat   int64
+	}{
+		{"执行第一步", -1, nil, 15, 0, []string{"email"}, createdAt + 10*minute, now + 15*minute},
+		{"升级到第二步并合并渠道", 0, []string{"email"}, 15, 1, []string{"email", "dingtalk"}, createdAt + 30*minute, now + 15*minute},
+		{"最后一步没有下一次升级", 1, []string{"email", "dingtalk"}, 15, 2, []string{"email", "dingtalk", "pagerduty"}, 0, now + 15*minute},
+		{"不重复通知", -1, nil, 0, 0, []string{"email"}, createdAt + 10*minute, 0},
+	}
+
+	s := &EscalationService{logger: zap.NewNop()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testEscalationPolicy()
+			policy.RepeatInterval = tt.repeat
+			escalation := &models.AlertEscalation{StepIndex: tt.stepIndex, Channels: tt.channels, CreatedAt: createdAt}
+
+			s.Advance(escalation, policy, now)
+			if escalation.StepIndex != tt.wantStep {
+				t.Fatalf("StepIndex = %d, want %d", escalation.StepIndex, tt.wantStep)
+			}
+			if !slices.Equal(escalation.Channels, tt.wantChannels) {
+				t.Fatalf("Channels = %v, want %v", escalation.Channels, tt.wantChannels)
+			}
+			if escalation.NextEscalateAt != tt.wantEscalate {
+				t.Fatalf("NextEscalateAt = %d, want %d", escalation.NextEscalateAt, tt.wantEscalate)
+			}
+			if escalation.NextRepeatAt != tt.wantRepeat {
+				t.Fatalf("NextRepeatAt = %d, want %d", escalation.NextRepeatAt, tt.wantRepeat)
+			}
+		})
+	}
+}
+
+func TestEscalationMatchPolicy(t *testing.T) {
+	byLevel := models.EscalationPolicy{ID: "level", Levels: []string{"critical"}}
+	byPolicy := models.EscalationPolicy{ID: "policy", AlertPolicyIds: []string{"p1"}}
+
+	tests := []struct {
+		name     string
+		policyID string
+		level    string
+		want     string
+	}{
+		{"告警策略优先于级别", "p1", "critical", "policy"},
+		{"按级别匹配", "p2", "critical", "level"},
+		{"没有告警策略时按级别匹配", "", "critical", "level"},
+		{"未匹配", "p2", "warning", ""},
+	}
+
+	s := NewEscalationService(zap.NewNop(), nil)
+	// 预置缓存，避免查询数据库
+	s.policyCache.Set(escalationPolicyCacheKey, []models.EscalationPolicy{byLevel, byPolicy}, time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &models.AlertRecord{PolicyID: tt.policyID, Level: tt.level}
+			got := ""
+			if policy := s.MatchPolicy(context.Background(), record); policy != nil {
+				got = policy.ID
+			}
+			if got != tt.want {
+				t.Fatalf("MatchPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscalationStart(t *testing.T) {
+	const firedAt = int64(1_000_000)
+	minute := time.Minute.Milliseconds()
+
+	tests := []struct {
+		name         string
+		firstDelay   int
+		wantChannels []string
+		wantStep     int
+		wantEscalate int64
+	}{
+		{"第一步立即通知", 0, []string{"email"}, 0, firedAt + 10*minute},
+		{"第一步延迟执行", 5, nil, -1, firedAt + 5*minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.AlertEscalation{})
+			s := NewEscalationService(zap.NewNop(), db)
+			policy := testEscalationPolicy()
+			policy.Steps[0].Delay = tt.firstDelay
+			record := &models.AlertRecord{ID: 1, FiredAt: firedAt}
+
+			escalation, channels, err := s.Start(context.Background(), policy, record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(channels, tt.wantChannels) {
+				t.Fatalf("channels = %v, want %v", channels, tt.wantChannels)
+			}
+			if escalation.StepIndex != tt.wantStep || escalation.NextEscalateAt != tt.wantEscalate {
+				t.Fatalf("escalation = step %d next %d, want step %d next %d",
+					escalation.StepIndex, escalation.NextEscalateAt, tt.wantStep, tt.wantEscalate)
+			}
+
+			// 到期查询以下一次升级时间为准
+			due, err := s.AlertEscalationRepo.FindDue(context.Background(), tt.wantEscalate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(due) != 1 || due[0].RecordID != record.ID {
+				t.Fatalf("FindDue() = %+v, want record %d", due, record.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"github.com/go-orz/cache"
+	"go.uber.org/zap"
+)
+
+const (
+	forecastCacheTTL = 10 * time.Minute // 磁盘预测结果缓存时间
+	// 线性拟合至少需要的样本数和时间跨度
+	forecastMinPoints = 10
+	forecastMinSpan   = time.Hour
+	// 流量预测至少需要的周期内时长，避免周期刚开始时的突发流量导致误报
+	trafficForecastMinElapsed = 24 * time.Hour
+)
+
+// ForecastService 容量预测服务，预测磁盘写满时间和周期结束时的流量使用
+type ForecastService struct {
+	logger        *zap.Logger
+	vmClient      *vmclient.VMClient
+	policyService *AlertPolicyService
+
+	diskCache cache.Cache[string, []DiskForecast]
+}
+
+// AgentForecast 探针容量预测
+type AgentForecast struct {
+	Disks   []DiskForecast   `json:"disks"`             // 各挂载点的磁盘预测
+	Traffic *TrafficForecast `json:"traffic,omitempty"` // 流量预测，未配置限额和重置日期或数据不足时为空
+}
+
+// DiskForecast 磁盘写满预测
+type DiskForecast struct {
+	MountPoint    string  `json:"mountPoint"`            // 挂载点
+	Total         uint64  `json:"total"`                 // 总容量（字节）
+	Used          uint64  `json:"used"`                  // 当前已使用（字节）
+	GrowthPerHour float64 `json:"growthPerHour"`         // 拟合得到的每小时增长（字节），负数表示在减少
+	HoursToFull   float64 `json:"hoursToFull,omitempty"` // 预计写满的剩余小时数
+	FullAt        int64   `json:"fullAt,omitempty"`      // 预计写满时间（时间戳毫秒），0 表示不会写满
+}
+
+// TrafficForecast 流量超额预测
+type TrafficForecast struct {
+	Used             uint64  `json:"used"`               // 当前周期已使用（字节）
+	Limit            uint64  `json:"limit"`              // 流量限额（字节）
+	PeriodEnd        int64   `json:"periodEnd"`          // 周期结束（下次重置）时间（时间戳毫秒）
+	Projected        uint64  `json:"projected"`          // 预计周期结束时的使用量（字节）
+	ProjectedPercent float64 `json:"projectedPercent"`   // 预计周期结束时的使用百分比
+	ExceedAt         int64   `json:"exceedAt,omitempty"` // 预计超出限额的时间（时间戳毫秒），0 表示周期内不会超出
+}
+
+func NewForecastService(logger *zap.Logger, vmClient *vmclient.VMClient, policyService *AlertPolicyService) *ForecastService {
+	return &ForecastService{
+		logger:        logger,
+		vmClient:      vmClient,
+		policyService: policyService,
+		diskCache:     cache.New[string, []DiskForecast](time.Minute),
+	}
+}
+
+// GetAgentForecast 获取探针的容量预测，磁盘拟合时长使用探针生效的告警策略
+func (s *ForecastService) GetAgentForecast(ctx context.Context, agent *models.Agent) (*AgentForecast, error) {
+	policy, err := s.policyService.ResolveForAgent(ctx, agent)
+	if err != nil {
+		return nil, err
+	}
+	disks, err := s.ForecastDisks(ctx, agent.ID, time.Duration(policy.Rules.DiskForecastWindow)*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	stats := agent.TrafficStats.Data()
+	return &AgentForecast{
+		Disks:   disks,
+		Traffic: ForecastTraffic(&stats, time.Now()),
+	}, nil
+}
+
+// ForecastDisks 对各挂载点最近 window 时间内的已用空间做线性回归，预测写满时间
+func (s *ForecastService) ForecastDisks(ctx context.Context, agentID string, window time.Duration) ([]DiskForecast, error) {
+	cacheKey := fmt.Sprintf("%s:%d", agentID, int64(window.Hours()))
+	if forecasts, ok := s.diskCache.Get(cacheKey); ok {
+		return forecasts, nil
+	}
+
+	end := time.Now()
+	start := end.Add(-window)
+	step := max(window/240, time.Minute)
+
+	usedResult, err := s.vmClient.QueryRange(ctx, fmt.Sprintf(`pika_disk_used_bytes{agent_id="%s"}`, agentID), start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	totalResult, err := s.vmClient.Query(ctx, fmt.Sprintf(`pika_disk_total_bytes{agent_id="%s"}`, agentID))
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	for _, r := range totalResult.Data.Result {
+		if value, ok := r.InstantValue(); ok {
+			totals[r.Metric["mount_point"]] = value
+		}
+	}
+
+	series := make(map[string][]vmclient.DataPoint)
+	for _, point := range vmclient.ConvertToDataPoints(usedResult) {
+		mountPoint := point.Labels["mount_point"]
+		series[mountPoint] = append(series[mountPoint], point)
+	}
+
+	forecasts := make([]DiskForecast, 0, len(series))
+	for mountPoint, points := range series {
+		total, ok := totals[mountPoint]
+		if !ok || total <= 0 {
+			continue
+		}
+		if forecast, ok := forecastDisk(mountPoint, total, points, end); ok {
+			forecasts = append(forecasts, forecast)
+		}
+	}
+	slices.SortFunc(forecasts, func(a, b DiskForecast) int {
+		return strings.Compare(a.MountPoint, b.MountPoint)
+	})
+
+	s.diskCache.Set(cacheKey, forecasts, forecastCacheTTL)
+	return forecasts, nil
+}
+
+// forecastDisk 根据已用空间的历史数据预测写满时间，数据不足时返回 false
+func forecastDisk(mountPoint string, total float64, points []vmclient.DataPoint, now time.Time) (DiskForecast, bool) {
+	if len(points) < forecastMinPoints {
+		return DiskForecast{}, false
+	}
+	slices.SortFunc(points, func(a, b vmclient.DataPoint) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	first, last := points[0], points[len(points)-1]
+	if time.Duration(last.Timestamp-first.Timestamp)*time.Millisecond < forecastMinSpan {
+		return DiskForecast{}, false
+	}
+
+	// 以小时为单位拟合 used = intercept + slope * t
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, point := range points {
+		xs[i] = float64(point.Timestamp-first.Timestamp) / float64(time.Hour.Milliseconds())
+		ys[i] = point.Value
+	}
+	slope, _ := linearRegression(xs, ys)
+
+	forecast := DiskForecast{
+		MountPoint:    mountPoint,
+		Total:         uint64(total),
+		Used:          uint64(last.Value),
+		GrowthPerHour: slope,
+	}
+	if slope > 0 {
+		hours := max(total-last.Value, 0) / slope
+		forecast.HoursToFull = hours
+		forecast.FullAt = now.Add(time.Duration(hours * float64(time.Hour))).UnixMilli()
+	}
+	return forecast, true
+}
+
+// linearRegression 最小二乘线性回归，返回斜率和截距
+func linearRegression(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, sumY / n
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept = (sumY - slope*sumX) / n
+	return slope, intercept
+}
+
+// ForecastTraffic 按当前周期的平均使用速度预测周期结束时的流量使用
+// 未启用流量统计、未配置限额或重置日期、周期内时长不足时返回 nil
+func ForecastTraffic(stats *models.TrafficStatsData, now time.Time) *TrafficForecast {
+	if !stats.Enabled || stats.Limit == 0 || stats.ResetDay == 0 || stats.PeriodStart == 0 {
+		return nil
+	}
+	periodStart := time.UnixMilli(stats.PeriodStart)
+	elapsed := now.Sub(periodStart)
+	if elapsed < trafficForecastMinElapsed {
+		return nil
+	}
+	periodEnd := calculateNextResetDate(periodStart, stats.ResetDay)
+	if !periodEnd.After(now) {
+		return nil
+	}
+
+	rate := float64(stats.Used) / elapsed.Seconds() // 字节/秒
+	projected := float64(stats.Used) + rate*periodEnd.Sub(now).Seconds()
+	forecast := &TrafficForecast{
+		Used:             stats.Used,
+		Limit:            stats.Limit,
+		PeriodEnd:        periodEnd.UnixMilli(),
+		Projected:        uint64(math.Round(projected)),
+		ProjectedPercent: projected / float64(stats.Limit) * 100,
+	}
+	if stats.Used < stats.Limit && projected > float64(stats.Limit) && rate > 0 {
+		seconds := float64(stats.Limit-stats.Used) / rate
+		forecast.ExceedAt = now.Add(time.Duration(seconds * float64(time.Second))).UnixMilli()
+	}
+	return forecast
+}
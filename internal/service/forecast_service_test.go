@@ -0,0 +1,82 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/vmclient"
+)
+
+func TestForecastDisk(t *testing.T) {
+	now := time.Now()
+	const gb = 1 << 30
+	// 过去 12 小时每小时增长 1GB，当前已用 80GB，总容量 100GB
+	var points []vmclient.DataPoint
+	for i := 12; i >= 0; i-- {
+		points = append(points, vmclient.DataPoint{
+			Timestamp: now.Add(-time.Duration(i) * time.Hour).UnixMilli(),
+			Value:     float64(80-i) * gb,
+		})
+	}
+
+	forecast, ok := forecastDisk("/data", 100*gb, points, now)
+	if !ok {
+		t.Fatal("数据充足时应返回预测结果")
+	}
+	if math.Abs(forecast.GrowthPerHour-gb) > 1 {
+		t.Errorf("GrowthPerHour = %v, want %v", forecast.GrowthPerHour, gb)
+	}
+	if math.Abs(forecast.HoursToFull-20) > 0.01 {
+		t.Errorf("HoursToFull = %v, want 20", forecast.HoursToFull)
+	}
+
+	// 使用量下降时不会写满
+	for i := range points {
+		points[i].Value = float64(80*gb) - float64(i)*gb
+	}
+	forecast, _ = forecastDisk("/data", 100*gb, points, now)
+	if forecast.FullAt != 0 {
+		t.Errorf("使用量下降时不应预测写满时间")
+	}
+
+	// 样本不足
+	if _, ok := forecastDisk("/data", 100*gb, points[:3], now); ok {
+		t.Error("样本不足时不应返回预测结果")
+	}
+}
+
+func TestForecastTraffic(t *testing.T) {
+	now := time.Date(2026, 10, 11, 0, 0, 0, 0, time.Local)
+	stats := &models.TrafficStatsData{
+		Enabled:     true,
+		Limit:       1000,
+		Used:        500,
+		ResetDay:    1,
+		PeriodStart: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local).UnixMilli(),
+	}
+
+	// 10 天用了 500，剩余 21 天预计再用 1050
+	forecast := ForecastTraffic(stats, now)
+	if forecast == nil {
+		t.Fatal("应返回流量预测")
+	}
+	if forecast.Projected != 1550 {
+		t.Errorf("Projected = %d, want 1550", forecast.Projected)
+	}
+	wantExceed := now.Add(10 * 24 * time.Hour).UnixMilli()
+	if forecast.ExceedAt != wantExceed {
+		t.Errorf("ExceedAt = %v, want %v", time.UnixMilli(forecast.ExceedAt), time.UnixMilli(wantExceed))
+	}
+
+	stats.Used = 100
+	if forecast := ForecastTraffic(stats, now); forecast.ExceedAt != 0 {
+		t.Errorf("预计不超出限额时 ExceedAt 应为 0")
+	}
+
+	stats.ResetDay = 0
+	if ForecastTraffic(stats, now) != nil {
+		t.Error("未配置重置日期时不应预测")
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建内存 SQLite 数据库并迁移指定的表
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	// 内存数据库每个连接相互独立，只保留一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("迁移数据库失败: %v", err)
+	}
+	return db
+}
+
+// capturedRequest 本地 HTTP 服务收到的请求
+type capturedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Raw    string
+	Body   map[string]interface{}
+}
+
+// captureServer 记录收到的全部请求并返回固定响应的本地 HTTP 服务
+type captureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []capturedRequest
+}
+
+// newCaptureServer 创建本地 HTTP 服务，请求体为 JSON 时解析到 Body
+func newCaptureServer(t *testing.T, statusCode int, response string) *captureServer {
+	t.Helper()
+	s := &captureServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		captured := capturedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header.Clone(),
+			Raw:    string(data),
+		}
+		_ = json.Unmarshal(data, &captured.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, captured)
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests 返回收到的全部请求
+func (s *captureServer) Requests() []capturedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]capturedRequest(nil), s.requests...)
+}
+
+// Last 返回最后一次收到的请求，没有请求时返回零值
+func (s *captureServer) Last() capturedRequest {
+	requests := s.Requests()
+	if len(requests) == 0 {
+		return capturedRequest{}
+	}
+	return requests[len(requests)-1]
+}
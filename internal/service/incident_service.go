@@ -0,0 +1,326 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// incidentReopenWindow 告警自动恢复后在该时间内再次触发，重新打开原事件而不是创建新事件（抑制抖动）
+	incidentReopenWindow = 30 * time.Minute
+	// incidentRelatedLookback 时间线中关联 SSH 登录和防篡改事件的回看时间
+	incidentRelatedLookback = 30 * time.Minute
+)
+
+var alertLevelOrder = map[string]int{
+	"info":     1,
+	"warning":  2,
+	"critical": 3,
+}
+
+// IncidentService 告警事件服务
+type IncidentService struct {
+	logger *zap.Logger
+	*orz.Service
+	IncidentRepo      *repo.IncidentRepo
+	incidentEventRepo *repo.IncidentEventRepo
+	alertRecordRepo   *repo.AlertRecordRepo
+	sshLoginRepo      *repo.SSHLoginEventRepo
+	tamperEventRepo   *repo.TamperEventRepo
+}
+
+func NewIncidentService(logger *zap.Logger, db *gorm.DB) *IncidentService {
+	return &IncidentService{
+		logger:            logger,
+		Service:           orz.NewService(db),
+		IncidentRepo:      repo.NewIncidentRepo(db),
+		incidentEventRepo: repo.NewIncidentEventRepo(db),
+		alertRecordRepo:   repo.NewAlertRecordRepo(db),
+		sshLoginRepo:      repo.NewSSHLoginEventRepo(db),
+		tamperEventRepo:   repo.NewTamperEventRepo(db),
+	}
+}
+
+// IncidentTimelineItem 时间线条目
+type IncidentTimelineItem struct {
+	Time     int64  `json:"time"`               // 时间（时间戳毫秒）
+	Type     string `json:"type"`               // 类型
+	Message  string `json:"message"`            // 内容
+	Operator string `json:"operator,omitempty"` // 操作人
+	RefID    string `json:"refId,omitempty"`    // 关联记录ID
+}
+
+// IncidentDetail 告警事件详情
+type IncidentDetail struct {
+	models.Incident
+	Timeline []IncidentTimelineItem `json:"timeline"`
+}
+
+func (s *IncidentService) addEvent(ctx context.Context, incidentID int64, eventType, message, operator, refID string) error {
+	return s.incidentEventRepo.Create(ctx, &models.IncidentEvent{
+		IncidentID: incidentID,
+		Type:       eventType,
+		Message:    message,
+		Operator:   operator,
+		RefID:      refID,
+		CreatedAt:  time.Now().UnixMilli(),
+	})
+}
+
+// OnAlertFired 告警触发时归并到事件：存在未解决事件时追加，刚自动恢复的事件重新打开，否则创建新事件
+func (s *IncidentService) OnAlertFired(ctx context.Context, groupKey string, record *models.AlertRecord) error {
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		now := time.Now().UnixMilli()
+
+		incident, err := s.IncidentRepo.FindActiveByGroupKey(ctx, groupKey)
+		if err != nil {
+			return err
+		}
+		reopened := false
+		if incident == nil {
+			incident, err = s.IncidentRepo.FindLatestResolvedByGroupKey(ctx, groupKey, now-incidentReopenWindow.Milliseconds())
+			if err != nil {
+				return err
+			}
+			if incident != nil {
+				reopened = true
+				incident.State = models.IncidentStateOpen
+				incident.ResolvedAt = 0
+				incident.AcknowledgedBy = ""
+				incident.AcknowledgedAt = 0
+			}
+		}
+		if incident == nil {
+			incident = &models.Incident{
+				GroupKey:  groupKey,
+				AgentID:   record.AgentID,
+				AlertType: record.AlertType,
+				State:     models.IncidentStateOpen,
+				StartedAt: record.FiredAt,
+				CreatedAt: now,
+			}
+		}
+
+		incident.AgentName = record.AgentName
+		incident.Title = record.Message
+		if alertLevelOrder[record.Level] > alertLevelOrder[incident.Level] {
+			incident.Level = record.Level
+		}
+		incident.AlertCount++
+		incident.LastRecordID = record.ID
+		incident.UpdatedAt = now
+		if err := s.IncidentRepo.Save(ctx, incident); err != nil {
+			return err
+		}
+
+		if reopened {
+			if err := s.addEvent(ctx, incident.ID, models.IncidentEventReopened, "告警再次触发，事件重新打开", "", ""); err != nil {
+				return err
+			}
+		}
+		if err := s.addEvent(ctx, incident.ID, models.IncidentEventAlertFiring, record.Message, "", strconv.FormatInt(record.ID, 10)); err != nil {
+			return err
+		}
+
+		record.IncidentID = incident.ID
+		return s.alertRecordRepo.Save(ctx, record)
+	})
+}
+
+// OnAlertResolved 告警恢复时记录时间线并自动解决事件
+func (s *IncidentService) OnAlertResolved(ctx context.Context, record *models.AlertRecord) error {
+	if record.IncidentID == 0 {
+		return nil
+	}
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		incident, err := s.IncidentRepo.FindById(ctx, record.IncidentID)
+		if err != nil {
+			return err
+		}
+		if err := s.addEvent(ctx, incident.ID, models.IncidentEventAlertResolved, "告警已恢复", "", strconv.FormatInt(record.ID, 10)); err != nil {
+			return err
+		}
+		if incident.State == models.IncidentStateResolved {
+			return nil
+		}
+		incident.State = models.IncidentStateResolved
+		incident.ResolvedAt = record.ResolvedAt
+		return s.IncidentRepo.Save(ctx, &incident)
+	})
+}
+
+// OnNotificationSent 记录通知发送情况
+func (s *IncidentService) OnNotificationSent(ctx context.Context, incidentID int64, message string) error {
+	if incidentID == 0 {
+		return nil
+	}
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		incident, err := s.IncidentRepo.FindById(ctx, incidentID)
+		if err != nil {
+			return err
+		}
+		incident.NotifyCount++
+		incident.LastNotifiedAt = time.Now().UnixMilli()
+		if err := s.IncidentRepo.Save(ctx, &incident); err != nil {
+			return err
+		}
+		return s.addEvent(ctx, incidentID, models.IncidentEventNotification, message, "", "")
+	})
+}
+
+// Acknowledge 确认事件，确认后不再重复发送通知
+func (s *IncidentService) Acknowledge(ctx context.Context, id int64, operator, note string) (*models.Incident, error) {
+	var incident models.Incident
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		incident, err = s.IncidentRepo.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if incident.State != models.IncidentStateOpen {
+			return orz.NewError(400, "只能确认未处理的事件")
+		}
+
+		incident.State = models.IncidentStateAcknowledged
+		incident.AcknowledgedBy = operator
+		incident.AcknowledgedAt = time.Now().UnixMilli()
+		if err := s.IncidentRepo.Save(ctx, &incident); err != nil {
+			return err
+		}
+		return s.addEvent(ctx, id, models.IncidentEventAcknowledged, note, operator, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// Resolve 手动解决事件
+// 告警条件仍满足时不会再次触发新的告警，直到告警自动恢复后重新触发
+func (s *IncidentService) Resolve(ctx context.Context, id int64, operator, note string) (*models.Incident, error) {
+	var incident models.Incident
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		incident, err = s.IncidentRepo.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if incident.State == models.IncidentStateResolved {
+			return orz.NewError(400, "事件已解决")
+		}
+
+		incident.State = models.IncidentStateResolved
+		incident.ResolvedBy = operator
+		incident.ResolvedAt = time.Now().UnixMilli()
+		if err := s.IncidentRepo.Save(ctx, &incident); err != nil {
+			return err
+		}
+		return s.addEvent(ctx, id, models.IncidentEventResolved, note, operator, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// AddComment 添加评论
+func (s *IncidentService) AddComment(ctx context.Context, id int64, operator, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return orz.NewError(400, "评论内容不能为空")
+	}
+	if _, err := s.IncidentRepo.FindById(ctx, id); err != nil {
+		return err
+	}
+	return s.addEvent(ctx, id, models.IncidentEventComment, content, operator, "")
+}
+
+// GetDetail 获取事件详情和时间线，时间线中包含同一探针在事件期间的 SSH 登录和防篡改事件
+func (s *IncidentService) GetDetail(ctx context.Context, id int64) (*IncidentDetail, error) {
+	incident, err := s.IncidentRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.incidentEventRepo.FindByIncidentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	timeline := make([]IncidentTimelineItem, 0, len(events))
+	for _, event := range events {
+		timeline = append(timeline, IncidentTimelineItem{
+			Time:     event.CreatedAt,
+			Type:     event.Type,
+			Message:  event.Message,
+			Operator: event.Operator,
+			RefID:    event.RefID,
+		})
+	}
+
+	// 推送监控和多点确认事件没有真实探针
+	if incident.AgentID != PushMonitorAgentID && incident.AgentID != QuorumMonitorAgentID {
+		start := incident.StartedAt - incidentRelatedLookback.Milliseconds()
+		end := time.Now().UnixMilli()
+		if incident.ResolvedAt > 0 {
+			end = incident.ResolvedAt + 1
+		}
+
+		logins, err := s.sshLoginRepo.FindByAgentBetween(ctx, incident.AgentID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, login := range logins {
+			timeline = append(timeline, IncidentTimelineItem{
+				Time:    login.Timestamp,
+				Type:    models.IncidentEventSSHLogin,
+				Message: fmt.Sprintf("用户 %s 从 %s 登录", login.Username, login.IP),
+				RefID:   login.ID,
+			})
+		}
+
+		tamperEvents, err := s.tamperEventRepo.FindByAgentBetween(ctx, incident.AgentID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range tamperEvents {
+			timeline = append(timeline, IncidentTimelineItem{
+				Time:    event.Timestamp,
+				Type:    models.IncidentEventTamper,
+				Message: fmt.Sprintf("%s %s", event.Operation, event.Path),
+				RefID:   event.ID,
+			})
+		}
+	}
+
+	slices.SortStableFunc(timeline, func(a, b IncidentTimelineItem) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+
+	return &IncidentDetail{
+		Incident: incident,
+		Timeline: timeline,
+	}, nil
+}
+
+// ListDueForRepeat 查询需要重复通知的事件（未确认且距上次通知超过间隔）
+func (s *IncidentService) ListDueForRepeat(ctx context.Context, interval time.Duration) ([]models.Incident, error) {
+	return s.IncidentRepo.FindOpenNotifiedBefore(ctx, time.Now().Add(-interval).UnixMilli())
+}
+
+// Clear 清空告警事件
+func (s *IncidentService) Clear(ctx context.Context) error {
+	if err := s.incidentEventRepo.Clear(ctx); err != nil {
+		return err
+	}
+	return s.IncidentRepo.Clear(ctx)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func newTestIncidentService(t *testing.T) *IncidentService {
+	t.Helper()
+	db := newTestDB(t, &models.Incident{}, &models.IncidentEvent{}, &models.AlertRecord{}, &models.SSHLoginEvent{}, &models.TamperEvent{})
+	return NewIncidentService(zap.NewNop(), db)
+}
+
+// fireTestAlert 创建告警记录并归并到事件
+func fireTestAlert(t *testing.T, s *IncidentService, groupKey, level string) *models.AlertRecord {
+	t.Helper()
+	record := &models.AlertRecord{
+		AgentID: "agent-1", AgentName: "web", AlertType: "cpu", Level: level,
+		Status: "firing", Message: "CPU使用率过高", FiredAt: time.Now().UnixMilli(),
+	}
+	if err := s.alertRecordRepo.Create(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.OnAlertFired(context.Background(), groupKey, record); err != nil {
+		t.Fatalf("OnAlertFired: %v", err)
+	}
+	return record
+}
+
+func getTestIncident(t *testing.T, s *IncidentService, id int64) models.Incident {
+	t.Helper()
+	incident, err := s.IncidentRepo.FindById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return incident
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := newTestIncidentService(t)
+	const groupKey = "agent-1:global:cpu"
+
+	// 首次触发创建事件，后续触发归并并提升级别
+	first := fireTestAlert(t, s, groupKey, "warning")
+	if first.IncidentID == 0 {
+		t.Fatal("告警记录应关联事件")
+	}
+	second := fireTestAlert(t, s, groupKey, "critical")
+	if second.IncidentID != first.IncidentID {
+		t.Fatalf("同一归并键应归并到同一事件: %d != %d", second.IncidentID, first.IncidentID)
+	}
+	incident := getTestIncident(t, s, first.IncidentID)
+	if incident.State != models.IncidentStateOpen || incident.AlertCount != 2 || incident.Level != "critical" {
+		t.Errorf("incident = %+v", incident)
+	}
+
+	// 确认
+	if _, err := s.Acknowledge(ctx, incident.ID, "admin", "处理中"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Acknowledge(ctx, incident.ID, "admin", ""); err == nil {
+		t.Error("已确认的事件不能再次确认")
+	}
+	// 已确认的事件仍归并新告警
+	fireTestAlert(t, s, groupKey, "info")
+	incident = getTestIncident(t, s, first.IncidentID)
+	if incident.State != models.IncidentStateAcknowledged || incident.AcknowledgedBy != "admin" || incident.Level != "critical" {
+		t.Errorf("incident = %+v", incident)
+	}
+
+	// 告警恢复自动解决事件
+	second.Status = "resolved"
+	second.ResolvedAt = time.Now().UnixMilli()
+	if err := s.OnAlertResolved(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	incident = getTestIncident(t, s, first.IncidentID)
+	if incident.State != models.IncidentStateResolved || incident.ResolvedAt != second.ResolvedAt || incident.ResolvedBy != "" {
+		t.Errorf("incident = %+v", incident)
+	}
+
+	// 自动恢复后短时间内再次触发，重新打开原事件并清除确认信息
+	reopened := fireTestAlert(t, s, groupKey, "warning")
+	if reopened.IncidentID != first.IncidentID {
+		t.Fatalf("应重新打开原事件: %d != %d", reopened.IncidentID, first.IncidentID)
+	}
+	incident = getTestIncident(t, s, first.IncidentID)
+	if incident.State != models.IncidentStateOpen || incident.ResolvedAt != 0 || incident.AcknowledgedBy != "" {
+		t.Errorf("incident = %+v", incident)
+	}
+
+	detail, err := s.GetDetail(ctx, incident.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, item := range detail.Timeline {
+		counts[item.Type]++
+	}
+	if counts[models.IncidentEventAlertFiring] != 4 || counts[models.IncidentEventAcknowledged] != 1 ||
+		counts[models.IncidentEventAlertResolved] != 1 || counts[models.IncidentEventReopened] != 1 {
+		t.Errorf("时间线 = %v", counts)
+	}
+}
+
+func TestIncidentManualResolve(t *testing.T) {
+	ctx := context.Background()
+	s := newTestIncidentService(t)
+	const groupKey = "agent-1:global:memory"
+
+	record := fireTestAlert(t, s, groupKey, "warning")
+	if _, err := s.Resolve(ctx, record.IncidentID, "admin", "已扩容"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Resolve(ctx, record.IncidentID, "admin", ""); err == nil {
+		t.Error("已解决的事件不能再次解决")
+	}
+	if _, err := s.Acknowledge(ctx, record.IncidentID, "admin", ""); err == nil {
+		t.Error("已解决的事件不能确认")
+	}
+
+	// 手动解决的事件不会被重新打开
+	next := fireTestAlert(t, s, groupKey, "warning")
+	if next.IncidentID == record.IncidentID {
+		t.Error("手动解决的事件不应重新打开")
+	}
+}
+
+func TestIncidentReopenWindow(t *testing.T) {
+	ctx := context.Background()
+	s := newTestIncidentService(t)
+	const groupKey = "agent-1:global:disk"
+
+	record := fireTestAlert(t, s, groupKey, "warning")
+	record.Status = "resolved"
+	// 超过重新打开的时间窗口
+	record.ResolvedAt = time.Now().Add(-incidentReopenWindow - time.Minute).UnixMilli()
+	if err := s.OnAlertResolved(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	next := fireTestAlert(t, s, groupKey, "warning")
+	if next.IncidentID == record.IncidentID {
+		t.Error("超过时间窗口后应创建新事件")
+	}
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/cache"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maintenanceCacheKey 已启用维护窗口的缓存键
+const maintenanceCacheKey = "enabled"
+
+// MaintenanceService 维护窗口服务
+type MaintenanceService struct {
+	logger          *zap.Logger
+	MaintenanceRepo *repo.MaintenanceRepo // 导出用于 handler 的 PageBuilder
+
+	windowCache cache.Cache[string, []models.MaintenanceWindow] // 已启用维护窗口缓存
+}
+
+func NewMaintenanceService(logger *zap.Logger, db *gorm.DB) *MaintenanceService {
+	return &MaintenanceService{
+		logger:          logger,
+		MaintenanceRepo: repo.NewMaintenanceRepo(db),
+		windowCache:     cache.New[string, []models.MaintenanceWindow](time.Minute),
+	}
+}
+
+// MaintenanceWindowRequest 创建/更新维护窗口请求
+type MaintenanceWindowRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Type        string   `json:"type"`
+	StartTime   int64    `json:"startTime"`
+	EndTime     int64    `json:"endTime"`
+	Cron        string   `json:"cron"`
+	Duration    int      `json:"duration"` // 每次维护持续时间（分钟）
+	Timezone    string   `json:"timezone"`
+	AgentIds    []string `json:"agentIds"`
+	Tags        []string `json:"tags"`
+	MonitorIds  []string `json:"monitorIds"`
+}
+
+// ActiveMaintenanceWindow 当前生效的维护窗口
+type ActiveMaintenanceWindow struct {
+	models.MaintenanceWindow
+	ActiveStart int64 `json:"activeStart"` // 本次维护开始时间（时间戳毫秒）
+	ActiveEnd   int64 `json:"activeEnd"`   // 本次维护结束时间（时间戳毫秒）
+}
+
+// ValidateMaintenanceWindow 校验维护窗口配置
+func ValidateMaintenanceWindow(req *MaintenanceWindowRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("名称不能为空")
+	}
+	switch req.Type {
+	case models.MaintenanceTypeOnce:
+		if req.StartTime <= 0 || req.EndTime <= 0 {
+			return errors.New("一次性维护必须设置开始时间和结束时间")
+		}
+		if req.EndTime <= req.StartTime {
+			return errors.New("结束时间必须晚于开始时间")
+		}
+	case models.MaintenanceTypeRecurring:
+		if req.Duration <= 0 {
+			return errors.New("周期性维护的持续时间必须大于0")
+		}
+		if _, err := parseMaintenanceSchedule(req.Cron, req.Timezone); err != nil {
+			return fmt.Errorf("cron 表达式或时区错误: %w", err)
+		}
+		if req.StartTime > 0 && req.EndTime > 0 && req.EndTime <= req.StartTime {
+			return errors.New("结束时间必须晚于开始时间")
+		}
+	default:
+		return errors.New("维护类型只能是 once 或 recurring")
+	}
+	return nil
+}
+
+// parseMaintenanceSchedule 解析周期性维护的 cron 表达式
+func parseMaintenanceSchedule(spec, timezone string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("cron 表达式不能为空")
+	}
+	if timezone != "" {
+		spec = "CRON_TZ=" + timezone + " " + spec
+	}
+	return cron.ParseStandard(spec)
+}
+
+// CreateWindow 创建维护窗口
+func (s *MaintenanceService) CreateWindow(ctx context.Context, req *MaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	now := time.Now().UnixMilli()
+	window := &models.MaintenanceWindow{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.applyRequest(window, req)
+
+	if err := s.MaintenanceRepo.Create(ctx, window); err != nil {
+		return nil, err
+	}
+	s.windowCache.Delete(maintenanceCacheKey)
+	return window, nil
+}
+
+// UpdateWindow 更新维护窗口
+func (s *MaintenanceService) UpdateWindow(ctx context.Context, id string, req *MaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	window, err := s.MaintenanceRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.applyRequest(&window, req)
+
+	if err := s.MaintenanceRepo.Save(ctx, &window); err != nil {
+		return nil, err
+	}
+	s.windowCache.Delete(maintenanceCacheKey)
+	return &window, nil
+}
+
+// DeleteWindow 删除维护窗口
+func (s *MaintenanceService) DeleteWindow(ctx context.Context, id string) error {
+	if err := s.MaintenanceRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	s.windowCache.Delete(maintenanceCacheKey)
+	return nil
+}
+
+func (s *MaintenanceService) applyRequest(window *models.MaintenanceWindow, req *MaintenanceWindowRequest) {
+	window.Name = strings.TrimSpace(req.Name)
+	window.Description = req.Description
+	window.Enabled = req.Enabled
+	window.Type = req.Type
+	window.StartTime = req.StartTime
+	window.EndTime = req.EndTime
+	window.Cron = strings.TrimSpace(req.Cron)
+	window.Duration = req.Duration
+	window.Timezone = req.Timezone
+	window.AgentIds = datatypes.JSONSlice[string](req.AgentIds)
+	window.Tags = datatypes.JSONSlice[string](req.Tags)
+	window.MonitorIds = datatypes.JSONSlice[string](req.MonitorIds)
+}
+
+// getEnabledWindows 获取所有已启用的维护窗口（带缓存）
+func (s *MaintenanceService) getEnabledWindows(ctx context.Context) []models.MaintenanceWindow {
+	if windows, ok := s.windowCache.Get(maintenanceCacheKey); ok {
+		return windows
+	}
+	windows, err := s.MaintenanceRepo.FindAllEnabled(ctx)
+	if err != nil {
+		s.logger.Error("查询维护窗口失败", zap.Error(err))
+		return nil
+	}
+	s.windowCache.Set(maintenanceCacheKey, windows, time.Minute)
+	return windows
+}
+
+// ActiveRange 计算维护窗口在指定时间是否生效，生效时返回本次维护的起止时间
+func (s *MaintenanceService) ActiveRange(window *models.MaintenanceWindow, now time.Time) (start, end time.Time, ok bool) {
+	nowMilli := now.UnixMilli()
+	if window.StartTime > 0 && nowMilli < window.StartTime {
+		return start, end, false
+	}
+	if window.EndTime > 0 && nowMilli >= window.EndTime {
+		return start, end, false
+	}
+
+	switch window.Type {
+	case models.MaintenanceTypeOnce:
+		return time.UnixMilli(window.StartTime), time.UnixMilli(window.EndTime), true
+	case models.MaintenanceTypeRecurring:
+		schedule, err := parseMaintenanceSchedule(window.Cron, window.Timezone)
+		if err != nil || window.Duration <= 0 {
+			return start, end, false
+		}
+		duration := time.Duration(window.Duration) * time.Minute
+		// 在 [now-duration, now] 区间内有开始时间，说明当前处于本次维护中
+		start = schedule.Next(now.Add(-duration))
+		if start.IsZero() || start.After(now) {
+			return time.Time{}, time.Time{}, false
+		}
+		return start, start.Add(duration), true
+	}
+	return start, end, false
+}
+
+// ListActive 获取当前生效的维护窗口
+func (s *MaintenanceService) ListActive(ctx context.Context) []ActiveMaintenanceWindow {
+	now := time.Now()
+	result := make([]ActiveMaintenanceWindow, 0)
+	for _, window := range s.getEnabledWindows(ctx) {
+		start, end, ok := s.ActiveRange(&window, now)
+		if !ok {
+			continue
+		}
+		result = append(result, ActiveMaintenanceWindow{
+			MaintenanceWindow: window,
+			ActiveStart:       start.UnixMilli(),
+			ActiveEnd:         end.UnixMilli(),
+		})
+	}
+	return result
+}
+
+// IsInMaintenance 判断探针或监控项当前是否处于维护中
+// agent 为 nil 时只按监控项匹配，monitorID 为空时只按探针匹配
+func (s *MaintenanceService) IsInMaintenance(ctx context.Context, agent *models.Agent, monitorID string) bool {
+	now := time.Now()
+	for _, window := range s.getEnabledWindows(ctx) {
+		if !matchMaintenanceWindow(&window, agent, monitorID) {
+			continue
+		}
+		if _, _, ok := s.ActiveRange(&window, now); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMaintenanceWindow 判断维护窗口的作用范围是否包含探针或监控项
+func matchMaintenanceWindow(window *models.MaintenanceWindow, agent *models.Agent, monitorID string) bool {
+	if window.IsGlobal() {
+		return true
+	}
+	if monitorID != "" && slices.Contains(window.MonitorIds, monitorID) {
+		return true
+	}
+	if agent == nil {
+		return false
+	}
+	if slices.Contains(window.AgentIds, agent.ID) {
+		return true
+	}
+	for _, tag := range agent.Tags {
+		if slices.Contains(window.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Occurrences 计算探针或监控项在 [start, end) 时间范围内的维护时间段（已合并重叠部分）
+func (s *MaintenanceService) Occurrences(ctx context.Context, agent *models.Agent, monitorID string, start, end time.Time) []metric.TimeRange {
+	var ranges []metric.TimeRange
+	for _, window := range s.getEnabledWindows(ctx) {
+		if !matchMaintenanceWindow(&window, agent, monitorID) {
+			continue
+		}
+		occurrences, truncated := windowOccurrences(&window, start, end)
+		if truncated {
+			s.logger.Warn("周期性维护窗口展开次数超过上限，统计范围内的维护时间可能偏少",
+				zap.String("windowID", window.ID),
+				zap.String("cron", window.Cron),
+				zap.Time("start", start),
+				zap.Time("end", end))
+		}
+		for _, r := range occurrences {
+			// 周期性维护受规则有效期限制
+			if window.StartTime > 0 && r.Start < window.StartTime {
+				r.Start = window.StartTime
+			}
+			if window.EndTime > 0 && r.End > window.EndTime {
+				r.End = window.EndTime
+			}
+			if r.Start < r.End {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return mergeTimeRanges(ranges)
+}
+
+// windowOccurrences 展开维护窗口在 [start, end) 内的每次维护时间段，相邻或重叠的维护合并为一段
+// 展开次数按统计范围以 cron 的最小粒度（分钟）计算上限，超过上限（如 @every 秒级间隔）时截断并返回 truncated
+func windowOccurrences(window *models.MaintenanceWindow, start, end time.Time) (ranges []metric.TimeRange, truncated bool) {
+	clip := func(from, to time.Time) (metric.TimeRange, bool) {
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		return metric.TimeRange{Start: from.UnixMilli(), End: to.UnixMilli()}, from.Before(to)
+	}
+
+	switch window.Type {
+	case models.MaintenanceTypeOnce:
+		if r, ok := clip(time.UnixMilli(window.StartTime), time.UnixMilli(window.EndTime)); ok {
+			ranges = append(ranges, r)
+		}
+	case models.MaintenanceTypeRecurring:
+		schedule, err := parseMaintenanceSchedule(window.Cron, window.Timezone)
+		if err != nil || window.Duration <= 0 {
+			return nil, false
+		}
+		duration := time.Duration(window.Duration) * time.Minute
+		from := start.Add(-duration)
+		limit := int(end.Sub(from)/time.Minute) + 1
+		t := schedule.Next(from)
+		for i := 0; !t.IsZero() && t.Before(end); i++ {
+			if i >= limit {
+				return ranges, true
+			}
+			if r, ok := clip(t, t.Add(duration)); ok {
+				if n := len(ranges); n > 0 && r.Start <= ranges[n-1].End {
+					ranges[n-1].End = max(ranges[n-1].End, r.End)
+				} else {
+					ranges = append(ranges, r)
+				}
+			}
+			t = schedule.Next(t)
+		}
+	}
+	return ranges, false
+}
+
+// mergeTimeRanges 合并重叠的时间段
+func mergeTimeRanges(ranges []metric.TimeRange) []metric.TimeRange {
+	if len(ranges) == 0 {
+		return ranges
+	}
+	slices.SortFunc(ranges, func(a, b metric.TimeRange) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	merged := []metric.TimeRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
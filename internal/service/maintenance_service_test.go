@@ -0,0 +1,164 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestMaintenanceActiveRange(t *testing.T) {
+	s := &MaintenanceService{}
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	once := &models.MaintenanceWindow{Type: models.MaintenanceTypeOnce, StartTime: at(1, 0).UnixMilli(), EndTime: at(3, 0).UnixMilli()}
+	nightly := &models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "0 2 * * *", Duration: 60, Timezone: "UTC"}
+	expired := &models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "0 2 * * *", Duration: 60, Timezone: "UTC", EndTime: at(0, 0).UnixMilli()}
+	invalidCron := &models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "not a cron", Duration: 60}
+	noDuration := &models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "0 2 * * *", Timezone: "UTC"}
+
+	tests := []struct {
+		name      string
+		window    *models.MaintenanceWindow
+		now       time.Time
+		wantOK    bool
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"一次性维护开始前", once, at(0, 59), false, time.Time{}, time.Time{}},
+		{"一次性维护中", once, at(2, 0), true, at(1, 0), at(3, 0)},
+		{"一次性维护结束", once, at(3, 0), false, time.Time{}, time.Time{}},
+		{"周期性维护开始时", nightly, at(2, 0), true, at(2, 0), at(3, 0)},
+		{"周期性维护中", nightly, at(2, 59), true, at(2, 0), at(3, 0)},
+		{"周期性维护结束", nightly, at(3, 0), false, time.Time{}, time.Time{}},
+		{"周期性维护开始前", nightly, at(1, 59), false, time.Time{}, time.Time{}},
+		{"超过规则有效期", expired, at(2, 30), false, time.Time{}, time.Time{}},
+		{"无效的 cron", invalidCron, at(2, 30), false, time.Time{}, time.Time{}},
+		{"未设置持续时间", noDuration, at(2, 30), false, time.Time{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := s.ActiveRange(tt.window, tt.now)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (!start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd)) {
+				t.Errorf("range = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestMatchMaintenanceWindow(t *testing.T) {
+	agent := &models.Agent{ID: "agent-1", Tags: []string{"db", "prod"}}
+
+	tests := []struct {
+		name      string
+		window    models.MaintenanceWindow
+		agent     *models.Agent
+		monitorID string
+		want      bool
+	}{
+		{"全局维护", models.MaintenanceWindow{}, agent, "", true},
+		{"全局维护匹配监控项", models.MaintenanceWindow{}, nil, "m1", true},
+		{"匹配探针ID", models.MaintenanceWindow{AgentIds: []string{"agent-1"}}, agent, "", true},
+		{"探针ID不匹配", models.MaintenanceWindow{AgentIds: []string{"agent-2"}}, agent, "", false},
+		{"匹配标签", models.MaintenanceWindow{Tags: []string{"prod"}}, agent, "", true},
+		{"标签不匹配", models.MaintenanceWindow{Tags: []string{"web"}}, agent, "", false},
+		{"匹配监控项", models.MaintenanceWindow{MonitorIds: []string{"m1"}}, nil, "m1", true},
+		{"监控项不匹配", models.MaintenanceWindow{MonitorIds: []string{"m1"}}, nil, "m2", false},
+		{"仅按监控项匹配时忽略探针范围", models.MaintenanceWindow{AgentIds: []string{"agent-1"}}, nil, "m1", false},
+		{"监控项不匹配时按探针匹配", models.MaintenanceWindow{MonitorIds: []string{"m1"}, Tags: []string{"db"}}, agent, "m2", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchMaintenanceWindow(&tt.window, tt.agent, tt.monitorID); got != tt.want {
+				t.Errorf("matchMaintenanceWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowOccurrences(t *testing.T) {
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	at := func(days, hour, minute int) int64 {
+		return day.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute).UnixMilli()
+	}
+
+	tests := []struct {
+		name          string
+		window        models.MaintenanceWindow
+		start, end    time.Time
+		want          []metric.TimeRange
+		wantTruncated bool
+	}{
+		{
+			name:   "一次性维护裁剪到统计范围",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeOnce, StartTime: at(-1, 22, 0), EndTime: at(0, 1, 0)},
+			start:  day, end: day.AddDate(0, 0, 1),
+			want: []metric.TimeRange{{Start: at(0, 0, 0), End: at(0, 1, 0)}},
+		},
+		{
+			name:   "一次性维护不在统计范围内",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeOnce, StartTime: at(2, 0, 0), EndTime: at(2, 1, 0)},
+			start:  day, end: day.AddDate(0, 0, 1),
+		},
+		{
+			name:   "每天一次",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "0 2 * * *", Duration: 60, Timezone: "UTC"},
+			start:  day, end: day.AddDate(0, 0, 3),
+			want: []metric.TimeRange{
+				{Start: at(0, 2, 0), End: at(0, 3, 0)},
+				{Start: at(1, 2, 0), End: at(1, 3, 0)},
+				{Start: at(2, 2, 0), End: at(2, 3, 0)},
+			},
+		},
+		{
+			name:   "统计开始时已在维护中",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "0 2 * * *", Duration: 60, Timezone: "UTC"},
+			start:  time.UnixMilli(at(0, 2, 30)).UTC(), end: time.UnixMilli(at(0, 12, 0)).UTC(),
+			want: []metric.TimeRange{{Start: at(0, 2, 30), End: at(0, 3, 0)}},
+		},
+		{
+			name:   "重叠的维护合并为一段",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "*/30 * * * *", Duration: 60, Timezone: "UTC"},
+			start:  day, end: day.Add(3 * time.Hour),
+			want: []metric.TimeRange{{Start: at(0, 0, 0), End: at(0, 3, 0)}},
+		},
+		{
+			name:   "长统计范围内的分钟级维护不截断",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "* * * * *", Duration: 1, Timezone: "UTC"},
+			start:  day, end: day.AddDate(0, 0, 30),
+			want: []metric.TimeRange{{Start: at(0, 0, 0), End: at(30, 0, 0)}},
+		},
+		{
+			name:   "秒级间隔超过上限时截断",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "@every 10s", Duration: 1},
+			start:  day, end: day.Add(time.Hour),
+			// 按分钟粒度计算的上限为 62 次，10 秒间隔只展开约 10 分钟
+			want:          []metric.TimeRange{{Start: at(0, 0, 0), End: day.Add(10*time.Minute + 20*time.Second).UnixMilli()}},
+			wantTruncated: true,
+		},
+		{
+			name:   "无效的 cron",
+			window: models.MaintenanceWindow{Type: models.MaintenanceTypeRecurring, Cron: "invalid", Duration: 60},
+			start:  day, end: day.AddDate(0, 0, 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := windowOccurrences(&tt.window, tt.start, tt.end)
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("windowOccurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/utils"
+)
+
+// 消息模板的事件类型
+const (
+	TemplateKindFiring   = "firing"    // 告警触发
+	TemplateKindResolved = "resolved"  // 告警恢复
+	TemplateKindSSHLogin = "ssh_login" // SSH 登录
+	TemplateKindTamper   = "tamper"    // 防篡改事件
+	TemplateKindTraffic  = "traffic"   // 流量告警
+	TemplateKindCert     = "cert"      // 证书告警
+)
+
+// MessageTemplateKinds 支持自定义模板的事件类型
+var MessageTemplateKinds = []string{
+	TemplateKindFiring,
+	TemplateKindResolved,
+	TemplateKindSSHLogin,
+	TemplateKindTamper,
+	TemplateKindTraffic,
+	TemplateKindCert,
+}
+
+// markdownChannels 支持 Markdown 的渠道类型
+var markdownChannels = map[string]bool{
+	"dingtalk": true,
+	"feishu":   true,
+	"telegram": true,
+}
+
+// MessageTemplateData 消息模板可用的变量
+type MessageTemplateData struct {
+	Kind          string               // 事件类型
+	Agent         MessageTemplateAgent // 探针
+	Record        models.AlertRecord   // 告警记录
+	Level         string               // 告警级别
+	LevelIcon     string               // 告警级别图标
+	AlertType     string               // 告警类型
+	AlertTypeName string               // 告警类型名称
+	Message       string               // 告警消息
+	Value         float64              // 当前值
+	Threshold     float64              // 阈值
+	ValueUnit     string               // 当前值单位
+	ThresholdUnit string               // 阈值单位
+	FiredAt       string               // 触发时间
+	ResolvedAt    string               // 恢复时间
+	Duration      string               // 持续时间
+	Links         MessageTemplateLinks // 链接
+}
+
+// MessageTemplateAgent 模板中的探针信息
+type MessageTemplateAgent struct {
+	ID       string
+	Name     string
+	Hostname string
+	IP       string // 按配置打码后的 IP
+	Tags     []string
+}
+
+// MessageTemplateLinks 模板中的链接，需要在系统配置中设置站点访问地址
+type MessageTemplateLinks struct {
+	Agent  string // 探针详情页
+	Alerts string // 告警记录页
+}
+
+// MessageTemplateVariable 模板变量说明
+type MessageTemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// MessageTemplateVariables 模板变量说明，供前端展示
+var MessageTemplateVariables = []MessageTemplateVariable{
+	{"{{.Kind}}", "事件类型: firing, resolved, ssh_login, tamper, traffic, cert"},
+	{"{{.Agent.ID}}", "探针ID"},
+	{"{{.Agent.Name}}", "探针名称"},
+	{"{{.Agent.Hostname}}", "主机名"},
+	{"{{.Agent.IP}}", "IP 地址（开启打码时为打码后的地址）"},
+	{"{{.Agent.Tags}}", "探针标签"},
+	{"{{.Record}}", "告警记录，例如 {{.Record.ID}}、{{.Record.Status}}"},
+	{"{{.Level}}", "告警级别: info, warning, critical"},
+	{"{{.LevelIcon}}", "告警级别图标"},
+	{"{{.AlertType}}", "告警类型，例如 cpu、cert、service"},
+	{"{{.AlertTypeName}}", "告警类型名称，例如 CPU告警"},
+	{"{{.Message}}", "告警消息"},
+	{"{{.Value}}", "当前值，例如 {{printf \"%.2f\" .Value}}"},
+	{"{{.Threshold}}", "阈值"},
+	{"{{.ValueUnit}}", "当前值单位"},
+	{"{{.ThresholdUnit}}", "阈值单位"},
+	{"{{.FiredAt}}", "触发时间"},
+	{"{{.ResolvedAt}}", "恢复时间"},
+	{"{{.Duration}}", "持续时间（仅恢复通知）"},
+	{"{{.Links.Agent}}", "探针详情页链接"},
+	{"{{.Links.Alerts}}", "告警记录页链接"},
+}
+
+// templateKind 根据告警记录判断事件类型
+func templateKind(record *models.AlertRecord) string {
+	if record.Status == "resolved" {
+		return TemplateKindResolved
+	}
+	switch record.AlertType {
+	case TemplateKindSSHLogin, TemplateKindTamper, TemplateKindTraffic, TemplateKindCert:
+		return record.AlertType
+	default:
+		return TemplateKindFiring
+	}
+}
+
+// newMessageTemplateData 构建模板变量
+func newMessageTemplateData(agent *models.Agent, record *models.AlertRecord, maskIP bool, serverURL string) MessageTemplateData {
+	metadata := getAlertTypeMetadata(record.AlertType)
+
+	var duration string
+	if record.FiredAt > 0 && record.ResolvedAt > record.FiredAt {
+		duration = utils.FormatDuration(record.ResolvedAt - record.FiredAt)
+	}
+	var resolvedAt string
+	if record.ResolvedAt > 0 {
+		resolvedAt = utils.FormatTimestamp(record.ResolvedAt)
+	}
+
+	var links MessageTemplateLinks
+	if serverURL = strings.TrimRight(serverURL, "/"); serverURL != "" {
+		links.Agent = fmt.Sprintf("%s/admin/agents/%s", serverURL, agent.ID)
+		links.Alerts = fmt.Sprintf("%s/admin/alert-records", serverURL)
+	}
+
+	return MessageTemplateData{
+		Kind: templateKind(record),
+		Agent: MessageTemplateAgent{
+			ID:       agent.ID,
+			Name:     agent.Name,
+			Hostname: agent.Hostname,
+			IP:       formatAgentIP(agent, maskIP),
+			Tags:     agent.Tags,
+		},
+		Record:        *record,
+		Level:         record.Level,
+		LevelIcon:     getLevelIcon(record.Level),
+		AlertType:     record.AlertType,
+		AlertTypeName: metadata.Name,
+		Message:       record.Message,
+		Value:         record.ActualValue,
+		Threshold:     record.Threshold,
+		ValueUnit:     metadata.ValueUnit,
+		ThresholdUnit: metadata.ThresholdUnit,
+		FiredAt:       utils.FormatTimestamp(record.FiredAt),
+		ResolvedAt:    resolvedAt,
+		Duration:      duration,
+		Links:         links,
+	}
+}
+
+// parseMessageTemplate 解析消息模板
+func parseMessageTemplate(text string) (*template.Template, error) {
+	return template.New("message").Option("missingkey=zero").Parse(text)
+}
+
+// RenderMessageTemplate 使用模板变量渲染消息
+func RenderMessageTemplate(text string, data MessageTemplateData) (string, error) {
+	tmpl, err := parseMessageTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ValidateChannelTemplates 校验通知渠道中的消息模板
+func ValidateChannelTemplates(channels []models.NotificationChannelConfig) error {
+	for _, channel := range channels {
+		for kind, text := range channel.Templates {
+			if text == "" {
+				continue
+			}
+			if _, err := parseMessageTemplate(text); err != nil {
+				return fmt.Errorf("渠道 %s 的 %s 模板错误: %w", channel.Type, kind, err)
+			}
+		}
+		if channel.Type == "email" {
+			if err := validateEmailTemplates(channel.Config); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SupportsMarkdown 判断渠道是否支持 Markdown
+func SupportsMarkdown(channelType string) bool {
+	return markdownChannels[channelType]
+}
+
+// plainToMarkdown 将默认的纯文本消息转换为对应渠道的 Markdown 格式
+// 第一行作为标题，"名称: 值" 格式的行将名称加粗
+func plainToMarkdown(channelType, message string) string {
+	bold := func(s string) string { return "**" + s + "**" }
+	escape := func(s string) string { return s }
+	separator := "\n"
+	switch channelType {
+	case "dingtalk":
+		// 钉钉 Markdown 需要空行才能换行
+		separator = "\n\n"
+	case "telegram":
+		// Telegram 的 Markdown 使用单星号加粗，且需要转义特殊字符
+		bold = func(s string) string { return "*" + s + "*" }
+		escape = telegramMarkdownEscaper.Replace
+	}
+
+	lines := strings.Split(message, "\n")
+	result := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		if i == 0 {
+			if channelType == "dingtalk" {
+				result = append(result, "### "+escape(line))
+			} else {
+				result = append(result, bold(escape(line)))
+			}
+			continue
+		}
+		if name, value, ok := strings.Cut(line, ": "); ok {
+			result = append(result, bold(escape(name))+": "+escape(value))
+		} else {
+			result = append(result, escape(line))
+		}
+	}
+	return strings.Join(result, separator)
+}
+
+var telegramMarkdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// escapeTemplateData 按渠道转义模板变量中的文本，避免探针名称、告警消息等包含的特殊字符破坏渠道的消息格式
+// 目前只有 Telegram 的 Markdown 在格式错误时会拒绝整条消息，链接不转义以免破坏 URL
+func escapeTemplateData(channelType string, data MessageTemplateData) MessageTemplateData {
+	if channelType != "telegram" {
+		return data
+	}
+	escape := telegramMarkdownEscaper.Replace
+
+	data.Agent.Name = escape(data.Agent.Name)
+	data.Agent.Hostname = escape(data.Agent.Hostname)
+	data.Agent.IP = escape(data.Agent.IP)
+	tags := make([]string, len(data.Agent.Tags))
+	for i, tag := range data.Agent.Tags {
+		tags[i] = escape(tag)
+	}
+	data.Agent.Tags = tags
+	data.Record.AgentName = escape(data.Record.AgentName)
+	data.Record.Message = escape(data.Record.Message)
+	data.Record.PolicyName = escape(data.Record.PolicyName)
+	data.AlertType = escape(data.AlertType)
+	data.AlertTypeName = escape(data.AlertTypeName)
+	data.Message = escape(data.Message)
+	return data
+}
+
+// sampleTemplateData 生成预览模板用的示例数据
+func sampleTemplateData(kind, serverURL string) MessageTemplateData {
+	now := time.Now().UnixMilli()
+	agent := &models.Agent{
+		ID:       "sample-agent",
+		Name:     "示例探针",
+		Hostname: "sample-host",
+		IPv4:     "192.168.1.100",
+		Tags:     []string{"prod"},
+	}
+	record := &models.AlertRecord{
+		ID:          1,
+		AgentID:     agent.ID,
+		AgentName:   agent.Name,
+		AlertType:   "cpu",
+		Message:     "CPU使用率持续60秒超过80.00%，当前值95.00%",
+		Threshold:   80,
+		ActualValue: 95,
+		Level:       "critical",
+		Status:      "firing",
+		FiredAt:     now - 10*time.Minute.Milliseconds(),
+	}
+	switch kind {
+	case TemplateKindResolved:
+		record.Status = "resolved"
+		record.ActualValue = 35
+		record.ResolvedAt = now
+	case TemplateKindSSHLogin:
+		record.AlertType = "ssh_login"
+		record.Status = "notice"
+		record.Level = "info"
+		record.Message = "用户 root 从 10.0.0.8 登录成功"
+	case TemplateKindTamper:
+		record.AlertType = "tamper"
+		record.Status = "notice"
+		record.Level = "warning"
+		record.Message = "受保护目录 /etc/nginx 中的文件被修改"
+	case TemplateKindTraffic:
+		record.AlertType = "traffic"
+		record.Status = "notice"
+		record.Level = "warning"
+		record.Message = "流量使用已达到 90%"
+		record.Threshold = 90
+		record.ActualValue = 91.5
+	case TemplateKindCert:
+		record.AlertType = "cert"
+		record.Level = "warning"
+		record.Message = "证书 example.com 将在 5 天后过期"
+		record.Threshold = 7
+		record.ActualValue = 5
+	}
+	return newMessageTemplateData(agent, record, false, serverURL)
+}
+
+// PreviewMessageTemplate 使用示例数据渲染模板，模板为空时渲染默认消息
+func (n *Notifier) PreviewMessageTemplate(channelType, kind, text, serverURL string) (string, error) {
+	data := sampleTemplateData(kind, serverURL)
+	if text != "" {
+		return RenderMessageTemplate(text, escapeTemplateData(channelType, data))
+	}
+	agent := &models.Agent{ID: data.Agent.ID, Name: data.Agent.Name, Hostname: data.Agent.Hostname, IPv4: data.Agent.IP}
+	message := n.buildMessage(agent, &data.Record, false)
+	if SupportsMarkdown(channelType) {
+		message = plainToMarkdown(channelType, message)
+	}
+	return message, nil
+}
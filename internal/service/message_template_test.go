@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestRenderMessageTemplateTelegramEscape(t *testing.T) {
+	agent := &models.Agent{ID: "agent-1", Name: "web_01*[prod]"}
+	record := &models.AlertRecord{AlertType: "cpu", Level: "critical", Status: "firing", Message: "CPU使用率持续超过`80%`"}
+	data := newMessageTemplateData(agent, record, false, "")
+	text := "*{{.Agent.Name}}* {{.Message}}"
+
+	got, err := RenderMessageTemplate(text, escapeTemplateData("telegram", data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "*web\\_01\\*\\[prod]* CPU使用率持续超过\\`80%\\`"
+	if got != want {
+		t.Errorf("telegram = %q, want %q", got, want)
+	}
+
+	// 其他渠道保持原样
+	got, err = RenderMessageTemplate(text, escapeTemplateData("dingtalk", data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "*web_01*[prod]* CPU使用率持续超过`80%`"; got != want {
+		t.Errorf("dingtalk = %q, want %q", got, want)
+	}
+}
@@ -102,6 +102,12 @@ func (s *MetricService) convertToMetrics(agentID string, metricType string, data
 				"target":       monitorData.Target,
 			}
 			metrics = append(metrics, createMetric("pika_monitor_response_time_ms", agentID, labels, float64(monitorData.ResponseTime), timestamp))
+			// 监控状态：1-正常，0-异常，用于可用性统计
+			status := 0.0
+			if monitorData.Status == "up" {
+				status = 1
+			}
+			metrics = append(metrics, createMetric("pika_monitor_status", agentID, labels, status, timestamp))
 		}
 	}
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/protocol"
 	"github.com/dushixiang/pika/internal/repo"
 	"github.com/dushixiang/pika/internal/vmclient"
@@ -32,8 +33,13 @@ type MetricService struct {
 	latestCache cache.Cache[string, *metric.LatestMetrics] // Agent 最新指标缓存
 
 	monitorLatestCache cache.Cache[string, *metric.LatestMonitorMetrics] // 监控最新指标缓存
+
+	monitorFailureHandler MonitorFailureHandler // 监控检测失败回调
 }
 
+// MonitorFailureHandler 监控检测失败回调，consecutiveFailures 为该探针的连续失败次数
+type MonitorFailureHandler func(monitorID, agentID string, consecutiveFailures int)
+
 // NewMetricService 创建指标服务
 func NewMetricService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, trafficService *TrafficService, vmClient *vmclient.VMClient) *MetricService {
 	return &MetricService{
@@ -48,6 +54,11 @@ func NewMetricService(logger *zap.Logger, db *gorm.DB, propertyService *Property
 	}
 }
 
+// SetMonitorFailureHandler 设置监控检测失败回调（由外部注入，避免循环依赖）
+func (s *MetricService) SetMonitorFailureHandler(handler MonitorFailureHandler) {
+	s.monitorFailureHandler = handler
+}
+
 // HandleMetricData 处理指标数据
 func (s *MetricService) HandleMetricData(ctx context.Context, agentID string, metricType string, data json.RawMessage, timestamp int64) error {
 	if timestamp == 0 {
@@ -152,6 +163,13 @@ func (s *MetricService) HandleMetricData(ctx context.Context, agentID string, me
 		if err := json.Unmarshal(data, &diskIODataList); err != nil {
 			return err
 		}
+		// 计算汇总数据用于缓存
+		diskIOSummary := &metric.DiskIOSummary{}
+		for _, diskIOData := range diskIODataList {
+			diskIOSummary.TotalReadBytesRate += diskIOData.ReadBytesRate
+			diskIOSummary.TotalWriteBytesRate += diskIOData.WriteBytesRate
+		}
+		latestMetrics.DiskIO = diskIOSummary
 		metrics := s.convertToMetrics(agentID, metricType, diskIODataList, timestamp)
 		return s.vmClient.Write(ctx, metrics)
 
@@ -330,12 +348,54 @@ func (s *MetricService) updateMonitorCache(agentID string, monitorData *protocol
 		}
 	}
 
+	// 探针断线期间缓存的历史数据补传时，不覆盖更新的检测结果
+	existing, exists := latestMetrics.Agents.Get(agentID)
+	if exists && existing.CheckedAt > monitorData.CheckedAt {
+		return
+	}
+
+	// 统计连续失败次数
+	monitorData.ConsecutiveFailures = 0
+	if monitorData.Status == "down" {
+		monitorData.ConsecutiveFailures = 1
+		if exists {
+			monitorData.ConsecutiveFailures = existing.ConsecutiveFailures + 1
+		}
+	}
+
 	// 更新探针数据
 	latestMetrics.Agents.Set(agentID, monitorData)
 	latestMetrics.UpdatedAt = timestamp
 
 	// 保存到缓存（5分钟过期）
 	s.monitorLatestCache.Set(monitorID, latestMetrics, 5*time.Minute)
+
+	if monitorData.Status == "down" && s.monitorFailureHandler != nil {
+		go s.monitorFailureHandler(monitorID, agentID, monitorData.ConsecutiveFailures)
+	}
+}
+
+// SaveMonitorData 保存单条监控数据（用于服务端产生的监控结果，如推送监控）
+func (s *MetricService) SaveMonitorData(ctx context.Context, agentID string, monitorData *protocol.MonitorData) error {
+	timestamp := monitorData.CheckedAt
+	if timestamp == 0 {
+		timestamp = time.Now().UnixMilli()
+	}
+	monitorData.AgentId = agentID
+	s.updateMonitorCache(agentID, monitorData, timestamp)
+
+	metrics := s.convertToMetrics(agentID, string(protocol.MetricTypeMonitor), []protocol.MonitorData{*monitorData}, timestamp)
+	return s.vmClient.Write(ctx, metrics)
+}
+
+// RefreshMonitorCache 延长监控缓存有效期（用于检测周期较长的监控任务）
+func (s *MetricService) RefreshMonitorCache(monitorID string) bool {
+	latestMetrics, ok := s.monitorLatestCache.Get(monitorID)
+	if !ok {
+		return false
+	}
+	s.monitorLatestCache.Set(monitorID, latestMetrics, 5*time.Minute)
+	return true
 }
 
 // GetLatestMetrics 获取最新指标
@@ -722,7 +782,9 @@ func (s *MetricService) GetMonitorAgentStats(monitorID string) []protocol.Monito
 			}
 		} else {
 			// 无过滤条件，返回所有 agent 数据
-			stat.AgentName = agentNameMap[stat.AgentId] // 填充 agent 名称
+			if agentName, ok := agentNameMap[stat.AgentId]; ok {
+				stat.AgentName = agentName // 填充 agent 名称（推送监控没有对应的探针，保留原名称）
+			}
 			result = append(result, *stat)
 		}
 	}
@@ -756,11 +818,11 @@ func (s *MetricService) GetMonitorStats(monitorID string) *metric.MonitorStatsRe
 	}
 
 	// 聚合各探针数据
-	return s.aggregateMonitorStats(latestMetrics, monitorTask.AgentIds)
+	return s.aggregateMonitorStats(latestMetrics, &monitorTask)
 }
 
 // aggregateMonitorStats 聚合各探针的监控数据
-func (s *MetricService) aggregateMonitorStats(latestMetrics *metric.LatestMonitorMetrics, agentIds []string) *metric.MonitorStatsResult {
+func (s *MetricService) aggregateMonitorStats(latestMetrics *metric.LatestMonitorMetrics, monitorTask *models.MonitorTask) *metric.MonitorStatsResult {
 	result := &metric.MonitorStatsResult{
 		Status: "unknown",
 	}
@@ -774,7 +836,10 @@ func (s *MetricService) aggregateMonitorStats(latestMetrics *metric.LatestMonito
 	var maxResponseTime int64
 	var lastCheckTime int64
 	var upCount, downCount, unknownCount int
-	var validCount int // 实际聚合的探针数量
+	var failedCount int // 达到连续失败次数的探针数量
+	var validCount int  // 实际聚合的探针数量
+	agentIds := monitorTask.AgentIds
+	quorum := monitorTask.QuorumConfig.Data()
 	hasCert := false
 	var minCertExpiryTime int64
 	var minCertDaysLeft int
@@ -809,6 +874,9 @@ func (s *MetricService) aggregateMonitorStats(latestMetrics *metric.LatestMonito
 			upCount++
 		case "down":
 			downCount++
+			if stat.ConsecutiveFailures >= quorum.RequiredConsecutiveFailures() {
+				failedCount++
+			}
 		default:
 			unknownCount++
 		}
@@ -834,9 +902,17 @@ func (s *MetricService) aggregateMonitorStats(latestMetrics *metric.LatestMonito
 	result.AgentStats.Up = upCount
 	result.AgentStats.Down = downCount
 	result.AgentStats.Unknown = unknownCount
+	result.AgentStats.Failed = failedCount
 
-	// 聚合状态：只要有一个探针 up，整体就是 up
-	if upCount > 0 {
+	if quorum.Enabled {
+		// 多点确认：失败探针数量满足条件时才判定为离线
+		if quorum.IsQuorumMet(failedCount, validCount) {
+			result.Status = "down"
+		} else if upCount > 0 || downCount > 0 {
+			result.Status = "up"
+		}
+	} else if upCount > 0 {
+		// 聚合状态：只要有一个探针 up，整体就是 up
 		result.Status = "up"
 	} else if downCount > 0 {
 		result.Status = "down"
@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/dushixiang/pika/internal/metric"
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/protocol"
 	"github.com/dushixiang/pika/internal/repo"
 	ws "github.com/dushixiang/pika/internal/websocket"
+	"github.com/go-orz/cache"
 	"github.com/go-orz/orz"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -23,30 +26,42 @@ type MonitorService struct {
 	logger *zap.Logger
 	*repo.MonitorRepo
 	*orz.Service
-	agentRepo     *repo.AgentRepo
-	metricService *MetricService
-	wsManager     *ws.Manager
+	agentRepo          *repo.AgentRepo
+	metricService      *MetricService
+	wsManager          *ws.Manager
+	maintenanceService *MaintenanceService
 
-	// 调度器引用（用于动态管理任务）
+	// 调度器引用（用于在配置变化时向探针同步）
 	scheduler MonitorScheduler
+
+	// 失败复检请求的节流记录，key: monitorID:agentID
+	recheckThrottle cache.Cache[string, struct{}]
+	// 失败复检使用的监控任务缓存，避免每条失败结果都查询数据库
+	taskCache cache.Cache[string, models.MonitorTask]
 }
 
 // MonitorScheduler 调度器接口（避免循环依赖）
 type MonitorScheduler interface {
-	AddTask(monitorID string, interval int) error
-	UpdateTask(monitorID string, interval int) error
-	RemoveTask(monitorID string)
+	RequestSync()
 }
 
-func NewMonitorService(logger *zap.Logger, db *gorm.DB, metricService *MetricService, wsManager *ws.Manager) *MonitorService {
-	return &MonitorService{
-		logger:        logger,
-		Service:       orz.NewService(db),
-		MonitorRepo:   repo.NewMonitorRepo(db),
-		agentRepo:     repo.NewAgentRepo(db),
-		metricService: metricService,
-		wsManager:     wsManager,
+func NewMonitorService(logger *zap.Logger, db *gorm.DB, metricService *MetricService, wsManager *ws.Manager, maintenanceService *MaintenanceService) *MonitorService {
+	s := &MonitorService{
+		logger:          logger,
+		Service:         orz.NewService(db),
+		MonitorRepo:     repo.NewMonitorRepo(db),
+		agentRepo:       repo.NewAgentRepo(db),
+		metricService:   metricService,
+		wsManager:       wsManager,
+		recheckThrottle: cache.New[string, struct{}](time.Minute),
+		taskCache:       cache.New[string, models.MonitorTask](time.Minute),
+
+		maintenanceService: maintenanceService,
+	}
+	if metricService != nil {
+		metricService.SetMonitorFailureHandler(s.handleMonitorFailure)
 	}
+	return s
 }
 
 // SetScheduler 设置调度器（由外部注入，避免循环依赖）
@@ -66,6 +81,8 @@ type MonitorTaskRequest struct {
 	HTTPConfig       protocol.HTTPMonitorConfig `json:"httpConfig,omitempty"`
 	TCPConfig        protocol.TCPMonitorConfig  `json:"tcpConfig,omitempty"`
 	ICMPConfig       protocol.ICMPMonitorConfig `json:"icmpConfig,omitempty"`
+	PushConfig       protocol.PushMonitorConfig `json:"pushConfig,omitempty"`
+	QuorumConfig     models.MonitorQuorumConfig `json:"quorumConfig,omitempty"` // 多点确认配置
 	AgentIds         []string                   `json:"agentIds,omitempty"`
 }
 
@@ -96,21 +113,24 @@ func (s *MonitorService) CreateMonitor(ctx context.Context, req *MonitorTaskRequ
 		HTTPConfig:       datatypes.NewJSONType(req.HTTPConfig),
 		TCPConfig:        datatypes.NewJSONType(req.TCPConfig),
 		ICMPConfig:       datatypes.NewJSONType(req.ICMPConfig),
+		PushConfig:       datatypes.NewJSONType(req.PushConfig),
+		QuorumConfig:     datatypes.NewJSONType(req.QuorumConfig),
 		CreatedAt:        0,
 		UpdatedAt:        0,
 	}
 
+	// 推送监控生成上报令牌
+	if task.Type == MonitorTypePush {
+		task.PushToken = newPushToken()
+	}
+
 	if err := s.MonitorRepo.Create(ctx, task); err != nil {
 		return nil, err
 	}
 
-	// 如果任务启用，添加到调度器
+	// 如果任务启用，同步配置到探针
 	if task.Enabled && s.scheduler != nil {
-		if err := s.scheduler.AddTask(task.ID, task.Interval); err != nil {
-			s.logger.Error("添加监控任务到调度器失败",
-				zap.String("taskID", task.ID),
-				zap.Error(err))
-		}
+		s.scheduler.RequestSync()
 	}
 
 	return task, nil
@@ -122,9 +142,8 @@ func (s *MonitorService) UpdateMonitor(ctx context.Context, id string, req *Moni
 		return nil, err
 	}
 
-	// 记录旧状态，用于判断是否需要更新调度器
+	// 记录旧状态，用于判断是否需要同步配置
 	oldEnabled := task.Enabled
-	oldInterval := task.Interval
 
 	task.Enabled = req.Enabled
 	task.Name = strings.TrimSpace(req.Name)
@@ -145,10 +164,19 @@ func (s *MonitorService) UpdateMonitor(ctx context.Context, id string, req *Moni
 	task.HTTPConfig = datatypes.NewJSONType(req.HTTPConfig)
 	task.TCPConfig = datatypes.NewJSONType(req.TCPConfig)
 	task.ICMPConfig = datatypes.NewJSONType(req.ICMPConfig)
+	task.PushConfig = datatypes.NewJSONType(req.PushConfig)
+	task.QuorumConfig = datatypes.NewJSONType(req.QuorumConfig)
+	// 切换为其他类型时作废上报令牌，避免旧令牌继续写入检测结果
+	if task.Type != MonitorTypePush {
+		task.PushToken = ""
+	} else if task.PushToken == "" {
+		task.PushToken = newPushToken()
+	}
 
-	if err := s.MonitorRepo.Save(ctx, &task); err != nil {
+	if err := s.MonitorRepo.SaveConfig(ctx, &task); err != nil {
 		return nil, err
 	}
+	s.taskCache.Delete(id)
 
 	// 清理监控缓存中不再关联的探针数据
 	if s.metricService != nil {
@@ -160,27 +188,9 @@ func (s *MonitorService) UpdateMonitor(ctx context.Context, id string, req *Moni
 		}
 	}
 
-	// 更新调度器
-	if s.scheduler != nil {
-		// 如果从禁用变为启用，或者间隔时间改变
-		if !oldEnabled && task.Enabled {
-			// 添加任务到调度器
-			if err := s.scheduler.AddTask(task.ID, task.Interval); err != nil {
-				s.logger.Error("添加监控任务到调度器失败",
-					zap.String("taskID", task.ID),
-					zap.Error(err))
-			}
-		} else if oldEnabled && !task.Enabled {
-			// 从调度器中移除任务
-			s.scheduler.RemoveTask(task.ID)
-		} else if task.Enabled && oldInterval != task.Interval {
-			// 更新任务间隔
-			if err := s.scheduler.UpdateTask(task.ID, task.Interval); err != nil {
-				s.logger.Error("更新监控任务调度器失败",
-					zap.String("taskID", task.ID),
-					zap.Error(err))
-			}
-		}
+	// 启用状态或配置发生变化，同步配置到探针
+	if s.scheduler != nil && (oldEnabled || task.Enabled) {
+		s.scheduler.RequestSync()
 	}
 
 	return &task, nil
@@ -198,10 +208,11 @@ func (s *MonitorService) DeleteMonitor(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
+	s.taskCache.Delete(id)
 
-	// 从调度器中移除
+	// 同步配置到探针，移除已删除的监控项
 	if s.scheduler != nil {
-		s.scheduler.RemoveTask(id)
+		s.scheduler.RequestSync()
 	}
 
 	return nil
@@ -219,7 +230,7 @@ func (s *MonitorService) ListByAuth(ctx context.Context, isAuthenticated bool) (
 	items := make([]metric.PublicMonitorOverview, 0, len(monitors))
 	for _, monitor := range monitors {
 		// 查询统计数据
-		stats := s.metricService.GetMonitorStats(monitor.ID)
+		stats := s.getMonitorStats(ctx, monitor.ID)
 		// 构建监控概览对象
 		item := s.buildMonitorOverview(monitor, stats)
 		items = append(items, item)
@@ -228,6 +239,15 @@ func (s *MonitorService) ListByAuth(ctx context.Context, isAuthenticated bool) (
 	return items, nil
 }
 
+// getMonitorStats 获取监控任务的聚合统计数据，处于维护窗口内时状态为 maintenance
+func (s *MonitorService) getMonitorStats(ctx context.Context, monitorID string) *metric.MonitorStatsResult {
+	stats := s.metricService.GetMonitorStats(monitorID)
+	if s.maintenanceService.IsInMaintenance(ctx, nil, monitorID) {
+		stats.Status = "maintenance"
+	}
+	return stats
+}
+
 // buildMonitorOverview 构建监控概览对象
 func (s *MonitorService) buildMonitorOverview(monitor models.MonitorTask, stats *metric.MonitorStatsResult) metric.PublicMonitorOverview {
 	// 根据 ShowTargetPublic 字段决定是否返回真实的 Target
@@ -274,27 +294,13 @@ func (s *MonitorService) sendMonitorConfigToAgent(agentID string, payload protoc
 	return s.wsManager.SendToClient(agentID, msgData)
 }
 
-// SendMonitorTaskToAgents 向指定探针发送单个监控任务（公开方法）
-func (s *MonitorService) SendMonitorTaskToAgents(ctx context.Context, monitor models.MonitorTask) error {
-	// 确定目标探针 ID 列表
-	var targetAgentIDs []string
-	if len(monitor.AgentIds) == 0 {
-		// 没有指定探针，向所有在线探针发送
-		targetAgentIDs = s.wsManager.GetAllClients()
-	} else {
-		// 指定了探针
-		targetAgentIDs = monitor.AgentIds
-	}
-
-	if len(targetAgentIDs) == 0 {
-		return nil
-	}
-
-	// 构建监控项
+// buildMonitorItem 构建下发到探针的监控项
+func (s *MonitorService) buildMonitorItem(monitor models.MonitorTask) protocol.MonitorItem {
 	item := protocol.MonitorItem{
-		ID:     monitor.ID,
-		Type:   monitor.Type,
-		Target: monitor.Target,
+		ID:       monitor.ID,
+		Type:     monitor.Type,
+		Target:   monitor.Target,
+		Interval: monitor.Interval,
 	}
 
 	if monitor.Type == "http" || monitor.Type == "https" {
@@ -308,24 +314,60 @@ func (s *MonitorService) SendMonitorTaskToAgents(ctx context.Context, monitor mo
 		item.ICMPConfig = &icmpConfig
 	}
 
-	// 构建 payload
-	payload := protocol.MonitorConfigPayload{
-		Interval: 0,
-		Items:    []protocol.MonitorItem{item},
+	return item
+}
+
+// BuildMonitorConfigForAgent 构建指定探针的完整监控配置
+func (s *MonitorService) BuildMonitorConfigForAgent(ctx context.Context, agentID string) (*protocol.MonitorConfigPayload, error) {
+	monitors, err := s.FindByEnabled(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildMonitorConfig(monitors, agentID), nil
+}
+
+// buildMonitorConfig 从监控任务列表中筛选出指定探针需要执行的监控项
+func (s *MonitorService) buildMonitorConfig(monitors []models.MonitorTask, agentID string) *protocol.MonitorConfigPayload {
+	payload := &protocol.MonitorConfigPayload{
+		Full:  true,
+		Items: make([]protocol.MonitorItem, 0),
+	}
+	for _, monitor := range monitors {
+		// 推送监控由被监控方主动上报，无需下发到探针
+		if monitor.Type == MonitorTypePush {
+			continue
+		}
+		// 指定了探针时只下发给对应探针，未指定则下发给所有探针
+		if len(monitor.AgentIds) > 0 && !slices.Contains(monitor.AgentIds, agentID) {
+			continue
+		}
+		payload.Items = append(payload.Items, s.buildMonitorItem(monitor))
+	}
+	return payload
+}
+
+// SyncMonitorConfigToAgents 向所有在线探针下发完整监控配置，由探针自主调度检测
+func (s *MonitorService) SyncMonitorConfigToAgents(ctx context.Context) error {
+	agentIDs := s.wsManager.GetAllClients()
+	if len(agentIDs) == 0 {
+		return nil
+	}
+
+	monitors, err := s.FindByEnabled(ctx, true)
+	if err != nil {
+		return err
 	}
 
-	// 向每个目标探针发送
-	for _, agentID := range targetAgentIDs {
-		if err := s.sendMonitorConfigToAgent(agentID, payload); err != nil {
+	for _, agentID := range agentIDs {
+		payload := s.buildMonitorConfig(monitors, agentID)
+		if err := s.sendMonitorConfigToAgent(agentID, *payload); err != nil {
 			if errors.Is(err, ws.ErrClientNotFound) {
 				// 忽略未连接的探针
-			} else {
-				s.logger.Error("发送监控配置失败",
-					zap.String("taskID", monitor.ID),
-					zap.String("taskName", monitor.Name),
-					zap.String("agentID", agentID),
-					zap.Error(err))
+				continue
 			}
+			s.logger.Error("同步监控配置失败",
+				zap.String("agentID", agentID),
+				zap.Error(err))
 		}
 	}
 
@@ -341,7 +383,7 @@ func (s *MonitorService) GetMonitorStatsByID(ctx context.Context, monitorID stri
 	}
 
 	// 查询统计数据
-	stats := s.metricService.GetMonitorStats(monitorID)
+	stats := s.getMonitorStats(ctx, monitorID)
 	// 构建监控概览对象
 	overview := s.buildMonitorOverview(monitor, stats)
 
@@ -412,3 +454,229 @@ func (s *MonitorService) GetAllLatestMonitorMetrics(ctx context.Context) ([]prot
 	}
 	return result, nil
 }
+
+// GetServiceStatusMetrics 获取用于判定服务是否离线的监控数据
+// 未启用多点确认的监控项按探针逐个返回，启用多点确认的监控项只返回一条聚合后的数据
+func (s *MonitorService) GetServiceStatusMetrics(ctx context.Context) ([]protocol.MonitorData, error) {
+	monitorTasks, err := s.FindByEnabled(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []protocol.MonitorData
+	for _, task := range monitorTasks {
+		quorum := task.QuorumConfig.Data()
+		if !quorum.Enabled || task.Type == MonitorTypePush {
+			monitorData := s.metricService.GetMonitorAgentStats(task.ID)
+			for i := range monitorData {
+				monitorData[i].MonitorName = task.Name
+			}
+			result = append(result, monitorData...)
+			continue
+		}
+
+		stats := s.metricService.GetMonitorStats(task.ID)
+		if stats.AgentCount == 0 {
+			continue
+		}
+		status := "up"
+		if stats.Status == "down" {
+			status = "down"
+		}
+		result = append(result, protocol.MonitorData{
+			AgentId:      QuorumMonitorAgentID,
+			MonitorId:    task.ID,
+			MonitorName:  task.Name,
+			Type:         task.Type,
+			Target:       task.Target,
+			Status:       status,
+			ResponseTime: stats.ResponseTime,
+			CheckedAt:    stats.LastCheckTime,
+			Message:      fmt.Sprintf("%d/%d 个探针检测失败", stats.AgentStats.Failed, stats.AgentCount),
+		})
+	}
+	return result, nil
+}
+
+// handleMonitorFailure 探针检测失败时，按多点确认配置请求探针立即复检
+// 首次失败时请求所有关联的在线探针复检，之后只请求失败探针继续复检直到达到连续失败次数
+func (s *MonitorService) handleMonitorFailure(monitorID, agentID string, consecutiveFailures int) {
+	task, err := s.getRecheckTask(monitorID)
+	if err != nil {
+		return
+	}
+	quorum := task.QuorumConfig.Data()
+	if !task.Enabled || !quorum.Enabled || !quorum.RetryOnFailure || task.Type == MonitorTypePush {
+		return
+	}
+
+	var targets []string
+	if consecutiveFailures < quorum.RequiredConsecutiveFailures() {
+		targets = append(targets, agentID)
+	}
+	if consecutiveFailures == 1 {
+		for _, id := range s.wsManager.GetAllClients() {
+			if id == agentID {
+				continue
+			}
+			if len(task.AgentIds) > 0 && !slices.Contains(task.AgentIds, id) {
+				continue
+			}
+			// 多个探针同时失败时避免重复请求复检
+			key := monitorID + ":" + id
+			if _, ok := s.recheckThrottle.Get(key); ok {
+				continue
+			}
+			s.recheckThrottle.Set(key, struct{}{}, recheckThrottleTTL)
+			targets = append(targets, id)
+		}
+	}
+
+	payload := protocol.MonitorConfigPayload{
+		Interval: task.Interval,
+		Items:    []protocol.MonitorItem{s.buildMonitorItem(task)},
+	}
+	for _, id := range targets {
+		if err := s.sendMonitorConfigToAgent(id, payload); err != nil && !errors.Is(err, ws.ErrClientNotFound) {
+			s.logger.Warn("请求探针复检失败",
+				zap.String("monitorID", monitorID),
+				zap.String("agentID", id),
+				zap.Error(err))
+		}
+	}
+}
+
+// getRecheckTask 获取失败复检使用的监控任务，配置变更时缓存会被清除
+func (s *MonitorService) getRecheckTask(monitorID string) (models.MonitorTask, error) {
+	if task, ok := s.taskCache.Get(monitorID); ok {
+		return task, nil
+	}
+	task, err := s.MonitorRepo.FindById(context.Background(), monitorID)
+	if err != nil {
+		return task, err
+	}
+	s.taskCache.Set(monitorID, task, recheckTaskCacheTTL)
+	return task, nil
+}
+
+const (
+	// MonitorTypePush 推送（心跳）监控类型，由被监控方主动上报
+	MonitorTypePush = "push"
+	// PushMonitorAgentID 推送监控数据使用的虚拟探针 ID
+	PushMonitorAgentID = "push"
+	// QuorumMonitorAgentID 多点确认监控聚合结果使用的虚拟探针 ID
+	QuorumMonitorAgentID = "quorum"
+	// recheckThrottleTTL 同一探针复检请求的最小间隔
+	recheckThrottleTTL = 10 * time.Second
+	// recheckTaskCacheTTL 失败复检使用的监控任务缓存时间
+	recheckTaskCacheTTL = time.Minute
+)
+
+// ErrMonitorDisabled 监控任务已禁用
+var ErrMonitorDisabled = errors.New("monitor is disabled")
+
+// newPushToken 生成推送监控令牌
+func newPushToken() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// ResetPushToken 重新生成推送监控令牌
+func (s *MonitorService) ResetPushToken(ctx context.Context, id string) (*models.MonitorTask, error) {
+	task, err := s.MonitorRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Type != MonitorTypePush {
+		return nil, fmt.Errorf("仅推送监控支持重置令牌")
+	}
+	task.PushToken = newPushToken()
+	if err := s.MonitorRepo.UpdateById(ctx, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// HandlePush 处理推送监控的心跳上报
+// status 为空时视为 up，responseTime 为可选的耗时（毫秒）
+func (s *MonitorService) HandlePush(ctx context.Context, token, status, message string, responseTime int64) error {
+	if status == "" {
+		status = "up"
+	}
+	if status != "up" && status != "down" {
+		return fmt.Errorf("状态只能是 up 或 down")
+	}
+
+	task, err := s.MonitorRepo.FindByPushToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if task.Type != MonitorTypePush {
+		return fmt.Errorf("监控任务不是推送类型")
+	}
+	if !task.Enabled {
+		return ErrMonitorDisabled
+	}
+
+	now := time.Now().UnixMilli()
+	if err := s.MonitorRepo.UpdateLastPushAt(ctx, task.ID, now); err != nil {
+		return err
+	}
+
+	monitorData := &protocol.MonitorData{
+		AgentName:    task.Name,
+		MonitorId:    task.ID,
+		Type:         MonitorTypePush,
+		Target:       task.Target,
+		Status:       status,
+		ResponseTime: responseTime,
+		CheckedAt:    now,
+		Message:      message,
+	}
+	if status == "down" {
+		monitorData.Error = message
+	}
+
+	return s.metricService.SaveMonitorData(ctx, PushMonitorAgentID, monitorData)
+}
+
+// CheckPushMonitors 检查推送监控是否按时上报心跳，超时则判定为离线
+func (s *MonitorService) CheckPushMonitors(ctx context.Context) error {
+	tasks, err := s.FindByEnabledAndType(ctx, true, MonitorTypePush)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	for _, task := range tasks {
+		// 从未收到心跳时，以任务最后更新时间作为起点
+		lastPushAt := task.LastPushAt
+		if lastPushAt == 0 {
+			lastPushAt = task.UpdatedAt
+		}
+
+		timeout := int64(task.Interval+task.PushConfig.Data().Grace) * 1000
+		if now-lastPushAt <= timeout {
+			// 仍在有效期内，延长缓存避免检测周期较长的任务状态丢失
+			s.metricService.RefreshMonitorCache(task.ID)
+			continue
+		}
+
+		monitorData := &protocol.MonitorData{
+			AgentName: task.Name,
+			MonitorId: task.ID,
+			Type:      MonitorTypePush,
+			Target:    task.Target,
+			Status:    "down",
+			CheckedAt: now,
+			Error:     fmt.Sprintf("超过%d秒未收到心跳", (now-lastPushAt)/1000),
+		}
+		if err := s.metricService.SaveMonitorData(ctx, PushMonitorAgentID, monitorData); err != nil {
+			s.logger.Error("保存推送监控数据失败",
+				zap.String("taskID", task.ID),
+				zap.String("taskName", task.Name),
+				zap.Error(err))
+		}
+	}
+
+	return nil
+}
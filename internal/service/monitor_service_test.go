@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func newTestMonitorService(t *testing.T) *MonitorService {
+	t.Helper()
+	db := newTestDB(t, &models.MonitorTask{})
+	return NewMonitorService(zap.NewNop(), db, nil, nil, nil)
+}
+
+func TestUpdateMonitorClearsPushToken(t *testing.T) {
+	ctx := context.Background()
+	s := newTestMonitorService(t)
+
+	task, err := s.CreateMonitor(ctx, &MonitorTaskRequest{Name: "cron", Type: MonitorTypePush, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.PushToken == "" {
+		t.Fatal("推送监控应生成上报令牌")
+	}
+	token := task.PushToken
+
+	updated, err := s.UpdateMonitor(ctx, task.ID, &MonitorTaskRequest{Name: "cron", Type: "http", Target: "https://example.com", Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.PushToken != "" {
+		t.Errorf("切换为 http 后令牌应被清除，实际 %q", updated.PushToken)
+	}
+	// 旧令牌无法再上报
+	if err := s.HandlePush(ctx, token, "down", "fake", 0); err == nil {
+		t.Error("切换类型后旧令牌上报应失败")
+	}
+
+	// 切换回推送监控时生成新令牌
+	updated, err = s.UpdateMonitor(ctx, task.ID, &MonitorTaskRequest{Name: "cron", Type: MonitorTypePush, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.PushToken == "" || updated.PushToken == token {
+		t.Errorf("切换回推送监控应生成新令牌，实际 %q", updated.PushToken)
+	}
+}
+
+func TestSaveConfigKeepsLastPushAt(t *testing.T) {
+	ctx := context.Background()
+	s := newTestMonitorService(t)
+
+	task, err := s.CreateMonitor(ctx, &MonitorTaskRequest{Name: "cron", Type: MonitorTypePush, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := s.MonitorRepo.FindById(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 编辑期间收到心跳
+	if err := s.MonitorRepo.UpdateLastPushAt(ctx, task.ID, 1700000000000); err != nil {
+		t.Fatal(err)
+	}
+	stale.Description = "每小时执行"
+	if err := s.MonitorRepo.SaveConfig(ctx, &stale); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := s.MonitorRepo.FindById(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.LastPushAt != 1700000000000 {
+		t.Errorf("LastPushAt = %d，保存配置不应覆盖心跳时间", saved.LastPushAt)
+	}
+	if saved.Description != "每小时执行" {
+		t.Errorf("Description = %q", saved.Description)
+	}
+}
+
+func TestBuildMonitorConfig(t *testing.T) {
+	s := &MonitorService{}
+	monitors := []models.MonitorTask{
+		{ID: "all", Type: "http", Target: "https://example.com", Interval: 30},
+		{ID: "only-a", Type: "tcp", Target: "example.com:22", AgentIds: []string{"agent-a"}},
+		{ID: "push", Type: MonitorTypePush},
+	}
+
+	tests := []struct {
+		agentID string
+		want    []string
+	}{
+		{"agent-a", []string{"all", "only-a"}},
+		{"agent-b", []string{"all"}},
+	}
+	for _, tt := range tests {
+		payload := s.buildMonitorConfig(monitors, tt.agentID)
+		if !payload.Full {
+			t.Errorf("%s: 应下发完整配置", tt.agentID)
+		}
+		var got []string
+		for _, item := range payload.Items {
+			got = append(got, item.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: items = %v, want %v", tt.agentID, got, tt.want)
+		}
+	}
+
+	// 只下发对应类型的配置
+	items := s.buildMonitorConfig(monitors, "agent-a").Items
+	if items[0].HTTPConfig == nil || items[0].TCPConfig != nil || items[0].Interval != 30 {
+		t.Errorf("http 监控项错误: %+v", items[0])
+	}
+	if items[1].TCPConfig == nil || items[1].HTTPConfig != nil {
+		t.Errorf("tcp 监控项错误: %+v", items[1])
+	}
+}
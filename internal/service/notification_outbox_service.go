@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"github.com/jpillora/backoff"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// outboxMaxAttempts 每条通知的最大尝试次数（含首次发送），按退避间隔约一小时后放弃
+	outboxMaxAttempts = 8
+	// outboxBatchSize 每轮最多处理的通知数
+	outboxBatchSize = 200
+	// outboxSendTimeout 单次发送的超时时间
+	outboxSendTimeout = 30 * time.Second
+	// outboxRetention 已结束通知的保留时间
+	outboxRetention = 30 * 24 * time.Hour
+	// outboxResponseLimit 尝试记录中保存的响应长度上限
+	outboxResponseLimit = 2000
+)
+
+// outboxBackoff 重试间隔: 30s, 1m, 2m, 4m ... 最长 1h
+var outboxBackoff = &backoff.Backoff{
+	Min:    30 * time.Second,
+	Max:    time.Hour,
+	Factor: 2,
+	Jitter: true,
+}
+
+// defaultChannelRateLimits 各渠道默认每分钟最多发送的消息数，参考各平台机器人的频率限制
+// 可以在渠道配置中通过 rateLimit 覆盖
+var defaultChannelRateLimits = map[string]int{
+	"dingtalk": 20,
+	"wecom":    20,
+	"feishu":   100,
+	"telegram": 20,
+	"discord":  30,
+}
+
+// defaultChannelRateLimit 未单独配置的渠道每分钟最多发送的消息数
+const defaultChannelRateLimit = 60
+
+// channelLimiter 渠道的频率限制器，配置变更时重建
+type channelLimiter struct {
+	limit   int
+	limiter *rate.Limiter
+}
+
+// NotificationOutboxService 通知发件箱，通知先写入数据库再由后台任务发送，失败时按指数退避重试
+type NotificationOutboxService struct {
+	logger          *zap.Logger
+	DeliveryRepo    *repo.NotificationDeliveryRepo // 导出用于 handler 的 PageBuilder
+	AttemptRepo     *repo.NotificationAttemptRepo
+	propertyService *PropertyService
+	notifier        *Notifier
+
+	limiterMu sync.Mutex
+	limiters  map[string]*channelLimiter
+	wakeup    chan struct{}
+}
+
+func NewNotificationOutboxService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, notifier *Notifier) *NotificationOutboxService {
+	return &NotificationOutboxService{
+		logger:          logger,
+		DeliveryRepo:    repo.NewNotificationDeliveryRepo(db),
+		AttemptRepo:     repo.NewNotificationAttemptRepo(db),
+		propertyService: propertyService,
+		notifier:        notifier,
+		limiters:        make(map[string]*channelLimiter),
+		wakeup:          make(chan struct{}, 1),
+	}
+}
+
+// NotificationDeliveryDetail 通知投递详情
+type NotificationDeliveryDetail struct {
+	models.NotificationDelivery
+	AttemptLogs []models.NotificationAttempt `json:"attemptLogs"`
+}
+
+// Enqueue 将告警通知写入发件箱，每个渠道一条，发送时按渠道模板渲染消息
+// 不在渠道发送时段内的通知按渠道配置丢弃或延迟到下一个发送时段
+func (s *NotificationOutboxService) Enqueue(ctx context.Context, channels []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, maskIP bool) error {
+	return s.enqueue(ctx, models.DeliveryKindAlert, channels, "", record, agent, maskIP)
+}
+
+// EnqueueMessage 将已构建好的消息写入发件箱（分组通知和摘要使用）
+func (s *NotificationOutboxService) EnqueueMessage(ctx context.Context, channels []models.NotificationChannelConfig, message string, record *models.AlertRecord, agent *models.Agent) error {
+	return s.enqueue(ctx, models.DeliveryKindMessage, channels, message, record, agent, false)
+}
+
+func (s *NotificationOutboxService) enqueue(ctx context.Context, kind string, channels []models.NotificationChannelConfig, message string, record *models.AlertRecord, agent *models.Agent, maskIP bool) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	summary := record.Message
+	if kind == models.DeliveryKindMessage {
+		summary, _ = splitTitle(message)
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, channel := range channels {
+		// 告警已恢复，尚未发送（等待重试或延迟到发送时段）的触发通知不再发送
+		if kind == models.DeliveryKindAlert && record.Status == "resolved" && record.ID != 0 {
+			if count, err := s.DeliveryRepo.SupersedePending(ctx, record.ID, channel.Type); err != nil {
+				errs = append(errs, fmt.Errorf("取消未发送的告警通知失败: %w", err))
+			} else if count > 0 {
+				s.logger.Debug("告警已恢复，取消未发送的告警通知",
+					zap.String("channel", channel.Type),
+					zap.Int64("recordId", record.ID),
+					zap.Int64("count", count),
+				)
+			}
+		}
+
+		// 按渠道的发送时段丢弃或延迟通知
+		sendAt, ok := ScheduleDelivery(channel.Schedule, record.Level, now)
+		if !ok {
+			s.logger.Debug("不在渠道发送时段内，丢弃通知",
+				zap.String("channel", channel.Type),
+				zap.Int64("recordId", record.ID),
+				zap.String("level", record.Level),
+			)
+			continue
+		}
+		delivery := &models.NotificationDelivery{
+			ChannelType:   channel.Type,
+			Kind:          kind,
+			RecordID:      record.ID,
+			AgentID:       agent.ID,
+			AlertType:     record.AlertType,
+			Level:         record.Level,
+			Summary:       truncateRunes(summary, 500),
+			Message:       message,
+			MaskIP:        maskIP,
+			Record:        datatypes.NewJSONType(*record),
+			Agent:         datatypes.NewJSONType(agentSnapshot(agent)),
+			Status:        models.DeliveryStatusPending,
+			MaxAttempts:   outboxMaxAttempts,
+			NextAttemptAt: sendAt.UnixMilli(),
+			CreatedAt:     now.UnixMilli(),
+		}
+		if err := s.DeliveryRepo.Create(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("写入通知发件箱失败: %w", err))
+		}
+	}
+
+	s.wake()
+	return errors.Join(errs...)
+}
+
+// agentSnapshot 保存发送通知需要的探针信息
+func agentSnapshot(agent *models.Agent) models.Agent {
+	return models.Agent{
+		ID:       agent.ID,
+		Name:     agent.Name,
+		Hostname: agent.Hostname,
+		IP:       agent.IP,
+		IPv4:     agent.IPv4,
+		IPv6:     agent.IPv6,
+		OS:       agent.OS,
+		Arch:     agent.Arch,
+		Tags:     agent.Tags,
+	}
+}
+
+// wake 唤醒发送任务，立即处理新写入的通知
+func (s *NotificationOutboxService) wake() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Run 启动通知发送任务
+func (s *NotificationOutboxService) Run(ctx context.Context) {
+	s.logger.Info("通知发送任务已启动")
+
+	// 上次退出时正在发送的通知重新发送
+	if count, err := s.DeliveryRepo.ResetSending(ctx); err != nil {
+		s.logger.Error("重置发送中的通知失败", zap.Error(err))
+	} else if count > 0 {
+		s.logger.Info("重新发送上次中断的通知", zap.Int64("count", count))
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("通知发送任务已停止")
+			return
+		case <-cleanupTicker.C:
+			before := time.Now().Add(-outboxRetention).UnixMilli()
+			if err := s.DeliveryRepo.DeleteFinishedBefore(ctx, before); err != nil {
+				s.logger.Error("清理通知发送记录失败", zap.Error(err))
+			}
+			continue
+		case <-ticker.C:
+		case <-s.wakeup:
+		}
+
+		if err := s.Dispatch(ctx); err != nil {
+			s.logger.Error("发送通知失败", zap.Error(err))
+		}
+	}
+}
+
+// Dispatch 发送到期的通知，不同渠道并行发送，同一渠道按顺序发送并受频率限制，超出限制的通知留到下一轮
+func (s *NotificationOutboxService) Dispatch(ctx context.Context) error {
+	deliveries, err := s.DeliveryRepo.FindDue(ctx, time.Now().UnixMilli(), outboxBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	channels := make(map[string]*models.NotificationChannelConfig, len(channelConfigs))
+	for i := range channelConfigs {
+		if _, ok := channels[channelConfigs[i].Type]; !ok {
+			channels[channelConfigs[i].Type] = &channelConfigs[i]
+		}
+	}
+
+	groups := make(map[string][]models.NotificationDelivery)
+	for _, delivery := range deliveries {
+		groups[delivery.ChannelType] = append(groups[delivery.ChannelType], delivery)
+	}
+
+	var wg sync.WaitGroup
+	for channelType, items := range groups {
+		channel := channels[channelType]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var limiter *rate.Limiter
+			if channel != nil && channel.Enabled {
+				limiter = s.limiter(channel)
+			}
+			for i := range items {
+				if ctx.Err() != nil {
+					return
+				}
+				if limiter != nil && !limiter.Allow() {
+					s.logger.Debug("通知渠道超出频率限制，剩余通知等待下一轮发送",
+						zap.String("channelType", channelType),
+						zap.Int("remaining", len(items)-i),
+					)
+					return
+				}
+				s.deliver(ctx, &items[i], channel)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// limiter 获取渠道的频率限制器
+func (s *NotificationOutboxService) limiter(channel *models.NotificationChannelConfig) *rate.Limiter {
+	limit := defaultChannelRateLimit
+	if v, ok := defaultChannelRateLimits[channel.Type]; ok {
+		limit = v
+	}
+	if v, ok := channel.Config["rateLimit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	s.limiterMu.Lock()
+	defer s.limiterMu.Unlock()
+
+	if l, ok := s.limiters[channel.Type]; ok && l.limit == limit {
+		return l.limiter
+	}
+	// 允许短时间内突发发送 10 秒的配额
+	l := &channelLimiter{
+		limit:   limit,
+		limiter: rate.NewLimiter(rate.Limit(float64(limit)/60), max(1, limit/6)),
+	}
+	s.limiters[channel.Type] = l
+	return l.limiter
+}
+
+// deliver 发送一条通知并记录尝试结果，失败时按退避间隔安排重试
+func (s *NotificationOutboxService) deliver(ctx context.Context, delivery *models.NotificationDelivery, channel *models.NotificationChannelConfig) {
+	// 只领取仍在等待发送的通知，避免与其他发送任务或恢复通知的取代操作冲突
+	claimed, err := s.DeliveryRepo.Claim(ctx, delivery.ID)
+	if err != nil {
+		s.logger.Error("更新通知状态失败", zap.Int64("deliveryId", delivery.ID), zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	start := time.Now()
+	var sendErr error
+	var response *deliveryResponse
+	// 渠道被删除或禁用时不再重试
+	retryable := true
+	if channel == nil || !channel.Enabled {
+		sendErr = fmt.Errorf("通知渠道 %s 未配置或已禁用", delivery.ChannelType)
+		retryable = false
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+		sendCtx, response = withDeliveryResponse(withDeliveryID(sendCtx, delivery.ID))
+		sendErr = s.send(sendCtx, delivery, channel)
+		cancel()
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	attempt := &models.NotificationAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		Duration:   now.Sub(start).Milliseconds(),
+		CreatedAt:  now.UnixMilli(),
+	}
+	if response != nil {
+		attempt.StatusCode = response.StatusCode
+		attempt.Response = truncateRunes(response.Body, outboxResponseLimit)
+	}
+
+	switch {
+	case sendErr == nil:
+		attempt.Status = models.DeliveryStatusSuccess
+		delivery.Status = models.DeliveryStatusSuccess
+		delivery.DeliveredAt = now.UnixMilli()
+		delivery.LastError = ""
+	case !retryable || delivery.Attempts >= delivery.MaxAttempts:
+		attempt.Status = models.DeliveryStatusFailed
+		attempt.Error = sendErr.Error()
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.LastError = sendErr.Error()
+	default:
+		attempt.Status = models.DeliveryStatusFailed
+		attempt.Error = sendErr.Error()
+		delivery.Status = models.DeliveryStatusPending
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(outboxBackoff.ForAttempt(float64(delivery.Attempts - 1))).UnixMilli()
+	}
+
+	if sendErr != nil {
+		s.logger.Warn("通知发送失败",
+			zap.Int64("deliveryId", delivery.ID),
+			zap.String("channelType", delivery.ChannelType),
+			zap.Int("attempt", delivery.Attempts),
+			zap.String("status", delivery.Status),
+			zap.Error(sendErr),
+		)
+	}
+
+	if err := s.AttemptRepo.Create(ctx, attempt); err != nil {
+		s.logger.Error("保存通知尝试记录失败", zap.Int64("deliveryId", delivery.ID), zap.Error(err))
+	}
+	if finished, err := s.DeliveryRepo.Finish(ctx, delivery); err != nil {
+		s.logger.Error("更新通知状态失败", zap.Int64("deliveryId", delivery.ID), zap.Error(err))
+	} else if !finished {
+		s.logger.Warn("通知状态已被修改，忽略本次发送结果", zap.Int64("deliveryId", delivery.ID))
+	}
+}
+
+// send 按内容类型发送通知
+func (s *NotificationOutboxService) send(ctx context.Context, delivery *models.NotificationDelivery, channel *models.NotificationChannelConfig) error {
+	record := delivery.Record.Data()
+	agent := delivery.Agent.Data()
+	if delivery.Kind == models.DeliveryKindMessage {
+		return s.notifier.sendMessageByConfig(ctx, channel, delivery.Message, &record, &agent)
+	}
+	return s.notifier.SendNotificationByConfig(ctx, channel, &record, &agent, delivery.MaskIP)
+}
+
+// GetDelivery 获取通知投递详情和尝试记录
+func (s *NotificationOutboxService) GetDelivery(ctx context.Context, id int64) (*NotificationDeliveryDetail, error) {
+	delivery, err := s.DeliveryRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.AttemptRepo.FindByDeliveryID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationDeliveryDetail{
+		NotificationDelivery: delivery,
+		AttemptLogs:          attempts,
+	}, nil
+}
+
+// Resend 重新发送失败的通知，重新计算重试次数
+func (s *NotificationOutboxService) Resend(ctx context.Context, id int64) (*models.NotificationDelivery, error) {
+	delivery, err := s.DeliveryRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status != models.DeliveryStatusFailed {
+		return nil, orz.NewError(400, "只能重新发送失败的通知")
+	}
+
+	delivery.Status = models.DeliveryStatusPending
+	delivery.MaxAttempts = delivery.Attempts + outboxMaxAttempts
+	delivery.NextAttemptAt = time.Now().UnixMilli()
+	if err := s.DeliveryRepo.Save(ctx, &delivery); err != nil {
+		return nil, err
+	}
+
+	s.wake()
+	return &delivery, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// newTestOutbox 创建使用内存数据库的发件箱，渠道配置写入属性表
+func newTestOutbox(t *testing.T, channels ...models.NotificationChannelConfig) *NotificationOutboxService {
+	t.Helper()
+	db := newTestDB(t, &models.NotificationDelivery{}, &models.NotificationAttempt{}, &models.Property{})
+	propertyService := NewPropertyService(zap.NewNop(), db)
+	if err := propertyService.Set(context.Background(), PropertyIDNotificationChannels, "通知渠道", channels); err != nil {
+		t.Fatal(err)
+	}
+	return NewNotificationOutboxService(zap.NewNop(), db, propertyService, NewNotifier(zap.NewNop(), propertyService))
+}
+
+// ntfyChannel 发送到本地 HTTP 服务的 ntfy 渠道
+func ntfyChannel(server *captureServer, enabled bool) models.NotificationChannelConfig {
+	return models.NotificationChannelConfig{
+		Type:    "ntfy",
+		Enabled: enabled,
+		Config:  map[string]interface{}{"server": server.URL, "topic": "alerts"},
+	}
+}
+
+func enqueueTestAlert(t *testing.T, s *NotificationOutboxService, channel models.NotificationChannelConfig, id int64, status string) {
+	t.Helper()
+	record := testPushRecord("warning", status)
+	record.ID = id
+	agent := &models.Agent{ID: "agent-1", Name: "测试探针"}
+	if err := s.Enqueue(context.Background(), []models.NotificationChannelConfig{channel}, record, agent, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findDeliveries(t *testing.T, s *NotificationOutboxService) []models.NotificationDelivery {
+	t.Helper()
+	deliveries, err := s.DeliveryRepo.FindAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+// makeDue 将等待重试的通知调整为立即到期
+func makeDue(t *testing.T, s *NotificationOutboxService) {
+	t.Helper()
+	err := s.DeliveryRepo.GetDB(context.Background()).
+		Model(&models.NotificationDelivery{}).
+		Where("status = ?", models.DeliveryStatusPending).
+		Update("next_attempt_at", 0).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dispatch(t *testing.T, s *NotificationOutboxService) {
+	t.Helper()
+	if err := s.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxRetryBackoff(t *testing.T) {
+	server := newCaptureServer(t, http.StatusInternalServerError, `{"error":"unavailable"}`)
+	channel := ntfyChannel(server, true)
+	s := newTestOutbox(t, channel)
+	enqueueTestAlert(t, s, channel, 1, "firing")
+
+	// 第一次重试间隔 30s，第二次 30s ~ 60s（带抖动）
+	wantDelays := []struct{ min, max time.Duration }{
+		{30 * time.Second, 30 * time.Second},
+		{30 * time.Second, 60 * time.Second},
+	}
+	for i, want := range wantDelays {
+		makeDue(t, s)
+		before := time.Now()
+		dispatch(t, s)
+		after := time.Now()
+
+		delivery := findDeliveries(t, s)[0]
+		if delivery.Status != models.DeliveryStatusPending || delivery.Attempts != i+1 {
+			t.Fatalf("第 %d 次: status = %s, attempts = %d", i+1, delivery.Status, delivery.Attempts)
+		}
+		if delivery.LastError == "" {
+			t.Errorf("第 %d 次: 缺少失败原因", i+1)
+		}
+		next := time.UnixMilli(delivery.NextAttemptAt)
+		if next.Before(before.Add(want.min).Truncate(time.Millisecond)) || next.After(after.Add(want.max)) {
+			t.Errorf("第 %d 次: 下次尝试时间 %v 不在 [%v, %v] 之后", i+1, next.Sub(before), want.min, want.max)
+		}
+	}
+
+	// 未到重试时间不发送
+	dispatch(t, s)
+	if n := len(server.Requests()); n != 2 {
+		t.Fatalf("请求数 = %d, want 2", n)
+	}
+
+	attempts, err := s.AttemptRepo.FindByDeliveryID(context.Background(), findDeliveries(t, s)[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[1].Attempt != 2 || attempts[1].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("attempts = %+v", attempts)
+	}
+}
+
+func TestOutboxMaxAttempts(t *testing.T) {
+	server := newCaptureServer(t, http.StatusInternalServerError, `{}`)
+	channel := ntfyChannel(server, true)
+	s := newTestOutbox(t, channel)
+	enqueueTestAlert(t, s, channel, 1, "firing")
+
+	for i := 0; i < outboxMaxAttempts; i++ {
+		makeDue(t, s)
+		dispatch(t, s)
+	}
+
+	delivery := findDeliveries(t, s)[0]
+	if delivery.Status != models.DeliveryStatusFailed || delivery.Attempts != outboxMaxAttempts {
+		t.Fatalf("status = %s, attempts = %d", delivery.Status, delivery.Attempts)
+	}
+
+	// 失败后不再发送
+	makeDue(t, s)
+	dispatch(t, s)
+	if n := len(server.Requests()); n != outboxMaxAttempts {
+		t.Fatalf("请求数 = %d, want %d", n, outboxMaxAttempts)
+	}
+}
+
+func TestOutboxDisabledChannel(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{}`)
+	channel := ntfyChannel(server, true)
+	// 写入发件箱后渠道被禁用
+	s := newTestOutbox(t, ntfyChannel(server, false))
+	enqueueTestAlert(t, s, channel, 1, "firing")
+
+	dispatch(t, s)
+
+	delivery := findDeliveries(t, s)[0]
+	if delivery.Status != models.DeliveryStatusFailed || delivery.Attempts != 1 {
+		t.Fatalf("渠道禁用时不应重试: status = %s, attempts = %d", delivery.Status, delivery.Attempts)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Fatalf("请求数 = %d, want 0", n)
+	}
+}
+
+func TestOutboxRateLimit(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{}`)
+	channel := ntfyChannel(server, true)
+	// 每分钟 6 条，突发配额为 1 条
+	channel.Config["rateLimit"] = float64(6)
+	s := newTestOutbox(t, channel)
+	for id := int64(1); id <= 3; id++ {
+		enqueueTestAlert(t, s, channel, id, "firing")
+	}
+
+	dispatch(t, s)
+
+	counts := map[string]int{}
+	for _, delivery := range findDeliveries(t, s) {
+		counts[delivery.Status]++
+		if delivery.Status == models.DeliveryStatusPending && delivery.Attempts != 0 {
+			t.Errorf("超出频率限制的通知不应计入尝试次数: %+v", delivery)
+		}
+	}
+	if counts[models.DeliveryStatusSuccess] != 1 || counts[models.DeliveryStatusPending] != 2 {
+		t.Fatalf("status counts = %v", counts)
+	}
+	if n := len(server.Requests()); n != 1 {
+		t.Fatalf("请求数 = %d, want 1", n)
+	}
+}
+
+func TestOutboxResetSending(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{}`)
+	channel := ntfyChannel(server, true)
+	s := newTestOutbox(t, channel)
+	enqueueTestAlert(t, s, channel, 1, "firing")
+	enqueueTestAlert(t, s, channel, 2, "firing")
+
+	// 模拟上次退出时第一条正在发送
+	ctx := context.Background()
+	first := findDeliveries(t, s)[0]
+	if claimed, err := s.DeliveryRepo.Claim(ctx, first.ID); err != nil || !claimed {
+		t.Fatalf("Claim() = %v, %v", claimed, err)
+	}
+	if claimed, _ := s.DeliveryRepo.Claim(ctx, first.ID); claimed {
+		t.Fatal("发送中的通知不能被重复领取")
+	}
+
+	count, err := s.DeliveryRepo.ResetSending(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("ResetSending() = %d, %v", count, err)
+	}
+	dispatch(t, s)
+	for _, delivery := range findDeliveries(t, s) {
+		if delivery.Status != models.DeliveryStatusSuccess || delivery.Attempts != 1 {
+			t.Errorf("delivery %d: status = %s, attempts = %d", delivery.ID, delivery.Status, delivery.Attempts)
+		}
+	}
+}
+
+func TestOutboxSupersede(t *testing.T) {
+	server := newCaptureServer(t, http.StatusInternalServerError, `{}`)
+	channel := ntfyChannel(server, true)
+	s := newTestOutbox(t, channel)
+	enqueueTestAlert(t, s, channel, 1, "firing")
+	dispatch(t, s)
+
+	// 触发通知等待重试期间告警恢复
+	stale := findDeliveries(t, s)[0]
+	enqueueTestAlert(t, s, channel, 1, "resolved")
+	deliveries := findDeliveries(t, s)
+	if len(deliveries) != 2 || deliveries[0].Status != models.DeliveryStatusSuperseded || deliveries[1].Status != models.DeliveryStatusPending {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+
+	// 取代前已查询出的触发通知不再发送
+	s.deliver(context.Background(), &stale, &channel)
+	if n := len(server.Requests()); n != 1 {
+		t.Fatalf("请求数 = %d, want 1", n)
+	}
+	if delivery := findDeliveries(t, s)[0]; delivery.Status != models.DeliveryStatusSuperseded || delivery.Attempts != 1 {
+		t.Fatalf("status = %s, attempts = %d", delivery.Status, delivery.Attempts)
+	}
+
+	// 已结束的通知不会被发送结果覆盖
+	stale.Status = models.DeliveryStatusSuccess
+	if finished, err := s.DeliveryRepo.Finish(context.Background(), &stale); err != nil || finished {
+		t.Fatalf("Finish() = %v, %v", finished, err)
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// NotificationRouteResult 通知路由结果
+type NotificationRouteResult struct {
+	MatchedRules []string `json:"matchedRules"` // 匹配的规则名称
+	Channels     []string `json:"channels"`     // 路由到的渠道类型，为空表示所有启用的渠道
+	Fallback     bool     `json:"fallback"`     // 是否使用了默认渠道
+}
+
+// ValidateNotificationRouting 校验通知路由配置
+func ValidateNotificationRouting(config *models.NotificationRoutingConfig) error {
+	for i, route := range config.Rules {
+		name := route.Name
+		if name == "" {
+			name = fmt.Sprintf("第 %d 条", i+1)
+		}
+		if len(route.Channels) == 0 {
+			return fmt.Errorf("路由规则 %s 至少需要一个通知渠道", name)
+		}
+		for _, level := range route.Levels {
+			if _, ok := alertLevelOrder[level]; !ok {
+				return fmt.Errorf("路由规则 %s 的告警级别不支持: %s", name, level)
+			}
+		}
+		for _, weekday := range route.Weekdays {
+			if weekday < 0 || weekday > 6 {
+				return fmt.Errorf("路由规则 %s 的星期必须在 0 ~ 6 之间", name)
+			}
+		}
+		if (route.StartTime == "") != (route.EndTime == "") {
+			return fmt.Errorf("路由规则 %s 的开始时间和结束时间需同时设置", name)
+		}
+		if route.StartTime != "" {
+			if _, err := parseClock(route.StartTime); err != nil {
+				return fmt.Errorf("路由规则 %s 的开始时间格式错误", name)
+			}
+			if _, err := parseClock(route.EndTime); err != nil {
+				return fmt.Errorf("路由规则 %s 的结束时间格式错误", name)
+			}
+		}
+	}
+	return nil
+}
+
+// RouteNotification 计算告警应发送到的渠道类型
+func RouteNotification(config *models.NotificationRoutingConfig, record *models.AlertRecord, tags []string, now time.Time) NotificationRouteResult {
+	result := NotificationRouteResult{MatchedRules: []string{}}
+	if config == nil || !config.Enabled {
+		return result
+	}
+
+	for _, route := range config.Rules {
+		if !matchRoute(&route, record, tags, now) {
+			continue
+		}
+		result.MatchedRules = append(result.MatchedRules, route.Name)
+		for _, channel := range route.Channels {
+			if !slices.Contains(result.Channels, channel) {
+				result.Channels = append(result.Channels, channel)
+			}
+		}
+		if route.Stop {
+			break
+		}
+	}
+
+	if len(result.MatchedRules) == 0 {
+		result.Fallback = true
+		result.Channels = slices.Clone(config.DefaultChannels)
+	}
+	return result
+}
+
+// matchRoute 判断告警是否满足路由规则的全部条件
+func matchRoute(route *models.NotificationRoute, record *models.AlertRecord, tags []string, now time.Time) bool {
+	if len(route.Levels) > 0 && !slices.Contains(route.Levels, record.Level) {
+		return false
+	}
+	if len(route.AlertTypes) > 0 && !slices.Contains(route.AlertTypes, record.AlertType) {
+		return false
+	}
+	if len(route.Tags) > 0 && !slices.ContainsFunc(route.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	}) {
+		return false
+	}
+	if len(route.Weekdays) > 0 && !slices.Contains(route.Weekdays, int(now.Weekday())) {
+		return false
+	}
+	if route.StartTime != "" && route.EndTime != "" {
+		start, err1 := parseClock(route.StartTime)
+		end, err2 := parseClock(route.EndTime)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		minute := now.Hour()*60 + now.Minute()
+		if start <= end {
+			if minute < start || minute >= end {
+				return false
+			}
+		} else if minute < start && minute >= end {
+			// 跨零点，例如 22:00 ~ 06:00
+			return false
+		}
+	}
+	return true
+}
+
+// parseClock 解析 HH:MM 格式的时间，返回当天的分钟数
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, errors.New("时间格式必须为 HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// filterChannels 过滤出启用且属于指定类型的渠道，channelTypes 为空时返回所有启用的渠道
+func filterChannels(channelConfigs []models.NotificationChannelConfig, channelTypes []string) []models.NotificationChannelConfig {
+	var channels []models.NotificationChannelConfig
+	for _, channel := range channelConfigs {
+		if !channel.Enabled {
+			continue
+		}
+		if len(channelTypes) > 0 && !slices.Contains(channelTypes, channel.Type) {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// GetRoutedChannelConfigs 按通知路由获取告警应发送的已启用渠道，路由配置读取失败时发送到所有启用的渠道
+func (s *PropertyService) GetRoutedChannelConfigs(ctx context.Context, record *models.AlertRecord, agent *models.Agent) ([]models.NotificationChannelConfig, error) {
+	channelConfigs, err := s.GetNotificationChannelConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	routing, err := s.GetNotificationRoutingConfig(ctx)
+	if err != nil {
+		s.logger.Warn("获取通知路由配置失败，发送到所有启用的渠道", zap.Error(err))
+		return filterChannels(channelConfigs, nil), nil
+	}
+
+	var tags []string
+	if agent != nil {
+		tags = agent.Tags
+	}
+	result := RouteNotification(routing, record, tags, time.Now())
+	return filterChannels(channelConfigs, result.Channels), nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestMatchRoute(t *testing.T) {
+	// 2026-10-16 是周五
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 16, hour, minute, 0, 0, time.Local)
+	}
+	record := &models.AlertRecord{AlertType: "cpu", Level: "critical"}
+	tags := []string{"prod", "db"}
+
+	tests := []struct {
+		name  string
+		route models.NotificationRoute
+		now   time.Time
+		want  bool
+	}{
+		{"无条件匹配所有告警", models.NotificationRoute{}, at(10, 0), true},
+		{"级别匹配", models.NotificationRoute{Levels: []string{"warning", "critical"}}, at(10, 0), true},
+		{"级别不匹配", models.NotificationRoute{Levels: []string{"info"}}, at(10, 0), false},
+		{"告警类型不匹配", models.NotificationRoute{AlertTypes: []string{"memory"}}, at(10, 0), false},
+		{"命中任一标签", models.NotificationRoute{Tags: []string{"web", "db"}}, at(10, 0), true},
+		{"标签全部不命中", models.NotificationRoute{Tags: []string{"web"}}, at(10, 0), false},
+		{"星期匹配", models.NotificationRoute{Weekdays: []int{5}}, at(10, 0), true},
+		{"星期不匹配", models.NotificationRoute{Weekdays: []int{0, 6}}, at(10, 0), false},
+		{"时段内", models.NotificationRoute{StartTime: "09:00", EndTime: "18:00"}, at(9, 0), true},
+		{"时段结束时间不包含", models.NotificationRoute{StartTime: "09:00", EndTime: "18:00"}, at(18, 0), false},
+		{"跨零点时段夜间", models.NotificationRoute{StartTime: "22:00", EndTime: "06:00"}, at(23, 30), true},
+		{"跨零点时段凌晨", models.NotificationRoute{StartTime: "22:00", EndTime: "06:00"}, at(5, 59), true},
+		{"跨零点时段白天", models.NotificationRoute{StartTime: "22:00", EndTime: "06:00"}, at(12, 0), false},
+		{"时间格式错误不匹配", models.NotificationRoute{StartTime: "9点", EndTime: "18:00"}, at(10, 0), false},
+		{"条件需同时满足", models.NotificationRoute{Levels: []string{"critical"}, Tags: []string{"web"}}, at(10, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchRoute(&tt.route, record, tags, tt.now); got != tt.want {
+				t.Fatalf("matchRoute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteNotification(t *testing.T) {
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+	critical := models.NotificationRoute{Name: "严重", Levels: []string{"critical"}, Channels: []string{"pagerduty", "email"}}
+	db := models.NotificationRoute{Name: "数据库", Tags: []string{"db"}, Channels: []string{"email", "dingtalk"}}
+	stop := critical
+	stop.Stop = true
+
+	tests := []struct {
+		name         string
+		config       *models.NotificationRoutingConfig
+		level        string
+		wantRules    []string
+		wantChannels []string
+		wantFallback bool
+	}{
+		{"未配置", nil, "critical", []string{}, nil, false},
+		{"未启用", &models.NotificationRoutingConfig{Rules: []models.NotificationRoute{critical}}, "critical", []string{}, nil, false},
+		{"多条规则合并去重渠道", &models.NotificationRoutingConfig{Enabled: true, Rules: []models.NotificationRoute{critical, db}}, "critical",
+			[]string{"严重", "数据库"}, []string{"pagerduty", "email", "dingtalk"}, false},
+		{"匹配后停止", &models.NotificationRoutingConfig{Enabled: true, Rules: []models.NotificationRoute{stop, db}}, "critical",
+			[]string{"严重"}, []string{"pagerduty", "email"}, false},
+		{"未命中使用默认渠道", &models.NotificationRoutingConfig{Enabled: true, Rules: []models.NotificationRoute{critical}, DefaultChannels: []string{"wecom"}}, "info",
+			[]string{}, []string{"wecom"}, true},
+		{"未命中且无默认渠道", &models.NotificationRoutingConfig{Enabled: true, Rules: []models.NotificationRoute{critical}}, "info",
+			[]string{}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &models.AlertRecord{AlertType: "cpu", Level: tt.level}
+			got := RouteNotification(tt.config, record, []string{"db"}, now)
+			if !slices.Equal(got.MatchedRules, tt.wantRules) {
+				t.Fatalf("MatchedRules = %v, want %v", got.MatchedRules, tt.wantRules)
+			}
+			if !slices.Equal(got.Channels, tt.wantChannels) {
+				t.Fatalf("Channels = %v, want %v", got.Channels, tt.wantChannels)
+			}
+			if got.Fallback != tt.wantFallback {
+				t.Fatalf("Fallback = %v, want %v", got.Fallback, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestFilterChannels(t *testing.T) {
+	configs := []models.NotificationChannelConfig{
+		{Type: "email", Enabled: true},
+		{Type: "dingtalk", Enabled: false},
+		{Type: "pagerduty", Enabled: true},
+	}
+
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+	}{
+		{"不限类型返回所有启用的渠道", nil, []string{"email", "pagerduty"}},
+		{"按类型过滤", []string{"pagerduty"}, []string{"pagerduty"}},
+		{"跳过禁用的渠道", []string{"dingtalk", "email"}, []string{"email"}},
+		{"类型不存在", []string{"slack"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, channel := range filterChannels(configs, tt.types) {
+				got = append(got, channel.Type)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("filterChannels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
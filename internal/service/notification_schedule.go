@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// ValidateChannelSchedules 校验通知渠道的发送时段
+func ValidateChannelSchedules(channels []models.NotificationChannelConfig) error {
+	for _, channel := range channels {
+		schedule := channel.Schedule
+		if schedule == nil || !schedule.Enabled {
+			continue
+		}
+		if schedule.Timezone != "" {
+			if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+				return fmt.Errorf("渠道 %s 的时区不支持: %s", channel.Type, schedule.Timezone)
+			}
+		}
+		switch schedule.Outside {
+		case "", models.ScheduleOutsideDrop, models.ScheduleOutsideDelay, models.ScheduleOutsideCriticalOnly:
+		default:
+			return fmt.Errorf("渠道 %s 的非发送时段处理方式不支持: %s", channel.Type, schedule.Outside)
+		}
+		if len(schedule.Windows) == 0 {
+			return fmt.Errorf("渠道 %s 至少需要一个发送时段", channel.Type)
+		}
+		for i, window := range schedule.Windows {
+			for _, weekday := range window.Weekdays {
+				if weekday < 0 || weekday > 6 {
+					return fmt.Errorf("渠道 %s 第 %d 个发送时段的星期必须在 0 ~ 6 之间", channel.Type, i+1)
+				}
+			}
+			if _, err := parseClock(window.StartTime); err != nil {
+				return fmt.Errorf("渠道 %s 第 %d 个发送时段的开始时间格式错误", channel.Type, i+1)
+			}
+			if _, err := parseClock(window.EndTime); err != nil {
+				return fmt.Errorf("渠道 %s 第 %d 个发送时段的结束时间格式错误", channel.Type, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// ScheduleDelivery 根据渠道的发送时段计算通知的发送时间，返回 false 表示丢弃
+func ScheduleDelivery(schedule *models.ChannelSchedule, level string, now time.Time) (time.Time, bool) {
+	if schedule == nil || !schedule.Enabled || len(schedule.Windows) == 0 {
+		return now, true
+	}
+
+	now = now.In(scheduleLocation(schedule))
+	if inSchedule(schedule, now) {
+		return now, true
+	}
+
+	switch schedule.Outside {
+	case models.ScheduleOutsideDrop:
+		return time.Time{}, false
+	case models.ScheduleOutsideDelay:
+		next, ok := nextScheduleStart(schedule, now)
+		if !ok {
+			return time.Time{}, false
+		}
+		return next, true
+	default:
+		// 严重告警（包括其恢复通知）不受免打扰限制
+		return now, level == "critical"
+	}
+}
+
+// scheduleLocation 获取发送时段的时区，未配置或无效时使用服务器时区
+func scheduleLocation(schedule *models.ChannelSchedule) *time.Location {
+	if schedule.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// inSchedule 判断时间是否处于任一发送时段内
+func inSchedule(schedule *models.ChannelSchedule, now time.Time) bool {
+	for _, window := range schedule.Windows {
+		if inWindow(&window, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// inWindow 判断时间是否处于发送时段内，跨零点的时段按开始时间所在的星期判断
+func inWindow(window *models.ScheduleWindow, now time.Time) bool {
+	start, err1 := parseClock(window.StartTime)
+	end, err2 := parseClock(window.EndTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	weekday := int(now.Weekday())
+	minute := now.Hour()*60 + now.Minute()
+	switch {
+	case start == end:
+		return windowOnDay(window, weekday)
+	case start < end:
+		return windowOnDay(window, weekday) && minute >= start && minute < end
+	default:
+		// 跨零点，例如周五 22:00 ~ 周六 06:00
+		if minute >= start {
+			return windowOnDay(window, weekday)
+		}
+		return minute < end && windowOnDay(window, (weekday+6)%7)
+	}
+}
+
+// windowOnDay 判断发送时段在指定星期是否生效
+func windowOnDay(window *models.ScheduleWindow, weekday int) bool {
+	return len(window.Weekdays) == 0 || slices.Contains(window.Weekdays, weekday)
+}
+
+// nextScheduleStart 计算下一个发送时段的开始时间
+func nextScheduleStart(schedule *models.ChannelSchedule, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, window := range schedule.Windows {
+		start, err := parseClock(window.StartTime)
+		if err != nil {
+			continue
+		}
+		for day := 0; day <= 7; day++ {
+			date := now.AddDate(0, 0, day)
+			if !windowOnDay(&window, int(date.Weekday())) {
+				continue
+			}
+			candidate := time.Date(date.Year(), date.Month(), date.Day(), start/60, start%60, 0, 0, now.Location())
+			if !candidate.After(now) {
+				continue
+			}
+			if next.IsZero() || candidate.Before(next) {
+				next = candidate
+			}
+			break
+		}
+	}
+	return next, !next.IsZero()
+}
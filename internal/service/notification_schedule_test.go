@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestScheduleDelivery(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	// 2026-10-16 是周五
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, loc)
+	}
+	workdays := []models.ScheduleWindow{{Weekdays: []int{1, 2, 3, 4, 5}, StartTime: "09:00", EndTime: "22:00"}}
+	overnight := []models.ScheduleWindow{{Weekdays: []int{5}, StartTime: "22:00", EndTime: "06:00"}}
+
+	tests := []struct {
+		name    string
+		windows []models.ScheduleWindow
+		outside string
+		level   string
+		now     time.Time
+		want    time.Time
+		wantOK  bool
+	}{
+		{"时段内直接发送", workdays, models.ScheduleOutsideDrop, "warning", at(16, 10, 0), at(16, 10, 0), true},
+		{"时段外丢弃", workdays, models.ScheduleOutsideDrop, "critical", at(16, 23, 0), time.Time{}, false},
+		{"时段外仅严重告警", workdays, models.ScheduleOutsideCriticalOnly, "critical", at(16, 3, 0), at(16, 3, 0), true},
+		{"时段外丢弃非严重告警", workdays, "", "warning", at(16, 3, 0), time.Time{}, false},
+		{"延迟到当天开始", workdays, models.ScheduleOutsideDelay, "warning", at(16, 3, 0), at(16, 9, 0), true},
+		{"周末延迟到周一", workdays, models.ScheduleOutsideDelay, "info", at(17, 12, 0), at(19, 9, 0), true},
+		{"跨零点时段次日凌晨", overnight, models.ScheduleOutsideDrop, "info", at(17, 5, 59), at(17, 5, 59), true},
+		{"跨零点时段结束后", overnight, models.ScheduleOutsideDrop, "info", at(17, 6, 0), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &models.ChannelSchedule{
+				Enabled:  true,
+				Timezone: "Asia/Shanghai",
+				Windows:  tt.windows,
+				Outside:  tt.outside,
+			}
+			got, ok := ScheduleDelivery(schedule, tt.level, tt.now.UTC())
+			if ok != tt.wantOK {
+				t.Fatalf("ScheduleDelivery() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("ScheduleDelivery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleDeliveryDisabled(t *testing.T) {
+	now := time.Now()
+	schedule := &models.ChannelSchedule{Outside: models.ScheduleOutsideDrop}
+	if got, ok := ScheduleDelivery(schedule, "info", now); !ok || !got.Equal(now) {
+		t.Errorf("未启用的发送时段应直接发送")
+	}
+}
+
+func TestValidateChannelSchedules(t *testing.T) {
+	channel := func(schedule *models.ChannelSchedule) []models.NotificationChannelConfig {
+		return []models.NotificationChannelConfig{{Type: "telegram", Schedule: schedule}}
+	}
+	window := []models.ScheduleWindow{{StartTime: "09:00", EndTime: "18:00"}}
+
+	if err := ValidateChannelSchedules(channel(&models.ChannelSchedule{Enabled: true, Timezone: "Asia/Shanghai", Windows: window})); err != nil {
+		t.Errorf("合法配置校验失败: %v", err)
+	}
+	invalid := []*models.ChannelSchedule{
+		{Enabled: true, Timezone: "Mars/Base", Windows: window},
+		{Enabled: true, Windows: window, Outside: "later"},
+		{Enabled: true},
+		{Enabled: true, Windows: []models.ScheduleWindow{{Weekdays: []int{7}, StartTime: "09:00", EndTime: "18:00"}}},
+		{Enabled: true, Windows: []models.ScheduleWindow{{StartTime: "9点", EndTime: "18:00"}}},
+	}
+	for i, schedule := range invalid {
+		if err := ValidateChannelSchedules(channel(schedule)); err == nil {
+			t.Errorf("第 %d 个非法配置未被拒绝", i+1)
+		}
+	}
+}
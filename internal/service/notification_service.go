@@ -17,18 +17,34 @@ const (
 type NotificationService struct {
 	logger          *zap.Logger
 	propertyService *PropertyService
-	notifier        *Notifier
+	outbox          *NotificationOutboxService
+	silenceService  *SilenceService
 }
 
-func NewNotificationService(logger *zap.Logger, propertyService *PropertyService, notifier *Notifier) *NotificationService {
+func NewNotificationService(logger *zap.Logger, propertyService *PropertyService, outbox *NotificationOutboxService, silenceService *SilenceService) *NotificationService {
 	return &NotificationService{
 		logger:          logger,
 		propertyService: propertyService,
-		notifier:        notifier,
+		outbox:          outbox,
+		silenceService:  silenceService,
 	}
 }
 
-// SendAlertNotification 根据配置发送通知
+// ApplySilence 匹配生效中的静默，匹配时在记录上记录静默ID并返回 true
+// 流量、SSH 登录和防篡改等提醒类通知没有恢复流程，被静默的提醒在静默结束后不会补发
+func (s *NotificationService) ApplySilence(ctx context.Context, record *models.AlertRecord, agent *models.Agent) bool {
+	if s.silenceService == nil || !s.silenceService.Apply(ctx, record, agent) {
+		return false
+	}
+	s.logger.Info("通知匹配静默，不发送通知",
+		zap.String("agentId", record.AgentID),
+		zap.String("alertType", record.AlertType),
+		zap.String("silenceId", record.SuppressedBy),
+	)
+	return true
+}
+
+// SendAlertNotification 根据配置和通知路由将通知写入发件箱，由后台任务发送
 func (s *NotificationService) SendAlertNotification(ctx context.Context, notificationType string, record *models.AlertRecord, agent *models.Agent) error {
 	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
 	if err != nil {
@@ -43,23 +59,21 @@ func (s *NotificationService) SendAlertNotification(ctx context.Context, notific
 		return nil
 	}
 
-	channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
-	if err != nil {
-		return err
+	// 调用方已匹配过静默（如需要保存静默记录的流量告警）时不再重复匹配
+	if record.SuppressedBy != "" || s.ApplySilence(ctx, record, agent) {
+		return nil
 	}
 
-	var enabledChannels []models.NotificationChannelConfig
-	for _, channel := range channelConfigs {
-		if channel.Enabled {
-			enabledChannels = append(enabledChannels, channel)
-		}
+	enabledChannels, err := s.propertyService.GetRoutedChannelConfigs(ctx, record, agent)
+	if err != nil {
+		return err
 	}
 
 	if len(enabledChannels) == 0 {
 		return nil
 	}
 
-	if err := s.notifier.SendNotificationByConfigs(ctx, enabledChannels, record, agent, alertConfig.MaskIP); err != nil {
+	if err := s.outbox.Enqueue(ctx, enabledChannels, record, agent, alertConfig.MaskIP); err != nil {
 		s.logger.Error("发送通知失败", zap.Error(err))
 		return err
 	}
@@ -10,17 +10,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/utils"
+	"github.com/dushixiang/pika/pkg/webhook"
 	"github.com/go-orz/cache"
+	"github.com/google/uuid"
 	"github.com/valyala/fasttemplate"
 	"go.uber.org/zap"
-	"gopkg.in/gomail.v2"
 )
 
 // AlertTypeMetadata 告警类型元数据
@@ -69,6 +70,83 @@ var alertTypeMetadataMap = map[string]AlertTypeMetadata{
 		ShowThreshold: true,
 		ShowActual:    true,
 	},
+	"load": {
+		Name:          "负载告警",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"swap": {
+		Name:          "Swap告警",
+		ThresholdUnit: "%",
+		ValueUnit:     "%",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"disk_io": {
+		Name:          "磁盘IO告警",
+		ThresholdUnit: "MB/s",
+		ValueUnit:     "MB/s",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"temperature": {
+		Name:          "温度告警",
+		ThresholdUnit: "°C",
+		ValueUnit:     "°C",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"gpu": {
+		Name:          "GPU告警",
+		ThresholdUnit: "%",
+		ValueUnit:     "%",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"gpu_memory": {
+		Name:          "GPU显存告警",
+		ThresholdUnit: "%",
+		ValueUnit:     "%",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"gpu_temperature": {
+		Name:          "GPU温度告警",
+		ThresholdUnit: "°C",
+		ValueUnit:     "°C",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"conn_established": {
+		Name:          "ESTABLISHED连接数告警",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"conn_time_wait": {
+		Name:          "TIME_WAIT连接数告警",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"disk_forecast": {
+		Name:          "磁盘写满预测",
+		ThresholdUnit: "小时",
+		ValueUnit:     "小时",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
+	"traffic_forecast": {
+		Name:          "流量超额预测",
+		ThresholdUnit: "%",
+		ValueUnit:     "%",
+		ShowThreshold: true,
+		ShowActual:    true,
+	},
 	"cert": {
 		Name:          "证书告警",
 		ThresholdUnit: "天",
@@ -104,6 +182,13 @@ var alertTypeMetadataMap = map[string]AlertTypeMetadata{
 		ShowThreshold: false,
 		ShowActual:    false,
 	},
+	"expression": {
+		Name:          "表达式告警",
+		ThresholdUnit: "",
+		ValueUnit:     "",
+		ShowThreshold: false,
+		ShowActual:    true,
+	},
 }
 
 // 告警级别图标映射
@@ -115,12 +200,14 @@ var levelIconMap = map[string]string{
 
 // Notifier 告警通知服务
 type Notifier struct {
-	logger *zap.Logger
+	logger          *zap.Logger
+	propertyService *PropertyService
 }
 
-func NewNotifier(logger *zap.Logger) *Notifier {
+func NewNotifier(logger *zap.Logger, propertyService *PropertyService) *Notifier {
 	return &Notifier{
-		logger: logger,
+		logger:          logger,
+		propertyService: propertyService,
 	}
 }
 
@@ -324,8 +411,8 @@ func (n *Notifier) buildNoticeMessage(
 	return strings.Join(lines, "\n")
 }
 
-// sendDingTalk 发送钉钉通知
-func (n *Notifier) sendDingTalk(ctx context.Context, webhook, secret, message string) error {
+// sendDingTalk 发送钉钉通知，title 不为空时按 Markdown 格式发送
+func (n *Notifier) sendDingTalk(ctx context.Context, webhook, secret, title, message string) error {
 	// 构造钉钉消息体
 	body := map[string]interface{}{
 		"msgtype": "text",
@@ -333,6 +420,15 @@ func (n *Notifier) sendDingTalk(ctx context.Context, webhook, secret, message st
 			"content": message,
 		},
 	}
+	if title != "" {
+		body = map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": title,
+				"text":  message,
+			},
+		}
+	}
 
 	// 如果有加签密钥，计算签名
 	timestamp := time.Now().UnixMilli()
@@ -466,13 +562,36 @@ func (n *Notifier) sendWeComApp(ctx context.Context, origin, corpId, corpSecret
 }
 
 // sendFeishu 发送飞书通知
-func (n *Notifier) sendFeishu(ctx context.Context, webhook, signSecret, message string) error {
+func (n *Notifier) sendFeishu(ctx context.Context, webhook, signSecret, title, message string) error {
 	body := map[string]interface{}{
 		"msg_type": "text",
 		"content": map[string]string{
 			"text": message,
 		},
 	}
+	if title != "" {
+		// 消息卡片支持 lark_md 格式
+		body = map[string]interface{}{
+			"msg_type": "interactive",
+			"card": map[string]interface{}{
+				"header": map[string]interface{}{
+					"title": map[string]string{
+						"tag":     "plain_text",
+						"content": title,
+					},
+				},
+				"elements": []interface{}{
+					map[string]interface{}{
+						"tag": "div",
+						"text": map[string]string{
+							"tag":     "lark_md",
+							"content": message,
+						},
+					},
+				},
+			},
+		}
+	}
 
 	// 如果有加签密钥，计算签名
 	if signSecret != "" {
@@ -498,8 +617,8 @@ func (n *Notifier) sendFeishu(ctx context.Context, webhook, signSecret, message
 	return nil
 }
 
-// sendTelegram 发送 Telegram 通知
-func (n *Notifier) sendTelegram(ctx context.Context, botToken, chatID, message string) error {
+// sendTelegram 发送 Telegram 通知，markdown 为 true 时按 Markdown 格式发送
+func (n *Notifier) sendTelegram(ctx context.Context, botToken, chatID, message string, markdown bool) error {
 	// 构造 Telegram Bot API URL
 	webhookURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", botToken)
 
@@ -507,8 +626,9 @@ func (n *Notifier) sendTelegram(ctx context.Context, botToken, chatID, message s
 	body := map[string]interface{}{
 		"chat_id": chatID,
 		"text":    message,
-		// 可选：使用 Markdown 格式
-		// "parse_mode": "Markdown",
+	}
+	if markdown {
+		body["parse_mode"] = "Markdown"
 	}
 
 	_, err := n.sendJSONRequest(ctx, webhookURL, body)
@@ -518,38 +638,13 @@ func (n *Notifier) sendTelegram(ctx context.Context, botToken, chatID, message s
 	return nil
 }
 
-// sendEmail 发送邮件通知
-func (n *Notifier) sendEmail(ctx context.Context, smtpHost string, smtpPort int, fromEmail, password, toEmail, subject, message string) error {
-	// 创建邮件消息
-	m := gomail.NewMessage()
-	m.SetHeader("From", fromEmail)
-	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", message)
-
-	// 创建 SMTP 拨号器
-	d := gomail.NewDialer(smtpHost, smtpPort, fromEmail, password)
-
-	// 发送邮件
-	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("发送邮件失败: %w", err)
-	}
-
-	n.logger.Info("邮件发送成功",
-		zap.String("from", fromEmail),
-		zap.String("to", toEmail),
-		zap.String("subject", subject),
-	)
-
-	return nil
-}
-
 // webhookConfig Webhook 配置
 type webhookConfig struct {
 	URL        string
 	Method     string
 	Headers    map[string]string
 	CustomBody string
+	Secret     string
 }
 
 // parseWebhookConfig 解析 Webhook 配置
@@ -579,11 +674,15 @@ func parseWebhookConfig(config map[string]interface{}) (*webhookConfig, error) {
 	// 获取自定义请求体
 	customBody, _ := config["customBody"].(string)
 
+	// 获取签名密钥（可选）
+	secret, _ := config["secret"].(string)
+
 	return &webhookConfig{
 		URL:        webhookURL,
 		Method:     method,
 		Headers:    headers,
 		CustomBody: customBody,
+		Secret:     secret,
 	}, nil
 }
 
@@ -650,6 +749,43 @@ func (n *Notifier) buildCustomBody(agent *models.Agent, record *models.AlertReco
 	return strings.NewReader(bodyStr), nil
 }
 
+// deliveryResponseKey 用于在 context 中记录通知请求的响应
+type deliveryResponseKey struct{}
+
+// deliveryResponse 通知请求的响应，发件箱用于记录每次投递尝试
+type deliveryResponse struct {
+	StatusCode int
+	Body       string
+}
+
+// withDeliveryResponse 返回记录响应的 context，同一次发送的多个请求（如先获取 token）保留最后一个响应
+func withDeliveryResponse(ctx context.Context) (context.Context, *deliveryResponse) {
+	resp := &deliveryResponse{}
+	return context.WithValue(ctx, deliveryResponseKey{}, resp), resp
+}
+
+// recordDeliveryResponse 记录通知请求的响应
+func recordDeliveryResponse(ctx context.Context, statusCode int, body []byte) {
+	if resp, ok := ctx.Value(deliveryResponseKey{}).(*deliveryResponse); ok {
+		resp.StatusCode = statusCode
+		resp.Body = string(body)
+	}
+}
+
+// deliveryIDKey 用于在 context 中传递发件箱的通知ID，渠道据此生成重试时不变的幂等键
+type deliveryIDKey struct{}
+
+// withDeliveryID 返回携带通知ID的 context
+func withDeliveryID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, deliveryIDKey{}, id)
+}
+
+// deliveryIDFrom 获取 context 中的通知ID，不经发件箱发送（如测试通知）时返回 false
+func deliveryIDFrom(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(deliveryIDKey{}).(int64)
+	return id, ok && id > 0
+}
+
 // sendHTTPRequest 发送 HTTP 请求
 func (n *Notifier) sendHTTPRequest(ctx context.Context, method, webhookURL string, body io.Reader, headers map[string]string, contentType string) error {
 	// 创建请求
@@ -679,6 +815,7 @@ func (n *Notifier) sendHTTPRequest(ctx context.Context, method, webhookURL strin
 
 	// 读取响应
 	respBody, _ := io.ReadAll(resp.Body)
+	recordDeliveryResponse(ctx, resp.StatusCode, respBody)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
@@ -694,25 +831,38 @@ func (n *Notifier) sendHTTPRequest(ctx context.Context, method, webhookURL strin
 }
 
 // sendCustomWebhook 发送自定义Webhook
-func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
+func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string, maskIP bool) error {
 	// 解析配置
 	cfg, err := parseWebhookConfig(config)
 	if err != nil {
 		return err
 	}
 
-	// 构建消息内容
-	message := n.buildMessage(agent, record, maskIP)
-
 	// 构建自定义请求体
 	reqBody, err := n.buildCustomBody(agent, record, message, cfg.CustomBody, maskIP)
 	if err != nil {
 		return err
 	}
 
+	return n.sendWebhookRequest(ctx, cfg, reqBody)
+}
+
+// sendWebhookRequest 发送 Webhook 请求，配置了签名密钥时附带时间戳、签名和投递ID请求头
+func (n *Notifier) sendWebhookRequest(ctx context.Context, cfg *webhookConfig, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("读取请求体失败: %w", err)
+	}
+
+	headers := maps.Clone(cfg.Headers)
+	if cfg.Secret != "" {
+		// 签名请求头覆盖同名的自定义请求头
+		maps.Copy(headers, webhook.Headers(cfg.Secret, uuid.NewString(), time.Now().Unix(), data))
+	}
+
 	// 发送 HTTP 请求，使用 application/json 作为默认 Content-Type
 	contentType := "application/json"
-	return n.sendHTTPRequest(ctx, cfg.Method, cfg.URL, reqBody, cfg.Headers, contentType)
+	return n.sendHTTPRequest(ctx, cfg.Method, cfg.URL, bytes.NewReader(data), headers, contentType)
 }
 
 // sendJSONRequest 发送JSON请求
@@ -741,6 +891,7 @@ func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interfa
 
 	// 读取响应
 	respBody, _ := io.ReadAll(resp.Body)
+	recordDeliveryResponse(ctx, resp.StatusCode, respBody)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
@@ -752,6 +903,11 @@ func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interfa
 
 // sendDingTalkByConfig 根据配置发送钉钉通知
 func (n *Notifier) sendDingTalkByConfig(ctx context.Context, config map[string]interface{}, message string) error {
+	return n.sendDingTalkMessageByConfig(ctx, config, "", message)
+}
+
+// sendDingTalkMessageByConfig 根据配置发送钉钉通知，title 不为空时按 Markdown 格式发送
+func (n *Notifier) sendDingTalkMessageByConfig(ctx context.Context, config map[string]interface{}, title, message string) error {
 	secretKey, ok := config["secretKey"].(string)
 	if !ok || secretKey == "" {
 		return fmt.Errorf("钉钉配置缺少 secretKey")
@@ -763,7 +919,7 @@ func (n *Notifier) sendDingTalkByConfig(ctx context.Context, config map[string]i
 	// 检查是否有加签密钥
 	signSecret, _ := config["signSecret"].(string)
 
-	return n.sendDingTalk(ctx, webhook, signSecret, message)
+	return n.sendDingTalk(ctx, webhook, signSecret, title, message)
 }
 
 // sendWeComByConfig 根据配置发送企业微信通知
@@ -811,6 +967,11 @@ func (n *Notifier) sendWeComAppByConfig(ctx context.Context, config map[string]i
 
 // sendFeishuByConfig 根据配置发送飞书通知
 func (n *Notifier) sendFeishuByConfig(ctx context.Context, config map[string]interface{}, message string) error {
+	return n.sendFeishuMessageByConfig(ctx, config, "", message)
+}
+
+// sendFeishuMessageByConfig 根据配置发送飞书通知，title 不为空时按 Markdown 卡片发送
+func (n *Notifier) sendFeishuMessageByConfig(ctx context.Context, config map[string]interface{}, title, message string) error {
 	secretKey, ok := config["secretKey"].(string)
 	if !ok || secretKey == "" {
 		return fmt.Errorf("飞书配置缺少 secretKey")
@@ -822,11 +983,16 @@ func (n *Notifier) sendFeishuByConfig(ctx context.Context, config map[string]int
 	// 检查是否有加签密钥
 	signSecret, _ := config["signSecret"].(string)
 
-	return n.sendFeishu(ctx, webhook, signSecret, message)
+	return n.sendFeishu(ctx, webhook, signSecret, title, message)
 }
 
 // sendTelegramByConfig 根据配置发送 Telegram 通知
 func (n *Notifier) sendTelegramByConfig(ctx context.Context, config map[string]interface{}, message string) error {
+	return n.sendTelegramMessageByConfig(ctx, config, message, false)
+}
+
+// sendTelegramMessageByConfig 根据配置发送 Telegram 通知，markdown 为 true 时按 Markdown 格式发送
+func (n *Notifier) sendTelegramMessageByConfig(ctx context.Context, config map[string]interface{}, message string, markdown bool) error {
 	botToken, ok := config["botToken"].(string)
 	if !ok || botToken == "" {
 		return fmt.Errorf("Telegram 配置缺少 botToken")
@@ -837,58 +1003,12 @@ func (n *Notifier) sendTelegramByConfig(ctx context.Context, config map[string]i
 		return fmt.Errorf("Telegram 配置缺少 chatID")
 	}
 
-	return n.sendTelegram(ctx, botToken, chatID, message)
-}
-
-// sendEmailByConfig 根据配置发送邮件通知
-func (n *Notifier) sendEmailByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	smtpHost, ok := config["smtpHost"].(string)
-	if !ok || smtpHost == "" {
-		return fmt.Errorf("邮件配置缺少 smtpHost")
-	}
-
-	// 端口可能是 float64 或 string
-	var smtpPort int
-	switch v := config["smtpPort"].(type) {
-	case float64:
-		smtpPort = int(v)
-	case string:
-		port, err := strconv.Atoi(v)
-		if err != nil {
-			return fmt.Errorf("邮件配置 smtpPort 格式错误: %w", err)
-		}
-		smtpPort = port
-	default:
-		return fmt.Errorf("邮件配置缺少 smtpPort")
-	}
-
-	fromEmail, ok := config["fromEmail"].(string)
-	if !ok || fromEmail == "" {
-		return fmt.Errorf("邮件配置缺少 fromEmail")
-	}
-
-	password, ok := config["password"].(string)
-	if !ok || password == "" {
-		return fmt.Errorf("邮件配置缺少 password")
-	}
-
-	toEmail, ok := config["toEmail"].(string)
-	if !ok || toEmail == "" {
-		return fmt.Errorf("邮件配置缺少 toEmail")
-	}
-
-	// 邮件主题，默认为"Pika 告警通知"
-	subject, ok := config["subject"].(string)
-	if !ok || subject == "" {
-		subject = "Pika 告警通知"
-	}
-
-	return n.sendEmail(ctx, smtpHost, smtpPort, fromEmail, password, toEmail, subject, message)
+	return n.sendTelegram(ctx, botToken, chatID, message, markdown)
 }
 
 // sendWebhookByConfig 根据配置发送自定义Webhook
 func (n *Notifier) sendWebhookByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, maskIP bool) error {
-	return n.sendCustomWebhook(ctx, config, agent, record, maskIP)
+	return n.sendCustomWebhook(ctx, config, agent, record, n.buildMessage(agent, record, maskIP), maskIP)
 }
 
 // SendNotificationByConfig 根据新的配置结构发送通知
@@ -901,29 +1021,139 @@ func (n *Notifier) SendNotificationByConfig(ctx context.Context, channelConfig *
 		zap.String("channelType", channelConfig.Type),
 	)
 
-	// 构造通知消息内容
-	message := n.buildMessage(agent, record, maskIP)
+	// 构造通知消息内容（渠道配置了模板时使用模板）
+	message := n.renderMessage(ctx, channelConfig, agent, record, maskIP)
+	title := getAlertTypeMetadata(record.AlertType).Name
 
 	switch channelConfig.Type {
 	case "dingtalk":
-		return n.sendDingTalkByConfig(ctx, channelConfig.Config, message)
+		return n.sendDingTalkMessageByConfig(ctx, channelConfig.Config, title, message)
 	case "wecom":
 		return n.sendWeComByConfig(ctx, channelConfig.Config, message)
 	case "wecomApp":
 		return n.sendWeComAppByConfig(ctx, channelConfig.Config, message)
 	case "feishu":
-		return n.sendFeishuByConfig(ctx, channelConfig.Config, message)
+		return n.sendFeishuMessageByConfig(ctx, channelConfig.Config, title, message)
 	case "telegram":
-		return n.sendTelegramByConfig(ctx, channelConfig.Config, message)
+		return n.sendTelegramMessageByConfig(ctx, channelConfig.Config, message, true)
 	case "email":
-		return n.sendEmailByConfig(ctx, channelConfig.Config, message)
+		data := n.templateData(ctx, agent, record, maskIP)
+		return n.sendEmailByConfig(ctx, channelConfig.Config, message, &data)
+	case "slack":
+		return n.sendSlackByConfig(ctx, channelConfig.Config, message, levelColor(record))
+	case "discord":
+		return n.sendDiscordByConfig(ctx, channelConfig.Config, message, levelColor(record))
+	case "matrix":
+		return n.sendMatrixByConfig(ctx, channelConfig.Config, message)
+	case "ntfy":
+		return n.sendNtfyByConfig(ctx, channelConfig.Config, message, pushLevel(record))
+	case "gotify":
+		return n.sendGotifyByConfig(ctx, channelConfig.Config, message, pushLevel(record))
+	case "bark":
+		return n.sendBarkByConfig(ctx, channelConfig.Config, message, pushLevel(record))
+	case "serverchan":
+		return n.sendServerChanByConfig(ctx, channelConfig.Config, message)
+	case "pushplus":
+		return n.sendPushPlusByConfig(ctx, channelConfig.Config, message)
+	case "pagerduty":
+		return n.sendPagerDutyByConfig(ctx, channelConfig.Config, agent, record)
+	case "opsgenie":
+		return n.sendOpsgenieByConfig(ctx, channelConfig.Config, agent, record)
 	case "webhook":
-		return n.sendWebhookByConfig(ctx, channelConfig.Config, agent, record, maskIP)
+		return n.sendCustomWebhook(ctx, channelConfig.Config, agent, record, message, maskIP)
 	default:
 		return fmt.Errorf("不支持的通知渠道类型: %s", channelConfig.Type)
 	}
 }
 
+// renderMessage 构建通知消息，渠道配置了对应事件类型的模板时使用模板渲染，渲染失败时使用默认消息
+// 支持 Markdown 的渠道默认消息转换为 Markdown 格式
+func (n *Notifier) renderMessage(ctx context.Context, channelConfig *models.NotificationChannelConfig, agent *models.Agent, record *models.AlertRecord, maskIP bool) string {
+	if text := channelConfig.Templates[templateKind(record)]; text != "" {
+		data := escapeTemplateData(channelConfig.Type, n.templateData(ctx, agent, record, maskIP))
+		message, err := RenderMessageTemplate(text, data)
+		if err == nil {
+			return message
+		}
+		n.logger.Warn("渲染消息模板失败，使用默认消息",
+			zap.String("channelType", channelConfig.Type),
+			zap.Error(err),
+		)
+	}
+
+	message := n.buildMessage(agent, record, maskIP)
+	if SupportsMarkdown(channelConfig.Type) {
+		message = plainToMarkdown(channelConfig.Type, message)
+	}
+	return message
+}
+
+// templateData 构建模板变量，链接使用系统配置中的站点访问地址
+func (n *Notifier) templateData(ctx context.Context, agent *models.Agent, record *models.AlertRecord, maskIP bool) MessageTemplateData {
+	var serverURL string
+	if n.propertyService != nil {
+		if systemConfig, err := n.propertyService.GetSystemConfig(ctx); err == nil {
+			serverURL = systemConfig.ServerURL
+		}
+	}
+	return newMessageTemplateData(agent, record, maskIP, serverURL)
+}
+
+// SendMessageByConfigs 向多个渠道发送已构建好的消息（分组通知和摘要使用）
+// Webhook 渠道使用 record 和 agent 填充模板中的其他变量
+func (n *Notifier) SendMessageByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, message string, record *models.AlertRecord, agent *models.Agent) error {
+	var errs []error
+
+	for _, channelConfig := range channelConfigs {
+		if err := n.sendMessageByConfig(ctx, &channelConfig, message, record, agent); err != nil {
+			n.logger.Error("发送通知失败",
+				zap.String("channelType", channelConfig.Type),
+				zap.Error(err),
+			)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("部分通知发送失败: %v", errs)
+	}
+
+	return nil
+}
+
+// sendMessageByConfig 向单个渠道发送已构建好的消息，区分级别的渠道（颜色、优先级）按 record 的级别发送
+func (n *Notifier) sendMessageByConfig(ctx context.Context, channelConfig *models.NotificationChannelConfig, message string, record *models.AlertRecord, agent *models.Agent) error {
+	config := channelConfig.Config
+	switch channelConfig.Type {
+	case "email":
+		data := n.templateData(ctx, agent, record, false)
+		return n.sendEmailByConfig(ctx, config, message, &data)
+	case "slack":
+		return n.sendSlackByConfig(ctx, config, message, levelColor(record))
+	case "discord":
+		return n.sendDiscordByConfig(ctx, config, message, levelColor(record))
+	case "ntfy":
+		return n.sendNtfyByConfig(ctx, config, message, pushLevel(record))
+	case "gotify":
+		return n.sendGotifyByConfig(ctx, config, message, pushLevel(record))
+	case "bark":
+		return n.sendBarkByConfig(ctx, config, message, pushLevel(record))
+	case "webhook":
+		cfg, err := parseWebhookConfig(config)
+		if err != nil {
+			return err
+		}
+		reqBody, err := n.buildCustomBody(agent, record, message, cfg.CustomBody, false)
+		if err != nil {
+			return err
+		}
+		return n.sendWebhookRequest(ctx, cfg, reqBody)
+	default:
+		// 其他渠道的消息格式与告警级别无关
+		return n.SendTestNotification(ctx, channelConfig.Type, config, message)
+	}
+}
+
 // SendNotificationByConfigs 根据新的配置结构向多个渠道发送通知
 func (n *Notifier) SendNotificationByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, maskIP bool) error {
 	var errs []error
@@ -972,7 +1202,20 @@ func (n *Notifier) SendTelegramByConfig(ctx context.Context, config map[string]i
 
 // SendEmailByConfig 导出方法供外部调用
 func (n *Notifier) SendEmailByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	return n.sendEmailByConfig(ctx, config, message)
+	return n.sendEmailByConfig(ctx, config, message, nil)
+}
+
+// SendEmailTo 使用邮件渠道的 SMTP 配置向指定收件人发送邮件，toEmail 为空时使用渠道配置的收件人
+func (n *Notifier) SendEmailTo(ctx context.Context, config map[string]interface{}, toEmail, subject, message string) error {
+	emailConfig := maps.Clone(config)
+	if toEmail != "" {
+		// 指定收件人时不再抄送渠道配置的地址
+		emailConfig["toEmail"] = toEmail
+		delete(emailConfig, "cc")
+		delete(emailConfig, "bcc")
+	}
+	emailConfig["subject"] = subject
+	return n.sendEmailByConfig(ctx, emailConfig, message, nil)
 }
 
 // SendWebhookByConfig 导出方法供外部调用（测试用）
@@ -1010,7 +1253,25 @@ func (n *Notifier) SendTestNotification(ctx context.Context, channelType string,
 	case "telegram":
 		return n.sendTelegramByConfig(ctx, config, message)
 	case "email":
-		return n.sendEmailByConfig(ctx, config, message)
+		return n.sendEmailByConfig(ctx, config, message, testEmailTemplateData(message))
+	case "slack":
+		return n.sendSlackByConfig(ctx, config, message, levelColor(nil))
+	case "discord":
+		return n.sendDiscordByConfig(ctx, config, message, levelColor(nil))
+	case "matrix":
+		return n.sendMatrixByConfig(ctx, config, message)
+	case "ntfy":
+		return n.sendNtfyByConfig(ctx, config, message, pushLevel(nil))
+	case "gotify":
+		return n.sendGotifyByConfig(ctx, config, message, pushLevel(nil))
+	case "bark":
+		return n.sendBarkByConfig(ctx, config, message, pushLevel(nil))
+	case "serverchan":
+		return n.sendServerChanByConfig(ctx, config, message)
+	case "pushplus":
+		return n.sendPushPlusByConfig(ctx, config, message)
+	case "pagerduty", "opsgenie":
+		return n.sendOncallTestNotification(ctx, channelType, config, message)
 	case "webhook":
 		// Webhook 需要 agent 和 record，创建测试数据
 		agent := &models.Agent{
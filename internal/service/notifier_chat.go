@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/google/uuid"
+)
+
+// 告警级别对应的颜色
+var levelColorMap = map[string]int{
+	"info":     0x1976D2,
+	"warning":  0xF9A825,
+	"critical": 0xD32F2F,
+}
+
+// resolvedColor 恢复通知的颜色
+const resolvedColor = 0x2E7D32
+
+// levelColor 获取告警记录对应的颜色，恢复通知使用绿色
+func levelColor(record *models.AlertRecord) int {
+	if record != nil && record.Status == "resolved" {
+		return resolvedColor
+	}
+	if record != nil {
+		if color, ok := levelColorMap[record.Level]; ok {
+			return color
+		}
+	}
+	return levelColorMap["info"]
+}
+
+// 各平台消息的长度限制（字符数），超出时平台会拒绝整条消息
+const (
+	slackHeaderLimit        = 150  // Slack header 块的文本
+	slackSectionLimit       = 3000 // Slack section 块的文本
+	discordTitleLimit       = 256  // Discord Embed 标题
+	discordDescriptionLimit = 4096 // Discord Embed 描述
+	discordFieldNameLimit   = 256  // Discord Embed 字段名
+	discordFieldValueLimit  = 1024 // Discord Embed 字段值
+	discordMaxFields        = 25   // Discord Embed 字段数
+	discordEmbedLimit       = 6000 // Discord Embed 所有文本的总长度
+)
+
+// clipText 按字符数截断文本，超出时以省略号结尾
+func clipText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// messageField 消息中 "名称: 值" 格式的字段
+type messageField struct {
+	Name  string
+	Value string
+}
+
+// splitMessage 拆分纯文本消息，第一行作为标题，"名称: 值" 格式的行作为字段，其他行作为正文
+func splitMessage(message string) (title string, fields []messageField, lines []string) {
+	for i, line := range strings.Split(message, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if i == 0 {
+			title = line
+			continue
+		}
+		if name, value, ok := strings.Cut(line, ": "); ok {
+			fields = append(fields, messageField{Name: name, Value: value})
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	return title, fields, lines
+}
+
+// sendSlack 通过 Incoming Webhook 发送 Slack 通知，使用带颜色的附件和 Block Kit
+func (n *Notifier) sendSlack(ctx context.Context, webhookURL, message string, color int) error {
+	title, fields, lines := splitMessage(message)
+
+	var text []string
+	for _, field := range fields {
+		text = append(text, fmt.Sprintf("*%s*: %s", slackEscape(field.Name), slackEscape(field.Value)))
+	}
+	for _, line := range lines {
+		text = append(text, slackEscape(line))
+	}
+
+	blocks := []interface{}{
+		map[string]interface{}{
+			"type": "header",
+			"text": map[string]interface{}{
+				"type":  "plain_text",
+				"text":  clipText(title, slackHeaderLimit),
+				"emoji": true,
+			},
+		},
+	}
+	if len(text) > 0 {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]string{
+				"type": "mrkdwn",
+				"text": clipText(strings.Join(text, "\n"), slackSectionLimit),
+			},
+		})
+	}
+
+	body := map[string]interface{}{
+		// 通知栏预览使用的纯文本
+		"text": title,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"color":  fmt.Sprintf("#%06X", color),
+				"blocks": blocks,
+			},
+		},
+	}
+	_, err := n.sendJSONRequest(ctx, webhookURL, body)
+	return err
+}
+
+// slackEscape 转义 Slack mrkdwn 的控制字符
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// sendDiscord 通过 Webhook 发送 Discord 通知，使用 Embed 展示字段
+// 超出字段数上限的字段并入描述，超出总长度上限的字段不再展示
+func (n *Notifier) sendDiscord(ctx context.Context, webhookURL, message string, color int) error {
+	title, fields, lines := splitMessage(message)
+	title = clipText(title, discordTitleLimit)
+
+	if len(fields) > discordMaxFields {
+		overflow := make([]string, 0, len(fields)-discordMaxFields)
+		for _, field := range fields[discordMaxFields:] {
+			overflow = append(overflow, field.Name+": "+field.Value)
+		}
+		lines = append(overflow, lines...)
+		fields = fields[:discordMaxFields]
+	}
+	description := clipText(strings.Join(lines, "\n"), discordDescriptionLimit)
+
+	budget := discordEmbedLimit - utf8.RuneCountInString(title) - utf8.RuneCountInString(description)
+	embedFields := make([]map[string]interface{}, 0, len(fields))
+	for _, field := range fields {
+		// Discord 字段值不能为空
+		value := field.Value
+		if value == "" {
+			value = "-"
+		}
+		name := clipText(field.Name, discordFieldNameLimit)
+		value = clipText(value, discordFieldValueLimit)
+		size := utf8.RuneCountInString(name) + utf8.RuneCountInString(value)
+		if size > budget {
+			break
+		}
+		budget -= size
+		embedFields = append(embedFields, map[string]interface{}{
+			"name":   name,
+			"value":  value,
+			"inline": len(value) <= 32,
+		})
+	}
+
+	embed := map[string]interface{}{
+		"title":     title,
+		"color":     color,
+		"fields":    embedFields,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}
+	if description != "" {
+		embed["description"] = description
+	}
+
+	body := map[string]interface{}{
+		"embeds": []interface{}{embed},
+	}
+	_, err := n.sendJSONRequest(ctx, webhookURL, body)
+	return err
+}
+
+// sendMatrix 通过 Client-Server API 向房间发送 m.room.message，附带 HTML 格式的消息体
+func (n *Notifier) sendMatrix(ctx context.Context, homeserver, accessToken, roomID, message string) error {
+	title, fields, lines := splitMessage(message)
+
+	var formatted strings.Builder
+	formatted.WriteString("<strong>" + html.EscapeString(title) + "</strong>")
+	for _, field := range fields {
+		formatted.WriteString(fmt.Sprintf("<br><b>%s</b>: %s", html.EscapeString(field.Name), html.EscapeString(field.Value)))
+	}
+	for _, line := range lines {
+		formatted.WriteString("<br>" + html.EscapeString(line))
+	}
+
+	body := map[string]string{
+		"msgtype":        "m.text",
+		"body":           message,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	// 事务ID保证重试时消息不重复，经发件箱发送时由通知ID生成，同一条通知的多次尝试使用相同的事务ID
+	txnID := uuid.NewString()
+	if id, ok := deliveryIDFrom(ctx); ok {
+		txnID = fmt.Sprintf("pika-%d", id)
+	}
+	sendURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(homeserver, "/"), url.PathEscape(roomID), url.PathEscape(txnID))
+	headers := map[string]string{
+		"Authorization": "Bearer " + accessToken,
+	}
+	return n.sendHTTPRequest(ctx, http.MethodPut, sendURL, bytes.NewReader(data), headers, "application/json")
+}
+
+// chatChannelFields Slack、Discord、Matrix 渠道的必填配置项，格式说明见 models.NotificationChannelConfig
+var chatChannelFields = map[string][]string{
+	"slack":   {"webhookUrl"},
+	"discord": {"webhookUrl"},
+	"matrix":  {"homeserver", "accessToken", "roomId"},
+}
+
+// ValidateChatChannelConfigs 校验已启用的 Slack、Discord、Matrix 渠道配置
+func ValidateChatChannelConfigs(channels []models.NotificationChannelConfig) error {
+	for _, channel := range channels {
+		fields, ok := chatChannelFields[channel.Type]
+		if !ok || !channel.Enabled {
+			continue
+		}
+		for _, field := range fields {
+			value, _ := channel.Config[field].(string)
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("渠道 %s 缺少 %s", channel.Type, field)
+			}
+			if field == "webhookUrl" || field == "homeserver" {
+				u, err := url.Parse(value)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("渠道 %s 的 %s 必须是 http 或 https 地址", channel.Type, field)
+				}
+			}
+		}
+		// 发送接口只接受房间ID，不接受 # 开头的房间别名
+		if roomID, _ := channel.Config["roomId"].(string); channel.Type == "matrix" && !strings.HasPrefix(roomID, "!") {
+			return errors.New("渠道 matrix 的 roomId 必须是以 ! 开头的房间ID")
+		}
+	}
+	return nil
+}
+
+// sendSlackByConfig 根据配置发送 Slack 通知
+func (n *Notifier) sendSlackByConfig(ctx context.Context, config map[string]interface{}, message string, color int) error {
+	webhookURL, ok := config["webhookUrl"].(string)
+	if !ok || webhookURL == "" {
+		return fmt.Errorf("Slack 配置缺少 webhookUrl")
+	}
+	return n.sendSlack(ctx, webhookURL, message, color)
+}
+
+// sendDiscordByConfig 根据配置发送 Discord 通知
+func (n *Notifier) sendDiscordByConfig(ctx context.Context, config map[string]interface{}, message string, color int) error {
+	webhookURL, ok := config["webhookUrl"].(string)
+	if !ok || webhookURL == "" {
+		return fmt.Errorf("Discord 配置缺少 webhookUrl")
+	}
+	return n.sendDiscord(ctx, webhookURL, message, color)
+}
+
+// sendMatrixByConfig 根据配置发送 Matrix 通知
+func (n *Notifier) sendMatrixByConfig(ctx context.Context, config map[string]interface{}, message string) error {
+	homeserver, ok := config["homeserver"].(string)
+	if !ok || homeserver == "" {
+		return fmt.Errorf("Matrix 配置缺少 homeserver")
+	}
+
+	accessToken, ok := config["accessToken"].(string)
+	if !ok || accessToken == "" {
+		return fmt.Errorf("Matrix 配置缺少 accessToken")
+	}
+
+	roomID, ok := config["roomId"].(string)
+	if !ok || roomID == "" {
+		return fmt.Errorf("Matrix 配置缺少 roomId")
+	}
+
+	return n.sendMatrix(ctx, homeserver, accessToken, roomID, message)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestSendSlack(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{}`)
+	n := newTestNotifier()
+
+	title := strings.Repeat("告", 200)
+	message := title + "\n探针: web-1\n级别: <critical>\n磁盘即将写满"
+	if err := n.sendSlack(context.Background(), server.URL, message, 0xD32F2F); err != nil {
+		t.Fatalf("发送 Slack 通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	attachments, _ := captured.Body["attachments"].([]interface{})
+	if len(attachments) != 1 {
+		t.Fatalf("attachments = %v", captured.Body["attachments"])
+	}
+	attachment := attachments[0].(map[string]interface{})
+	if attachment["color"] != "#D32F2F" {
+		t.Errorf("color = %v", attachment["color"])
+	}
+	blocks := attachment["blocks"].([]interface{})
+	header := blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"].(string)
+	if n := utf8.RuneCountInString(header); n != slackHeaderLimit || !strings.HasSuffix(header, "…") {
+		t.Errorf("header 应截断到 %d 个字符，实际 %d", slackHeaderLimit, n)
+	}
+	section := blocks[1].(map[string]interface{})["text"].(map[string]interface{})["text"].(string)
+	if want := "*探针*: web-1\n*级别*: &lt;critical&gt;\n磁盘即将写满"; section != want {
+		t.Errorf("section = %q, want %q", section, want)
+	}
+}
+
+func TestSendDiscord(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{}`)
+	n := newTestNotifier()
+
+	lines := []string{strings.Repeat("T", 300)}
+	lines = append(lines, "详情: "+strings.Repeat("v", 2000))
+	for i := 1; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("字段%d: 值%d", i, i))
+	}
+	if err := n.sendDiscord(context.Background(), server.URL, strings.Join(lines, "\n"), 0x2E7D32); err != nil {
+		t.Fatalf("发送 Discord 通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	embeds, _ := captured.Body["embeds"].([]interface{})
+	if len(embeds) != 1 {
+		t.Fatalf("embeds = %v", captured.Body["embeds"])
+	}
+	embed := embeds[0].(map[string]interface{})
+	if title := embed["title"].(string); utf8.RuneCountInString(title) != discordTitleLimit {
+		t.Errorf("标题应截断到 %d 个字符，实际 %d", discordTitleLimit, utf8.RuneCountInString(title))
+	}
+	if embed["color"] != float64(0x2E7D32) {
+		t.Errorf("color = %v", embed["color"])
+	}
+	fields := embed["fields"].([]interface{})
+	if len(fields) != discordMaxFields {
+		t.Fatalf("字段数 = %d, want %d", len(fields), discordMaxFields)
+	}
+	if value := fields[0].(map[string]interface{})["value"].(string); utf8.RuneCountInString(value) != discordFieldValueLimit {
+		t.Errorf("字段值应截断到 %d 个字符，实际 %d", discordFieldValueLimit, utf8.RuneCountInString(value))
+	}
+	// 超出字段数上限的字段并入描述
+	if description, _ := embed["description"].(string); description != "字段25: 值25\n字段26: 值26\n字段27: 值27\n字段28: 值28\n字段29: 值29" {
+		t.Errorf("description = %q", description)
+	}
+}
+
+func TestSendDiscordEmbedLimit(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{}`)
+	n := newTestNotifier()
+
+	lines := []string{"磁盘告警"}
+	for i := 0; i < 10; i++ {
+		lines = append(lines, fmt.Sprintf("字段%d: %s", i, strings.Repeat("v", 1000)))
+	}
+	if err := n.sendDiscord(context.Background(), server.URL, strings.Join(lines, "\n"), 0); err != nil {
+		t.Fatalf("发送 Discord 通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	embed := captured.Body["embeds"].([]interface{})[0].(map[string]interface{})
+	total := utf8.RuneCountInString(embed["title"].(string))
+	for _, field := range embed["fields"].([]interface{}) {
+		field := field.(map[string]interface{})
+		total += utf8.RuneCountInString(field["name"].(string)) + utf8.RuneCountInString(field["value"].(string))
+	}
+	if total > discordEmbedLimit {
+		t.Errorf("Embed 总长度 %d 超出上限 %d", total, discordEmbedLimit)
+	}
+	if n := len(embed["fields"].([]interface{})); n != 5 {
+		t.Errorf("字段数 = %d, want 5", n)
+	}
+}
+
+func TestSendMatrixTxnID(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"event_id":"$1"}`)
+	n := newTestNotifier()
+	prefix := "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/"
+
+	send := func(ctx context.Context) string {
+		t.Helper()
+		if err := n.sendMatrix(ctx, server.URL+"/", "syt_token", "!room:example.org", "磁盘告警\n探针: web-1"); err != nil {
+			t.Fatalf("发送 Matrix 通知失败: %v", err)
+		}
+		captured := server.Last()
+		if captured.Method != http.MethodPut || !strings.HasPrefix(captured.Path, prefix) {
+			t.Fatalf("请求 = %s %s", captured.Method, captured.Path)
+		}
+		return strings.TrimPrefix(captured.Path, prefix)
+	}
+
+	// 同一条通知重试时事务ID不变，Matrix 据此去重
+	ctx := withDeliveryID(context.Background(), 42)
+	if first, second := send(ctx), send(ctx); first != "pika-42" || second != first {
+		t.Errorf("事务ID = %q, %q, want pika-42", first, second)
+	}
+	captured := server.Last()
+	if got := captured.Header.Get("Authorization"); got != "Bearer syt_token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if captured.Body["formatted_body"] != "<strong>磁盘告警</strong><br><b>探针</b>: web-1" {
+		t.Errorf("formatted_body = %v", captured.Body["formatted_body"])
+	}
+
+	// 不经发件箱发送时每次使用新的事务ID
+	if first, second := send(context.Background()), send(context.Background()); first == second {
+		t.Errorf("事务ID 不应重复: %q", first)
+	}
+}
+
+func TestValidateChatChannelConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel models.NotificationChannelConfig
+		wantErr bool
+	}{
+		{"Slack", models.NotificationChannelConfig{Type: "slack", Enabled: true, Config: map[string]interface{}{"webhookUrl": "https://hooks.slack.com/services/x"}}, false},
+		{"Slack 缺少地址", models.NotificationChannelConfig{Type: "slack", Enabled: true, Config: map[string]interface{}{}}, true},
+		{"Discord 地址不是 http", models.NotificationChannelConfig{Type: "discord", Enabled: true, Config: map[string]interface{}{"webhookUrl": "discord.com/api/webhooks/x"}}, true},
+		{"禁用的渠道不校验", models.NotificationChannelConfig{Type: "discord", Config: map[string]interface{}{}}, false},
+		{"Matrix", models.NotificationChannelConfig{Type: "matrix", Enabled: true, Config: map[string]interface{}{
+			"homeserver": "https://matrix.org", "accessToken": "syt", "roomId": "!abc:matrix.org"}}, false},
+		{"Matrix 缺少令牌", models.NotificationChannelConfig{Type: "matrix", Enabled: true, Config: map[string]interface{}{
+			"homeserver": "https://matrix.org", "roomId": "!abc:matrix.org"}}, true},
+		{"Matrix 使用房间别名", models.NotificationChannelConfig{Type: "matrix", Enabled: true, Config: map[string]interface{}{
+			"homeserver": "https://matrix.org", "accessToken": "syt", "roomId": "#ops:matrix.org"}}, true},
+		{"其他渠道不校验", models.NotificationChannelConfig{Type: "dingtalk", Enabled: true, Config: map[string]interface{}{}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChatChannelConfigs([]models.NotificationChannelConfig{tt.channel})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateChatChannelConfigs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+	"gopkg.in/gomail.v2"
+)
+
+// 邮件加密方式
+const (
+	EmailTLSModeAuto     = ""         // 465 端口使用 SSL，其他端口在服务器支持时使用 STARTTLS
+	EmailTLSModeSSL      = "ssl"      // 隐式 TLS（SMTPS）
+	EmailTLSModeStartTLS = "starttls" // 必须使用 STARTTLS
+	EmailTLSModeNone     = "none"     // 不加密，适用于内网中继
+)
+
+// defaultEmailSubject 未配置主题时使用的邮件主题
+const defaultEmailSubject = "Pika 告警通知"
+
+// emailConfig 邮件渠道配置
+type emailConfig struct {
+	Host         string
+	Port         int
+	Username     string
+	Password     string
+	From         string
+	FromName     string
+	To           []*mail.Address
+	Cc           []*mail.Address
+	Bcc          []*mail.Address
+	Subject      string
+	HTMLTemplate string
+	TLSMode      string
+	SkipVerify   bool
+}
+
+// emailTemplateData 邮件 HTML 模板可用的变量，在消息模板变量的基础上增加纯文本正文
+type emailTemplateData struct {
+	MessageTemplateData
+	Text string // 纯文本消息
+}
+
+// parseEmailConfig 解析邮件渠道配置
+func parseEmailConfig(config map[string]interface{}) (*emailConfig, error) {
+	cfg := &emailConfig{
+		Host:         configString(config, "smtpHost", ""),
+		From:         configString(config, "fromEmail", ""),
+		FromName:     configString(config, "fromName", ""),
+		Password:     configString(config, "password", ""),
+		Subject:      configString(config, "subject", defaultEmailSubject),
+		HTMLTemplate: configString(config, "htmlTemplate", ""),
+		TLSMode:      strings.ToLower(configString(config, "tlsMode", EmailTLSModeAuto)),
+	}
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("邮件配置缺少 smtpHost")
+	}
+
+	// 端口可能是 float64 或 string
+	switch v := config["smtpPort"].(type) {
+	case float64:
+		cfg.Port = int(v)
+	case string:
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("邮件配置 smtpPort 格式错误: %w", err)
+		}
+		cfg.Port = port
+	default:
+		return nil, fmt.Errorf("邮件配置缺少 smtpPort")
+	}
+
+	if cfg.From == "" {
+		return nil, fmt.Errorf("邮件配置缺少 fromEmail")
+	}
+	// 用户名默认与发件人相同，密码为空时不进行认证（本机或内网 MTA）
+	cfg.Username = configString(config, "username", cfg.From)
+
+	switch cfg.TLSMode {
+	case EmailTLSModeAuto:
+		if cfg.Port == 465 {
+			cfg.TLSMode = EmailTLSModeSSL
+		}
+	case EmailTLSModeSSL, EmailTLSModeStartTLS, EmailTLSModeNone:
+	default:
+		return nil, fmt.Errorf("不支持的邮件加密方式: %s", cfg.TLSMode)
+	}
+	cfg.SkipVerify, _ = config["skipVerify"].(bool)
+
+	var err error
+	if cfg.To, err = parseEmailAddresses(configStrings(config, "toEmail")); err != nil {
+		return nil, err
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("邮件配置缺少 toEmail")
+	}
+	if cfg.Cc, err = parseEmailAddresses(configStrings(config, "cc")); err != nil {
+		return nil, err
+	}
+	if cfg.Bcc, err = parseEmailAddresses(configStrings(config, "bcc")); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseEmailAddresses 解析邮件地址，支持 "名称 <地址>" 格式，兼容分号分隔
+func parseEmailAddresses(values []string) ([]*mail.Address, error) {
+	var addresses []*mail.Address
+	for _, value := range values {
+		for _, item := range strings.Split(value, ";") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			address, err := mail.ParseAddress(item)
+			if err != nil {
+				return nil, fmt.Errorf("邮件地址 %s 格式错误: %w", item, err)
+			}
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// renderEmailSubject 渲染邮件主题模板，例如 "[{{.Level}}] {{.Agent.Name}} {{.AlertTypeName}}"
+func renderEmailSubject(subject string, data *MessageTemplateData) (string, error) {
+	if data == nil || !strings.Contains(subject, "{{") {
+		return subject, nil
+	}
+	rendered, err := RenderMessageTemplate(subject, *data)
+	if err != nil {
+		return "", err
+	}
+	// 主题不能换行
+	return strings.Join(strings.Fields(rendered), " "), nil
+}
+
+// renderEmailHTML 渲染 HTML 正文，配置了 HTML 模板时使用模板，否则将纯文本消息转换为 HTML
+func renderEmailHTML(htmlTemplate, text string, data *MessageTemplateData) (string, error) {
+	if htmlTemplate != "" && data != nil {
+		tmpl, err := htmltemplate.New("email").Option("missingkey=zero").Parse(htmlTemplate)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, emailTemplateData{MessageTemplateData: *data, Text: text}); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	color := levelColorMap["info"]
+	if data != nil {
+		color = levelColor(&data.Record)
+	}
+	return plainToEmailHTML(text, color), nil
+}
+
+// plainToEmailHTML 将纯文本消息转换为 HTML，第一行作为标题，"名称: 值" 格式的行显示为表格
+func plainToEmailHTML(message string, color int) string {
+	title, fields, lines := splitMessage(message)
+
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html><body style="margin:0;padding:16px;background:#f5f5f5;font-family:-apple-system,'Segoe UI',Helvetica,Arial,sans-serif;">`)
+	b.WriteString(`<div style="max-width:600px;margin:0 auto;background:#ffffff;border-radius:6px;overflow:hidden;">`)
+	fmt.Fprintf(&b, `<div style="height:4px;background:#%06X;"></div>`, color)
+	fmt.Fprintf(&b, `<div style="padding:20px;"><h2 style="margin:0 0 16px;font-size:18px;color:#212121;">%s</h2>`, html.EscapeString(title))
+	if len(fields) > 0 {
+		b.WriteString(`<table style="border-collapse:collapse;width:100%;font-size:14px;">`)
+		for _, field := range fields {
+			fmt.Fprintf(&b, `<tr><td style="padding:6px 12px 6px 0;color:#757575;white-space:nowrap;vertical-align:top;">%s</td><td style="padding:6px 0;color:#212121;">%s</td></tr>`,
+				html.EscapeString(field.Name), html.EscapeString(field.Value))
+		}
+		b.WriteString(`</table>`)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(&b, `<p style="margin:12px 0 0;font-size:14px;color:#212121;">%s</p>`, html.EscapeString(line))
+	}
+	b.WriteString(`</div></div></body></html>`)
+	return b.String()
+}
+
+// sendEmail 发送邮件，同时包含纯文本和 HTML 正文
+func (n *Notifier) sendEmail(ctx context.Context, cfg *emailConfig, subject, text, htmlBody string) error {
+	m := gomail.NewMessage()
+	m.SetAddressHeader("From", cfg.From, cfg.FromName)
+	m.SetHeader("To", formatEmailAddresses(m, cfg.To)...)
+	if len(cfg.Cc) > 0 {
+		m.SetHeader("Cc", formatEmailAddresses(m, cfg.Cc)...)
+	}
+	// 密送地址只出现在 SMTP 信封中，不写入邮件头
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", text)
+	if htmlBody != "" {
+		m.AddAlternative("text/html", htmlBody)
+	}
+
+	var recipients []string
+	for _, address := range slices.Concat(cfg.To, cfg.Cc, cfg.Bcc) {
+		recipients = append(recipients, address.Address)
+	}
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		return fmt.Errorf("构建邮件失败: %w", err)
+	}
+	if err := sendSMTP(ctx, cfg, recipients, buf.Bytes()); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+
+	n.logger.Info("邮件发送成功",
+		zap.String("from", cfg.From),
+		zap.Strings("to", recipients),
+		zap.String("subject", subject),
+	)
+
+	return nil
+}
+
+func formatEmailAddresses(m *gomail.Message, addresses []*mail.Address) []string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		formatted = append(formatted, m.FormatAddress(address.Address, address.Name))
+	}
+	return formatted
+}
+
+// sendSMTP 通过 SMTP 发送邮件，按配置选择隐式 TLS、STARTTLS 或不加密
+func sendSMTP(ctx context.Context, cfg *emailConfig, recipients []string, data []byte) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.Host,
+		InsecureSkipVerify: cfg.SkipVerify,
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if cfg.TLSMode == EmailTLSModeSSL {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return err
+	}
+
+	encrypted := cfg.TLSMode == EmailTLSModeSSL
+	if cfg.TLSMode != EmailTLSModeSSL && cfg.TLSMode != EmailTLSModeNone {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("STARTTLS 失败: %w", err)
+			}
+			encrypted = true
+		} else if cfg.TLSMode == EmailTLSModeStartTLS {
+			return errors.New("SMTP 服务器不支持 STARTTLS")
+		}
+	}
+
+	if cfg.Password != "" {
+		// 只有明确选择不加密时才允许明文发送密码
+		if !encrypted && cfg.TLSMode != EmailTLSModeNone {
+			return errors.New("SMTP 服务器不支持加密连接，内网中继请将加密方式设置为 none")
+		}
+		ok, mechanisms := c.Extension("AUTH")
+		if !ok {
+			return errors.New("SMTP 服务器不支持认证，无需认证时请清空密码")
+		}
+		if err := c.Auth(smtpAuth(mechanisms, cfg.Username, cfg.Password)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := c.Rcpt(recipient); err != nil {
+			return fmt.Errorf("收件人 %s 被拒绝: %w", recipient, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// smtpAuth 按服务器支持的认证方式选择，优先 CRAM-MD5，仅支持 LOGIN 时使用 LOGIN，否则使用 PLAIN
+func smtpAuth(mechanisms, username, password string) smtp.Auth {
+	switch {
+	case strings.Contains(mechanisms, "CRAM-MD5"):
+		return smtp.CRAMMD5Auth(username, password)
+	case strings.Contains(mechanisms, "LOGIN") && !strings.Contains(mechanisms, "PLAIN"):
+		return &loginAuth{username: username, password: password}
+	default:
+		return &plainAuth{username: username, password: password}
+	}
+}
+
+// plainAuth PLAIN 认证，是否允许明文连接由调用方判断
+type plainAuth struct {
+	username string
+	password string
+}
+
+func (a *plainAuth) Start(_ *smtp.ServerInfo) (string, []byte, error) {
+	return "PLAIN", []byte("\x00" + a.username + "\x00" + a.password), nil
+}
+
+func (a *plainAuth) Next(_ []byte, more bool) ([]byte, error) {
+	if more {
+		return nil, errors.New("unexpected server challenge")
+	}
+	return nil, nil
+}
+
+// loginAuth LOGIN 认证
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(_ *smtp.ServerInfo) (string, []byte, error) {
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
+
+// sendEmailByConfig 根据配置发送邮件通知，data 为空时（测试通知、报告）不渲染 HTML 模板
+func (n *Notifier) sendEmailByConfig(ctx context.Context, config map[string]interface{}, message string, data *MessageTemplateData) error {
+	cfg, err := parseEmailConfig(config)
+	if err != nil {
+		return err
+	}
+
+	subject, err := renderEmailSubject(cfg.Subject, data)
+	if err != nil {
+		n.logger.Warn("渲染邮件主题失败，使用默认主题", zap.Error(err))
+		subject = defaultEmailSubject
+	}
+
+	htmlBody, err := renderEmailHTML(cfg.HTMLTemplate, message, data)
+	if err != nil {
+		n.logger.Warn("渲染邮件 HTML 模板失败，使用默认格式", zap.Error(err))
+		htmlBody = plainToEmailHTML(message, levelColor(nil))
+	}
+
+	return n.sendEmail(ctx, cfg, subject, message, htmlBody)
+}
+
+// validateEmailTemplates 校验邮件渠道的主题模板和 HTML 模板
+func validateEmailTemplates(config map[string]interface{}) error {
+	if subject := configString(config, "subject", ""); subject != "" {
+		if _, err := parseMessageTemplate(subject); err != nil {
+			return fmt.Errorf("邮件主题模板错误: %w", err)
+		}
+	}
+	if text := configString(config, "htmlTemplate", ""); text != "" {
+		if _, err := htmltemplate.New("email").Parse(text); err != nil {
+			return fmt.Errorf("邮件 HTML 模板错误: %w", err)
+		}
+	}
+	return nil
+}
+
+// testEmailTemplateData 测试通知使用的模板变量
+func testEmailTemplateData(message string) *MessageTemplateData {
+	agent := &models.Agent{
+		ID:       "test-agent",
+		Name:     "测试探针",
+		Hostname: "test-host",
+		IPv4:     "127.0.0.1",
+	}
+	record := &models.AlertRecord{
+		AlertType: "test",
+		Level:     "info",
+		Status:    "firing",
+		Message:   message,
+		FiredAt:   time.Now().UnixMilli(),
+	}
+	data := newMessageTemplateData(agent, record, false, "")
+	return &data
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// smtpMessage SMTP 替身收到的邮件
+type smtpMessage struct {
+	From       string
+	Recipients []string
+	Data       string
+	TLS        bool
+	Authed     bool
+}
+
+// smtpStandIn 本地 SMTP 替身服务器
+type smtpStandIn struct {
+	Port     int
+	messages chan smtpMessage
+
+	implicitTLS bool
+	startTLS    bool
+	authMechs   string // 为空时不支持认证
+	username    string
+	password    string
+	tlsConfig   *tls.Config
+}
+
+// testTLSConfig 使用 httptest 自带的测试证书
+func testTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	return &tls.Config{Certificates: server.TLS.Certificates}
+}
+
+func newSMTPStandIn(t *testing.T, configure func(s *smtpStandIn)) *smtpStandIn {
+	t.Helper()
+	s := &smtpStandIn{
+		messages: make(chan smtpMessage, 1),
+	}
+	if configure != nil {
+		configure(s)
+	}
+	if s.implicitTLS || s.startTLS {
+		s.tlsConfig = testTLSConfig(t)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("启动 SMTP 替身失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	s.Port = listener.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	msg := smtpMessage{}
+	if s.implicitTLS {
+		conn = tls.Server(conn, s.tlsConfig)
+		msg.TLS = true
+	}
+	tp := textproto.NewConn(conn)
+
+	reply := func(format string, args ...interface{}) {
+		_ = tp.PrintfLine(format, args...)
+	}
+	reply("220 localhost ESMTP stand-in")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			lines := []string{"localhost"}
+			if s.startTLS && !msg.TLS {
+				lines = append(lines, "STARTTLS")
+			}
+			if s.authMechs != "" {
+				lines = append(lines, "AUTH "+s.authMechs)
+			}
+			for i, l := range lines {
+				if i == len(lines)-1 {
+					reply("250 %s", l)
+				} else {
+					reply("250-%s", l)
+				}
+			}
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			conn = tls.Server(conn, s.tlsConfig)
+			tp = textproto.NewConn(conn)
+			msg.TLS = true
+		case "AUTH":
+			mech, initial, _ := strings.Cut(arg, " ")
+			var username, password string
+			switch strings.ToUpper(mech) {
+			case "PLAIN":
+				decoded, _ := base64.StdEncoding.DecodeString(initial)
+				parts := strings.Split(string(decoded), "\x00")
+				if len(parts) == 3 {
+					username, password = parts[1], parts[2]
+				}
+			case "LOGIN":
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Username:")))
+				u, _ := tp.ReadLine()
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Password:")))
+				p, _ := tp.ReadLine()
+				decodedUser, _ := base64.StdEncoding.DecodeString(u)
+				decodedPass, _ := base64.StdEncoding.DecodeString(p)
+				username, password = string(decodedUser), string(decodedPass)
+			}
+			if username == s.username && password == s.password {
+				msg.Authed = true
+				reply("235 authentication succeeded")
+			} else {
+				reply("535 authentication failed")
+			}
+		case "MAIL":
+			if s.authMechs != "" && !msg.Authed {
+				reply("530 authentication required")
+				continue
+			}
+			msg.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			msg.Recipients = append(msg.Recipients, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			reply("250 ok")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.messages <- msg
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// receive 等待替身收到邮件
+func (s *smtpStandIn) receive(t *testing.T) smtpMessage {
+	t.Helper()
+	select {
+	case msg := <-s.messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP 替身未收到邮件")
+		return smtpMessage{}
+	}
+}
+
+func (s *smtpStandIn) config(extra map[string]interface{}) map[string]interface{} {
+	config := map[string]interface{}{
+		"smtpHost":  "127.0.0.1",
+		"smtpPort":  float64(s.Port),
+		"fromEmail": "alert@example.com",
+		"toEmail":   "ops@example.com",
+	}
+	for k, v := range extra {
+		config[k] = v
+	}
+	return config
+}
+
+func sendEmailNotification(t *testing.T, config map[string]interface{}) error {
+	t.Helper()
+	n := newTestNotifier()
+	agent := &models.Agent{ID: "agent-1", Name: "测试探针", Hostname: "host-1", IPv4: "10.0.0.1"}
+	channel := &models.NotificationChannelConfig{Type: "email", Enabled: true, Config: config}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return n.SendNotificationByConfig(ctx, channel, testPushRecord("critical", "firing"), agent, false)
+}
+
+func TestSendEmailPlainWithoutAuth(t *testing.T) {
+	server := newSMTPStandIn(t, nil)
+
+	config := server.config(map[string]interface{}{
+		"tlsMode":  "none",
+		"fromName": "Pika 告警",
+		"toEmail":  []interface{}{"ops@example.com", "值班 <oncall@example.com>"},
+		"cc":       "lead@example.com",
+		"bcc":      "audit@example.com",
+		"subject":  "[{{.Level}}] {{.Agent.Name}} {{.AlertTypeName}}",
+	})
+	if err := sendEmailNotification(t, config); err != nil {
+		t.Fatalf("发送邮件失败: %v", err)
+	}
+
+	msg := server.receive(t)
+	if msg.TLS || msg.Authed {
+		t.Errorf("不加密且无密码时不应使用 TLS 和认证: %+v", msg)
+	}
+	if msg.From != "alert@example.com" {
+		t.Errorf("MAIL FROM = %s", msg.From)
+	}
+	wantRecipients := []string{"ops@example.com", "oncall@example.com", "lead@example.com", "audit@example.com"}
+	if !slices.Equal(msg.Recipients, wantRecipients) {
+		t.Errorf("RCPT TO = %v, want %v", msg.Recipients, wantRecipients)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.Data))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+	decoder := new(mime.WordDecoder)
+	subject, _ := decoder.DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != "[critical] 测试探针 CPU告警" {
+		t.Errorf("Subject = %q", subject)
+	}
+	from, err := parsed.Header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Name != "Pika 告警" || from[0].Address != "alert@example.com" {
+		t.Errorf("From = %v, %v", from, err)
+	}
+	if to, _ := parsed.Header.AddressList("To"); len(to) != 2 {
+		t.Errorf("To = %v", to)
+	}
+	if parsed.Header.Get("Cc") == "" {
+		t.Error("缺少 Cc 邮件头")
+	}
+	if parsed.Header.Get("Bcc") != "" {
+		t.Error("密送地址不应写入邮件头")
+	}
+
+	// 正文包含纯文本和 HTML 两部分
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %s, %v", mediaType, err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var partTypes []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("读取邮件正文失败: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		partTypes = append(partTypes, partType)
+		body, _ := io.ReadAll(part)
+		if partType == "text/html" && !strings.Contains(string(body), "<table") {
+			t.Errorf("HTML 正文应包含字段表格: %s", body)
+		}
+	}
+	if !slices.Equal(partTypes, []string{"text/plain", "text/html"}) {
+		t.Errorf("正文类型 = %v", partTypes)
+	}
+}
+
+func TestSendEmailStartTLSWithAuth(t *testing.T) {
+	server := newSMTPStandIn(t, func(s *smtpStandIn) {
+		s.startTLS = true
+		s.authMechs = "PLAIN LOGIN"
+		s.username = "alert@example.com"
+		s.password = "secret"
+	})
+
+	config := server.config(map[string]interface{}{
+		"tlsMode":    "starttls",
+		"skipVerify": true,
+		"password":   "secret",
+	})
+	if err := sendEmailNotification(t, config); err != nil {
+		t.Fatalf("发送邮件失败: %v", err)
+	}
+
+	msg := server.receive(t)
+	if !msg.TLS || !msg.Authed {
+		t.Errorf("应在 STARTTLS 后认证: %+v", msg)
+	}
+}
+
+func TestSendEmailImplicitTLSWithLoginAuth(t *testing.T) {
+	server := newSMTPStandIn(t, func(s *smtpStandIn) {
+		s.implicitTLS = true
+		s.authMechs = "LOGIN"
+		s.username = "smtp-user"
+		s.password = "secret"
+	})
+
+	config := server.config(map[string]interface{}{
+		"tlsMode":    "ssl",
+		"skipVerify": true,
+		"username":   "smtp-user",
+		"password":   "secret",
+	})
+	if err := sendEmailNotification(t, config); err != nil {
+		t.Fatalf("发送邮件失败: %v", err)
+	}
+
+	msg := server.receive(t)
+	if !msg.TLS || !msg.Authed {
+		t.Errorf("应使用隐式 TLS 和 LOGIN 认证: %+v", msg)
+	}
+}
+
+func TestSendEmailRequiresEncryptionForPassword(t *testing.T) {
+	server := newSMTPStandIn(t, func(s *smtpStandIn) {
+		s.authMechs = "PLAIN"
+		s.username = "alert@example.com"
+		s.password = "secret"
+	})
+
+	// 服务器不支持 STARTTLS 时不能在自动模式下明文发送密码
+	config := server.config(map[string]interface{}{"password": "secret"})
+	if err := sendEmailNotification(t, config); err == nil {
+		t.Fatal("未加密的连接不应发送密码")
+	}
+
+	// 强制 STARTTLS 时服务器不支持应返回错误
+	config = server.config(map[string]interface{}{"tlsMode": "starttls"})
+	if err := sendEmailNotification(t, config); err == nil {
+		t.Fatal("服务器不支持 STARTTLS 时应返回错误")
+	}
+
+	// 明确选择不加密时允许认证
+	config = server.config(map[string]interface{}{"tlsMode": "none", "password": "secret"})
+	if err := sendEmailNotification(t, config); err != nil {
+		t.Fatalf("发送邮件失败: %v", err)
+	}
+	if msg := server.receive(t); !msg.Authed {
+		t.Errorf("应完成认证: %+v", msg)
+	}
+}
+
+func TestParseEmailConfig(t *testing.T) {
+	base := map[string]interface{}{
+		"smtpHost":  "smtp.example.com",
+		"smtpPort":  "465",
+		"fromEmail": "alert@example.com",
+		"toEmail":   "a@example.com, b@example.com; c@example.com",
+	}
+
+	cfg, err := parseEmailConfig(base)
+	if err != nil {
+		t.Fatalf("parseEmailConfig() 失败: %v", err)
+	}
+	if cfg.TLSMode != EmailTLSModeSSL {
+		t.Errorf("465 端口默认应使用 ssl，实际 %q", cfg.TLSMode)
+	}
+	if len(cfg.To) != 3 {
+		t.Errorf("To = %v", cfg.To)
+	}
+	if cfg.Username != "alert@example.com" || cfg.Subject != defaultEmailSubject {
+		t.Errorf("默认值错误: %+v", cfg)
+	}
+
+	invalid := []map[string]interface{}{
+		{"toEmail": "not-an-address"},
+		{"toEmail": ""},
+		{"tlsMode": "tls1.3"},
+		{"smtpPort": "abc"},
+	}
+	for _, override := range invalid {
+		config := map[string]interface{}{}
+		for k, v := range base {
+			config[k] = v
+		}
+		for k, v := range override {
+			config[k] = v
+		}
+		if _, err := parseEmailConfig(config); err == nil {
+			t.Errorf("配置 %v 应返回错误", override)
+		}
+	}
+}
+
+func TestRenderEmailHTMLTemplate(t *testing.T) {
+	data := newMessageTemplateData(&models.Agent{Name: "<web-1>"}, testPushRecord("warning", "firing"), false, "")
+	body, err := renderEmailHTML(`<p>{{.Agent.Name}}</p><pre>{{.Text}}</pre>`, "CPU 95%", &data)
+	if err != nil {
+		t.Fatalf("渲染 HTML 模板失败: %v", err)
+	}
+	if body != "<p>&lt;web-1&gt;</p><pre>CPU 95%</pre>" {
+		t.Errorf("HTML = %s", body)
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// PagerDuty 严重级别: critical, error, warning, info
+var pagerDutySeverityMap = map[string]string{
+	"info":     "info",
+	"warning":  "warning",
+	"critical": "critical",
+}
+
+// Opsgenie 优先级: P1 ~ P5
+var opsgeniePriorityMap = map[string]string{
+	"info":     "P5",
+	"warning":  "P3",
+	"critical": "P1",
+}
+
+// IsOncallChannel 判断是否为值班系统渠道，值班系统按告警逐条触发和恢复，不接收合并后的消息
+func IsOncallChannel(channelType string) bool {
+	return channelType == "pagerduty" || channelType == "opsgenie"
+}
+
+// oncallDedupKey 生成稳定的去重键，与告警状态ID格式一致（agentId:configId:alertType），
+// 同一告警的触发和恢复使用相同的键；通知类告警每条都是独立事件，在末尾追加记录ID
+func oncallDedupKey(record *models.AlertRecord) string {
+	if record.StateID != "" {
+		return record.StateID
+	}
+	key := fmt.Sprintf("%s:global:%s", record.AgentID, record.AlertType)
+	if record.Status == "notice" {
+		id := record.ID
+		if id == 0 {
+			id = record.FiredAt
+		}
+		key = fmt.Sprintf("%s:%d", key, id)
+	}
+	return key
+}
+
+// oncallEventAction 恢复通知发送 resolve，其他发送 trigger
+func oncallEventAction(record *models.AlertRecord) string {
+	if record.Status == "resolved" {
+		return "resolve"
+	}
+	return "trigger"
+}
+
+// oncallSummary 生成告警摘要
+func oncallSummary(agent *models.Agent, record *models.AlertRecord) string {
+	summary := fmt.Sprintf("[%s] %s: %s", strings.ToUpper(record.Level), agent.Name, record.Message)
+	return truncateRunes(summary, 1000)
+}
+
+// oncallDetails 告警记录中的自定义字段
+func oncallDetails(agent *models.Agent, record *models.AlertRecord) map[string]interface{} {
+	details := map[string]interface{}{
+		"agentId":     record.AgentID,
+		"agentName":   agent.Name,
+		"hostname":    agent.Hostname,
+		"alertType":   record.AlertType,
+		"level":       record.Level,
+		"message":     record.Message,
+		"threshold":   record.Threshold,
+		"actualValue": record.ActualValue,
+		"firedAt":     time.UnixMilli(record.FiredAt).UTC().Format(time.RFC3339),
+	}
+	if record.PolicyName != "" {
+		details["policyName"] = record.PolicyName
+	}
+	if record.MonitorID != "" {
+		details["monitorId"] = record.MonitorID
+	}
+	if labels := record.Labels.Data(); len(labels) > 0 {
+		details["labels"] = labels
+	}
+	return details
+}
+
+// oncallSource 告警来源主机
+func oncallSource(agent *models.Agent) string {
+	if agent.Hostname != "" {
+		return agent.Hostname
+	}
+	if agent.Name != "" {
+		return agent.Name
+	}
+	return "pika"
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// sendPagerDuty 发送 PagerDuty Events API v2 事件
+func (n *Notifier) sendPagerDuty(ctx context.Context, server, routingKey string, agent *models.Agent, record *models.AlertRecord) error {
+	action := oncallEventAction(record)
+	body := map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": action,
+		"dedup_key":    oncallDedupKey(record),
+	}
+	if action == "trigger" {
+		severity, ok := pagerDutySeverityMap[record.Level]
+		if !ok {
+			severity = "info"
+		}
+		body["client"] = "Pika"
+		body["payload"] = map[string]interface{}{
+			"summary":        oncallSummary(agent, record),
+			"source":         oncallSource(agent),
+			"severity":       severity,
+			"timestamp":      time.UnixMilli(record.FiredAt).UTC().Format(time.RFC3339),
+			"component":      agent.Name,
+			"class":          record.AlertType,
+			"custom_details": oncallDetails(agent, record),
+		}
+	}
+
+	_, err := n.sendJSONRequest(ctx, strings.TrimRight(server, "/")+"/v2/enqueue", body)
+	return err
+}
+
+// sendOpsgenie 通过 Opsgenie Alert API 创建或关闭告警，使用去重键作为告警别名
+func (n *Notifier) sendOpsgenie(ctx context.Context, server, apiKey string, agent *models.Agent, record *models.AlertRecord) error {
+	server = strings.TrimRight(server, "/")
+	alias := oncallDedupKey(record)
+	headers := map[string]string{
+		"Authorization": "GenieKey " + apiKey,
+	}
+
+	var target string
+	var body map[string]interface{}
+	if oncallEventAction(record) == "resolve" {
+		target = fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", server, url.PathEscape(alias))
+		body = map[string]interface{}{
+			"source": "Pika",
+			"note":   record.Message,
+		}
+	} else {
+		priority, ok := opsgeniePriorityMap[record.Level]
+		if !ok {
+			priority = "P5"
+		}
+		details := map[string]string{}
+		for k, v := range oncallDetails(agent, record) {
+			details[k] = fmt.Sprint(v)
+		}
+		target = server + "/v2/alerts"
+		body = map[string]interface{}{
+			// message 最长 130 个字符
+			"message":     truncateRunes(oncallSummary(agent, record), 130),
+			"alias":       alias,
+			"description": record.Message,
+			"priority":    priority,
+			"source":      oncallSource(agent),
+			"entity":      agent.Name,
+			"tags":        []string{"pika", record.AlertType, record.Level},
+			"details":     details,
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+	return n.sendHTTPRequest(ctx, "POST", target, bytes.NewReader(data), headers, "application/json")
+}
+
+// sendOncallEvent 发送值班事件。通知类告警（流量、SSH登录、防篡改）不会恢复，
+// 以 info 级别触发后立即恢复，避免在值班系统中遗留无法关闭的事件
+func sendOncallEvent(record *models.AlertRecord, send func(record *models.AlertRecord) error) error {
+	if record.Status != "notice" {
+		return send(record)
+	}
+	notice := *record
+	notice.Level = "info"
+	// 恢复事件沿用触发时的去重键
+	notice.StateID = oncallDedupKey(record)
+	if err := send(&notice); err != nil {
+		return err
+	}
+	notice.Status = "resolved"
+	return send(&notice)
+}
+
+// sendPagerDutyByConfig 根据配置发送 PagerDuty 事件
+func (n *Notifier) sendPagerDutyByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) error {
+	routingKey := configString(config, "routingKey", "")
+	if routingKey == "" {
+		return fmt.Errorf("PagerDuty 配置缺少 routingKey")
+	}
+	server := configString(config, "server", "https://events.pagerduty.com")
+	return sendOncallEvent(record, func(record *models.AlertRecord) error {
+		return n.sendPagerDuty(ctx, server, routingKey, agent, record)
+	})
+}
+
+// sendOpsgenieByConfig 根据配置发送 Opsgenie 告警
+func (n *Notifier) sendOpsgenieByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) error {
+	apiKey := configString(config, "apiKey", "")
+	if apiKey == "" {
+		return fmt.Errorf("Opsgenie 配置缺少 apiKey")
+	}
+	server := configString(config, "server", "https://api.opsgenie.com")
+	return sendOncallEvent(record, func(record *models.AlertRecord) error {
+		return n.sendOpsgenie(ctx, server, apiKey, agent, record)
+	})
+}
+
+// sendOncallTestNotification 发送测试事件，触发后立即恢复，避免在值班系统中遗留未关闭的事件
+func (n *Notifier) sendOncallTestNotification(ctx context.Context, channelType string, config map[string]interface{}, message string) error {
+	agent := &models.Agent{
+		ID:       "test-agent",
+		Name:     "测试探针",
+		Hostname: "test-host",
+	}
+	record := &models.AlertRecord{
+		AgentID:   agent.ID,
+		AgentName: agent.Name,
+		StateID:   "test-agent:global:test",
+		AlertType: "test",
+		Level:     "info",
+		Status:    "firing",
+		Message:   message,
+		FiredAt:   time.Now().UnixMilli(),
+	}
+
+	send := n.sendPagerDutyByConfig
+	if channelType == "opsgenie" {
+		send = n.sendOpsgenieByConfig
+	}
+	if err := send(ctx, config, agent, record); err != nil {
+		return err
+	}
+	record.Status = "resolved"
+	record.ResolvedAt = time.Now().UnixMilli()
+	return send(ctx, config, agent, record)
+}
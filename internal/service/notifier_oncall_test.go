@@ -0,0 +1,112 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestOncallDedupKey(t *testing.T) {
+	firing := testPushRecord("warning", "firing")
+	firing.StateID = "agent-1:global:cpu"
+	resolved := *firing
+	resolved.Status = "resolved"
+	if oncallDedupKey(firing) != "agent-1:global:cpu" || oncallDedupKey(&resolved) != oncallDedupKey(firing) {
+		t.Errorf("触发和恢复应使用告警状态ID作为去重键: %s, %s", oncallDedupKey(firing), oncallDedupKey(&resolved))
+	}
+
+	// 通知类告警每条独立
+	first := &models.AlertRecord{ID: 1, AgentID: "agent-1", AlertType: "ssh_login", Status: "notice"}
+	second := &models.AlertRecord{ID: 2, AgentID: "agent-1", AlertType: "ssh_login", Status: "notice"}
+	if oncallDedupKey(first) != "agent-1:global:ssh_login:1" {
+		t.Errorf("dedup key = %s", oncallDedupKey(first))
+	}
+	if oncallDedupKey(first) == oncallDedupKey(second) {
+		t.Error("不同的通知类告警不应使用相同的去重键")
+	}
+}
+
+func TestSendPagerDutyTriggerAndResolve(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"status":"success"}`)
+	config := map[string]interface{}{"server": server.URL, "routingKey": "rk"}
+
+	record := testPushRecord("critical", "firing")
+	record.StateID = "agent-1:global:cpu"
+	if err := sendPushNotification(t, "pagerduty", config, record); err != nil {
+		t.Fatalf("发送触发事件失败: %v", err)
+	}
+	record.Status = "resolved"
+	if err := sendPushNotification(t, "pagerduty", config, record); err != nil {
+		t.Fatalf("发送恢复事件失败: %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("请求数 = %d, want 2", len(requests))
+	}
+	trigger, resolve := requests[0], requests[1]
+	if trigger.Path != "/v2/enqueue" || trigger.Body["event_action"] != "trigger" || trigger.Body["dedup_key"] != "agent-1:global:cpu" {
+		t.Errorf("触发事件错误: %s %v", trigger.Path, trigger.Body)
+	}
+	if payload, _ := trigger.Body["payload"].(map[string]interface{}); payload["severity"] != "critical" {
+		t.Errorf("severity = %v", payload["severity"])
+	}
+	if resolve.Body["event_action"] != "resolve" || resolve.Body["dedup_key"] != "agent-1:global:cpu" {
+		t.Errorf("恢复事件错误: %v", resolve.Body)
+	}
+	if _, ok := resolve.Body["payload"]; ok {
+		t.Error("恢复事件不应包含 payload")
+	}
+}
+
+func TestSendPagerDutyNotice(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"status":"success"}`)
+	config := map[string]interface{}{"server": server.URL, "routingKey": "rk"}
+
+	record := &models.AlertRecord{ID: 7, AgentID: "agent-1", AlertType: "ssh_login", Level: "warning", Status: "notice", Message: "SSH登录", FiredAt: 1700000000000}
+	if err := sendPushNotification(t, "pagerduty", config, record); err != nil {
+		t.Fatalf("发送通知失败: %v", err)
+	}
+
+	// 通知类告警以 info 级别触发后立即恢复
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("请求数 = %d, want 2", len(requests))
+	}
+	trigger, resolve := requests[0], requests[1]
+	if trigger.Body["event_action"] != "trigger" || resolve.Body["event_action"] != "resolve" {
+		t.Errorf("event_action = %v, %v", trigger.Body["event_action"], resolve.Body["event_action"])
+	}
+	if payload, _ := trigger.Body["payload"].(map[string]interface{}); payload["severity"] != "info" {
+		t.Errorf("severity = %v, want info", payload["severity"])
+	}
+	if trigger.Body["dedup_key"] != "agent-1:global:ssh_login:7" || resolve.Body["dedup_key"] != trigger.Body["dedup_key"] {
+		t.Errorf("dedup_key = %v, %v", trigger.Body["dedup_key"], resolve.Body["dedup_key"])
+	}
+	if record.Status != "notice" || record.Level != "warning" {
+		t.Error("不应修改原告警记录")
+	}
+}
+
+func TestSendOpsgenieNotice(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"status":"success"}`)
+	config := map[string]interface{}{"server": server.URL, "apiKey": "key"}
+
+	record := &models.AlertRecord{ID: 3, AgentID: "agent-1", AlertType: "tamper", Level: "critical", Status: "notice", Message: "文件被修改", FiredAt: 1700000000000}
+	if err := sendPushNotification(t, "opsgenie", config, record); err != nil {
+		t.Fatalf("发送通知失败: %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("请求数 = %d, want 2", len(requests))
+	}
+	create, closeReq := requests[0], requests[1]
+	if create.Path != "/v2/alerts" || create.Body["priority"] != "P5" || create.Body["alias"] != "agent-1:global:tamper:3" {
+		t.Errorf("创建告警错误: %s %v", create.Path, create.Body)
+	}
+	if closeReq.Path != "/v2/alerts/agent-1:global:tamper:3/close" || closeReq.Query != "identifierType=alias" {
+		t.Errorf("关闭告警错误: %s?%s", closeReq.Path, closeReq.Query)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// pushLevel 推送渠道使用的级别，恢复通知单独处理
+func pushLevel(record *models.AlertRecord) string {
+	if record == nil {
+		return "info"
+	}
+	if record.Status == "resolved" {
+		return "resolved"
+	}
+	return record.Level
+}
+
+// ntfy 优先级: 1 min ~ 5 max
+var ntfyPriorityMap = map[string]int{
+	"resolved": 3,
+	"info":     3,
+	"warning":  4,
+	"critical": 5,
+}
+
+// Gotify 优先级: 0 ~ 10，8 及以上在客户端会弹出提醒
+var gotifyPriorityMap = map[string]int{
+	"resolved": 2,
+	"info":     2,
+	"warning":  5,
+	"critical": 8,
+}
+
+// Bark 中断级别: passive, active, timeSensitive, critical
+var barkLevelMap = map[string]string{
+	"resolved": "active",
+	"info":     "passive",
+	"warning":  "timeSensitive",
+	"critical": "critical",
+}
+
+// ntfy 标签会显示为 emoji
+var ntfyTagMap = map[string]string{
+	"resolved": "white_check_mark",
+	"info":     "information_source",
+	"warning":  "warning",
+	"critical": "rotating_light",
+}
+
+// splitTitle 拆分消息的标题（第一行）和正文
+func splitTitle(message string) (string, string) {
+	title, body, _ := strings.Cut(message, "\n")
+	body = strings.TrimLeft(body, "\n")
+	if body == "" {
+		body = title
+	}
+	return title, body
+}
+
+// configString 读取字符串配置，为空时使用默认值
+func configString(config map[string]interface{}, key, defaultValue string) string {
+	if v, ok := config[key].(string); ok && v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// configStrings 读取字符串数组配置，兼容逗号分隔的字符串
+func configStrings(config map[string]interface{}, key string) []string {
+	var values []string
+	switch v := config[key].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	case []string:
+		values = append(values, v...)
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// sendNtfyByConfig 根据配置发送 ntfy 通知
+// 配置: { "server": "https://ntfy.sh", "topic": "xxx", "token": "xxx", "tags": ["pika"] }
+func (n *Notifier) sendNtfyByConfig(ctx context.Context, config map[string]interface{}, message, level string) error {
+	topic := configString(config, "topic", "")
+	if topic == "" {
+		return fmt.Errorf("ntfy 配置缺少 topic")
+	}
+	server := strings.TrimRight(configString(config, "server", "https://ntfy.sh"), "/")
+
+	title, body := splitTitle(message)
+	tags := []string{ntfyTagMap[level]}
+	tags = append(tags, configStrings(config, "tags")...)
+
+	payload := map[string]interface{}{
+		"topic":    topic,
+		"title":    title,
+		"message":  body,
+		"priority": ntfyPriorityMap[level],
+		"tags":     tags,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	headers := map[string]string{}
+	if token := configString(config, "token", ""); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	// JSON 方式发布需要发送到服务根路径
+	return n.sendHTTPRequest(ctx, "POST", server+"/", bytes.NewReader(data), headers, "application/json")
+}
+
+// sendGotifyByConfig 根据配置发送 Gotify 通知
+// 配置: { "server": "https://gotify.example.com", "appToken": "xxx" }
+func (n *Notifier) sendGotifyByConfig(ctx context.Context, config map[string]interface{}, message, level string) error {
+	server := configString(config, "server", "")
+	if server == "" {
+		return fmt.Errorf("Gotify 配置缺少 server")
+	}
+	appToken := configString(config, "appToken", "")
+	if appToken == "" {
+		return fmt.Errorf("Gotify 配置缺少 appToken")
+	}
+
+	title, body := splitTitle(message)
+	payload := map[string]interface{}{
+		"title":    title,
+		"message":  body,
+		"priority": gotifyPriorityMap[level],
+	}
+	webhookURL := fmt.Sprintf("%s/message?token=%s", strings.TrimRight(server, "/"), url.QueryEscape(appToken))
+	_, err := n.sendJSONRequest(ctx, webhookURL, payload)
+	return err
+}
+
+// sendBarkByConfig 根据配置发送 Bark 通知
+// 配置: { "server": "https://api.day.app", "deviceKey": "xxx", "sound": "alarm", "group": "pika" }
+func (n *Notifier) sendBarkByConfig(ctx context.Context, config map[string]interface{}, message, level string) error {
+	deviceKey := configString(config, "deviceKey", "")
+	if deviceKey == "" {
+		return fmt.Errorf("Bark 配置缺少 deviceKey")
+	}
+	server := strings.TrimRight(configString(config, "server", "https://api.day.app"), "/")
+
+	title, body := splitTitle(message)
+	payload := map[string]interface{}{
+		"device_key": deviceKey,
+		"title":      title,
+		"body":       body,
+		"level":      barkLevelMap[level],
+		"group":      configString(config, "group", "Pika"),
+	}
+	if sound := configString(config, "sound", ""); sound != "" {
+		payload["sound"] = sound
+	}
+
+	result, err := n.sendJSONRequest(ctx, server+"/push", payload)
+	if err != nil {
+		return err
+	}
+	var barkResult struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(result, &barkResult); err == nil && barkResult.Code != 200 {
+		return fmt.Errorf("Bark 推送失败: %s", barkResult.Message)
+	}
+	return nil
+}
+
+// sendServerChanByConfig 根据配置发送 Server 酱通知，Server 酱没有优先级，级别图标体现在标题中
+// 配置: { "sendKey": "xxx", "server": "https://sctapi.ftqq.com" }
+func (n *Notifier) sendServerChanByConfig(ctx context.Context, config map[string]interface{}, message string) error {
+	sendKey := configString(config, "sendKey", "")
+	if sendKey == "" {
+		return fmt.Errorf("Server 酱配置缺少 sendKey")
+	}
+	server := strings.TrimRight(configString(config, "server", "https://sctapi.ftqq.com"), "/")
+
+	title, body := splitTitle(message)
+	payload := map[string]string{
+		"title": title,
+		// desp 支持 Markdown，两个换行才会分段
+		"desp": strings.ReplaceAll(body, "\n", "\n\n"),
+	}
+
+	result, err := n.sendJSONRequest(ctx, fmt.Sprintf("%s/%s.send", server, url.PathEscape(sendKey)), payload)
+	if err != nil {
+		return err
+	}
+	var serverChanResult struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(result, &serverChanResult); err == nil && serverChanResult.Code != 0 {
+		return fmt.Errorf("Server 酱推送失败: %s", serverChanResult.Message)
+	}
+	return nil
+}
+
+// sendPushPlusByConfig 根据配置发送 PushPlus 通知，PushPlus 没有优先级，级别图标体现在标题中
+// 配置: { "token": "xxx", "topic": "群组编码", "server": "https://www.pushplus.plus" }
+func (n *Notifier) sendPushPlusByConfig(ctx context.Context, config map[string]interface{}, message string) error {
+	token := configString(config, "token", "")
+	if token == "" {
+		return fmt.Errorf("PushPlus 配置缺少 token")
+	}
+	server := strings.TrimRight(configString(config, "server", "https://www.pushplus.plus"), "/")
+
+	title, body := splitTitle(message)
+	payload := map[string]string{
+		"token":    token,
+		"title":    title,
+		"content":  body,
+		"template": "txt",
+	}
+	if topic := configString(config, "topic", ""); topic != "" {
+		payload["topic"] = topic
+	}
+
+	result, err := n.sendJSONRequest(ctx, server+"/send", payload)
+	if err != nil {
+		return err
+	}
+	var pushPlusResult struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(result, &pushPlusResult); err == nil && pushPlusResult.Code != 200 {
+		return fmt.Errorf("PushPlus 推送失败: %s", pushPlusResult.Msg)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func newTestNotifier() *Notifier {
+	return &Notifier{logger: zap.NewNop()}
+}
+
+func testPushRecord(level, status string) *models.AlertRecord {
+	return &models.AlertRecord{
+		AgentID:     "agent-1",
+		AgentName:   "测试探针",
+		AlertType:   "cpu",
+		Message:     "CPU使用率过高",
+		Threshold:   80,
+		ActualValue: 95,
+		Level:       level,
+		Status:      status,
+		FiredAt:     1700000000000,
+	}
+}
+
+func sendPushNotification(t *testing.T, channelType string, config map[string]interface{}, record *models.AlertRecord) error {
+	t.Helper()
+	n := newTestNotifier()
+	agent := &models.Agent{ID: "agent-1", Name: "测试探针", Hostname: "host-1", IPv4: "10.0.0.1"}
+	channel := &models.NotificationChannelConfig{Type: channelType, Enabled: true, Config: config}
+	return n.SendNotificationByConfig(context.Background(), channel, record, agent, false)
+}
+
+func TestSendNtfy(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"id":"1"}`)
+
+	config := map[string]interface{}{
+		"server": server.URL,
+		"topic":  "alerts",
+		"token":  "tk_123",
+		"tags":   []interface{}{"pika"},
+	}
+	if err := sendPushNotification(t, "ntfy", config, testPushRecord("critical", "firing")); err != nil {
+		t.Fatalf("发送 ntfy 通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	if captured.Method != http.MethodPost || captured.Path != "/" {
+		t.Errorf("请求应发送到根路径，实际 %s %s", captured.Method, captured.Path)
+	}
+	if got := captured.Header.Get("Authorization"); got != "Bearer tk_123" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if captured.Body["topic"] != "alerts" {
+		t.Errorf("topic = %v", captured.Body["topic"])
+	}
+	if captured.Body["priority"] != float64(5) {
+		t.Errorf("critical 应映射为优先级 5，实际 %v", captured.Body["priority"])
+	}
+	tags, _ := captured.Body["tags"].([]interface{})
+	if len(tags) != 2 || tags[0] != "rotating_light" || tags[1] != "pika" {
+		t.Errorf("tags = %v", captured.Body["tags"])
+	}
+	if captured.Body["title"] == "" || captured.Body["message"] == "" {
+		t.Errorf("标题和正文不能为空: %v", captured.Body)
+	}
+}
+
+func TestSendGotify(t *testing.T) {
+	tests := []struct {
+		level    string
+		status   string
+		priority float64
+	}{
+		{"info", "firing", 2},
+		{"warning", "firing", 5},
+		{"critical", "firing", 8},
+		{"critical", "resolved", 2},
+	}
+
+	for _, tt := range tests {
+		server := newCaptureServer(t, http.StatusOK, `{"id":1}`)
+		config := map[string]interface{}{
+			"server":   server.URL,
+			"appToken": "app-token",
+		}
+		if err := sendPushNotification(t, "gotify", config, testPushRecord(tt.level, tt.status)); err != nil {
+			t.Fatalf("发送 Gotify 通知失败: %v", err)
+		}
+		captured := server.Last()
+		if captured.Path != "/message" || captured.Query != "token=app-token" {
+			t.Errorf("请求地址错误: %s?%s", captured.Path, captured.Query)
+		}
+		if captured.Body["priority"] != tt.priority {
+			t.Errorf("%s/%s 应映射为优先级 %v，实际 %v", tt.level, tt.status, tt.priority, captured.Body["priority"])
+		}
+	}
+}
+
+func TestSendBark(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"code":200,"message":"success"}`)
+
+	config := map[string]interface{}{
+		"server":    server.URL,
+		"deviceKey": "device-key",
+		"sound":     "alarm",
+		"group":     "ops",
+	}
+	if err := sendPushNotification(t, "bark", config, testPushRecord("warning", "firing")); err != nil {
+		t.Fatalf("发送 Bark 通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	if captured.Path != "/push" {
+		t.Errorf("path = %s", captured.Path)
+	}
+	if captured.Body["device_key"] != "device-key" || captured.Body["sound"] != "alarm" || captured.Body["group"] != "ops" {
+		t.Errorf("请求体错误: %v", captured.Body)
+	}
+	if captured.Body["level"] != "timeSensitive" {
+		t.Errorf("warning 应映射为 timeSensitive，实际 %v", captured.Body["level"])
+	}
+}
+
+func TestSendBarkError(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"code":400,"message":"failed to get device token"}`)
+
+	config := map[string]interface{}{
+		"server":    server.URL,
+		"deviceKey": "invalid",
+	}
+	if err := sendPushNotification(t, "bark", config, testPushRecord("info", "firing")); err == nil {
+		t.Fatal("Bark 返回错误码时应返回错误")
+	}
+}
+
+func TestSendServerChan(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"code":0,"message":""}`)
+
+	config := map[string]interface{}{
+		"server":  server.URL,
+		"sendKey": "SCT123",
+	}
+	if err := sendPushNotification(t, "serverchan", config, testPushRecord("critical", "firing")); err != nil {
+		t.Fatalf("发送 Server 酱通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	if captured.Path != "/SCT123.send" {
+		t.Errorf("path = %s", captured.Path)
+	}
+	if captured.Body["title"] == "" || captured.Body["desp"] == "" {
+		t.Errorf("请求体错误: %v", captured.Body)
+	}
+}
+
+func TestSendPushPlus(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"code":200,"msg":"请求成功"}`)
+
+	config := map[string]interface{}{
+		"server": server.URL,
+		"token":  "pp-token",
+		"topic":  "ops",
+	}
+	if err := sendPushNotification(t, "pushplus", config, testPushRecord("info", "firing")); err != nil {
+		t.Fatalf("发送 PushPlus 通知失败: %v", err)
+	}
+
+	captured := server.Last()
+	if captured.Path != "/send" {
+		t.Errorf("path = %s", captured.Path)
+	}
+	if captured.Body["token"] != "pp-token" || captured.Body["topic"] != "ops" || captured.Body["template"] != "txt" {
+		t.Errorf("请求体错误: %v", captured.Body)
+	}
+}
+
+func TestSendPushPlusError(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"code":903,"msg":"无效的用户token"}`)
+
+	config := map[string]interface{}{
+		"server": server.URL,
+		"token":  "invalid",
+	}
+	if err := sendPushNotification(t, "pushplus", config, testPushRecord("info", "firing")); err == nil {
+		t.Fatal("PushPlus 返回错误码时应返回错误")
+	}
+}
+
+func TestSendPushTestNotification(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"id":"1"}`)
+
+	n := newTestNotifier()
+	config := map[string]interface{}{
+		"server": server.URL,
+		"topic":  "alerts",
+	}
+	if err := n.SendTestNotification(context.Background(), "ntfy", config, "这是一条测试通知消息"); err != nil {
+		t.Fatalf("发送测试通知失败: %v", err)
+	}
+	captured := server.Last()
+	if captured.Body["message"] != "这是一条测试通知消息" {
+		t.Errorf("message = %v", captured.Body["message"])
+	}
+	if captured.Body["priority"] != float64(3) {
+		t.Errorf("测试通知应使用默认优先级 3，实际 %v", captured.Body["priority"])
+	}
+}
+
+func TestPushChannelMissingConfig(t *testing.T) {
+	for _, channelType := range []string{"ntfy", "gotify", "bark", "serverchan", "pushplus"} {
+		err := sendPushNotification(t, channelType, map[string]interface{}{}, testPushRecord("info", "firing"))
+		if err == nil {
+			t.Errorf("%s 缺少必填配置时应返回错误", channelType)
+		}
+	}
+}
+
+func TestSendMessageByConfigUsesRecordLevel(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, `{"id":"1"}`)
+
+	n := newTestNotifier()
+	channel := &models.NotificationChannelConfig{
+		Type:   "ntfy",
+		Config: map[string]interface{}{"server": server.URL, "topic": "alerts"},
+	}
+	// 合并消息按代表告警的级别推送
+	if err := n.sendMessageByConfig(context.Background(), channel, "合并告警", testPushRecord("critical", "firing"), nil); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	captured := server.Last()
+	if captured.Body["priority"] != float64(5) {
+		t.Errorf("严重告警应使用优先级 5，实际 %v", captured.Body["priority"])
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/pkg/webhook"
+)
+
+func TestSendCustomWebhookSigned(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, "")
+
+	config := map[string]interface{}{
+		"url":        server.URL,
+		"customBody": `{"message":"{{message}}"}`,
+		"secret":     "s3cret",
+	}
+	n := newTestNotifier()
+	agent := &models.Agent{ID: "agent-1", Name: "测试探针"}
+	if err := n.sendCustomWebhook(context.Background(), config, agent, testPushRecord("critical", "firing"), "CPU使用率过高", false); err != nil {
+		t.Fatalf("发送 Webhook 失败: %v", err)
+	}
+
+	captured := server.Last()
+	if err := webhook.Verify("s3cret", captured.Header, []byte(captured.Raw), 0); err != nil {
+		t.Errorf("签名校验失败: %v", err)
+	}
+	if captured.Header.Get(webhook.HeaderDelivery) == "" {
+		t.Error("缺少投递ID")
+	}
+}
+
+func TestSendCustomWebhookUniqueDelivery(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, "")
+
+	config := map[string]interface{}{
+		"url":        server.URL,
+		"customBody": `{}`,
+		"secret":     "s3cret",
+	}
+	n := newTestNotifier()
+	agent := &models.Agent{ID: "agent-1"}
+	record := testPushRecord("info", "firing")
+
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		if err := n.sendCustomWebhook(context.Background(), config, agent, record, "", false); err != nil {
+			t.Fatalf("发送 Webhook 失败: %v", err)
+		}
+		id := server.Last().Header.Get(webhook.HeaderDelivery)
+		if seen[id] {
+			t.Fatalf("投递ID重复: %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSendCustomWebhookUnsigned(t *testing.T) {
+	server := newCaptureServer(t, http.StatusOK, "")
+
+	config := map[string]interface{}{
+		"url":        server.URL,
+		"customBody": `{}`,
+		"headers":    map[string]interface{}{"X-Token": "abc"},
+	}
+	n := newTestNotifier()
+	if err := n.sendCustomWebhook(context.Background(), config, &models.Agent{}, testPushRecord("info", "firing"), "", false); err != nil {
+		t.Fatalf("发送 Webhook 失败: %v", err)
+	}
+
+	captured := server.Last()
+	if captured.Header.Get(webhook.HeaderSignature) != "" {
+		t.Error("未配置密钥时不应携带签名")
+	}
+	if captured.Header.Get("X-Token") != "abc" {
+		t.Errorf("自定义请求头丢失: %v", captured.Header)
+	}
+}
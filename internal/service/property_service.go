@@ -28,6 +28,10 @@ const (
 	PropertyIDAlertConfig = "alert_config"
 	// PropertyIDDNSProviders DNS 服务商配置的固定 ID
 	PropertyIDDNSProviders = "dns_providers"
+	// PropertyIDSLAReportConfig 可用性报告定时发送配置的固定 ID
+	PropertyIDSLAReportConfig = "sla_report_config"
+	// PropertyIDNotificationRouting 通知路由配置的固定 ID
+	PropertyIDNotificationRouting = "notification_routing"
 )
 
 var defaultPublicIPv4APIs = []string{
@@ -239,6 +243,29 @@ func (s *PropertyService) SetAlertConfig(ctx context.Context, config models.Aler
 	return s.Set(ctx, PropertyIDAlertConfig, "告警配置", config)
 }
 
+// GetSLAReportConfig 获取可用性报告定时发送配置
+func (s *PropertyService) GetSLAReportConfig(ctx context.Context) (*models.SLAReportConfig, error) {
+	var config models.SLAReportConfig
+	if err := s.GetValue(ctx, PropertyIDSLAReportConfig, &config); err != nil {
+		return nil, fmt.Errorf("获取可用性报告配置失败: %w", err)
+	}
+	return &config, nil
+}
+
+// SetSLAReportConfig 设置可用性报告定时发送配置
+func (s *PropertyService) SetSLAReportConfig(ctx context.Context, config models.SLAReportConfig) error {
+	return s.Set(ctx, PropertyIDSLAReportConfig, "可用性报告配置", config)
+}
+
+// GetNotificationRoutingConfig 获取通知路由配置
+func (s *PropertyService) GetNotificationRoutingConfig(ctx context.Context) (*models.NotificationRoutingConfig, error) {
+	var config models.NotificationRoutingConfig
+	if err := s.GetValue(ctx, PropertyIDNotificationRouting, &config); err != nil {
+		return nil, fmt.Errorf("获取通知路由配置失败: %w", err)
+	}
+	return &config, nil
+}
+
 // GetDNSProviderConfigs 获取 DNS 服务商配置列表
 func (s *PropertyService) GetDNSProviderConfigs(ctx context.Context) ([]models.DNSProviderConfig, error) {
 	var providers []models.DNSProviderConfig
@@ -366,26 +393,6 @@ func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 					SSHLoginSuccessEnabled: true,
 					TamperEventEnabled:     true,
 				},
-				Rules: models.AlertRules{
-					CPUEnabled:           true,
-					CPUThreshold:         80,
-					CPUDuration:          300, // 5分钟
-					MemoryEnabled:        true,
-					MemoryThreshold:      80,
-					MemoryDuration:       300, // 5分钟
-					DiskEnabled:          true,
-					DiskThreshold:        85,
-					DiskDuration:         300, // 5分钟
-					NetworkEnabled:       false,
-					NetworkThreshold:     100,
-					NetworkDuration:      300, // 5分钟
-					CertEnabled:          true,
-					CertThreshold:        30, // 30天
-					ServiceEnabled:       true,
-					ServiceDuration:      300, // 5分钟
-					AgentOfflineEnabled:  true,
-					AgentOfflineDuration: 300, // 5分钟
-				},
 			},
 		},
 		{
@@ -393,6 +400,25 @@ func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 			Name:  "DNS 服务商配置",
 			Value: []models.DNSProviderConfig{}, // 默认为空数组
 		},
+		{
+			ID:   PropertyIDSLAReportConfig,
+			Name: "可用性报告配置",
+			Value: models.SLAReportConfig{
+				Enabled:  false,
+				Schedule: models.SLAReportScheduleMonthly,
+				Hour:     9,
+				ToEmails: []string{},
+			},
+		},
+		{
+			ID:   PropertyIDNotificationRouting,
+			Name: "通知路由配置",
+			Value: models.NotificationRoutingConfig{
+				Enabled:         false,
+				Rules:           []models.NotificationRoute{},
+				DefaultChannels: []string{},
+			},
+		},
 	}
 
 	// 遍历并初始化每个配置
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/cache"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// silenceCacheKey 生效中静默的缓存键
+const silenceCacheKey = "active"
+
+// silenceCacheTTL 生效中静默的缓存时间，静默的开始和结束时间精确到该粒度
+const silenceCacheTTL = 10 * time.Second
+
+// SilenceService 告警静默服务
+type SilenceService struct {
+	logger          *zap.Logger
+	SilenceRepo     *repo.SilenceRepo
+	alertRecordRepo *repo.AlertRecordRepo
+
+	activeCache cache.Cache[string, []models.Silence]
+}
+
+func NewSilenceService(logger *zap.Logger, db *gorm.DB) *SilenceService {
+	return &SilenceService{
+		logger:          logger,
+		SilenceRepo:     repo.NewSilenceRepo(db),
+		alertRecordRepo: repo.NewAlertRecordRepo(db),
+		activeCache:     cache.New[string, []models.Silence](time.Minute),
+	}
+}
+
+// SilenceRequest 创建/更新静默请求
+type SilenceRequest struct {
+	Matchers []models.SilenceMatcher `json:"matchers"`
+	StartsAt int64                   `json:"startsAt"` // 为空时立即开始
+	EndsAt   int64                   `json:"endsAt"`
+	Comment  string                  `json:"comment"`
+}
+
+// SilenceView 静默列表项
+type SilenceView struct {
+	models.Silence
+	State           string `json:"state"`           // 状态: pending, active, expired
+	SuppressedCount int64  `json:"suppressedCount"` // 已抑制的告警数量
+}
+
+var silenceMatcherNames = []string{
+	models.SilenceMatcherAlertType,
+	models.SilenceMatcherAgentID,
+	models.SilenceMatcherTag,
+	models.SilenceMatcherMonitorID,
+	models.SilenceMatcherLevel,
+}
+
+// ValidateSilence 校验静默配置
+func ValidateSilence(req *SilenceRequest) error {
+	if len(req.Matchers) == 0 {
+		return errors.New("至少需要一个匹配条件")
+	}
+	for i := range req.Matchers {
+		matcher := &req.Matchers[i]
+		if !slices.Contains(silenceMatcherNames, matcher.Name) {
+			return fmt.Errorf("不支持的匹配字段: %s", matcher.Name)
+		}
+		if matcher.Operator == "" {
+			matcher.Operator = models.SilenceOperatorEqual
+		}
+		switch matcher.Operator {
+		case models.SilenceOperatorEqual, models.SilenceOperatorNotEqual:
+		case models.SilenceOperatorRegex:
+			if _, err := regexp.Compile("^(?:" + matcher.Value + ")$"); err != nil {
+				return fmt.Errorf("正则表达式错误: %w", err)
+			}
+		default:
+			return fmt.Errorf("不支持的运算符: %s", matcher.Operator)
+		}
+	}
+	if req.StartsAt == 0 {
+		req.StartsAt = time.Now().UnixMilli()
+	}
+	if req.EndsAt <= req.StartsAt {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+	return nil
+}
+
+// CreateSilence 创建静默
+func (s *SilenceService) CreateSilence(ctx context.Context, req *SilenceRequest, creator string) (*models.Silence, error) {
+	now := time.Now().UnixMilli()
+	silence := &models.Silence{
+		ID:        uuid.NewString(),
+		Matchers:  req.Matchers,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedBy: creator,
+		Comment:   req.Comment,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.SilenceRepo.Create(ctx, silence); err != nil {
+		return nil, err
+	}
+	s.activeCache.Delete(silenceCacheKey)
+	return silence, nil
+}
+
+// UpdateSilence 更新静默，已过期的静默不能修改
+func (s *SilenceService) UpdateSilence(ctx context.Context, id string, req *SilenceRequest) (*models.Silence, error) {
+	silence, err := s.SilenceRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if silence.State(time.Now().UnixMilli()) == models.SilenceStateExpired {
+		return nil, orz.NewError(400, "静默已过期，不能修改")
+	}
+
+	silence.Matchers = req.Matchers
+	silence.StartsAt = req.StartsAt
+	silence.EndsAt = req.EndsAt
+	silence.Comment = req.Comment
+	if err := s.SilenceRepo.Save(ctx, &silence); err != nil {
+		return nil, err
+	}
+	s.activeCache.Delete(silenceCacheKey)
+	return &silence, nil
+}
+
+// ExpireSilence 立即结束静默
+func (s *SilenceService) ExpireSilence(ctx context.Context, id string) (*models.Silence, error) {
+	silence, err := s.SilenceRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	if silence.State(now) == models.SilenceStateExpired {
+		return &silence, nil
+	}
+	silence.EndsAt = now
+	if silence.StartsAt > now {
+		silence.StartsAt = now
+	}
+	if err := s.SilenceRepo.Save(ctx, &silence); err != nil {
+		return nil, err
+	}
+	s.activeCache.Delete(silenceCacheKey)
+	return &silence, nil
+}
+
+// DeleteSilence 删除静默
+func (s *SilenceService) DeleteSilence(ctx context.Context, id string) error {
+	if err := s.SilenceRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	s.activeCache.Delete(silenceCacheKey)
+	return nil
+}
+
+// ListSilences 按状态查询静默及其抑制的告警数量
+func (s *SilenceService) ListSilences(ctx context.Context, state string) ([]SilenceView, error) {
+	now := time.Now().UnixMilli()
+	silences, err := s.SilenceRepo.FindByState(ctx, state, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(silences))
+	for _, silence := range silences {
+		ids = append(ids, silence.ID)
+	}
+	counts := map[string]int64{}
+	if len(ids) > 0 {
+		counts, err = s.alertRecordRepo.CountBySilenceIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	views := make([]SilenceView, 0, len(silences))
+	for _, silence := range silences {
+		views = append(views, SilenceView{
+			Silence:         silence,
+			State:           silence.State(now),
+			SuppressedCount: counts[silence.ID],
+		})
+	}
+	return views, nil
+}
+
+// listActive 获取生效中的静默（带缓存）
+func (s *SilenceService) listActive(ctx context.Context) []models.Silence {
+	if silences, ok := s.activeCache.Get(silenceCacheKey); ok {
+		return silences
+	}
+	silences, err := s.SilenceRepo.FindActive(ctx, time.Now().UnixMilli())
+	if err != nil {
+		s.logger.Error("查询生效中的静默失败", zap.Error(err))
+		return nil
+	}
+	s.activeCache.Set(silenceCacheKey, silences, silenceCacheTTL)
+	return silences
+}
+
+// Match 查找匹配告警记录的生效中静默，未匹配时返回 nil
+func (s *SilenceService) Match(ctx context.Context, record *models.AlertRecord, agent *models.Agent) *models.Silence {
+	now := time.Now().UnixMilli()
+	for _, silence := range s.listActive(ctx) {
+		// 缓存可能略有延迟，再次按时间校验
+		if silence.State(now) != models.SilenceStateActive {
+			continue
+		}
+		if matchSilence(&silence, record, agent) {
+			return &silence
+		}
+	}
+	return nil
+}
+
+// Apply 匹配生效中的静默，匹配时在告警记录上记录静默ID并返回 true
+func (s *SilenceService) Apply(ctx context.Context, record *models.AlertRecord, agent *models.Agent) bool {
+	silence := s.Match(ctx, record, agent)
+	if silence == nil {
+		return false
+	}
+	record.SuppressedBy = silence.ID
+	if record.SilenceID == "" {
+		record.SilenceID = silence.ID
+	}
+	return true
+}
+
+// IsActive 判断静默当前是否仍然生效
+func (s *SilenceService) IsActive(ctx context.Context, id string) bool {
+	now := time.Now().UnixMilli()
+	for _, silence := range s.listActive(ctx) {
+		if silence.ID == id && silence.State(now) == models.SilenceStateActive {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSilence 判断告警是否满足静默的全部匹配条件
+func matchSilence(silence *models.Silence, record *models.AlertRecord, agent *models.Agent) bool {
+	for _, matcher := range silence.Matchers {
+		var values []string
+		switch matcher.Name {
+		case models.SilenceMatcherAlertType:
+			values = []string{record.AlertType}
+		case models.SilenceMatcherAgentID:
+			values = []string{record.AgentID}
+		case models.SilenceMatcherTag:
+			if agent != nil {
+				values = agent.Tags
+			}
+		case models.SilenceMatcherMonitorID:
+			values = []string{record.MonitorID}
+		case models.SilenceMatcherLevel:
+			values = []string{record.Level}
+		default:
+			return false
+		}
+		if !matchSilenceValues(matcher, values) {
+			return false
+		}
+	}
+	return len(silence.Matchers) > 0
+}
+
+// matchSilenceValues 多值字段（标签）任一值满足 = 和 =~ 即匹配，!= 要求所有值都不相等
+func matchSilenceValues(matcher models.SilenceMatcher, values []string) bool {
+	switch matcher.Operator {
+	case models.SilenceOperatorNotEqual:
+		return !slices.Contains(values, matcher.Value)
+	case models.SilenceOperatorRegex:
+		re, err := regexp.Compile("^(?:" + matcher.Value + ")$")
+		if err != nil {
+			return false
+		}
+		return slices.ContainsFunc(values, re.MatchString)
+	default:
+		return slices.Contains(values, matcher.Value)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestMatchSilence(t *testing.T) {
+	record := &models.AlertRecord{AgentID: "a1", AlertType: "cpu", Level: "warning"}
+	agent := &models.Agent{ID: "a1", Tags: []string{"prod", "db"}}
+	m := func(name, operator, value string) models.SilenceMatcher {
+		return models.SilenceMatcher{Name: name, Operator: operator, Value: value}
+	}
+
+	tests := []struct {
+		name     string
+		matchers []models.SilenceMatcher
+		agent    *models.Agent
+		want     bool
+	}{
+		{"无匹配条件不生效", nil, agent, false},
+		{"等于", []models.SilenceMatcher{m(models.SilenceMatcherAlertType, models.SilenceOperatorEqual, "cpu")}, agent, true},
+		{"默认运算符为等于", []models.SilenceMatcher{m(models.SilenceMatcherAgentID, "", "a1")}, agent, true},
+		{"不等于", []models.SilenceMatcher{m(models.SilenceMatcherLevel, models.SilenceOperatorNotEqual, "critical")}, agent, true},
+		{"正则整串匹配", []models.SilenceMatcher{m(models.SilenceMatcherAlertType, models.SilenceOperatorRegex, "cpu|memory")}, agent, true},
+		{"正则不做子串匹配", []models.SilenceMatcher{m(models.SilenceMatcherAlertType, models.SilenceOperatorRegex, "cp")}, agent, false},
+		{"非法正则不匹配", []models.SilenceMatcher{m(models.SilenceMatcherAlertType, models.SilenceOperatorRegex, "(")}, agent, false},
+		{"标签任一值相等", []models.SilenceMatcher{m(models.SilenceMatcherTag, models.SilenceOperatorEqual, "db")}, agent, true},
+		{"标签不等于要求全部不相等", []models.SilenceMatcher{m(models.SilenceMatcherTag, models.SilenceOperatorNotEqual, "db")}, agent, false},
+		{"探针不存在时标签不匹配", []models.SilenceMatcher{m(models.SilenceMatcherTag, models.SilenceOperatorEqual, "db")}, nil, false},
+		{"探针不存在时标签不等于成立", []models.SilenceMatcher{m(models.SilenceMatcherTag, models.SilenceOperatorNotEqual, "db")}, nil, true},
+		{"全部条件满足", []models.SilenceMatcher{
+			m(models.SilenceMatcherAgentID, models.SilenceOperatorEqual, "a1"),
+			m(models.SilenceMatcherLevel, models.SilenceOperatorEqual, "warning"),
+		}, agent, true},
+		{"任一条件不满足", []models.SilenceMatcher{
+			m(models.SilenceMatcherAgentID, models.SilenceOperatorEqual, "a1"),
+			m(models.SilenceMatcherLevel, models.SilenceOperatorEqual, "critical"),
+		}, agent, false},
+		{"监控项为空不匹配", []models.SilenceMatcher{m(models.SilenceMatcherMonitorID, models.SilenceOperatorEqual, "m1")}, agent, false},
+		{"未知字段不匹配", []models.SilenceMatcher{m("hostname", models.SilenceOperatorEqual, "a1")}, agent, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			silence := &models.Silence{Matchers: tt.matchers}
+			if got := matchSilence(silence, record, tt.agent); got != tt.want {
+				t.Fatalf("matchSilence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
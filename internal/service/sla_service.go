@@ -0,0 +1,590 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/dushixiang/pika/internal/vmclient"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	slaStateUp          = "up"
+	slaStateDown        = "down"
+	slaStateUnknown     = "unknown"
+	slaStateMaintenance = "maintenance"
+
+	// slaMaxPoints 单次范围查询的最大采样点数量
+	slaMaxPoints = 25000
+	// slaReportCheckInterval 定时报告检查间隔
+	slaReportCheckInterval = time.Hour
+)
+
+// slaSegment 状态时间段（时间戳毫秒，左闭右开）
+type slaSegment struct {
+	start int64
+	end   int64
+	state string
+}
+
+// SLAService 可用性报告服务
+type SLAService struct {
+	logger             *zap.Logger
+	agentRepo          *repo.AgentRepo
+	monitorRepo        *repo.MonitorRepo
+	statusEventRepo    *repo.AgentStatusEventRepo
+	vmClient           *vmclient.VMClient
+	maintenanceService *MaintenanceService
+	propertyService    *PropertyService
+	notifier           *Notifier
+}
+
+func NewSLAService(logger *zap.Logger, db *gorm.DB, vmClient *vmclient.VMClient, maintenanceService *MaintenanceService,
+	propertyService *PropertyService, notifier *Notifier) *SLAService {
+	return &SLAService{
+		logger:             logger,
+		agentRepo:          repo.NewAgentRepo(db),
+		monitorRepo:        repo.NewMonitorRepo(db),
+		statusEventRepo:    repo.NewAgentStatusEventRepo(db),
+		vmClient:           vmClient,
+		maintenanceService: maintenanceService,
+		propertyService:    propertyService,
+		notifier:           notifier,
+	}
+}
+
+// MonitorReport 计算监控项在 [start, end) 内的可用性报告
+func (s *SLAService) MonitorReport(ctx context.Context, monitorID string, start, end time.Time) (*metric.SLAReport, error) {
+	monitor, err := s.monitorRepo.FindById(ctx, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildMonitorReport(ctx, &monitor, start, end)
+}
+
+// MonitorReports 计算所有已启用监控项的可用性报告
+func (s *SLAService) MonitorReports(ctx context.Context, start, end time.Time) ([]metric.SLAReport, error) {
+	monitors, err := s.monitorRepo.FindByEnabled(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]metric.SLAReport, 0, len(monitors))
+	for _, monitor := range monitors {
+		report, err := s.buildMonitorReport(ctx, &monitor, start, end)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// AgentReport 计算探针在 [start, end) 内的可用性报告
+func (s *SLAService) AgentReport(ctx context.Context, agentID string, start, end time.Time) (*metric.SLAReport, error) {
+	agent, err := s.agentRepo.FindById(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildAgentReport(ctx, &agent, start, end)
+}
+
+// AgentReports 计算所有探针的可用性报告
+func (s *SLAService) AgentReports(ctx context.Context, start, end time.Time) ([]metric.SLAReport, error) {
+	agents, err := s.agentRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]metric.SLAReport, 0, len(agents))
+	for _, agent := range agents {
+		report, err := s.buildAgentReport(ctx, &agent, start, end)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// monitorLookback 计算监控状态查询的回看窗口，至少覆盖一个检测周期，避免检测频率低于采样步长时出现空洞
+func monitorLookback(monitor *models.MonitorTask, step time.Duration) string {
+	lookback := max(step, time.Duration(monitor.Interval)*1500*time.Millisecond)
+	return fmt.Sprintf("%ds", int(lookback.Seconds()))
+}
+
+// monitorSegments 根据指标库中的监控状态历史计算状态时间段
+func (s *SLAService) monitorSegments(ctx context.Context, monitor *models.MonitorTask, start, end time.Time) ([]slaSegment, error) {
+	step := slaReportStep(start, end)
+	window := monitorLookback(monitor, step)
+	selector := fmt.Sprintf(`{monitor_id="%s"}`, monitor.ID)
+
+	failed, err := s.queryRangeValues(ctx, fmt.Sprintf(`sum(1 - last_over_time(pika_monitor_status%s[%s]))`, selector, window), start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.queryRangeValues(ctx, fmt.Sprintf(`count(last_over_time(pika_monitor_status%s[%s]))`, selector, window), start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	quorum := monitor.QuorumConfig.Data()
+	var segments []slaSegment
+	// 指标库会将查询起点对齐到步长的整数倍，这里按相同方式对齐采样点
+	stepMilli := step.Milliseconds()
+	startMilli, endMilli := start.UnixMilli(), end.UnixMilli()
+	for ts := startMilli - startMilli%stepMilli; ts < endMilli; ts += stepMilli {
+		state := slaStateUnknown
+		if totalCount := int(math.Round(total[ts])); totalCount > 0 {
+			failedCount := int(math.Round(failed[ts]))
+			down := failedCount >= totalCount
+			if quorum.Enabled {
+				down = quorum.IsQuorumMet(failedCount, totalCount)
+			}
+			state = slaStateUp
+			if down {
+				state = slaStateDown
+			}
+		}
+		segments = appendSegment(segments, max(ts, startMilli), min(ts+stepMilli, endMilli), state)
+	}
+	return segments, nil
+}
+
+// buildMonitorReport 根据指标库中的监控状态历史计算可用性
+func (s *SLAService) buildMonitorReport(ctx context.Context, monitor *models.MonitorTask, start, end time.Time) (*metric.SLAReport, error) {
+	segments, err := s.monitorSegments(ctx, monitor, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &metric.SLAReport{
+		TargetType: "monitor",
+		TargetID:   monitor.ID,
+		TargetName: monitor.Name,
+	}
+	maintenance := s.maintenanceService.Occurrences(ctx, nil, monitor.ID, start, end)
+	summarizeSegments(report, start, end, segments, maintenance)
+
+	// 平均响应时间
+	step := slaReportStep(start, end)
+	query := fmt.Sprintf(`avg(avg_over_time(pika_monitor_response_time_ms{monitor_id="%s"}[%s]))`, monitor.ID, monitorLookback(monitor, step))
+	responseTimes, err := s.queryRangeValues(ctx, query, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	if len(responseTimes) > 0 {
+		var sum float64
+		for _, v := range responseTimes {
+			sum += v
+		}
+		report.AvgResponseTime = math.Round(sum/float64(len(responseTimes))*100) / 100
+	}
+
+	return report, nil
+}
+
+// agentSegments 根据探针上下线事件计算状态时间段
+func (s *SLAService) agentSegments(ctx context.Context, agent *models.Agent, start, end time.Time) ([]slaSegment, error) {
+	startMilli, endMilli := start.UnixMilli(), end.UnixMilli()
+
+	// 统计开始前的最后一个事件决定初始状态，没有事件时状态未知
+	state := slaStateUnknown
+	last, err := s.statusEventRepo.FindLastBefore(ctx, agent.ID, startMilli)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		state = agentEventState(last.Status)
+	}
+
+	events, err := s.statusEventRepo.FindByAgentBetween(ctx, agent.ID, startMilli, endMilli)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []slaSegment
+	cursor := startMilli
+	for _, event := range events {
+		segments = appendSegment(segments, cursor, event.CreatedAt, state)
+		state = agentEventState(event.Status)
+		cursor = event.CreatedAt
+	}
+	// 当前时间之后的时段没有数据
+	now := time.Now().UnixMilli()
+	segments = appendSegment(segments, cursor, min(now, endMilli), state)
+	segments = appendSegment(segments, max(cursor, now), endMilli, slaStateUnknown)
+	return segments, nil
+}
+
+// buildAgentReport 根据探针上下线事件计算可用性
+func (s *SLAService) buildAgentReport(ctx context.Context, agent *models.Agent, start, end time.Time) (*metric.SLAReport, error) {
+	segments, err := s.agentSegments(ctx, agent, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &metric.SLAReport{
+		TargetType: "agent",
+		TargetID:   agent.ID,
+		TargetName: agent.Name,
+	}
+	maintenance := s.maintenanceService.Occurrences(ctx, agent, "", start, end)
+	summarizeSegments(report, start, end, segments, maintenance)
+	return report, nil
+}
+
+// MonitorDailyUptime 计算监控项最近 days 天（含今天）每天的可用性
+func (s *SLAService) MonitorDailyUptime(ctx context.Context, monitor *models.MonitorTask, days int) ([]metric.DailyUptime, error) {
+	start, end := dailyRange(days)
+	segments, err := s.monitorSegments(ctx, monitor, start, end)
+	if err != nil {
+		return nil, err
+	}
+	maintenance := s.maintenanceService.Occurrences(ctx, nil, monitor.ID, start, end)
+	return summarizeDaily(start, days, segments, maintenance), nil
+}
+
+// AgentDailyUptime 计算探针最近 days 天（含今天）每天的可用性
+func (s *SLAService) AgentDailyUptime(ctx context.Context, agent *models.Agent, days int) ([]metric.DailyUptime, error) {
+	start, end := dailyRange(days)
+	segments, err := s.agentSegments(ctx, agent, start, end)
+	if err != nil {
+		return nil, err
+	}
+	maintenance := s.maintenanceService.Occurrences(ctx, agent, "", start, end)
+	return summarizeDaily(start, days, segments, maintenance), nil
+}
+
+// dailyRange 最近 days 天（含今天）的起止时间，按服务器时区的自然日划分
+func dailyRange(days int) (start, end time.Time) {
+	now := time.Now()
+	end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	return end.AddDate(0, 0, -days), end
+}
+
+// summarizeDaily 按自然日汇总状态时间段
+func summarizeDaily(start time.Time, days int, segments []slaSegment, maintenance []metric.TimeRange) []metric.DailyUptime {
+	result := make([]metric.DailyUptime, 0, days)
+	for i := 0; i < days; i++ {
+		dayStart := start.AddDate(0, 0, i)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+
+		var report metric.SLAReport
+		summarizeSegments(&report, dayStart, dayEnd, clipSegments(segments, dayStart.UnixMilli(), dayEnd.UnixMilli()), maintenance)
+		result = append(result, metric.DailyUptime{
+			Date:               dayStart.Format("2006-01-02"),
+			UptimePercent:      report.UptimePercent,
+			DownSeconds:        report.DownSeconds,
+			MaintenanceSeconds: report.MaintenanceSeconds,
+			OutageCount:        report.OutageCount,
+			HasData:            report.UpSeconds+report.DownSeconds > 0,
+		})
+	}
+	return result
+}
+
+// clipSegments 截取 [start, end) 范围内的状态时间段
+func clipSegments(segments []slaSegment, start, end int64) []slaSegment {
+	var result []slaSegment
+	for _, seg := range segments {
+		if seg.end <= start || seg.start >= end {
+			continue
+		}
+		result = appendSegment(result, max(seg.start, start), min(seg.end, end), seg.state)
+	}
+	return result
+}
+
+func agentEventState(status string) string {
+	if status == models.AgentStatusOnline {
+		return slaStateUp
+	}
+	return slaStateDown
+}
+
+// queryRangeValues 范围查询单条时间序列，返回 时间戳(毫秒) -> 值
+func (s *SLAService) queryRangeValues(ctx context.Context, query string, start, end time.Time, step time.Duration) (map[int64]float64, error) {
+	result, err := s.vmClient.QueryRange(ctx, query, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[int64]float64)
+	for _, point := range vmclient.ConvertToDataPoints(result) {
+		values[point.Timestamp] = point.Value
+	}
+	return values, nil
+}
+
+// slaReportStep 计算统计采样步长，最小 1 分钟，且采样点数量不超过 slaMaxPoints
+func slaReportStep(start, end time.Time) time.Duration {
+	step := time.Minute
+	if perPoint := end.Sub(start) / slaMaxPoints; perPoint > step {
+		step = perPoint.Truncate(time.Minute) + time.Minute
+	}
+	return step
+}
+
+// appendSegment 追加状态时间段，与上一段状态相同且相邻时合并
+func appendSegment(segments []slaSegment, start, end int64, state string) []slaSegment {
+	if start >= end {
+		return segments
+	}
+	if n := len(segments); n > 0 && segments[n-1].state == state && segments[n-1].end == start {
+		segments[n-1].end = end
+		return segments
+	}
+	return append(segments, slaSegment{start: start, end: end, state: state})
+}
+
+// splitByMaintenance 将与维护时间段重叠的部分标记为维护
+func splitByMaintenance(segments []slaSegment, maintenance []metric.TimeRange) []slaSegment {
+	if len(maintenance) == 0 {
+		return segments
+	}
+	var result []slaSegment
+	for _, seg := range segments {
+		cursor := seg.start
+		for _, m := range maintenance {
+			if m.End <= cursor || m.Start >= seg.end {
+				continue
+			}
+			result = appendSegment(result, cursor, m.Start, seg.state)
+			overlapEnd := min(seg.end, m.End)
+			result = appendSegment(result, max(cursor, m.Start), overlapEnd, slaStateMaintenance)
+			cursor = overlapEnd
+		}
+		result = appendSegment(result, cursor, seg.end, seg.state)
+	}
+	return result
+}
+
+// summarizeSegments 汇总状态时间段，计算可用率、故障次数和平均恢复时间
+// 维护时段不计入故障，维护期间中断的故障视为两次故障
+func summarizeSegments(report *metric.SLAReport, start, end time.Time, segments []slaSegment, maintenance []metric.TimeRange) {
+	report.Start = start.UnixMilli()
+	report.End = end.UnixMilli()
+	report.Outages = make([]metric.TimeRange, 0)
+
+	var upMs, downMs, maintenanceMs, unknownMs int64
+	for _, seg := range splitByMaintenance(segments, maintenance) {
+		duration := seg.end - seg.start
+		switch seg.state {
+		case slaStateUp:
+			upMs += duration
+		case slaStateDown:
+			downMs += duration
+			report.Outages = append(report.Outages, metric.TimeRange{Start: seg.start, End: seg.end})
+		case slaStateMaintenance:
+			maintenanceMs += duration
+		default:
+			unknownMs += duration
+		}
+	}
+
+	report.UpSeconds = upMs / 1000
+	report.DownSeconds = downMs / 1000
+	report.MaintenanceSeconds = maintenanceMs / 1000
+	report.UnknownSeconds = unknownMs / 1000
+	report.OutageCount = len(report.Outages)
+	if report.OutageCount > 0 {
+		report.MTTR = report.DownSeconds / int64(report.OutageCount)
+	}
+	if measured := upMs + downMs; measured > 0 {
+		report.UptimePercent = math.Round(float64(upMs)/float64(measured)*100*1000) / 1000
+	}
+}
+
+// WriteReportsCSV 将可用性报告导出为 CSV
+func WriteReportsCSV(w io.Writer, reports []metric.SLAReport) error {
+	// 写入 UTF-8 BOM，便于 Excel 正确识别中文
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	header := []string{"类型", "ID", "名称", "开始时间", "结束时间", "可用率(%)", "正常时长(秒)", "故障时长(秒)",
+		"维护时长(秒)", "无数据时长(秒)", "故障次数", "平均恢复时间(秒)", "平均响应时间(ms)"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, report := range reports {
+		row := []string{
+			report.TargetType,
+			report.TargetID,
+			report.TargetName,
+			formatReportTime(report.Start),
+			formatReportTime(report.End),
+			strconv.FormatFloat(report.UptimePercent, 'f', 3, 64),
+			strconv.FormatInt(report.UpSeconds, 10),
+			strconv.FormatInt(report.DownSeconds, 10),
+			strconv.FormatInt(report.MaintenanceSeconds, 10),
+			strconv.FormatInt(report.UnknownSeconds, 10),
+			strconv.Itoa(report.OutageCount),
+			strconv.FormatInt(report.MTTR, 10),
+			strconv.FormatFloat(report.AvgResponseTime, 'f', 2, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatReportTime(ts int64) string {
+	return time.UnixMilli(ts).Format("2006-01-02 15:04:05")
+}
+
+// formatReportDuration 格式化时长用于邮件正文
+func formatReportDuration(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	if d < time.Minute {
+		return fmt.Sprintf("%d秒", seconds)
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%d分钟", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%.1f小时", d.Hours())
+}
+
+// Run 定时检查并发送可用性报告邮件
+func (s *SLAService) Run(ctx context.Context) {
+	ticker := time.NewTicker(slaReportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.checkScheduledReport(ctx, time.Now()); err != nil {
+				s.logger.Error("发送可用性报告失败", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reportPeriod 根据发送周期计算上一个统计周期
+func reportPeriod(schedule string, now time.Time) (name string, start, end time.Time, due bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch schedule {
+	case models.SLAReportScheduleWeekly:
+		// 每周一发送上周（周一至周日）的报告
+		weekday := (int(today.Weekday()) + 6) % 7
+		end = today.AddDate(0, 0, -weekday)
+		start = end.AddDate(0, 0, -7)
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), start, end, weekday == 0
+	default:
+		// 每月1日发送上个自然月的报告
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		start = end.AddDate(0, -1, 0)
+		return start.Format("2006-01"), start, end, now.Day() == 1
+	}
+}
+
+// checkScheduledReport 到达发送时间且本周期尚未发送时，发送可用性报告邮件
+func (s *SLAService) checkScheduledReport(ctx context.Context, now time.Time) error {
+	config, err := s.propertyService.GetSLAReportConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !config.Enabled {
+		return nil
+	}
+
+	period, start, end, due := reportPeriod(config.Schedule, now)
+	if !due || now.Hour() < config.Hour || config.LastSentPeriod == period {
+		return nil
+	}
+
+	if err := s.SendReportEmail(ctx, period, start, end, config.ToEmails); err != nil {
+		return err
+	}
+
+	config.LastSentPeriod = period
+	return s.propertyService.SetSLAReportConfig(ctx, *config)
+}
+
+// SendReportEmail 通过邮件通知渠道发送可用性报告
+func (s *SLAService) SendReportEmail(ctx context.Context, period string, start, end time.Time, toEmails []string) error {
+	channels, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	var emailChannel *models.NotificationChannelConfig
+	for i := range channels {
+		if channels[i].Type == "email" && channels[i].Enabled {
+			emailChannel = &channels[i]
+			break
+		}
+	}
+	if emailChannel == nil {
+		return fmt.Errorf("未配置可用的邮件通知渠道")
+	}
+
+	monitorReports, err := s.MonitorReports(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	agentReports, err := s.AgentReports(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Pika 可用性报告 %s", period)
+	message := buildReportEmailMessage(period, start, end, monitorReports, agentReports)
+
+	if len(toEmails) == 0 {
+		// 使用渠道配置的收件人
+		toEmails = []string{""}
+	}
+	for _, toEmail := range toEmails {
+		if err := s.notifier.SendEmailTo(ctx, emailChannel.Config, toEmail, subject, message); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("可用性报告已发送", zap.String("period", period), zap.Int("recipients", len(toEmails)))
+	return nil
+}
+
+// buildReportEmailMessage 构建可用性报告邮件正文
+func buildReportEmailMessage(period string, start, end time.Time, monitorReports, agentReports []metric.SLAReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("可用性报告 %s\n", period))
+	sb.WriteString(fmt.Sprintf("统计时间: %s ~ %s\n", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04")))
+
+	writeSection := func(title string, reports []metric.SLAReport, withResponseTime bool) {
+		sb.WriteString(fmt.Sprintf("\n%s（%d）\n", title, len(reports)))
+		for _, report := range reports {
+			line := fmt.Sprintf("- %s: 可用率 %.3f%%，故障 %d 次，故障时长 %s，平均恢复时间 %s",
+				report.TargetName,
+				report.UptimePercent,
+				report.OutageCount,
+				formatReportDuration(report.DownSeconds),
+				formatReportDuration(report.MTTR),
+			)
+			if withResponseTime {
+				line += fmt.Sprintf("，平均响应 %.0fms", report.AvgResponseTime)
+			}
+			if report.MaintenanceSeconds > 0 {
+				line += fmt.Sprintf("，维护 %s", formatReportDuration(report.MaintenanceSeconds))
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	writeSection("服务监控", monitorReports, true)
+	writeSection("探针", agentReports, false)
+
+	return sb.String()
+}
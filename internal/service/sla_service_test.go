@@ -0,0 +1,114 @@
+package service
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestSummarizeSegments(t *testing.T) {
+	const s = int64(1000)
+	tests := []struct {
+		name        string
+		segments    []slaSegment
+		maintenance []metric.TimeRange
+		want        metric.SLAReport
+	}{
+		{
+			name: "维护时段不计入故障",
+			segments: []slaSegment{
+				{0, 100 * s, slaStateUp},
+				{100 * s, 200 * s, slaStateDown},
+				{200 * s, 400 * s, slaStateUp},
+			},
+			maintenance: []metric.TimeRange{{Start: 150 * s, End: 250 * s}},
+			want: metric.SLAReport{
+				UpSeconds: 250, DownSeconds: 50, MaintenanceSeconds: 100,
+				OutageCount: 1, MTTR: 50, UptimePercent: 83.333,
+				Outages: []metric.TimeRange{{Start: 100 * s, End: 150 * s}},
+			},
+		},
+		{
+			name: "维护中断的故障计为两次",
+			segments: []slaSegment{
+				{0, 100 * s, slaStateUp},
+				{100 * s, 300 * s, slaStateDown},
+				{300 * s, 400 * s, slaStateUp},
+			},
+			maintenance: []metric.TimeRange{{Start: 150 * s, End: 200 * s}},
+			want: metric.SLAReport{
+				UpSeconds: 200, DownSeconds: 150, MaintenanceSeconds: 50,
+				OutageCount: 2, MTTR: 75, UptimePercent: 57.143,
+				Outages: []metric.TimeRange{{Start: 100 * s, End: 150 * s}, {Start: 200 * s, End: 300 * s}},
+			},
+		},
+		{
+			name:     "无数据时可用率为 0",
+			segments: []slaSegment{{0, 400 * s, slaStateUnknown}},
+			want:     metric.SLAReport{UnknownSeconds: 400, Outages: []metric.TimeRange{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report metric.SLAReport
+			summarizeSegments(&report, time.UnixMilli(0), time.UnixMilli(400*s), tt.segments, tt.maintenance)
+
+			tt.want.Start, tt.want.End = 0, 400*s
+			if !slices.Equal(report.Outages, tt.want.Outages) {
+				t.Errorf("Outages = %v, want %v", report.Outages, tt.want.Outages)
+			}
+			report.Outages, tt.want.Outages = nil, nil
+			if !reflect.DeepEqual(report, tt.want) {
+				t.Errorf("report = %+v, want %+v", report, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  string
+		now       time.Time
+		wantName  string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantDue   bool
+	}{
+		{
+			name: "周一发送上周报告", schedule: models.SLAReportScheduleWeekly,
+			now:      time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC),
+			wantName: "2026-W01", wantStart: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC), wantEnd: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			wantDue: true,
+		},
+		{
+			name: "周三不发送", schedule: models.SLAReportScheduleWeekly,
+			now:      time.Date(2026, 1, 7, 9, 0, 0, 0, time.UTC),
+			wantName: "2026-W01", wantStart: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC), wantEnd: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "每月1日发送上月报告", schedule: models.SLAReportScheduleMonthly,
+			now:      time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+			wantName: "2026-02", wantStart: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), wantEnd: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantDue: true,
+		},
+		{
+			name: "跨年的月报", schedule: "",
+			now:      time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC),
+			wantName: "2025-12", wantStart: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), wantEnd: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, start, end, due := reportPeriod(tt.schedule, tt.now)
+			if name != tt.wantName || !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) || due != tt.wantDue {
+				t.Errorf("reportPeriod() = %s [%v, %v) %v, want %s [%v, %v) %v",
+					name, start, end, due, tt.wantName, tt.wantStart, tt.wantEnd, tt.wantDue)
+			}
+		})
+	}
+}
@@ -0,0 +1,862 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/metric"
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/cache"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// statusPageHistoryDays 状态页展示的历史天数
+	statusPageHistoryDays = 90
+	// statusPageHistoryTTL 状态页历史数据缓存时间
+	statusPageHistoryTTL = 5 * time.Minute
+	// statusPageResolvedIncidentDays 已解决事件在状态页上保留展示的天数
+	statusPageResolvedIncidentDays = 7
+	// statusPageFeedLimit 订阅源中的事件数量
+	statusPageFeedLimit = 50
+)
+
+const (
+	StatusPageOperational   = "operational"    // 全部正常
+	StatusPageMaintenance   = "maintenance"    // 维护中
+	StatusPagePartialOutage = "partial_outage" // 部分故障
+	StatusPageMajorOutage   = "major_outage"   // 全部故障
+)
+
+var statusPageSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// StatusPageService 状态页服务
+type StatusPageService struct {
+	logger *zap.Logger
+	*orz.Service
+	StatusPageRepo     *repo.StatusPageRepo // 导出用于 handler 的 PageBuilder
+	incidentRepo       *repo.StatusPageIncidentRepo
+	incidentUpdateRepo *repo.StatusPageIncidentUpdateRepo
+	agentRepo          *repo.AgentRepo
+	monitorService     *MonitorService
+	maintenanceService *MaintenanceService
+	slaService         *SLAService
+
+	historyCache cache.Cache[string, *StatusPageHistory] // 历史数据缓存，key: 状态页ID
+}
+
+func NewStatusPageService(logger *zap.Logger, db *gorm.DB, monitorService *MonitorService,
+	maintenanceService *MaintenanceService, slaService *SLAService) *StatusPageService {
+	return &StatusPageService{
+		logger:             logger,
+		Service:            orz.NewService(db),
+		StatusPageRepo:     repo.NewStatusPageRepo(db),
+		incidentRepo:       repo.NewStatusPageIncidentRepo(db),
+		incidentUpdateRepo: repo.NewStatusPageIncidentUpdateRepo(db),
+		agentRepo:          repo.NewAgentRepo(db),
+		monitorService:     monitorService,
+		maintenanceService: maintenanceService,
+		slaService:         slaService,
+		historyCache:       cache.New[string, *StatusPageHistory](time.Minute),
+	}
+}
+
+// StatusPageRequest 创建/更新状态页请求
+type StatusPageRequest struct {
+	Slug        string                     `json:"slug"`
+	Title       string                     `json:"title"`
+	Description string                     `json:"description"`
+	Domain      string                     `json:"domain"`
+	Enabled     bool                       `json:"enabled"`
+	Sections    []models.StatusPageSection `json:"sections"`
+}
+
+// IncidentRequest 创建事件请求
+type IncidentRequest struct {
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// IncidentUpdateRequest 事件进展更新请求
+type IncidentUpdateRequest struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// StatusPageItem 状态页中的监控项或探针
+type StatusPageItem struct {
+	Type          string               `json:"type"`                   // monitor, agent
+	ID            string               `json:"id"`                     // 监控项/探针ID
+	Name          string               `json:"name"`                   // 名称
+	Target        string               `json:"target,omitempty"`       // 目标地址（仅监控项允许公开时返回）
+	Status        string               `json:"status"`                 // up/down/unknown/maintenance
+	ResponseTime  int64                `json:"responseTime,omitempty"` // 当前平均响应时间(ms)，仅监控项
+	UptimePercent float64              `json:"uptimePercent"`          // 历史可用率(%)，仅历史接口
+	History       []metric.DailyUptime `json:"history,omitempty"`      // 每日可用性，仅历史接口
+}
+
+// StatusPageSectionView 状态页分组视图
+type StatusPageSectionView struct {
+	Name  string           `json:"name"`
+	Items []StatusPageItem `json:"items"`
+}
+
+// StatusPageView 状态页当前状态
+type StatusPageView struct {
+	ID          string                      `json:"id"`
+	Slug        string                      `json:"slug"`
+	Title       string                      `json:"title"`
+	Description string                      `json:"description"`
+	Status      string                      `json:"status"` // operational/maintenance/partial_outage/major_outage
+	Sections    []StatusPageSectionView     `json:"sections"`
+	Incidents   []models.StatusPageIncident `json:"incidents"` // 未解决及近期已解决的事件
+	Maintenance []ActiveMaintenanceWindow   `json:"maintenance"`
+	UpdatedAt   int64                       `json:"updatedAt"`
+}
+
+// StatusPageHistory 状态页历史可用性
+type StatusPageHistory struct {
+	Days      int                     `json:"days"`
+	Sections  []StatusPageSectionView `json:"sections"`
+	UpdatedAt int64                   `json:"updatedAt"`
+}
+
+// ValidateStatusPage 校验并规范化状态页配置
+func ValidateStatusPage(req *StatusPageRequest) error {
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if !statusPageSlugPattern.MatchString(req.Slug) {
+		return errors.New("访问路径只能包含小写字母、数字和中划线，且不能以中划线开头")
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return errors.New("标题不能为空")
+	}
+	req.Domain = normalizeHost(req.Domain)
+	for i, section := range req.Sections {
+		if strings.TrimSpace(section.Name) == "" {
+			return fmt.Errorf("第 %d 个分组名称不能为空", i+1)
+		}
+	}
+	return nil
+}
+
+func validateIncidentStatus(status string) error {
+	switch status {
+	case models.IncidentStatusInvestigating, models.IncidentStatusIdentified,
+		models.IncidentStatusMonitoring, models.IncidentStatusResolved:
+		return nil
+	default:
+		return errors.New("事件状态只能是 investigating、identified、monitoring 或 resolved")
+	}
+}
+
+// ValidateIncident 校验事件请求
+func ValidateIncident(req *IncidentRequest) error {
+	if strings.TrimSpace(req.Title) == "" {
+		return errors.New("标题不能为空")
+	}
+	if req.Status == "" {
+		req.Status = models.IncidentStatusInvestigating
+	}
+	return validateIncidentStatus(req.Status)
+}
+
+// ValidateIncidentUpdate 校验事件进展更新请求
+func ValidateIncidentUpdate(req *IncidentUpdateRequest) error {
+	if strings.TrimSpace(req.Message) == "" {
+		return errors.New("内容不能为空")
+	}
+	return validateIncidentStatus(req.Status)
+}
+
+// normalizeHost 去除端口并转为小写
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
+
+// checkUnique 检查访问路径和域名是否已被其他状态页使用
+func (s *StatusPageService) checkUnique(ctx context.Context, id string, req *StatusPageRequest) error {
+	page, err := s.StatusPageRepo.FindBySlug(ctx, req.Slug)
+	if err != nil {
+		return err
+	}
+	if page != nil && page.ID != id {
+		return orz.NewError(400, "访问路径已被使用")
+	}
+	if req.Domain == "" {
+		return nil
+	}
+	page, err = s.StatusPageRepo.FindByDomain(ctx, req.Domain)
+	if err != nil {
+		return err
+	}
+	if page != nil && page.ID != id {
+		return orz.NewError(400, "域名已被其他状态页使用")
+	}
+	return nil
+}
+
+func (s *StatusPageService) applyRequest(page *models.StatusPage, req *StatusPageRequest) {
+	sections := req.Sections
+	if sections == nil {
+		sections = []models.StatusPageSection{}
+	}
+	page.Slug = req.Slug
+	page.Title = req.Title
+	page.Description = req.Description
+	page.Domain = req.Domain
+	page.Enabled = req.Enabled
+	page.Sections = datatypes.NewJSONSlice(sections)
+}
+
+// checkTargets 检查状态页引用的监控项和探针均为公开可见，状态页无需登录即可访问
+func (s *StatusPageService) checkTargets(ctx context.Context, req *StatusPageRequest) error {
+	var monitorIDs, agentIDs []string
+	for _, section := range req.Sections {
+		monitorIDs = append(monitorIDs, section.MonitorIds...)
+		agentIDs = append(agentIDs, section.AgentIds...)
+	}
+	if len(monitorIDs) > 0 {
+		monitors, err := s.monitorService.FindByIdIn(ctx, monitorIDs)
+		if err != nil {
+			return err
+		}
+		for _, monitor := range monitors {
+			if monitor.Visibility != "public" {
+				return orz.NewError(400, fmt.Sprintf("监控项 %s 仅登录可见，不能添加到状态页", monitor.Name))
+			}
+		}
+	}
+	if len(agentIDs) > 0 {
+		agents, err := s.agentRepo.FindByIdIn(ctx, agentIDs)
+		if err != nil {
+			return err
+		}
+		for _, agent := range agents {
+			if agent.Visibility != "public" {
+				return orz.NewError(400, fmt.Sprintf("探针 %s 仅登录可见，不能添加到状态页", agent.Name))
+			}
+		}
+	}
+	return nil
+}
+
+// CreatePage 创建状态页
+func (s *StatusPageService) CreatePage(ctx context.Context, req *StatusPageRequest) (*models.StatusPage, error) {
+	if err := s.checkUnique(ctx, "", req); err != nil {
+		return nil, err
+	}
+	if err := s.checkTargets(ctx, req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	page := &models.StatusPage{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.applyRequest(page, req)
+
+	if err := s.StatusPageRepo.Create(ctx, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// UpdatePage 更新状态页
+func (s *StatusPageService) UpdatePage(ctx context.Context, id string, req *StatusPageRequest) (*models.StatusPage, error) {
+	page, err := s.StatusPageRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUnique(ctx, id, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkTargets(ctx, req); err != nil {
+		return nil, err
+	}
+	s.applyRequest(&page, req)
+
+	if err := s.StatusPageRepo.Save(ctx, &page); err != nil {
+		return nil, err
+	}
+	s.historyCache.Delete(id)
+	return &page, nil
+}
+
+// DeletePage 删除状态页及其事件
+func (s *StatusPageService) DeletePage(ctx context.Context, id string) error {
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		incidents, err := s.incidentRepo.FindByPageID(ctx, id, 0)
+		if err != nil {
+			return err
+		}
+		incidentIDs := make([]string, 0, len(incidents))
+		for _, incident := range incidents {
+			incidentIDs = append(incidentIDs, incident.ID)
+		}
+		if err := s.incidentUpdateRepo.DeleteByIncidentIDs(ctx, incidentIDs); err != nil {
+			return err
+		}
+		if err := s.incidentRepo.DeleteByPageID(ctx, id); err != nil {
+			return err
+		}
+		return s.StatusPageRepo.DeleteById(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	s.historyCache.Delete(id)
+	return nil
+}
+
+// FindPublicPage 根据访问路径或域名查找已启用的状态页，slug 为空时按域名查找
+func (s *StatusPageService) FindPublicPage(ctx context.Context, slug, host string) (*models.StatusPage, error) {
+	var (
+		page *models.StatusPage
+		err  error
+	)
+	if slug != "" {
+		page, err = s.StatusPageRepo.FindBySlug(ctx, strings.ToLower(slug))
+	} else if host = normalizeHost(host); host != "" {
+		page, err = s.StatusPageRepo.FindByDomain(ctx, host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if page == nil || !page.Enabled {
+		return nil, orz.NewError(404, "状态页不存在")
+	}
+	return page, nil
+}
+
+// CreateIncident 创建事件，同时记录第一条进展
+func (s *StatusPageService) CreateIncident(ctx context.Context, pageID string, req *IncidentRequest) (*models.StatusPageIncident, error) {
+	if _, err := s.StatusPageRepo.FindById(ctx, pageID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	incident := &models.StatusPageIncident{
+		ID:           uuid.NewString(),
+		StatusPageID: pageID,
+		Title:        req.Title,
+		Status:       req.Status,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if incident.Status == models.IncidentStatusResolved {
+		incident.ResolvedAt = now
+	}
+
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.incidentRepo.Create(ctx, incident); err != nil {
+			return err
+		}
+		if strings.TrimSpace(req.Message) == "" {
+			return nil
+		}
+		update := models.StatusPageIncidentUpdate{
+			ID:         uuid.NewString(),
+			IncidentID: incident.ID,
+			Status:     incident.Status,
+			Message:    req.Message,
+			CreatedAt:  now,
+		}
+		incident.Updates = append(incident.Updates, update)
+		return s.incidentUpdateRepo.Create(ctx, &update)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// UpdateIncidentTitle 修改事件标题
+func (s *StatusPageService) UpdateIncidentTitle(ctx context.Context, id, title string) (*models.StatusPageIncident, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, orz.NewError(400, "标题不能为空")
+	}
+	incident, err := s.incidentRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	incident.Title = title
+	if err := s.incidentRepo.Save(ctx, &incident); err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// AddIncidentUpdate 发布事件进展，并同步事件状态
+func (s *StatusPageService) AddIncidentUpdate(ctx context.Context, incidentID string, req *IncidentUpdateRequest) (*models.StatusPageIncidentUpdate, error) {
+	incident, err := s.incidentRepo.FindById(ctx, incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	update := &models.StatusPageIncidentUpdate{
+		ID:         uuid.NewString(),
+		IncidentID: incidentID,
+		Status:     req.Status,
+		Message:    req.Message,
+		CreatedAt:  now,
+	}
+
+	incident.Status = req.Status
+	if req.Status == models.IncidentStatusResolved {
+		incident.ResolvedAt = now
+	} else {
+		incident.ResolvedAt = 0
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.incidentUpdateRepo.Create(ctx, update); err != nil {
+			return err
+		}
+		return s.incidentRepo.Save(ctx, &incident)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// DeleteIncident 删除事件及其进展
+func (s *StatusPageService) DeleteIncident(ctx context.Context, id string) error {
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.incidentUpdateRepo.DeleteByIncidentIDs(ctx, []string{id}); err != nil {
+			return err
+		}
+		return s.incidentRepo.DeleteById(ctx, id)
+	})
+}
+
+// ListIncidents 查询状态页的全部事件（含进展）
+func (s *StatusPageService) ListIncidents(ctx context.Context, pageID string) ([]models.StatusPageIncident, error) {
+	incidents, err := s.incidentRepo.FindByPageID(ctx, pageID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.attachUpdates(ctx, incidents)
+}
+
+// attachUpdates 为事件填充进展更新
+func (s *StatusPageService) attachUpdates(ctx context.Context, incidents []models.StatusPageIncident) ([]models.StatusPageIncident, error) {
+	incidentIDs := make([]string, 0, len(incidents))
+	for _, incident := range incidents {
+		incidentIDs = append(incidentIDs, incident.ID)
+	}
+	updates, err := s.incidentUpdateRepo.FindByIncidentIDs(ctx, incidentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	updatesByIncident := make(map[string][]models.StatusPageIncidentUpdate)
+	for _, update := range updates {
+		updatesByIncident[update.IncidentID] = append(updatesByIncident[update.IncidentID], update)
+	}
+	for i := range incidents {
+		incidents[i].Updates = updatesByIncident[incidents[i].ID]
+		if incidents[i].Updates == nil {
+			incidents[i].Updates = []models.StatusPageIncidentUpdate{}
+		}
+	}
+	return incidents, nil
+}
+
+// pageTargets 状态页引用的监控项和探针
+type pageTargets struct {
+	monitors map[string]models.MonitorTask
+	agents   map[string]models.Agent
+}
+
+// loadTargets 加载状态页引用的监控项和探针，保存后改为仅登录可见的监控项和探针不会展示
+func (s *StatusPageService) loadTargets(ctx context.Context, page *models.StatusPage) (*pageTargets, error) {
+	var monitorIDs, agentIDs []string
+	for _, section := range page.Sections {
+		monitorIDs = append(monitorIDs, section.MonitorIds...)
+		agentIDs = append(agentIDs, section.AgentIds...)
+	}
+
+	targets := &pageTargets{
+		monitors: make(map[string]models.MonitorTask),
+		agents:   make(map[string]models.Agent),
+	}
+	if len(monitorIDs) > 0 {
+		monitors, err := s.monitorService.FindByIdIn(ctx, monitorIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, monitor := range monitors {
+			if monitor.Enabled && monitor.Visibility == "public" {
+				targets.monitors[monitor.ID] = monitor
+			}
+		}
+	}
+	if len(agentIDs) > 0 {
+		agents, err := s.agentRepo.FindByIdIn(ctx, agentIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, agent := range agents {
+			if agent.Visibility == "public" {
+				targets.agents[agent.ID] = agent
+			}
+		}
+	}
+	return targets, nil
+}
+
+// buildSections 按分组构建状态页条目，已删除、禁用或仅登录可见的监控项和探针会被忽略
+func (s *StatusPageService) buildSections(page *models.StatusPage, targets *pageTargets, build func(item *StatusPageItem, monitor *models.MonitorTask, agent *models.Agent)) []StatusPageSectionView {
+	sections := make([]StatusPageSectionView, 0, len(page.Sections))
+	for _, section := range page.Sections {
+		view := StatusPageSectionView{Name: section.Name, Items: make([]StatusPageItem, 0)}
+		for _, id := range section.MonitorIds {
+			monitor, ok := targets.monitors[id]
+			if !ok {
+				continue
+			}
+			item := StatusPageItem{Type: "monitor", ID: monitor.ID, Name: monitor.Name}
+			if monitor.ShowTargetPublic {
+				item.Target = monitor.Target
+			}
+			build(&item, &monitor, nil)
+			view.Items = append(view.Items, item)
+		}
+		for _, id := range section.AgentIds {
+			agent, ok := targets.agents[id]
+			if !ok {
+				continue
+			}
+			item := StatusPageItem{Type: "agent", ID: agent.ID, Name: agent.Name}
+			build(&item, nil, &agent)
+			view.Items = append(view.Items, item)
+		}
+		sections = append(sections, view)
+	}
+	return sections
+}
+
+// GetPageStatus 获取状态页当前状态
+func (s *StatusPageService) GetPageStatus(ctx context.Context, page *models.StatusPage) (*StatusPageView, error) {
+	targets, err := s.loadTargets(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := s.buildSections(page, targets, func(item *StatusPageItem, monitor *models.MonitorTask, agent *models.Agent) {
+		if monitor != nil {
+			stats := s.monitorService.getMonitorStats(ctx, monitor.ID)
+			item.Status = stats.Status
+			item.ResponseTime = stats.ResponseTime
+			return
+		}
+		switch {
+		case s.maintenanceService.IsInMaintenance(ctx, agent, ""):
+			item.Status = "maintenance"
+		case agent.Status == 1:
+			item.Status = "up"
+		default:
+			item.Status = "down"
+		}
+	})
+
+	since := time.Now().AddDate(0, 0, -statusPageResolvedIncidentDays).UnixMilli()
+	incidents, err := s.incidentRepo.FindVisibleByPageID(ctx, page.ID, since)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err = s.attachUpdates(ctx, incidents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusPageView{
+		ID:          page.ID,
+		Slug:        page.Slug,
+		Title:       page.Title,
+		Description: page.Description,
+		Status:      overallStatus(sections),
+		Sections:    sections,
+		Incidents:   incidents,
+		Maintenance: s.pageMaintenance(ctx, targets),
+		UpdatedAt:   time.Now().UnixMilli(),
+	}, nil
+}
+
+// pageMaintenance 当前对状态页中任一条目生效的维护窗口
+func (s *StatusPageService) pageMaintenance(ctx context.Context, targets *pageTargets) []ActiveMaintenanceWindow {
+	result := make([]ActiveMaintenanceWindow, 0)
+	for _, window := range s.maintenanceService.ListActive(ctx) {
+		matched := false
+		for id := range targets.monitors {
+			if matchMaintenanceWindow(&window.MaintenanceWindow, nil, id) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			for _, agent := range targets.agents {
+				if matchMaintenanceWindow(&window.MaintenanceWindow, &agent, "") {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			// 公开页面不返回作用范围
+			window.AgentIds = nil
+			window.Tags = nil
+			window.MonitorIds = nil
+			result = append(result, window)
+		}
+	}
+	return result
+}
+
+// overallStatus 根据各条目状态计算状态页整体状态，无数据的条目不参与计算
+func overallStatus(sections []StatusPageSectionView) string {
+	var up, down, maintenance int
+	for _, section := range sections {
+		for _, item := range section.Items {
+			switch item.Status {
+			case "up":
+				up++
+			case "down":
+				down++
+			case "maintenance":
+				maintenance++
+			}
+		}
+	}
+	switch {
+	case down > 0 && up == 0 && maintenance == 0:
+		return StatusPageMajorOutage
+	case down > 0:
+		return StatusPagePartialOutage
+	case maintenance > 0:
+		return StatusPageMaintenance
+	default:
+		return StatusPageOperational
+	}
+}
+
+// GetPageHistory 获取状态页最近 90 天的每日可用性
+func (s *StatusPageService) GetPageHistory(ctx context.Context, page *models.StatusPage) (*StatusPageHistory, error) {
+	if history, ok := s.historyCache.Get(page.ID); ok {
+		return history, nil
+	}
+
+	targets, err := s.loadTargets(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var buildErr error
+	sections := s.buildSections(page, targets, func(item *StatusPageItem, monitor *models.MonitorTask, agent *models.Agent) {
+		if buildErr != nil {
+			return
+		}
+		var days []metric.DailyUptime
+		if monitor != nil {
+			days, buildErr = s.slaService.MonitorDailyUptime(ctx, monitor, statusPageHistoryDays)
+		} else {
+			days, buildErr = s.slaService.AgentDailyUptime(ctx, agent, statusPageHistoryDays)
+		}
+		item.History = days
+		item.UptimePercent = averageUptime(days)
+	})
+	if buildErr != nil {
+		return nil, buildErr
+	}
+
+	history := &StatusPageHistory{
+		Days:      statusPageHistoryDays,
+		Sections:  sections,
+		UpdatedAt: time.Now().UnixMilli(),
+	}
+	s.historyCache.Set(page.ID, history, statusPageHistoryTTL)
+	return history, nil
+}
+
+// averageUptime 有数据天数的平均可用率
+func averageUptime(days []metric.DailyUptime) float64 {
+	var sum float64
+	var count int
+	for _, day := range days {
+		if day.HasData {
+			sum += day.UptimePercent
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return math.Round(sum/float64(count)*1000) / 1000
+}
+
+// ListFeedIncidents 查询订阅源中的事件（含进展）
+func (s *StatusPageService) ListFeedIncidents(ctx context.Context, pageID string) ([]models.StatusPageIncident, error) {
+	incidents, err := s.incidentRepo.FindByPageID(ctx, pageID, statusPageFeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	return s.attachUpdates(ctx, incidents)
+}
+
+var incidentStatusNames = map[string]string{
+	models.IncidentStatusInvestigating: "调查中",
+	models.IncidentStatusIdentified:    "已定位",
+	models.IncidentStatusMonitoring:    "观察中",
+	models.IncidentStatusResolved:      "已解决",
+}
+
+// incidentContent 事件进展汇总，用于订阅源正文
+func incidentContent(incident *models.StatusPageIncident) string {
+	var sb strings.Builder
+	for _, update := range incident.Updates {
+		sb.WriteString(fmt.Sprintf("[%s] %s %s\n",
+			time.UnixMilli(update.CreatedAt).Format("2006-01-02 15:04:05"),
+			incidentStatusNames[update.Status],
+			update.Message,
+		))
+	}
+	return sb.String()
+}
+
+func incidentUpdatedAt(incident *models.StatusPageIncident) int64 {
+	if len(incident.Updates) > 0 {
+		return max(incident.Updates[0].CreatedAt, incident.CreatedAt)
+	}
+	return incident.CreatedAt
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// BuildRSSFeed 生成事件 RSS 2.0 订阅源
+func BuildRSSFeed(page *models.StatusPage, link string, incidents []models.StatusPageIncident) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         page.Title,
+			Link:          link,
+			Description:   page.Description,
+			LastBuildDate: time.Now().Format(time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(incidents)),
+		},
+	}
+	for i := range incidents {
+		incident := &incidents[i]
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       fmt.Sprintf("[%s] %s", incidentStatusNames[incident.Status], incident.Title),
+			Link:        link,
+			Description: incidentContent(incident),
+			GUID:        rssGUID{Value: "urn:uuid:" + incident.ID},
+			PubDate:     time.UnixMilli(incident.CreatedAt).Format(time.RFC1123Z),
+		})
+	}
+	return marshalFeed(feed)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title     string   `xml:"title"`
+	ID        string   `xml:"id"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Content   string   `xml:"content"`
+}
+
+// BuildAtomFeed 生成事件 Atom 订阅源
+func BuildAtomFeed(page *models.StatusPage, link string, incidents []models.StatusPageIncident) ([]byte, error) {
+	updated := page.UpdatedAt
+	entries := make([]atomEntry, 0, len(incidents))
+	for i := range incidents {
+		incident := &incidents[i]
+		incidentUpdated := incidentUpdatedAt(incident)
+		updated = max(updated, incidentUpdated)
+		entries = append(entries, atomEntry{
+			Title:     fmt.Sprintf("[%s] %s", incidentStatusNames[incident.Status], incident.Title),
+			ID:        "urn:uuid:" + incident.ID,
+			Link:      atomLink{Href: link},
+			Published: time.UnixMilli(incident.CreatedAt).Format(time.RFC3339),
+			Updated:   time.UnixMilli(incidentUpdated).Format(time.RFC3339),
+			Content:   incidentContent(incident),
+		})
+	}
+
+	feed := atomFeed{
+		Title:   page.Title,
+		ID:      "urn:uuid:" + page.ID,
+		Link:    atomLink{Href: link},
+		Updated: time.UnixMilli(updated).Format(time.RFC3339),
+		Entries: entries,
+	}
+	return marshalFeed(feed)
+}
+
+func marshalFeed(feed any) ([]byte, error) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestValidateStatusPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        StatusPageRequest
+		wantErr    bool
+		wantSlug   string
+		wantDomain string
+	}{
+		{"规范化访问路径和域名", StatusPageRequest{Slug: " Status-1 ", Title: "状态", Domain: "Status.Example.com:8080"}, false, "status-1", "status.example.com"},
+		{"访问路径以中划线开头", StatusPageRequest{Slug: "-status", Title: "状态"}, true, "", ""},
+		{"访问路径包含非法字符", StatusPageRequest{Slug: "status_page", Title: "状态"}, true, "", ""},
+		{"标题为空", StatusPageRequest{Slug: "status", Title: "  "}, true, "", ""},
+		{"分组名称为空", StatusPageRequest{Slug: "status", Title: "状态", Sections: []models.StatusPageSection{{Name: ""}}}, true, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStatusPage(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (tt.req.Slug != tt.wantSlug || tt.req.Domain != tt.wantDomain) {
+				t.Errorf("slug = %q, domain = %q", tt.req.Slug, tt.req.Domain)
+			}
+		})
+	}
+}
+
+func TestStatusPageHidesPrivateTargets(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, &models.Agent{}, &models.MonitorTask{}, &models.StatusPage{})
+	monitorService := NewMonitorService(zap.NewNop(), db, nil, nil, nil)
+	s := NewStatusPageService(zap.NewNop(), db, monitorService, nil, nil)
+
+	for _, agent := range []models.Agent{
+		{ID: "public-agent", Name: "web", Visibility: "public"},
+		{ID: "private-agent", Name: "db", Visibility: "private"},
+	} {
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, monitor := range []models.MonitorTask{
+		{ID: "public-monitor", Name: "官网", Type: "http", Enabled: true, Visibility: "public"},
+		{ID: "private-monitor", Name: "后台", Type: "http", Enabled: true, Visibility: "private"},
+	} {
+		if err := db.Create(&monitor).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 保存时拒绝仅登录可见的探针和监控项
+	for _, section := range []models.StatusPageSection{
+		{Name: "服务", AgentIds: []string{"public-agent", "private-agent"}},
+		{Name: "服务", MonitorIds: []string{"public-monitor", "private-monitor"}},
+	} {
+		req := &StatusPageRequest{Slug: "status", Title: "状态", Sections: []models.StatusPageSection{section}}
+		if _, err := s.CreatePage(ctx, req); err == nil {
+			t.Errorf("包含仅登录可见的目标时应拒绝保存: %+v", section)
+		}
+	}
+
+	// 保存后改为仅登录可见的目标不展示
+	page := &models.StatusPage{Sections: []models.StatusPageSection{{
+		Name:       "服务",
+		AgentIds:   []string{"public-agent", "private-agent"},
+		MonitorIds: []string{"public-monitor", "private-monitor"},
+	}}}
+	targets, err := s.loadTargets(ctx, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := targets.agents["private-agent"]; ok || len(targets.agents) != 1 {
+		t.Errorf("agents = %v，不应包含仅登录可见的探针", targets.agents)
+	}
+	if _, ok := targets.monitors["private-monitor"]; ok || len(targets.monitors) != 1 {
+		t.Errorf("monitors = %v，不应包含仅登录可见的监控项", targets.monitors)
+	}
+}
@@ -165,6 +165,11 @@ func (s *TrafficService) sendTrafficAlert(ctx context.Context, agent *models.Age
 		CreatedAt:   now,
 	}
 
+	// 被静默的流量告警照常记录，但不发送通知
+	if s.notificationService != nil {
+		s.notificationService.ApplySilence(ctx, record, agent)
+	}
+
 	// 创建告警记录
 	if err := s.alertRecordRepo.CreateAlertRecord(ctx, record); err != nil {
 		s.logger.Error("创建流量告警记录失败", zap.Error(err))
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -34,7 +36,24 @@ type ResultData struct {
 // Result 单个时间序列结果
 type Result struct {
 	Metric map[string]string `json:"metric"`
-	Values [][]interface{}   `json:"values"` // [[timestamp, value], ...]
+	Values [][]interface{}   `json:"values"`          // [[timestamp, value], ...]
+	Value  []interface{}     `json:"value,omitempty"` // 即时查询结果 [timestamp, value]
+}
+
+// InstantValue 解析即时查询结果的值，值不存在或为 NaN 时返回 false
+func (r Result) InstantValue() (float64, bool) {
+	if len(r.Value) < 2 {
+		return 0, false
+	}
+	valueStr, ok := r.Value[1].(string)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil || math.IsNaN(value) {
+		return 0, false
+	}
+	return value, true
 }
 
 // DataPoint 数据点
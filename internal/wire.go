@@ -39,6 +39,18 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		service.NewDDNSService,
 		service.NewSSHLoginService,
 		service.NewPublicIPService,
+		service.NewMaintenanceService,
+		service.NewSLAService,
+		service.NewStatusPageService,
+		service.NewBadgeService,
+		service.NewIncidentService,
+		service.NewAlertPolicyService,
+		service.NewAlertRuleService,
+		service.NewSilenceService,
+		service.NewEscalationService,
+		service.NewNotificationOutboxService,
+		service.NewBaselineService,
+		service.NewForecastService,
 
 		service.NewNotifier,
 		// WebSocket Manager
@@ -55,6 +67,16 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		handler.NewDNSProviderHandler,
 		handler.NewDDNSHandler,
 		handler.NewSSHLoginHandler,
+		handler.NewMaintenanceHandler,
+		handler.NewSLAHandler,
+		handler.NewStatusPageHandler,
+		handler.NewBadgeHandler,
+		handler.NewIncidentHandler,
+		handler.NewAlertPolicyHandler,
+		handler.NewAlertRuleHandler,
+		handler.NewSilenceHandler,
+		handler.NewEscalationHandler,
+		handler.NewNotificationDeliveryHandler,
 
 		// App Components
 		wire.Struct(new(AppComponents), "*"),
@@ -64,28 +86,48 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 
 // AppComponents 应用组件
 type AppComponents struct {
-	AccountHandler     *handler.AccountHandler
-	AgentHandler       *handler.AgentHandler
-	ApiKeyHandler      *handler.ApiKeyHandler
-	AlertHandler       *handler.AlertHandler
-	PropertyHandler    *handler.PropertyHandler
-	MonitorHandler     *handler.MonitorHandler
-	TamperHandler      *handler.TamperHandler
-	DNSProviderHandler *handler.DNSProviderHandler
-	DDNSHandler        *handler.DDNSHandler
-	SSHLoginHandler    *handler.SSHLoginHandler
-
-	AgentService    *service.AgentService
-	TrafficService  *service.TrafficService
-	MetricService   *service.MetricService
-	AlertService    *service.AlertService
-	PropertyService *service.PropertyService
-	MonitorService  *service.MonitorService
-	ApiKeyService   *service.ApiKeyService
-	TamperService   *service.TamperService
-	DDNSService     *service.DDNSService
-	SSHLoginService *service.SSHLoginService
-	PublicIPService *service.PublicIPService
+	AccountHandler              *handler.AccountHandler
+	AgentHandler                *handler.AgentHandler
+	ApiKeyHandler               *handler.ApiKeyHandler
+	AlertHandler                *handler.AlertHandler
+	PropertyHandler             *handler.PropertyHandler
+	MonitorHandler              *handler.MonitorHandler
+	TamperHandler               *handler.TamperHandler
+	DNSProviderHandler          *handler.DNSProviderHandler
+	DDNSHandler                 *handler.DDNSHandler
+	SSHLoginHandler             *handler.SSHLoginHandler
+	MaintenanceHandler          *handler.MaintenanceHandler
+	SLAHandler                  *handler.SLAHandler
+	StatusPageHandler           *handler.StatusPageHandler
+	BadgeHandler                *handler.BadgeHandler
+	IncidentHandler             *handler.IncidentHandler
+	AlertPolicyHandler          *handler.AlertPolicyHandler
+	AlertRuleHandler            *handler.AlertRuleHandler
+	SilenceHandler              *handler.SilenceHandler
+	EscalationHandler           *handler.EscalationHandler
+	NotificationDeliveryHandler *handler.NotificationDeliveryHandler
+
+	AgentService              *service.AgentService
+	TrafficService            *service.TrafficService
+	MetricService             *service.MetricService
+	AlertService              *service.AlertService
+	PropertyService           *service.PropertyService
+	MonitorService            *service.MonitorService
+	ApiKeyService             *service.ApiKeyService
+	TamperService             *service.TamperService
+	DDNSService               *service.DDNSService
+	SSHLoginService           *service.SSHLoginService
+	PublicIPService           *service.PublicIPService
+	MaintenanceService        *service.MaintenanceService
+	SLAService                *service.SLAService
+	StatusPageService         *service.StatusPageService
+	BadgeService              *service.BadgeService
+	IncidentService           *service.IncidentService
+	AlertPolicyService        *service.AlertPolicyService
+	AlertRuleService          *service.AlertRuleService
+	SilenceService            *service.SilenceService
+	EscalationService         *service.EscalationService
+	NotificationOutboxService *service.NotificationOutboxService
 
 	WSManager *websocket.Manager
 	VMClient  *vmclient.VMClient
@@ -28,8 +28,10 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	accountHandler := handler.NewAccountHandler(accountService)
 	apiKeyService := service.NewApiKeyService(logger, db)
 	propertyService := service.NewPropertyService(logger, db)
-	notifier := service.NewNotifier(logger)
-	notificationService := service.NewNotificationService(logger, propertyService, notifier)
+	notifier := service.NewNotifier(logger, propertyService)
+	notificationOutboxService := service.NewNotificationOutboxService(logger, db, propertyService, notifier)
+	silenceService := service.NewSilenceService(logger, db)
+	notificationService := service.NewNotificationService(logger, propertyService, notificationOutboxService, silenceService)
 	trafficService := service.NewTrafficService(logger, db, notificationService)
 	vmClient := provideVMClient(cfg, logger)
 	metricService := service.NewMetricService(logger, db, propertyService, trafficService, vmClient)
@@ -39,14 +41,21 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	}
 	agentService := service.NewAgentService(logger, db, apiKeyService, metricService, geoIPService)
 	manager := websocket.NewManager(logger)
-	monitorService := service.NewMonitorService(logger, db, metricService, manager)
+	maintenanceService := service.NewMaintenanceService(logger, db)
+	monitorService := service.NewMonitorService(logger, db, metricService, manager, maintenanceService)
 	tamperService := service.NewTamperService(logger, db, manager, notificationService)
 	ddnsService := service.NewDDNSService(logger, db, propertyService, manager)
 	sshLoginService := service.NewSSHLoginService(logger, db, manager, geoIPService, notificationService)
 	publicIPService := service.NewPublicIPService(logger, propertyService, manager)
-	agentHandler := handler.NewAgentHandler(logger, agentService, trafficService, metricService, monitorService, tamperService, ddnsService, sshLoginService, apiKeyService, propertyService, manager)
+	alertPolicyService := service.NewAlertPolicyService(logger, db)
+	forecastService := service.NewForecastService(logger, vmClient, alertPolicyService)
+	agentHandler := handler.NewAgentHandler(logger, agentService, trafficService, metricService, monitorService, tamperService, ddnsService, sshLoginService, apiKeyService, propertyService, manager, maintenanceService, forecastService)
 	apiKeyHandler := handler.NewApiKeyHandler(logger, apiKeyService)
-	alertService := service.NewAlertService(logger, db, propertyService, monitorService, notifier)
+	incidentService := service.NewIncidentService(logger, db)
+	alertRuleService := service.NewAlertRuleService(logger, db, vmClient)
+	escalationService := service.NewEscalationService(logger, db)
+	baselineService := service.NewBaselineService(logger, vmClient)
+	alertService := service.NewAlertService(logger, db, propertyService, monitorService, notificationOutboxService, maintenanceService, incidentService, alertPolicyService, alertRuleService, silenceService, escalationService, baselineService, forecastService)
 	alertHandler := handler.NewAlertHandler(logger, alertService)
 	propertyHandler := handler.NewPropertyHandler(logger, propertyService, notifier)
 	monitorHandler := handler.NewMonitorHandler(logger, monitorService, metricService, agentService)
@@ -54,30 +63,63 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	dnsProviderHandler := handler.NewDNSProviderHandler(logger, propertyService)
 	ddnsHandler := handler.NewDDNSHandler(logger, ddnsService)
 	sshLoginHandler := handler.NewSSHLoginHandler(logger, sshLoginService)
+	maintenanceHandler := handler.NewMaintenanceHandler(logger, maintenanceService)
+	slaService := service.NewSLAService(logger, db, vmClient, maintenanceService, propertyService, notifier)
+	slaHandler := handler.NewSLAHandler(logger, slaService)
+	statusPageService := service.NewStatusPageService(logger, db, monitorService, maintenanceService, slaService)
+	statusPageHandler := handler.NewStatusPageHandler(logger, statusPageService)
+	badgeService := service.NewBadgeService(logger, db, monitorService, maintenanceService, slaService)
+	badgeHandler := handler.NewBadgeHandler(logger, badgeService)
+	incidentHandler := handler.NewIncidentHandler(logger, incidentService)
+	alertPolicyHandler := handler.NewAlertPolicyHandler(logger, alertPolicyService, agentService)
+	alertRuleHandler := handler.NewAlertRuleHandler(logger, alertRuleService)
+	silenceHandler := handler.NewSilenceHandler(logger, silenceService, alertService)
+	escalationHandler := handler.NewEscalationHandler(logger, escalationService)
+	notificationDeliveryHandler := handler.NewNotificationDeliveryHandler(logger, notificationOutboxService)
 	appComponents := &AppComponents{
-		AccountHandler:     accountHandler,
-		AgentHandler:       agentHandler,
-		ApiKeyHandler:      apiKeyHandler,
-		AlertHandler:       alertHandler,
-		PropertyHandler:    propertyHandler,
-		MonitorHandler:     monitorHandler,
-		TamperHandler:      tamperHandler,
-		DNSProviderHandler: dnsProviderHandler,
-		DDNSHandler:        ddnsHandler,
-		SSHLoginHandler:    sshLoginHandler,
-		AgentService:       agentService,
-		TrafficService:     trafficService,
-		MetricService:      metricService,
-		AlertService:       alertService,
-		PropertyService:    propertyService,
-		MonitorService:     monitorService,
-		ApiKeyService:      apiKeyService,
-		TamperService:      tamperService,
-		DDNSService:        ddnsService,
-		SSHLoginService:    sshLoginService,
-		PublicIPService:    publicIPService,
-		WSManager:          manager,
-		VMClient:           vmClient,
+		AccountHandler:              accountHandler,
+		AgentHandler:                agentHandler,
+		ApiKeyHandler:               apiKeyHandler,
+		AlertHandler:                alertHandler,
+		PropertyHandler:             propertyHandler,
+		MonitorHandler:              monitorHandler,
+		TamperHandler:               tamperHandler,
+		DNSProviderHandler:          dnsProviderHandler,
+		DDNSHandler:                 ddnsHandler,
+		SSHLoginHandler:             sshLoginHandler,
+		MaintenanceHandler:          maintenanceHandler,
+		SLAHandler:                  slaHandler,
+		StatusPageHandler:           statusPageHandler,
+		BadgeHandler:                badgeHandler,
+		IncidentHandler:             incidentHandler,
+		AlertPolicyHandler:          alertPolicyHandler,
+		AlertRuleHandler:            alertRuleHandler,
+		SilenceHandler:              silenceHandler,
+		EscalationHandler:           escalationHandler,
+		NotificationDeliveryHandler: notificationDeliveryHandler,
+		AgentService:                agentService,
+		TrafficService:              trafficService,
+		MetricService:               metricService,
+		AlertService:                alertService,
+		PropertyService:             propertyService,
+		MonitorService:              monitorService,
+		ApiKeyService:               apiKeyService,
+		TamperService:               tamperService,
+		DDNSService:                 ddnsService,
+		SSHLoginService:             sshLoginService,
+		PublicIPService:             publicIPService,
+		MaintenanceService:          maintenanceService,
+		SLAService:                  slaService,
+		StatusPageService:           statusPageService,
+		BadgeService:                badgeService,
+		IncidentService:             incidentService,
+		AlertPolicyService:          alertPolicyService,
+		AlertRuleService:            alertRuleService,
+		SilenceService:              silenceService,
+		EscalationService:           escalationService,
+		NotificationOutboxService:   notificationOutboxService,
+		WSManager:                   manager,
+		VMClient:                    vmClient,
 	}
 	return appComponents, nil
 }
@@ -86,28 +128,48 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 
 // AppComponents 应用组件
 type AppComponents struct {
-	AccountHandler     *handler.AccountHandler
-	AgentHandler       *handler.AgentHandler
-	ApiKeyHandler      *handler.ApiKeyHandler
-	AlertHandler       *handler.AlertHandler
-	PropertyHandler    *handler.PropertyHandler
-	MonitorHandler     *handler.MonitorHandler
-	TamperHandler      *handler.TamperHandler
-	DNSProviderHandler *handler.DNSProviderHandler
-	DDNSHandler        *handler.DDNSHandler
-	SSHLoginHandler    *handler.SSHLoginHandler
-
-	AgentService    *service.AgentService
-	TrafficService  *service.TrafficService
-	MetricService   *service.MetricService
-	AlertService    *service.AlertService
-	PropertyService *service.PropertyService
-	MonitorService  *service.MonitorService
-	ApiKeyService   *service.ApiKeyService
-	TamperService   *service.TamperService
-	DDNSService     *service.DDNSService
-	SSHLoginService *service.SSHLoginService
-	PublicIPService *service.PublicIPService
+	AccountHandler              *handler.AccountHandler
+	AgentHandler                *handler.AgentHandler
+	ApiKeyHandler               *handler.ApiKeyHandler
+	AlertHandler                *handler.AlertHandler
+	PropertyHandler             *handler.PropertyHandler
+	MonitorHandler              *handler.MonitorHandler
+	TamperHandler               *handler.TamperHandler
+	DNSProviderHandler          *handler.DNSProviderHandler
+	DDNSHandler                 *handler.DDNSHandler
+	SSHLoginHandler             *handler.SSHLoginHandler
+	MaintenanceHandler          *handler.MaintenanceHandler
+	SLAHandler                  *handler.SLAHandler
+	StatusPageHandler           *handler.StatusPageHandler
+	BadgeHandler                *handler.BadgeHandler
+	IncidentHandler             *handler.IncidentHandler
+	AlertPolicyHandler          *handler.AlertPolicyHandler
+	AlertRuleHandler            *handler.AlertRuleHandler
+	SilenceHandler              *handler.SilenceHandler
+	EscalationHandler           *handler.EscalationHandler
+	NotificationDeliveryHandler *handler.NotificationDeliveryHandler
+
+	AgentService              *service.AgentService
+	TrafficService            *service.TrafficService
+	MetricService             *service.MetricService
+	AlertService              *service.AlertService
+	PropertyService           *service.PropertyService
+	MonitorService            *service.MonitorService
+	ApiKeyService             *service.ApiKeyService
+	TamperService             *service.TamperService
+	DDNSService               *service.DDNSService
+	SSHLoginService           *service.SSHLoginService
+	PublicIPService           *service.PublicIPService
+	MaintenanceService        *service.MaintenanceService
+	SLAService                *service.SLAService
+	StatusPageService         *service.StatusPageService
+	BadgeService              *service.BadgeService
+	IncidentService           *service.IncidentService
+	AlertPolicyService        *service.AlertPolicyService
+	AlertRuleService          *service.AlertRuleService
+	SilenceService            *service.SilenceService
+	EscalationService         *service.EscalationService
+	NotificationOutboxService *service.NotificationOutboxService
 
 	WSManager *websocket.Manager
 	VMClient  *vmclient.VMClient
@@ -73,13 +73,14 @@ type Agent struct {
 	collectorMu      sync.RWMutex
 	collectorManager *collector.Manager
 	metricsBuffer    *metricsBuffer
+	monitorScheduler *monitorScheduler
 	tamperProtector  *tamper.Protector
 	sshMonitor       *sshmonitor.Monitor
 }
 
 // New 创建 Agent 实例
 func New(cfg *config.Config) *Agent {
-	return &Agent{
+	a := &Agent{
 		cfg:              cfg,
 		idMgr:            id.NewManager(),
 		collectorManager: collector.NewManager(cfg),
@@ -87,6 +88,8 @@ func New(cfg *config.Config) *Agent {
 		tamperProtector:  tamper.NewProtector(),
 		sshMonitor:       sshmonitor.NewMonitor(),
 	}
+	a.monitorScheduler = newMonitorScheduler(a)
+	return a
 }
 
 // Start 启动探针服务
@@ -97,6 +100,9 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	go a.metricsLoop(ctx)
 
+	// 启动服务监控本地调度（断线期间继续检测）
+	a.monitorScheduler.Start(ctx)
+
 	// 启动探针主循环
 	b := &backoff.Backoff{
 		Min:    5 * time.Second,
@@ -379,6 +385,17 @@ func (a *Agent) handleMonitorConfig(data json.RawMessage) {
 		return
 	}
 
+	// 完整监控配置：交由本地调度器按各监控项的检测频率执行
+	if payload.Full {
+		for i := range payload.Items {
+			if payload.Items[i].Interval <= 0 {
+				payload.Items[i].Interval = payload.Interval
+			}
+		}
+		a.monitorScheduler.Apply(payload.Items)
+		return
+	}
+
 	if len(payload.Items) == 0 {
 		slog.Info("收到空的服务监控配置，跳过")
 		return
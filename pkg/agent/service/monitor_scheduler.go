@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+	"github.com/dushixiang/pika/pkg/agent/utils"
+)
+
+const (
+	monitorConfigFileName  = "monitor_config.json"
+	defaultMonitorInterval = 60 // 默认检测频率（秒）
+	monitorJitterRatio     = 0.1
+)
+
+// monitorTask 探针本地调度的监控任务
+type monitorTask struct {
+	item   protocol.MonitorItem
+	cancel context.CancelFunc
+}
+
+// monitorScheduler 探针端监控调度器
+// 持有服务端下发的完整监控项集合，按各监控项的检测频率自主执行检测，连接断开期间检测结果写入指标缓存
+type monitorScheduler struct {
+	agent *Agent
+	path  string
+
+	mu    sync.Mutex
+	ctx   context.Context
+	tasks map[string]*monitorTask
+}
+
+func newMonitorScheduler(agent *Agent) *monitorScheduler {
+	return &monitorScheduler{
+		agent: agent,
+		path:  filepath.Join(utils.GetSafeHomeDir(), ".pika", monitorConfigFileName),
+		tasks: make(map[string]*monitorTask),
+	}
+}
+
+// Start 启动调度器，并加载上次保存的监控配置（服务端不可达时也能继续检测）
+func (s *monitorScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	items, err := s.load()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("加载本地监控配置失败", "error", err)
+		}
+		return
+	}
+	if len(items) > 0 {
+		slog.Info("已加载本地监控配置", "count", len(items))
+	}
+	s.apply(items)
+}
+
+// Apply 应用服务端下发的完整监控配置，只重新调度发生变化的监控项
+func (s *monitorScheduler) Apply(items []protocol.MonitorItem) {
+	s.apply(items)
+	if err := s.save(items); err != nil {
+		slog.Warn("保存本地监控配置失败", "error", err)
+	}
+}
+
+func (s *monitorScheduler) apply(items []protocol.MonitorItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx == nil {
+		return
+	}
+
+	latest := make(map[string]protocol.MonitorItem, len(items))
+	for _, item := range items {
+		latest[item.ID] = item
+	}
+
+	var added, updated, removed int
+
+	// 移除已删除或已变更的任务
+	for id, task := range s.tasks {
+		item, ok := latest[id]
+		if ok && reflect.DeepEqual(item, task.item) {
+			continue
+		}
+		task.cancel()
+		delete(s.tasks, id)
+		if ok {
+			updated++
+		} else {
+			removed++
+		}
+	}
+
+	// 启动新增或已变更的任务
+	for id, item := range latest {
+		if _, ok := s.tasks[id]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		s.tasks[id] = &monitorTask{item: item, cancel: cancel}
+		go s.run(ctx, item)
+		added++
+	}
+	added -= updated
+
+	if added > 0 || updated > 0 || removed > 0 {
+		slog.Info("服务监控配置已更新", "total", len(s.tasks), "added", added, "updated", updated, "removed", removed)
+	}
+}
+
+// run 按检测频率循环执行单个监控项
+func (s *monitorScheduler) run(ctx context.Context, item protocol.MonitorItem) {
+	interval := time.Duration(item.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultMonitorInterval * time.Second
+	}
+
+	// 首次执行随机延迟，避免所有监控项在同一时刻检测
+	timer := time.NewTimer(rand.N(interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.check(item)
+			timer.Reset(withJitter(interval))
+		}
+	}
+}
+
+// check 执行一次检测并上报，连接不可用时写入指标缓存
+func (s *monitorScheduler) check(item protocol.MonitorItem) {
+	manager := s.agent.getCollectorManager()
+	if manager == nil {
+		return
+	}
+
+	writer := newMetricsWriter(s.agent.getActiveConn(), s.agent.metricsBuffer)
+	if err := manager.CollectAndSendMonitor(writer, []protocol.MonitorItem{item}); err != nil {
+		slog.Warn("监控检测结果上报失败", "id", item.ID, "error", err)
+	}
+}
+
+// withJitter 为检测间隔增加 ±10% 的随机抖动
+func withJitter(interval time.Duration) time.Duration {
+	jitter := time.Duration(float64(interval) * monitorJitterRatio)
+	if jitter <= 0 {
+		return interval
+	}
+	return interval - jitter + rand.N(2*jitter)
+}
+
+func (s *monitorScheduler) load() ([]protocol.MonitorItem, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var items []protocol.MonitorItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("解析本地监控配置失败: %w", err)
+	}
+	return items, nil
+}
+
+func (s *monitorScheduler) save(items []protocol.MonitorItem) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建监控配置目录失败: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/protocol"
+)
+
+func newTestMonitorScheduler(t *testing.T) *monitorScheduler {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	s := newMonitorScheduler(&Agent{})
+	s.path = filepath.Join(t.TempDir(), monitorConfigFileName)
+	s.ctx = ctx
+	return s
+}
+
+func TestMonitorSchedulerApply(t *testing.T) {
+	s := newTestMonitorScheduler(t)
+	httpItem := protocol.MonitorItem{ID: "m1", Type: "http", Target: "https://example.com", Interval: 3600}
+	tcpItem := protocol.MonitorItem{ID: "m2", Type: "tcp", Target: "example.com:443", Interval: 3600}
+
+	s.apply([]protocol.MonitorItem{httpItem, tcpItem})
+	if len(s.tasks) != 2 {
+		t.Fatalf("任务数 = %d, want 2", len(s.tasks))
+	}
+	first := s.tasks["m1"]
+
+	// 记录被停止的任务
+	canceled := map[string]bool{}
+	for id, task := range s.tasks {
+		cancel := task.cancel
+		task.cancel = func() {
+			canceled[id] = true
+			cancel()
+		}
+	}
+
+	// 配置未变化的任务不重新调度，变化的任务重新调度，删除的任务停止
+	changed := httpItem
+	changed.Interval = 1800
+	s.apply([]protocol.MonitorItem{changed})
+
+	if len(s.tasks) != 1 {
+		t.Fatalf("任务数 = %d, want 1", len(s.tasks))
+	}
+	if s.tasks["m1"] == first {
+		t.Error("配置变化的任务应重新调度")
+	}
+	if s.tasks["m1"].item.Interval != 1800 {
+		t.Errorf("Interval = %d, want 1800", s.tasks["m1"].item.Interval)
+	}
+	current := s.tasks["m1"]
+	s.apply([]protocol.MonitorItem{changed})
+	if s.tasks["m1"] != current {
+		t.Error("配置未变化的任务不应重新调度")
+	}
+
+	if !canceled["m1"] || !canceled["m2"] {
+		t.Errorf("被替换和删除的任务应停止: %v", canceled)
+	}
+}
+
+func TestMonitorSchedulerSaveLoad(t *testing.T) {
+	s := newTestMonitorScheduler(t)
+	items := []protocol.MonitorItem{
+		{ID: "m1", Type: "http", Target: "https://example.com", Interval: 30},
+		{ID: "m2", Type: "icmp", Target: "1.1.1.1"},
+	}
+	s.Apply(items)
+
+	loaded, err := s.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].ID != "m1" || loaded[0].Interval != 30 || loaded[1].Target != "1.1.1.1" {
+		t.Errorf("load() = %+v", loaded)
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	interval := 60 * time.Second
+	for range 1000 {
+		got := withJitter(interval)
+		if got < 54*time.Second || got >= 66*time.Second {
+			t.Fatalf("withJitter(%v) = %v，超出 ±10%%", interval, got)
+		}
+	}
+	if got := withJitter(0); got != 0 {
+		t.Errorf("withJitter(0) = %v", got)
+	}
+}
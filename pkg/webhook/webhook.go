@@ -0,0 +1,180 @@
+// Package webhook 提供 Pika Webhook 请求的签名和校验，接收方可以直接引用此包校验请求来源。
+//
+// 配置了签名密钥的 Webhook 请求会携带以下请求头：
+//
+//	X-Pika-Timestamp: 发送时间（Unix 秒）
+//	X-Pika-Signature: sha256=<hex>，HMAC-SHA256(secret, timestamp + "." + body)
+//	X-Pika-Delivery:  投递ID，每次请求唯一（包括失败后的重试）
+//
+// 接收方示例：
+//
+//	verifier := webhook.NewVerifier(secret)
+//	http.HandleFunc("/pika", func(w http.ResponseWriter, r *http.Request) {
+//		body, err := verifier.VerifyRequest(r)
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusUnauthorized)
+//			return
+//		}
+//		// 处理 body
+//	})
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 签名相关的请求头
+const (
+	HeaderTimestamp = "X-Pika-Timestamp"
+	HeaderSignature = "X-Pika-Signature"
+	HeaderDelivery  = "X-Pika-Delivery"
+)
+
+// signaturePrefix 签名值的前缀，标明签名算法
+const signaturePrefix = "sha256="
+
+// DefaultTolerance 默认允许的时间偏差，超出时视为重放请求
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrMissingHeader    = errors.New("webhook: 缺少签名请求头")
+	ErrInvalidTimestamp = errors.New("webhook: 时间戳格式错误")
+	ErrExpired          = errors.New("webhook: 时间戳超出允许范围")
+	ErrInvalidSignature = errors.New("webhook: 签名不匹配")
+	ErrReplayed         = errors.New("webhook: 重复的投递")
+)
+
+// Sign 计算签名，返回 X-Pika-Signature 请求头的值
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Headers 生成签名请求头
+func Headers(secret, deliveryID string, timestamp int64, body []byte) map[string]string {
+	return map[string]string{
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: Sign(secret, timestamp, body),
+		HeaderDelivery:  deliveryID,
+	}
+}
+
+// Verify 校验签名和时间戳，不记录投递ID，tolerance 小于等于0时使用默认值
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	return verify(secret, header, body, tolerance, time.Now())
+}
+
+func verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestampValue := header.Get(HeaderTimestamp)
+	signature := header.Get(HeaderSignature)
+	if timestampValue == "" || signature == "" {
+		return ErrMissingHeader
+	}
+
+	timestamp, err := strconv.ParseInt(timestampValue, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if diff := now.Sub(time.Unix(timestamp, 0)); diff > tolerance || diff < -tolerance {
+		return ErrExpired
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Verifier 校验签名并记录允许时间范围内已处理的投递ID，拒绝重放的请求
+type Verifier struct {
+	Secret    string
+	Tolerance time.Duration // 允许的时间偏差，默认 5 分钟
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+	now  func() time.Time
+}
+
+// NewVerifier 创建校验器
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{
+		Secret:    secret,
+		Tolerance: DefaultTolerance,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Verify 校验签名、时间戳和投递ID，同一投递ID在允许的时间范围内只会校验通过一次
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+	if err := verify(v.Secret, header, body, v.Tolerance, now); err != nil {
+		return err
+	}
+
+	deliveryID := header.Get(HeaderDelivery)
+	if deliveryID == "" {
+		return ErrMissingHeader
+	}
+
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.seen == nil {
+		v.seen = make(map[string]time.Time)
+	}
+	// 清理超出时间范围的投递ID，超出范围的请求会因时间戳校验失败
+	for id, seenAt := range v.seen {
+		if now.Sub(seenAt) > 2*tolerance {
+			delete(v.seen, id)
+		}
+	}
+	if _, ok := v.seen[deliveryID]; ok {
+		return ErrReplayed
+	}
+	v.seen[deliveryID] = now
+	return nil
+}
+
+// VerifyRequest 读取并校验请求，返回请求体，请求体可以继续从 r.Body 读取
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: 读取请求体失败: %w", err)
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := v.Verify(r.Header, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
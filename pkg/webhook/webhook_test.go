@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signedHeader(secret, deliveryID string, timestamp int64, body []byte) http.Header {
+	header := http.Header{}
+	for k, v := range Headers(secret, deliveryID, timestamp, body) {
+		header.Set(k, v)
+	}
+	return header
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"alertType":"cpu"}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		want   error
+	}{
+		{"签名正确", signedHeader("secret", "d1", now, body), body, nil},
+		{"密钥错误", signedHeader("other", "d1", now, body), body, ErrInvalidSignature},
+		{"请求体被篡改", signedHeader("secret", "d1", now, body), []byte(`{"alertType":"memory"}`), ErrInvalidSignature},
+		{"时间戳过期", signedHeader("secret", "d1", now-600, body), body, ErrExpired},
+		{"时间戳超前", signedHeader("secret", "d1", now+600, body), body, ErrExpired},
+		{"缺少请求头", http.Header{}, body, ErrMissingHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("secret", tt.header, tt.body, 0)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTamperedTimestamp(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Now().Unix()
+	header := signedHeader("secret", "d1", now, body)
+	// 修改时间戳后签名不再匹配
+	header.Set(HeaderTimestamp, "1")
+	if err := Verify("secret", header, body, 100*365*24*time.Hour); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifierRejectsReplay(t *testing.T) {
+	body := []byte(`{}`)
+	verifier := NewVerifier("secret")
+	header := signedHeader("secret", "delivery-1", time.Now().Unix(), body)
+
+	if err := verifier.Verify(header, body); err != nil {
+		t.Fatalf("首次校验失败: %v", err)
+	}
+	if err := verifier.Verify(header, body); !errors.Is(err, ErrReplayed) {
+		t.Errorf("重复请求应返回 ErrReplayed，实际 %v", err)
+	}
+
+	other := signedHeader("secret", "delivery-2", time.Now().Unix(), body)
+	if err := verifier.Verify(other, body); err != nil {
+		t.Errorf("不同投递ID应校验通过: %v", err)
+	}
+}
+
+func TestVerifierForgetsExpiredDeliveries(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Now()
+	verifier := NewVerifier("secret")
+	verifier.now = func() time.Time { return now }
+
+	if err := verifier.Verify(signedHeader("secret", "old", now.Unix(), body), body); err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if err := verifier.Verify(signedHeader("secret", "new", now.Unix(), body), body); err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+	if _, ok := verifier.seen["old"]; ok {
+		t.Error("超出时间范围的投递ID应被清理")
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := []byte(`{"message":"hello"}`)
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	for k, v := range Headers("secret", "d1", time.Now().Unix(), body) {
+		req.Header.Set(k, v)
+	}
+
+	got, err := NewVerifier("secret").VerifyRequest(req)
+	if err != nil {
+		t.Fatalf("VerifyRequest() 失败: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("返回的请求体 = %s", got)
+	}
+	// 请求体可以再次读取
+	var again bytes.Buffer
+	_, _ = again.ReadFrom(req.Body)
+	if !bytes.Equal(again.Bytes(), body) {
+		t.Errorf("r.Body = %s", again.String())
+	}
+}
@@ -11,6 +11,9 @@ import {
 import {getErrorMessage} from '@/lib/utils';
 import NotificationCustomHelp from "@admin/pages/Settings/NotificationCustomHelp.tsx";
 
+// 本页面表单管理的渠道类型
+const formChannelTypes: NotificationChannel['type'][] = ['dingtalk', 'wecom', 'wecomApp', 'feishu', 'telegram', 'email', 'webhook'];
+
 const NotificationChannels = () => {
     const [form] = Form.useForm();
     const {message: messageApi} = App.useApp();
@@ -120,6 +123,12 @@ const NotificationChannels = () => {
         }
     }, [channels, form]);
 
+    // 合并已保存的渠道配置，保留表单中没有的字段（消息模板、发送时段、Webhook 签名密钥、邮件抄送等）
+    const mergeChannel = (type: NotificationChannel['type'], enabled: boolean, config: Record<string, any>): NotificationChannel => {
+        const saved = channels.find(channel => channel.type === type);
+        return {...saved, type, enabled, config: {...saved?.config, ...config}};
+    };
+
     // 将表单值转换回渠道数组
     const handleSave = async () => {
         try {
@@ -128,80 +137,56 @@ const NotificationChannels = () => {
 
             // 钉钉
             if (values.dingtalkEnabled || values.dingtalkSecretKey) {
-                newChannels.push({
-                    type: 'dingtalk',
-                    enabled: values.dingtalkEnabled || false,
-                    config: {
-                        secretKey: values.dingtalkSecretKey || '',
-                        signSecret: values.dingtalkSignSecret || '',
-                    },
-                });
+                newChannels.push(mergeChannel('dingtalk', values.dingtalkEnabled || false, {
+                    secretKey: values.dingtalkSecretKey || '',
+                    signSecret: values.dingtalkSignSecret || '',
+                }));
             }
 
             // 企业微信
             if (values.wecomEnabled || values.wecomSecretKey) {
-                newChannels.push({
-                    type: 'wecom',
-                    enabled: values.wecomEnabled || false,
-                    config: {
-                        secretKey: values.wecomSecretKey || '',
-                    },
-                });
+                newChannels.push(mergeChannel('wecom', values.wecomEnabled || false, {
+                    secretKey: values.wecomSecretKey || '',
+                }));
             }
 
             // 企业微信应用
             if (values.wecomAppEnabled || values.wecomAppOrigin) {
-                newChannels.push({
-                    type: 'wecomApp',
-                    enabled: values.wecomAppEnabled || false,
-                    config: {
-                        origin: values.wecomAppOrigin || '',
-                        corpId: values.wecomAppCorpId || '',
-                        corpSecret: values.wecomAppCorpSecret || '',
-                        agentId: values.wecomAppAgentId,
-                        toUser: values.wecomAppToUser || '',
-                    },
-                });
+                newChannels.push(mergeChannel('wecomApp', values.wecomAppEnabled || false, {
+                    origin: values.wecomAppOrigin || '',
+                    corpId: values.wecomAppCorpId || '',
+                    corpSecret: values.wecomAppCorpSecret || '',
+                    agentId: values.wecomAppAgentId,
+                    toUser: values.wecomAppToUser || '',
+                }));
             }
 
             // 飞书
             if (values.feishuEnabled || values.feishuSecretKey) {
-                newChannels.push({
-                    type: 'feishu',
-                    enabled: values.feishuEnabled || false,
-                    config: {
-                        secretKey: values.feishuSecretKey || '',
-                        signSecret: values.feishuSignSecret || '',
-                    },
-                });
+                newChannels.push(mergeChannel('feishu', values.feishuEnabled || false, {
+                    secretKey: values.feishuSecretKey || '',
+                    signSecret: values.feishuSignSecret || '',
+                }));
             }
 
             // Telegram
             if (values.telegramEnabled || values.telegramBotToken) {
-                newChannels.push({
-                    type: 'telegram',
-                    enabled: values.telegramEnabled || false,
-                    config: {
-                        botToken: values.telegramBotToken || '',
-                        chatID: values.telegramChatID || '',
-                    },
-                });
+                newChannels.push(mergeChannel('telegram', values.telegramEnabled || false, {
+                    botToken: values.telegramBotToken || '',
+                    chatID: values.telegramChatID || '',
+                }));
             }
 
             // 邮件
             if (values.emailEnabled || values.emailSmtpHost) {
-                newChannels.push({
-                    type: 'email',
-                    enabled: values.emailEnabled || false,
-                    config: {
-                        smtpHost: values.emailSmtpHost || '',
-                        smtpPort: values.emailSmtpPort || 587,
-                        fromEmail: values.emailFromEmail || '',
-                        password: values.emailPassword || '',
-                        toEmail: values.emailToEmail || '',
-                        subject: values.emailSubject || 'Pika 告警通知',
-                    },
-                });
+                newChannels.push(mergeChannel('email', values.emailEnabled || false, {
+                    smtpHost: values.emailSmtpHost || '',
+                    smtpPort: values.emailSmtpPort || 587,
+                    fromEmail: values.emailFromEmail || '',
+                    password: values.emailPassword || '',
+                    toEmail: values.emailToEmail || '',
+                    subject: values.emailSubject || 'Pika 告警通知',
+                }));
             }
 
             // 自定义Webhook
@@ -216,18 +201,19 @@ const NotificationChannels = () => {
                     });
                 }
 
-                newChannels.push({
-                    type: 'webhook',
-                    enabled: values.webhookEnabled || false,
-                    config: {
-                        url: values.webhookUrl || '',
-                        method: values.webhookMethod || 'POST',
-                        customBody: values.webhookCustomBody || '',
-                        headers: Object.keys(headersObj).length > 0 ? headersObj : undefined,
-                    },
-                });
+                newChannels.push(mergeChannel('webhook', values.webhookEnabled || false, {
+                    url: values.webhookUrl || '',
+                    method: values.webhookMethod || 'POST',
+                    customBody: values.webhookCustomBody || '',
+                    headers: Object.keys(headersObj).length > 0 ? headersObj : undefined,
+                }));
             }
 
+            // 表单未管理的渠道（Slack、ntfy、PagerDuty 等）原样保留
+            channels
+                .filter(channel => !formChannelTypes.includes(channel.type))
+                .forEach(channel => newChannels.push(channel));
+
             saveMutation.mutate(newChannels);
         } catch (error) {
             // 表单验证失败
@@ -54,9 +54,19 @@ const PROPERTY_ID_NOTIFICATION_CHANNELS = 'notification_channels';
 
 // 通知渠道配置（通过 type 标识，不再使用独立ID）
 export interface NotificationChannel {
-    type: 'dingtalk' | 'wecom' | 'wecomApp' | 'feishu' | 'email' | 'webhook' | 'telegram'; // 渠道类型，作为唯一标识
+    type: 'dingtalk' | 'wecom' | 'wecomApp' | 'feishu' | 'email' | 'webhook' | 'telegram' | 'slack' | 'discord' | 'matrix'
+        | 'ntfy' | 'gotify' | 'bark' | 'serverchan' | 'pushplus' | 'pagerduty' | 'opsgenie'; // 渠道类型，作为唯一标识
     enabled: boolean; // 是否启用
     config: Record<string, any>; // JSON配置，根据type不同而不同
+    templates?: Record<string, string>; // 消息模板，键为事件类型
+    schedule?: NotificationChannelSchedule; // 发送时段
+}
+
+export interface NotificationChannelSchedule {
+    enabled: boolean;
+    timezone: string;
+    windows: { weekdays: number[]; startTime: string; endTime: string }[];
+    outside: 'drop' | 'delay' | 'critical_only';
 }
 
 // 获取通知渠道列表
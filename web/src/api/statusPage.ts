@@ -0,0 +1,14 @@
+import {get} from './request';
+import type {StatusPageHistory, StatusPageView} from '../types';
+
+// 公开接口 - 获取状态页当前状态，未指定 slug 时按访问域名匹配
+export const getPublicStatusPage = (slug?: string) => {
+    const path = slug ? `/status-pages/${encodeURIComponent(slug)}` : '/status-page';
+    return get<StatusPageView>(path);
+};
+
+// 公开接口 - 获取状态页历史可用性
+export const getPublicStatusPageHistory = (slug?: string) => {
+    const path = slug ? `/status-pages/${encodeURIComponent(slug)}/history` : '/status-page/history';
+    return get<StatusPageHistory>(path);
+};
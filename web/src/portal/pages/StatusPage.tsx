@@ -0,0 +1,180 @@
+import {useParams} from 'react-router-dom';
+import {useQuery} from '@tanstack/react-query';
+import {getPublicStatusPage, getPublicStatusPageHistory} from '@/api/statusPage.ts';
+import type {StatusPageDailyUptime, StatusPageHistory, StatusPageIncident, StatusPageItem, StatusPageView} from '@/types';
+import {cn} from '@/lib/utils.ts';
+import {EmptyState} from '@portal/components/EmptyState.tsx';
+import {LoadingSpinner} from '@portal/components/LoadingSpinner.tsx';
+
+const pageStatusMeta: Record<StatusPageView['status'], { label: string; className: string }> = {
+    operational: {label: '所有服务运行正常', className: 'bg-emerald-500/10 text-emerald-500 border-emerald-500/60'},
+    maintenance: {label: '部分服务维护中', className: 'bg-blue-500/10 text-blue-500 border-blue-500/60'},
+    partial_outage: {label: '部分服务异常', className: 'bg-amber-500/10 text-amber-500 border-amber-500/60'},
+    major_outage: {label: '服务严重异常', className: 'bg-rose-500/10 text-rose-500 border-rose-500/60'},
+};
+
+const itemStatusMeta: Record<StatusPageItem['status'], { label: string; className: string }> = {
+    up: {label: '正常', className: 'text-emerald-500'},
+    down: {label: '异常', className: 'text-rose-500'},
+    maintenance: {label: '维护中', className: 'text-blue-500'},
+    unknown: {label: '未知', className: 'text-slate-400'},
+};
+
+const incidentStatusLabels: Record<string, string> = {
+    investigating: '调查中',
+    identified: '已定位',
+    monitoring: '观察中',
+    resolved: '已解决',
+};
+
+const formatTime = (timestamp: number) => new Date(timestamp).toLocaleString('zh-CN', {hour12: false});
+
+const uptimeColor = (day: StatusPageDailyUptime) => {
+    if (!day.hasData) return 'bg-slate-200 dark:bg-slate-700';
+    if (day.uptimePercent >= 99.9) return 'bg-emerald-500';
+    if (day.uptimePercent >= 99) return 'bg-lime-500';
+    if (day.uptimePercent >= 95) return 'bg-amber-500';
+    return 'bg-rose-500';
+};
+
+const UptimeBar = ({history}: { history: StatusPageDailyUptime[] }) => (
+    <div className="mt-2 flex h-6 gap-[2px]">
+        {history.map(day => (
+            <div
+                key={day.date}
+                className={cn('flex-1 rounded-sm', uptimeColor(day))}
+                title={day.hasData ? `${day.date} 可用率 ${day.uptimePercent.toFixed(2)}%` : `${day.date} 无数据`}
+            />
+        ))}
+    </div>
+);
+
+const IncidentCard = ({incident}: { incident: StatusPageIncident }) => (
+    <div className="rounded-lg border border-slate-200 dark:border-cyan-900/50 bg-white/80 dark:bg-black/40 p-4">
+        <div className="flex items-center justify-between gap-2">
+            <h3 className="font-medium">{incident.title}</h3>
+            <span className="text-xs text-slate-500 dark:text-cyan-500">
+                {incidentStatusLabels[incident.status] || incident.status}
+            </span>
+        </div>
+        <div className="mt-3 space-y-2">
+            {incident.updates?.map(update => (
+                <div key={update.id} className="text-sm">
+                    <span className="font-medium">{incidentStatusLabels[update.status] || update.status}</span>
+                    <span className="ml-2 text-xs text-slate-500">{formatTime(update.createdAt)}</span>
+                    <p className="mt-1 whitespace-pre-wrap text-slate-600 dark:text-slate-300">{update.message}</p>
+                </div>
+            ))}
+        </div>
+    </div>
+);
+
+/**
+ * 公开状态页
+ * 通过 /status/:slug 访问，自定义域名通过 /status 访问
+ */
+const StatusPage = () => {
+    const {slug} = useParams<{ slug: string }>();
+
+    const {data: page, isLoading} = useQuery<StatusPageView>({
+        queryKey: ['publicStatusPage', slug],
+        queryFn: async () => {
+            const response = await getPublicStatusPage(slug);
+            return response.data;
+        },
+        refetchInterval: 60000,
+    });
+
+    const {data: history} = useQuery<StatusPageHistory>({
+        queryKey: ['publicStatusPageHistory', slug],
+        queryFn: async () => {
+            const response = await getPublicStatusPageHistory(slug);
+            return response.data;
+        },
+        refetchInterval: 300000,
+    });
+
+    if (isLoading) {
+        return <LoadingSpinner/>;
+    }
+
+    if (!page) {
+        return <EmptyState message="状态页不存在"/>;
+    }
+
+    // 历史可用性按分组和条目匹配到当前状态
+    const historyOf = (sectionIndex: number, item: StatusPageItem) =>
+        history?.sections[sectionIndex]?.items.find(h => h.type === item.type && h.id === item.id);
+    const statusMeta = pageStatusMeta[page.status] || pageStatusMeta.operational;
+
+    return (
+        <div className="mx-auto flex max-w-4xl flex-col gap-6 px-4 py-8 sm:px-6">
+            <div>
+                <h1 className="text-2xl font-bold">{page.title}</h1>
+                {page.description && (
+                    <p className="mt-2 text-sm text-slate-600 dark:text-slate-400">{page.description}</p>
+                )}
+            </div>
+
+            <div className={cn('rounded-lg border px-4 py-3 font-medium', statusMeta.className)}>
+                {statusMeta.label}
+            </div>
+
+            {page.maintenance?.map(window => (
+                <div key={window.id}
+                     className="rounded-lg border border-blue-500/60 bg-blue-500/10 px-4 py-3 text-sm">
+                    <div className="font-medium">{window.name}</div>
+                    <div className="mt-1 text-slate-600 dark:text-slate-300">
+                        {formatTime(window.activeStart)} ~ {formatTime(window.activeEnd)}
+                    </div>
+                    {window.description && <p className="mt-1">{window.description}</p>}
+                </div>
+            ))}
+
+            {(page.incidents?.length ?? 0) > 0 && (
+                <section className="space-y-3">
+                    <h2 className="text-lg font-semibold">事件</h2>
+                    {page.incidents.map(incident => <IncidentCard key={incident.id} incident={incident}/>)}
+                </section>
+            )}
+
+            {page.sections?.map((section, sectionIndex) => (
+                <section key={`${section.name}-${sectionIndex}`}
+                         className="rounded-lg border border-slate-200 dark:border-cyan-900/50 bg-white/80 dark:bg-black/40">
+                    <h2 className="border-b border-slate-200 dark:border-cyan-900/50 px-4 py-3 font-semibold">
+                        {section.name}
+                    </h2>
+                    <div className="divide-y divide-slate-200 dark:divide-cyan-900/50">
+                        {section.items.map(item => {
+                            const itemHistory = historyOf(sectionIndex, item);
+                            const itemMeta = itemStatusMeta[item.status] || itemStatusMeta.unknown;
+                            return (
+                                <div key={`${item.type}-${item.id}`} className="px-4 py-3">
+                                    <div className="flex items-center justify-between gap-2">
+                                        <div className="min-w-0">
+                                            <div className="truncate font-medium">{item.name}</div>
+                                            {item.target && (
+                                                <div className="truncate text-xs text-slate-500">{item.target}</div>
+                                            )}
+                                        </div>
+                                        <div className="flex shrink-0 items-center gap-3 text-sm">
+                                            {itemHistory && (
+                                                <span className="text-slate-500">{itemHistory.uptimePercent.toFixed(2)}%</span>
+                                            )}
+                                            <span className={itemMeta.className}>{itemMeta.label}</span>
+                                        </div>
+                                    </div>
+                                    {itemHistory?.history && <UptimeBar history={itemHistory.history}/>}
+                                </div>
+                            );
+                        })}
+                    </div>
+                </section>
+            ))}
+
+            <p className="text-center text-xs text-slate-500">更新于 {formatTime(page.updatedAt)}</p>
+        </div>
+    );
+};
+
+export default StatusPage;
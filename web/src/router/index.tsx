@@ -17,6 +17,7 @@ const ServerListPage = lazy(() => import('@portal/pages/ServerList.tsx'));
 const ServerDetailPage = lazy(() => import('@portal/pages/ServerDetail.tsx'));
 const PublicMonitorListPage = lazy(() => import('@portal/pages/MonitorList.tsx'));
 const PublicMonitorDetailPage = lazy(() => import('@portal/pages/MonitorDetail.tsx'));
+const PublicStatusPage = lazy(() => import('@portal/pages/StatusPage.tsx'));
 const MonitorListPage = lazy(() => import('@admin/pages/Monitors/MonitorList'));
 const DDNSPage = lazy(() => import('@admin/pages/DDNS'));
 const AlertRecordListPage = lazy(() => import('@admin/pages/AlertRecords'));
@@ -67,6 +68,14 @@ const router = createBrowserRouter([
                 path: '/monitors/:id',
                 element: lazyLoad(PublicMonitorDetailPage),
             },
+            {
+                path: '/status',
+                element: lazyLoad(PublicStatusPage),
+            },
+            {
+                path: '/status/:slug',
+                element: lazyLoad(PublicStatusPage),
+            },
         ],
     },
     // 管理员页面 - 需要登录
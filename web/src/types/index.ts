@@ -560,5 +560,76 @@ export interface UpdateSSHLoginConfigRequest {
     ipWhitelist?: string[];  // IP白名单，白名单中的IP只记录不发送通知
 }
 
+// 状态页相关
+export interface StatusPageDailyUptime {
+    date: string;
+    uptimePercent: number;
+    downSeconds: number;
+    maintenanceSeconds: number;
+    outageCount: number;
+    hasData: boolean;
+}
+
+export interface StatusPageItem {
+    type: 'monitor' | 'agent';
+    id: string;
+    name: string;
+    target?: string;
+    status: 'up' | 'down' | 'unknown' | 'maintenance';
+    responseTime?: number;
+    uptimePercent: number;
+    history?: StatusPageDailyUptime[];
+}
+
+export interface StatusPageSectionView {
+    name: string;
+    items: StatusPageItem[];
+}
+
+export interface StatusPageIncidentUpdate {
+    id: string;
+    incidentId: string;
+    status: string;
+    message: string;
+    createdAt: number;
+}
+
+export interface StatusPageIncident {
+    id: string;
+    statusPageId: string;
+    title: string;
+    status: 'investigating' | 'identified' | 'monitoring' | 'resolved';
+    resolvedAt: number;
+    createdAt: number;
+    updatedAt: number;
+    updates: StatusPageIncidentUpdate[];
+}
+
+export interface StatusPageMaintenance {
+    id: string;
+    name: string;
+    description: string;
+    activeStart: number;
+    activeEnd: number;
+}
+
+export interface StatusPageView {
+    id: string;
+    slug: string;
+    title: string;
+    description: string;
+    status: 'operational' | 'maintenance' | 'partial_outage' | 'major_outage';
+    sections: StatusPageSectionView[];
+    incidents: StatusPageIncident[];
+    maintenance: StatusPageMaintenance[];
+    updatedAt: number;
+}
+
+export interface StatusPageHistory {
+    days: number;
+    sections: StatusPageSectionView[];
+    updatedAt: number;
+}
+
 // 导出 DDNS 相关类型
 export * from './ddns';